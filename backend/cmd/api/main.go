@@ -46,6 +46,8 @@ import (
 	"github.com/Himadryy/hidden-depths-backend/internal/ws"
 	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
 	"github.com/Himadryy/hidden-depths-backend/pkg/response"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -89,10 +91,22 @@ func main() {
 	}
 	defer cache.Close()
 
+	// Booking names and emails are encrypted at rest when keys are configured
+	if err := pii.Init(cfg.PIIKeys, cfg.PIIIndexKey); err != nil {
+		logger.Fatal("Invalid PII encryption keys", zap.Error(err))
+	}
+	if !pii.Enabled() && cfg.Environment == "production" {
+		logger.Warn("PII_ENCRYPTION_KEYS is not set; booking names and emails are stored in plaintext")
+	}
+
 	// 5. Start Background Scheduler (Email Reminders & Cleanup)
 	c := cron.New()
 	c.AddFunc("0 * * * *", services.CheckAndSendReminders)
 	c.AddFunc("*/5 * * * *", services.CleanupAbandonedBookings) // Every 5 min — faster self-healing
+	c.AddFunc("30 3 * * *", services.CleanupExpiredRevocations) // Daily — drop revocations of expired tokens
+	c.AddFunc("15 * * * *", services.SyncLedger)                // Hourly — post payments and refunds the hooks missed
+	// Daily — delete or anonymise old data per RETENTION (one instance runs it)
+	c.AddFunc("0 4 * * *", services.RetentionJob(cfg.Retention, cfg.RetentionDryRun))
 	c.Start()
 	logger.Info("Scheduler started")
 
@@ -104,6 +118,25 @@ func main() {
 		SearchWindowDays: cfg.BookingSearchWindowDays,
 		MaxBookableDates: cfg.BookingMaxBookableDates,
 		TimeSlots:        cfg.BookingTimeSlots,
+		SessionLength:    cfg.BookingSessionLength,
+		LateCancelWindow: cfg.BookingLateCancelWindow,
+		Holds: handlers.HoldLimits{
+			MaxActivePerUser:   cfg.BookingMaxActiveHolds,
+			MaxActivePerIP:     cfg.BookingMaxActiveHoldsPerIP,
+			MaxUpcomingPerUser: cfg.BookingMaxUpcomingPerUser,
+			AbandonLimit:       cfg.BookingAbandonLimit,
+			AbandonWindow:      cfg.BookingAbandonWindow,
+			Cooldown:           cfg.BookingHoldCooldown,
+		},
+	})
+
+	services.SetCrisisConfig(services.CrisisConfig{
+		AlertEmails:   cfg.CrisisAlertEmails,
+		DefaultRegion: cfg.CrisisDefaultRegion,
+	})
+	services.SetLedgerConfig(services.LedgerConfig{
+		Accounts: cfg.LedgerAccounts,
+		Timezone: cfg.LedgerTimezone,
 	})
 
 	// 7. Initialize WebSocket Hub (with origin validation)
@@ -111,38 +144,70 @@ func main() {
 	go hub.Run()
 	logger.Info("WebSocket Hub started")
 
-	// 8. Rate Limiters — protect critical endpoints (uses Redis when available)
-	globalLimiter := middleware.NewNamedRateLimiter("global", 200, time.Minute)  // 200 req/min general
-	bookingLimiter := middleware.NewNamedRateLimiter("booking", 10, time.Minute) // 10 req/min for booking creation
-	paymentLimiter := middleware.NewNamedRateLimiter("payment", 5, time.Minute)  // 5 req/min for payment verification
+	// 8. Rate Limiters — named policies from RATE_LIMITS (uses Redis when available).
+	// Mounted before auth they count per client IP, after auth per user or API key.
+	limit := func(name string) func(http.Handler) http.Handler {
+		policy := cfg.RateLimits[name]
+		return middleware.NewRateLimiter(middleware.RateLimitPolicy{
+			Name:   name,
+			Limit:  policy.Limit,
+			Period: policy.Period,
+		}).Handler
+	}
+
+	// Shared so every route group uses one JWKS cache
+	requireAuth := middleware.AuthMiddleware(middleware.AuthConfig{
+		JWTSecret:           cfg.JWTSecret,
+		SupabaseAnonKey:     cfg.SupabaseAnonKey,
+		SupabaseURL:         cfg.SupabaseURL,
+		Audience:            cfg.JWTAudience,
+		Issuer:              cfg.JWTIssuer,
+		JWKSURL:             cfg.JWKSURL,
+		JWKSRefreshInterval: cfg.JWKSRefreshInterval,
+		Revoked:             services.IsTokenRevoked,
+		Blocked:             services.IsUserBlocked,
+	})
+	apiKeyAuth := middleware.APIKeyMiddleware(services.AuthenticateAPIKey, services.ErrInvalidAPIKey)
 
 	// 9. Setup Router & Middleware
 	r := chi.NewRouter()
 
 	r.Use(chiMiddleware.RequestID)
 	r.Use(middleware.RequestIDResponse) // Propagate request ID to response headers
-	r.Use(chiMiddleware.RealIP)
+	r.Use(middleware.Locale)            // Negotiate response language (?lang= / Accept-Language)
+	r.Use(middleware.RealIP(cfg.TrustedProxies))
 	r.Use(chiMiddleware.Logger)
 	r.Use(middleware.SentryRecovery(logger.Log)) // Sentry panic capture (before chi Recoverer)
 	r.Use(chiMiddleware.Recoverer)
-	r.Use(chiMiddleware.Timeout(60 * time.Second))
 	r.Use(middleware.SecurityHeaders)   // Security headers (CSP, HSTS, X-Frame-Options, etc.)
 	r.Use(middleware.PrometheusMetrics) // Prometheus metrics collection
-	r.Use(globalLimiter.Handler)
+	r.Use(limit("global"))
 
 	// CORS Setup
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Request-Id", "X-Requested-With"},
-		ExposedHeaders:   []string{"Link", "X-Request-Id"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Request-Id", "X-Requested-With", "Accept-Language", "X-Captcha-Token"},
+		ExposedHeaders:   []string{"Link", "X-Request-Id", "Content-Language", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
 	r.Use(corsHandler.Handler)
 
 	// 10. Define Routes
-	r.Route("/api", func(r chi.Router) {
+	// Requests time out after 60 seconds, except admin exports, which stream
+	// for up to their own limit and are mounted outside /api below.
+	requestTimeout := chiMiddleware.Timeout(60 * time.Second)
+
+	// Admin Portal (user token or scoped API key, then per-route permissions)
+	adminMiddleware := chi.Middlewares{
+		middleware.UserOrAPIKey(requireAuth, apiKeyAuth),
+		limit("user"),
+		middleware.AdminMiddleware(services.NewRoleLookup(cfg.AdminEmails, auditService)),
+		middleware.UserLocale(services.PreferredLocale),
+	}
+
+	r.With(requestTimeout).Route("/api", func(r chi.Router) {
 		// Health endpoints at /api level (not versioned - for infrastructure)
 		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 			response.JSON(w, http.StatusOK, map[string]string{"status": "ok"}, "Healthy")
@@ -162,51 +227,253 @@ func main() {
 				r.Get("/policy", handlers.GetBookingPolicy)
 				r.Get("/slots/{date}", handlers.GetBookedSlots)
 				r.Get("/recommendations/{date}", handlers.GetRecommendedSlots)
-				r.With(paymentLimiter.Handler).Post("/verify", func(w http.ResponseWriter, r *http.Request) {
+				r.With(limit("payment")).Post("/verify", func(w http.ResponseWriter, r *http.Request) {
 					handlers.VerifyPayment(w, r, hub, auditService)
 				})
 
 				// Protected User Routes
 				r.Group(func(r chi.Router) {
-					r.Use(middleware.AuthMiddleware(cfg.JWTSecret, cfg.SupabaseAnonKey, cfg.SupabaseURL))
+					r.Use(requireAuth)
+					r.Use(limit("user"))
+					r.Use(middleware.UserLocale(services.PreferredLocale))
 
 					r.Get("/my", handlers.GetUserBookings)
 					r.Get("/{id}/status", handlers.GetBookingStatus)
 					r.Get("/subscriptions/active", handlers.GetActiveSubscription)
-					r.With(bookingLimiter.Handler).Post("/", func(w http.ResponseWriter, r *http.Request) {
+					r.With(limit("booking")).Post("/", func(w http.ResponseWriter, r *http.Request) {
 						handlers.CreateBooking(w, r, hub, auditService)
 					})
 					r.Post("/{id}/release-pending", func(w http.ResponseWriter, r *http.Request) {
 						handlers.ReleasePendingBooking(w, r, hub, auditService)
 					})
+					r.Post("/{id}/checkout-opened", handlers.ReportCheckoutOpened)
+					r.Post("/{id}/join", func(w http.ResponseWriter, r *http.Request) {
+						handlers.JoinSession(w, r, hub, auditService)
+					})
+					r.Post("/{id}/feedback", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SubmitSessionFeedback(w, r, auditService)
+					})
+					r.Get("/{id}/intake", handlers.GetBookingIntake)
+					r.Put("/{id}/intake", func(w http.ResponseWriter, r *http.Request) {
+						handlers.SubmitBookingIntake(w, r, auditService)
+					})
 					r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
 						handlers.CancelBooking(w, r, hub, auditService)
 					})
 				})
 			})
 
+			// Current user preferences
+			r.Route("/me", func(r chi.Router) {
+				r.Use(requireAuth)
+				r.Use(limit("user"))
+				r.Use(middleware.UserLocale(services.PreferredLocale))
+
+				r.Get("/", handlers.GetMyProfile)
+				r.Patch("/", func(w http.ResponseWriter, r *http.Request) {
+					handlers.UpdateMyProfile(w, r, auditService)
+				})
+				r.Get("/locale", handlers.GetLocalePreference)
+				r.Put("/locale", handlers.UpdateLocalePreference)
+
+				// Data subject rights (GDPR / DPDP)
+				r.Get("/export", func(w http.ResponseWriter, r *http.Request) {
+					handlers.ExportMyData(w, r, auditService)
+				})
+				r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+					handlers.DeleteMyAccount(w, r, auditService)
+				})
+			})
+
 			// Razorpay Webhook (public, signature-verified internally)
 			r.Post("/webhook/razorpay", func(w http.ResponseWriter, r *http.Request) {
 				handlers.RazorpayWebhook(w, r, hub, auditService)
 			})
 
+			// Resend delivery events (public, signature-verified internally)
+			r.Post("/webhook/resend", handlers.ResendWebhook)
+
+			// One-click unsubscribe links from notification emails (signed token)
+			r.Get("/email/unsubscribe", handlers.UnsubscribeEmail)
+			r.Post("/email/unsubscribe", handlers.UnsubscribeEmail)
+
 			// Insights (Public)
 			r.Get("/insights", handlers.GetAllInsights)
 
-			// Admin Portal (Double Protected)
+			// Testimonials (Public, approved only)
+			r.Get("/testimonials", handlers.GetTestimonials)
+
+			// Admin Portal
 			r.Route("/admin", func(r chi.Router) {
-				r.Use(middleware.AuthMiddleware(cfg.JWTSecret, cfg.SupabaseAnonKey, cfg.SupabaseURL))
-				r.Use(middleware.AdminMiddleware(cfg.AdminEmails))
+				r.Use(adminMiddleware...)
+
+				r.With(middleware.RequirePermission(rbac.PermStatsRead)).Get("/stats", handlers.GetAdminStats)
+				r.With(middleware.RequirePermission(rbac.PermStatsRead)).Get("/analytics", handlers.AdminGetAnalytics)
+				r.With(middleware.RequirePermission(rbac.PermStatsRead)).Get("/funnel", handlers.AdminGetBookingFunnel)
+				r.Route("/bookings", func(r chi.Router) {
+					r.With(middleware.RequirePermission(rbac.PermBookingsRead)).Get("/", handlers.GetAdminBookings)
+					r.With(middleware.RequirePermission(rbac.PermIntakeRead)).Get("/{id}/intake", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminGetBookingIntake(w, r, auditService)
+					})
 
-				r.Get("/stats", handlers.GetAdminStats)
-				r.Get("/bookings", handlers.GetAdminBookings)
-				r.Post("/test-email", handlers.TestEmail)
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequirePermission(rbac.PermBookingsManage))
+						r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminCreateBooking(w, r, hub, auditService)
+						})
+						r.Post("/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminCancelBooking(w, r, hub, auditService)
+						})
+						r.Post("/{id}/join", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminJoinSession(w, r, hub, auditService)
+						})
+						r.Post("/{id}/complete", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminCompleteBooking(w, r, hub, auditService)
+						})
+						r.Post("/{id}/no-show", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminMarkNoShow(w, r, hub, auditService)
+						})
+						r.Post("/{id}/confirm-payment", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminConfirmPayment(w, r, hub, auditService)
+						})
+						r.Patch("/{id}/meeting-link", func(w http.ResponseWriter, r *http.Request) {
+							handlers.AdminUpdateMeetingLink(w, r, hub, auditService)
+						})
+					})
+				})
+				r.With(middleware.RequirePermission(rbac.PermEmailSendTest)).Post("/test-email", handlers.TestEmail)
+				r.With(middleware.RequirePermission(rbac.PermAuditRead)).Get("/audit", handlers.GetAuditLog)
+				r.Route("/ledger", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermLedgerManage))
+					r.Get("/periods", handlers.AdminListLedgerPeriods)
+					r.Post("/periods/{period}/close", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminCloseLedgerPeriod(w, r, auditService)
+					})
+					r.Get("/periods/{period}/export", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminExportLedgerPeriod(w, r, auditService)
+					})
+					r.Get("/settlements", handlers.AdminListSettlementUploads)
+					r.Post("/settlements", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminUploadSettlementReport(w, r, auditService)
+					})
+					r.Get("/settlements/{id}", handlers.AdminGetSettlementUpload)
+				})
 
 				r.Route("/insights", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermInsightsWrite))
 					r.Post("/", handlers.CreateInsight)
 					r.Put("/{id}", handlers.UpdateInsight)
 					r.Delete("/{id}", handlers.DeleteInsight)
 				})
+
+				r.Route("/testimonials", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermTestimonialsModerate))
+					r.Get("/", handlers.AdminListTestimonials)
+					r.Patch("/{id}", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminModerateTestimonial(w, r, auditService)
+					})
+					r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminDeleteTestimonial(w, r, auditService)
+					})
+				})
+
+				r.Route("/email-templates", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermEmailTemplatesWrite))
+					r.Get("/", handlers.ListEmailTemplates)
+					r.Get("/{name}/versions", handlers.GetEmailTemplateVersions)
+					r.Put("/{name}", func(w http.ResponseWriter, r *http.Request) {
+						handlers.UpdateEmailTemplate(w, r, auditService)
+					})
+					r.Post("/{name}/preview", handlers.PreviewEmailTemplate)
+					r.Post("/{name}/test", handlers.SendEmailTemplateTest)
+					r.Post("/{name}/rollback", func(w http.ResponseWriter, r *http.Request) {
+						handlers.RollbackEmailTemplate(w, r, auditService)
+					})
+				})
+
+				r.Route("/crisis-events", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermCrisisRead))
+					r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminListCrisisEvents(w, r, auditService)
+					})
+					r.Post("/{id}/acknowledge", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminAcknowledgeCrisisEvent(w, r, auditService)
+					})
+				})
+
+				r.Route("/intake-forms", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermIntakeFormsWrite))
+					r.Get("/", handlers.AdminListIntakeForms)
+					r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminCreateIntakeForm(w, r, auditService)
+					})
+					r.Post("/activate", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminActivateIntakeForm(w, r, auditService)
+					})
+				})
+
+				r.Route("/email-suppressions", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermEmailSuppressionsManage))
+					r.Get("/", handlers.ListEmailSuppressions)
+					r.Delete("/{email}", func(w http.ResponseWriter, r *http.Request) {
+						handlers.DeleteEmailSuppression(w, r, auditService)
+					})
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermUsersRead))
+					r.Get("/users", handlers.AdminListUsers)
+					r.Get("/users/{userID}", handlers.AdminGetUser)
+					r.Get("/users/{userID}/bookings", handlers.AdminGetUserBookings)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermUsersManage))
+					r.Post("/users/{userID}/block", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminBlockUser(w, r, auditService)
+					})
+					r.Post("/users/{userID}/unblock", func(w http.ResponseWriter, r *http.Request) {
+						handlers.AdminUnblockUser(w, r, auditService)
+					})
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermSessionsManage))
+					r.Post("/users/{userID}/logout", func(w http.ResponseWriter, r *http.Request) {
+						handlers.ForceLogoutUser(w, r, auditService)
+					})
+					r.Delete("/users/{userID}/sessions", func(w http.ResponseWriter, r *http.Request) {
+						handlers.PurgeUserSessions(w, r, auditService)
+					})
+					r.Post("/tokens/revoke", func(w http.ResponseWriter, r *http.Request) {
+						handlers.RevokeToken(w, r, auditService)
+					})
+				})
+
+				r.Route("/roles", func(r chi.Router) {
+					r.Get("/me", handlers.GetMyRoles)
+
+					r.Group(func(r chi.Router) {
+						r.Use(middleware.RequirePermission(rbac.PermRolesManage))
+						r.Get("/", handlers.ListRoleAssignments)
+						r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+							handlers.GrantRole(w, r, auditService)
+						})
+						r.Delete("/{userID}/{role}", func(w http.ResponseWriter, r *http.Request) {
+							handlers.RevokeRole(w, r, auditService)
+						})
+					})
+				})
+
+				r.Route("/api-keys", func(r chi.Router) {
+					r.Use(middleware.RequirePermission(rbac.PermAPIKeysManage))
+					r.Get("/", handlers.ListAPIKeys)
+					r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+						handlers.CreateAPIKey(w, r, auditService)
+					})
+					r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+						handlers.RevokeAPIKey(w, r, auditService)
+					})
+				})
 			})
 		})
 
@@ -219,6 +486,24 @@ func main() {
 		})
 	})
 
+	// Admin exports stream large files for up to their own export timeout, so
+	// they are mounted without requestTimeout.
+	r.Route("/api/v1/admin/exports", func(r chi.Router) {
+		r.Use(adminMiddleware...)
+		r.With(middleware.RequirePermission(rbac.PermBookingsRead)).Get("/bookings", func(w http.ResponseWriter, r *http.Request) {
+			handlers.ExportAdminBookings(w, r, auditService)
+		})
+		r.With(middleware.RequirePermission(rbac.PermBookingsRead)).Get("/payments", func(w http.ResponseWriter, r *http.Request) {
+			handlers.ExportAdminPayments(w, r, auditService)
+		})
+		r.With(middleware.RequirePermission(rbac.PermBookingsRead)).Get("/coupon-uses", func(w http.ResponseWriter, r *http.Request) {
+			handlers.ExportAdminCouponUses(w, r, auditService)
+		})
+		r.With(middleware.RequirePermission(rbac.PermAuditRead)).Get("/audit", func(w http.ResponseWriter, r *http.Request) {
+			handlers.ExportAuditLog(w, r, auditService)
+		})
+	})
+
 	// 9. Start Server with Graceful Shutdown
 	srv := &http.Server{
 		Addr:         "0.0.0.0:" + cfg.Port,
@@ -248,7 +533,14 @@ func main() {
 
 	c.Stop()
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
+		logger.Error("Server forced to shutdown", zap.Error(err))
+	}
+
+	// Flush queued audit entries; handlers can no longer add to them.
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer flushCancel()
+	if err := auditService.Close(flushCtx); err != nil {
+		logger.Error("Audit log flush did not finish", zap.Error(err))
 	}
 
 	logger.Info("Server exited gracefully")
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/handlers"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func runBookings(args []string) error {
+	return subcommand("bookings", args, map[string]func([]string) error{
+		"list":   listBookings,
+		"cancel": cancelBooking,
+	}, "list", "cancel")
+}
+
+func listBookings(args []string) error {
+	fs := newFlagSet("bookings list", "bookings list [-date YYYY-MM-DD] [-status paid|pending|failed|cancelled] [-search TEXT] [-limit N]")
+	date := fs.String("date", "", "only bookings on this date")
+	status := fs.String("status", "", "only bookings with this payment status")
+	search := fs.String("search", "", "match full name or email, or part of a booking or user ID")
+	limit := fs.Int("limit", 50, "maximum rows (max 500)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 1 || *limit > 500 {
+		return errors.New("-limit must be between 1 and 500")
+	}
+	switch *status {
+	case "", "paid", "pending", "failed", "cancelled":
+	default:
+		return fmt.Errorf("invalid -status %q", *status)
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	if err := initPII(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	searchCondition, queryArgs := "TRUE", []interface{}{*date, *status, *limit}
+	if strings.TrimSpace(*search) != "" {
+		var searchArgs []interface{}
+		searchCondition, searchArgs = services.BookingSearchCondition(*search, len(queryArgs)+1)
+		queryArgs = append(queryArgs, searchArgs...)
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, date, time, name, email, payment_status, COALESCE(amount, 0), created_at
+		 FROM bookings
+		 WHERE ($1 = '' OR date = $1)
+		   AND ($2 = '' OR payment_status = $2)
+		   AND `+searchCondition+`
+		 ORDER BY date DESC, time, created_at DESC
+		 LIMIT $3`,
+		queryArgs...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	out := [][]string{}
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.PaymentStatus, &b.Amount, &b.CreatedAt); err != nil {
+			return err
+		}
+		if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+			return fmt.Errorf("booking %s: %w", b.ID, err)
+		}
+		out = append(out, []string{
+			b.ID, b.Date, b.Time, b.PaymentStatus,
+			strconv.FormatFloat(b.Amount, 'f', 2, 64), b.Name, b.Email,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	table([]string{"ID", "DATE", "TIME", "STATUS", "AMOUNT", "NAME", "EMAIL"}, out)
+	return nil
+}
+
+// cancelBooking cancels a pending or paid booking and frees its slot. Refunds
+// are not issued here; settle them in the Razorpay dashboard.
+func cancelBooking(args []string) error {
+	fs := newFlagSet("bookings cancel", "bookings cancel -yes [-reason TEXT] [-notify] BOOKING_ID")
+	yes := fs.Bool("yes", false, "confirm the cancellation")
+	reason := fs.String("reason", "", "reason recorded in the audit log")
+	notify := fs.Bool("notify", false, "email the cancellation notice to the client")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	id, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid booking ID %q", fs.Arg(0))
+	}
+	if !*yes {
+		return errors.New("refusing to cancel without -yes")
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	if err := initPII(); err != nil {
+		return err
+	}
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+	connectCache()
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var b models.Booking
+	err = database.Pool.QueryRow(ctx,
+		`SELECT date, time, COALESCE(session_status, '') FROM bookings WHERE id = $1`,
+		id.String(),
+	).Scan(&b.Date, &b.Time, &b.SessionStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("booking %s not found", id)
+	}
+	if err != nil {
+		return err
+	}
+	sessionStatus, ok := handlers.CancelledSessionStatus(b.SessionStatus, b.Date, b.Time, time.Now())
+	if !ok {
+		return fmt.Errorf("booking %s already has a session outcome (%s)", id, b.SessionStatus)
+	}
+
+	var previousStatus string
+	err = database.Pool.QueryRow(ctx,
+		`WITH target AS (
+			SELECT id, payment_status FROM bookings WHERE id = $1 FOR UPDATE
+		)
+		UPDATE bookings b
+		SET payment_status = 'cancelled',
+		    status_reason = 'cancelled_by_admin',
+		    cancelled_at = COALESCE(b.cancelled_at, NOW()),
+		    released_at = COALESCE(b.released_at, NOW()),
+		    session_status = $2,
+		    session_status_at = CASE WHEN b.session_status IS DISTINCT FROM $2 THEN NOW() ELSE b.session_status_at END
+		FROM target
+		WHERE b.id = target.id
+		  AND target.payment_status IN ('pending', 'paid')
+		  AND COALESCE(b.session_status, '') = $3
+		RETURNING b.name, b.email, b.locale, target.payment_status`,
+		id.String(), sessionStatus, b.SessionStatus,
+	).Scan(&b.Name, &b.Email, &b.Locale, &previousStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("booking %s is not pending/paid, or changed while cancelling", id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+		return fmt.Errorf("booking cancelled, but its details could not be decrypted: %w", err)
+	}
+
+	// Connected clients refresh on their next slot fetch; live WebSocket
+	// updates only come from the API process.
+	handlers.InvalidateSlotsCache(ctx, b.Date)
+
+	auditChange(ctx, "booking.admin_cancel", id.String(), "booking", map[string]interface{}{
+		"previous_status": previousStatus,
+		"session_status":  sessionStatus,
+		"reason":          *reason,
+		"notified":        *notify,
+	})
+	fmt.Printf("cancelled booking %s (%s %s, was %s)\n", id, b.Date, b.Time, previousStatus)
+	if previousStatus == "paid" {
+		fmt.Println("note: no refund was issued; refund the payment in Razorpay if needed")
+	}
+
+	if *notify {
+		data := services.NewEmailTemplateData(b.Locale, b.Name, b.Date, b.Time, "")
+		if err := services.SendTemplatedEmail(ctx, b.Email, services.TemplateBookingCancellation, data); err != nil {
+			return fmt.Errorf("booking cancelled, but the notice email failed: %w", err)
+		}
+		fmt.Printf("notified %s\n", b.Email)
+	}
+	return nil
+}
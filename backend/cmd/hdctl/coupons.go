@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+)
+
+func runCoupons(args []string) error {
+	return subcommand("coupons", args, map[string]func([]string) error{
+		"list":    listCoupons,
+		"create":  createCoupon,
+		"enable":  func(args []string) error { return setCouponActive(args, true) },
+		"disable": func(args []string) error { return setCouponActive(args, false) },
+	}, "list", "create", "enable", "disable")
+}
+
+func listCoupons(args []string) error {
+	fs := newFlagSet("coupons list", "coupons list [-all]")
+	all := fs.Bool("all", false, "include inactive coupons")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, code, COALESCE(description, ''), discount_type, discount_value, max_uses, COALESCE(uses_count, 0), valid_until, COALESCE(is_active, FALSE)
+		 FROM coupons
+		 WHERE $1 OR is_active
+		 ORDER BY created_at DESC`,
+		*all,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	out := [][]string{}
+	for rows.Next() {
+		var c models.Coupon
+		if err := rows.Scan(&c.ID, &c.Code, &c.Description, &c.DiscountType, &c.DiscountValue, &c.MaxUses, &c.UsesCount, &c.ValidUntil, &c.IsActive); err != nil {
+			return err
+		}
+		maxUses := "-"
+		if c.MaxUses != nil {
+			maxUses = strconv.Itoa(*c.MaxUses)
+		}
+		out = append(out, []string{
+			c.Code, formatDiscount(c.DiscountType, c.DiscountValue),
+			fmt.Sprintf("%d/%s", c.UsesCount, maxUses), formatTime(c.ValidUntil),
+			strconv.FormatBool(c.IsActive), c.Description,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	table([]string{"CODE", "DISCOUNT", "USES", "VALID UNTIL", "ACTIVE", "DESCRIPTION"}, out)
+	return nil
+}
+
+func createCoupon(args []string) error {
+	fs := newFlagSet("coupons create", "coupons create -code CODE -type percentage|fixed -value N [flags]")
+	code := fs.String("code", "", "coupon code (stored upper-case)")
+	discountType := fs.String("type", "percentage", "discount type: percentage or fixed")
+	value := fs.Float64("value", 0, "discount value (percent, or rupees for fixed)")
+	maxUses := fs.Int("max-uses", 0, "maximum redemptions (0 = unlimited)")
+	validUntil := fs.String("valid-until", "", "last valid day, YYYY-MM-DD (default: no expiry)")
+	description := fs.String("description", "", "description shown to admins")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := models.Coupon{
+		Code:          strings.ToUpper(strings.TrimSpace(*code)),
+		Description:   strings.TrimSpace(*description),
+		DiscountType:  *discountType,
+		DiscountValue: *value,
+		IsActive:      true,
+	}
+	if err := validateCoupon(c); err != nil {
+		return err
+	}
+	if *maxUses < 0 {
+		return errors.New("-max-uses must not be negative")
+	}
+	if *maxUses > 0 {
+		c.MaxUses = maxUses
+	}
+	if *validUntil != "" {
+		day, err := time.ParseInLocation("2006-01-02", *validUntil, time.Local)
+		if err != nil {
+			return fmt.Errorf("-valid-until must be YYYY-MM-DD: %w", err)
+		}
+		// Valid through the end of that day.
+		end := day.AddDate(0, 0, 1).Add(-time.Second)
+		c.ValidUntil = &end
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	err := database.Pool.QueryRow(ctx,
+		`INSERT INTO coupons (code, description, discount_type, discount_value, max_uses, valid_until, is_active)
+		 VALUES ($1, $2, $3, $4, $5, $6, TRUE)
+		 RETURNING id`,
+		c.Code, c.Description, c.DiscountType, c.DiscountValue, c.MaxUses, c.ValidUntil,
+	).Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+
+	auditChange(ctx, "coupon.create", c.ID, "coupon", map[string]interface{}{
+		"code":  c.Code,
+		"type":  c.DiscountType,
+		"value": c.DiscountValue,
+	})
+	fmt.Printf("created coupon %s (%s)\n", c.Code, formatDiscount(c.DiscountType, c.DiscountValue))
+	return nil
+}
+
+func setCouponActive(args []string, active bool) error {
+	verb := "disable"
+	if active {
+		verb = "enable"
+	}
+	fs := newFlagSet("coupons "+verb, "coupons "+verb+" CODE")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	code := strings.ToUpper(strings.TrimSpace(fs.Arg(0)))
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var id string
+	err := database.Pool.QueryRow(ctx,
+		`UPDATE coupons SET is_active = $2 WHERE UPPER(code) = $1 RETURNING id`,
+		code, active,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("coupon %s not found: %w", code, err)
+	}
+
+	auditChange(ctx, "coupon."+verb, id, "coupon", map[string]interface{}{"code": code})
+	fmt.Printf("%sd coupon %s\n", verb, code)
+	return nil
+}
+
+// validateCoupon checks the fields hdctl accepts on create.
+func validateCoupon(c models.Coupon) error {
+	if c.Code == "" || len(c.Code) > 50 {
+		return errors.New("-code must be 1-50 characters")
+	}
+	switch c.DiscountType {
+	case "percentage":
+		if c.DiscountValue <= 0 || c.DiscountValue > 100 {
+			return errors.New("percentage -value must be in (0, 100]")
+		}
+	case "fixed":
+		if c.DiscountValue <= 0 {
+			return errors.New("fixed -value must be positive")
+		}
+	default:
+		return fmt.Errorf("-type must be percentage or fixed, got %q", c.DiscountType)
+	}
+	return nil
+}
+
+func formatDiscount(discountType string, value float64) string {
+	if discountType == "percentage" {
+		return strconv.FormatFloat(value, 'f', -1, 64) + "%"
+	}
+	return "₹" + strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/handlers"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func runInsights(args []string) error {
+	return subcommand("insights", args, map[string]func([]string) error{
+		"list":   listInsights,
+		"create": createInsight,
+		"update": updateInsight,
+		"delete": deleteInsight,
+	}, "list", "create", "update", "delete")
+}
+
+func listInsights(args []string) error {
+	fs := newFlagSet("insights list", "insights list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, title, media_type, sort_order, media_url FROM insights ORDER BY sort_order ASC`,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	out := [][]string{}
+	for rows.Next() {
+		var i models.Insight
+		if err := rows.Scan(&i.ID, &i.Title, &i.MediaType, &i.SortOrder, &i.MediaURL); err != nil {
+			return err
+		}
+		out = append(out, []string{i.ID, strconv.Itoa(i.SortOrder), i.MediaType, i.Title, i.MediaURL})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	table([]string{"ID", "ORDER", "TYPE", "TITLE", "MEDIA URL"}, out)
+	return nil
+}
+
+// insightFlags registers the editable insight fields on fs.
+func insightFlags(fs *flag.FlagSet) *models.Insight {
+	i := &models.Insight{}
+	fs.StringVar(&i.Title, "title", "", "card title")
+	fs.StringVar(&i.Description, "description", "", "card text")
+	fs.StringVar(&i.MediaURL, "media-url", "", "image or video URL")
+	fs.StringVar(&i.MediaType, "media-type", "image", "image or video")
+	fs.IntVar(&i.SortOrder, "order", 0, "carousel position (ascending)")
+	return i
+}
+
+func validateInsight(i *models.Insight) error {
+	i.Title = strings.TrimSpace(i.Title)
+	if i.Title == "" {
+		return errors.New("-title is required")
+	}
+	if i.Description == "" || i.MediaURL == "" {
+		return errors.New("-description and -media-url are required")
+	}
+	if i.MediaType != "image" && i.MediaType != "video" {
+		return fmt.Errorf("-media-type must be image or video, got %q", i.MediaType)
+	}
+	return nil
+}
+
+func createInsight(args []string) error {
+	fs := newFlagSet("insights create", "insights create -title T -description D -media-url URL [-media-type image|video] [-order N]")
+	i := insightFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validateInsight(i); err != nil {
+		return err
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	connectCache()
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	err := database.Pool.QueryRow(ctx,
+		"INSERT INTO insights (title, description, media_url, media_type, sort_order) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		i.Title, i.Description, i.MediaURL, i.MediaType, i.SortOrder,
+	).Scan(&i.ID)
+	if err != nil {
+		return err
+	}
+	handlers.InvalidateInsightsCache(ctx)
+
+	auditChange(ctx, "insight.create", i.ID, "insight", map[string]interface{}{"title": i.Title})
+	fmt.Printf("created insight %s\n", i.ID)
+	return nil
+}
+
+// updateInsight changes only the flags that were passed.
+func updateInsight(args []string) error {
+	fs := newFlagSet("insights update", "insights update ID [-title T] [-description D] [-media-url URL] [-media-type image|video] [-order N]")
+	changes := insightFlags(fs)
+	id, err := parseInsightID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	connectCache()
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var i models.Insight
+	err = database.Pool.QueryRow(ctx,
+		"SELECT id, title, description, media_url, media_type, sort_order FROM insights WHERE id = $1",
+		id,
+	).Scan(&i.ID, &i.Title, &i.Description, &i.MediaURL, &i.MediaType, &i.SortOrder)
+	if err != nil {
+		return fmt.Errorf("insight %s not found: %w", id, err)
+	}
+
+	changed := []string{}
+	fs.Visit(func(f *flag.Flag) {
+		changed = append(changed, f.Name)
+		switch f.Name {
+		case "title":
+			i.Title = changes.Title
+		case "description":
+			i.Description = changes.Description
+		case "media-url":
+			i.MediaURL = changes.MediaURL
+		case "media-type":
+			i.MediaType = changes.MediaType
+		case "order":
+			i.SortOrder = changes.SortOrder
+		}
+	})
+	if len(changed) == 0 {
+		return errors.New("nothing to update; pass at least one field flag")
+	}
+	if err := validateInsight(&i); err != nil {
+		return err
+	}
+
+	_, err = database.Pool.Exec(ctx,
+		"UPDATE insights SET title=$1, description=$2, media_url=$3, media_type=$4, sort_order=$5 WHERE id=$6",
+		i.Title, i.Description, i.MediaURL, i.MediaType, i.SortOrder, id,
+	)
+	if err != nil {
+		return err
+	}
+	handlers.InvalidateInsightsCache(ctx)
+
+	auditChange(ctx, "insight.update", id, "insight", map[string]interface{}{"fields": changed})
+	fmt.Printf("updated insight %s (%s)\n", id, strings.Join(changed, ", "))
+	return nil
+}
+
+func deleteInsight(args []string) error {
+	fs := newFlagSet("insights delete", "insights delete ID")
+	id, err := parseInsightID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	connectCache()
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	result, err := database.Pool.Exec(ctx, "DELETE FROM insights WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("insight %s not found", id)
+	}
+	handlers.InvalidateInsightsCache(ctx)
+
+	auditChange(ctx, "insight.delete", id, "insight", nil)
+	fmt.Printf("deleted insight %s\n", id)
+	return nil
+}
+
+// parseInsightID accepts the ID before or after the flags.
+func parseInsightID(fs *flag.FlagSet, args []string) (string, error) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		args = append(append([]string{}, args[1:]...), args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", errUsage
+	}
+	id, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return "", fmt.Errorf("invalid insight ID %q", fs.Arg(0))
+	}
+	return id.String(), nil
+}
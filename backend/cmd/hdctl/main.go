@@ -0,0 +1,213 @@
+// Command hdctl is the operator CLI for the Hidden Depths backend. It mints
+// short-lived tokens, runs migrations, manages coupons and insights, inspects
+// the booking policy, lists and cancels bookings, applies data retention,
+// searches and verifies the audit log, migrates booking personal data to and
+// from encryption, and runs scheduler jobs on demand. It reads the same
+// environment (and .env file) as the API.
+//
+// Usage:
+//
+//	hdctl <command> [subcommand] [flags]
+//
+// Run "hdctl help" for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// commandTimeout bounds every database-backed command.
+const commandTimeout = 2 * time.Minute
+
+// errUsage is returned after a command has printed its own usage.
+var errUsage = errors.New("usage")
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"token", "Mint a short-lived access token for a user or role holder", runToken},
+	{"migrate", "Apply, roll back or inspect database migrations", runMigrate},
+	{"coupons", "List, create, enable and disable coupons", runCoupons},
+	{"insights", "List, create, update and delete insight cards", runInsights},
+	{"policy", "Show the booking policy and bookable dates", runPolicy},
+	{"bookings", "List and cancel bookings", runBookings},
+	{"retention", "Show, dry-run or apply data retention policies", runRetention},
+	{"audit", "List audit log entries and verify the hash chain", runAudit},
+	{"pii", "Encrypt, decrypt or re-key booking names and emails", runPII},
+	{"run", "Run a scheduler job now (reminders, reconcile, cleanup-revocations, ledger-sync)", runJob},
+}
+
+// audit records CLI changes with user agent "hdctl".
+var audit = services.NewAuditService()
+
+func main() {
+	_ = godotenv.Load()
+
+	if err := logger.Init(os.Getenv("ENVIRONMENT")); err != nil {
+		fmt.Fprintln(os.Stderr, "Error initializing logger:", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		return
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(args)
+		database.CloseDB()
+		cache.Close()
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: hdctl <command> [subcommand] [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run \"hdctl <command> -h\" for details.")
+}
+
+// subcommand dispatches args[0] to one of subs, printing usage when missing.
+func subcommand(command string, args []string, subs map[string]func(args []string) error, order ...string) error {
+	if len(args) > 0 {
+		if run, ok := subs[args[0]]; ok {
+			return run(args[1:])
+		}
+		if args[0] != "-h" && args[0] != "--help" && args[0] != "help" {
+			fmt.Fprintf(os.Stderr, "Unknown %s subcommand %q\n\n", command, args[0])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Usage: hdctl %s <%s> [flags]\n", command, strings.Join(order, "|"))
+	return errUsage
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting.
+func newFlagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: hdctl %s\n\n", usageLine)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// connectDB opens the pool against DATABASE_URL.
+func connectDB() error {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		return errors.New("DATABASE_URL is not set")
+	}
+	return database.ConnectDB(url)
+}
+
+// connectCache connects to Redis so changes invalidate the API's cached
+// reads. Without Redis there is nothing to invalidate.
+func connectCache() {
+	err := cache.Init(cache.Config{
+		URL:     os.Getenv("REDIS_URL"),
+		Enabled: os.Getenv("CACHE_ENABLED") != "false",
+	}, logger.Log)
+	if err != nil {
+		logger.Warn("Redis unavailable; cached API responses may be stale until they expire", zap.Error(err))
+	}
+}
+
+// initPII configures booking name/email encryption from PII_ENCRYPTION_KEYS
+// and PII_BLIND_INDEX_KEY, as the API does. Without keys it is a no-op.
+func initPII() error {
+	keys, err := pii.ParseKeys(os.Getenv("PII_ENCRYPTION_KEYS"))
+	if err != nil {
+		return fmt.Errorf("PII_ENCRYPTION_KEYS: %w", err)
+	}
+	if len(keys) == 0 {
+		return pii.Init(nil, nil)
+	}
+	indexKey, err := pii.DecodeKey(os.Getenv("PII_BLIND_INDEX_KEY"))
+	if err != nil {
+		return fmt.Errorf("PII_BLIND_INDEX_KEY: %w", err)
+	}
+	return pii.Init(keys, indexKey)
+}
+
+func commandContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), commandTimeout)
+}
+
+// operator identifies who ran a command, for audit details.
+func operator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// auditChange records a CLI change. Failures are reported but do not undo it.
+func auditChange(ctx context.Context, action, entityID, entityType string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["operator"] = operator()
+	if err := audit.LogSync(ctx, action, "", entityID, entityType, "", "hdctl", details); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: failed to write audit log:", err)
+	}
+}
+
+// table prints rows aligned under header.
+func table(header []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
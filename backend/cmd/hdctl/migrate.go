@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+)
+
+func runMigrate(args []string) error {
+	return subcommand("migrate", args, map[string]func([]string) error{
+		"up":     migrateUp,
+		"down":   migrateDown,
+		"status": migrateStatus,
+		"force":  migrateForce,
+	}, "up", "down", "status", "force")
+}
+
+// migrationFlags registers -dir on fs.
+func migrationFlags(fs *flag.FlagSet) *string {
+	return fs.String("dir", "migrations", "directory holding NNNNNN_name.{up,down}.sql files")
+}
+
+// migrationSteps reads the optional positional step count (0 means all).
+func migrationSteps(fs *flag.FlagSet) (int, error) {
+	if fs.NArg() == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("step count must be a positive integer, got %q", fs.Arg(0))
+	}
+	return n, nil
+}
+
+func migrateUp(args []string) error {
+	fs := newFlagSet("migrate up", "migrate up [-dir migrations] [N]")
+	dir := migrationFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	steps, err := migrationSteps(fs)
+	if err != nil {
+		return err
+	}
+	migrations, err := database.LoadMigrations(*dir)
+	if err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	applied, err := database.MigrateUp(ctx, migrations, steps)
+	for _, m := range applied {
+		fmt.Printf("applied %06d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("no pending migrations")
+	}
+	return nil
+}
+
+func migrateDown(args []string) error {
+	fs := newFlagSet("migrate down", "migrate down -yes [-dir migrations] [N]")
+	yes := fs.Bool("yes", false, "confirm the rollback; down migrations may drop data")
+	dir := migrationFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes {
+		fs.Usage()
+		return errors.New("refusing to roll back without -yes")
+	}
+	steps, err := migrationSteps(fs)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := database.LoadMigrations(*dir)
+	if err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	reverted, err := database.MigrateDown(ctx, migrations, steps)
+	for _, m := range reverted {
+		fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		fmt.Println("no migrations to roll back")
+	}
+	return nil
+}
+
+func migrateStatus(args []string) error {
+	fs := newFlagSet("migrate status", "migrate status [-dir migrations]")
+	dir := migrationFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	migrations, err := database.LoadMigrations(*dir)
+	if err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	status, err := database.GetMigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(migrations))
+	for _, m := range migrations {
+		state := "pending"
+		if m.Version <= status.Version {
+			state = "applied"
+		}
+		if m.Version == status.Version && status.Dirty {
+			state = "DIRTY"
+		}
+		rows = append(rows, []string{fmt.Sprintf("%06d", m.Version), m.Name, state})
+	}
+	table([]string{"VERSION", "NAME", "STATE"}, rows)
+	return nil
+}
+
+func migrateForce(args []string) error {
+	fs := newFlagSet("migrate force", "migrate force <version>")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	version, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil || version < database.NoMigrationVersion {
+		return fmt.Errorf("invalid version %q (use -1 for none)", fs.Arg(0))
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	if err := database.ForceMigrationVersion(ctx, version); err != nil {
+		return err
+	}
+	fmt.Printf("forced version %d (clean)\n", version)
+	return nil
+}
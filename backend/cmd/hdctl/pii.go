@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+)
+
+// Booking names and emails are encrypted with PII_ENCRYPTION_KEYS; see
+// package pii for the stored format and key rotation.
+
+func runPII(args []string) error {
+	return subcommand("pii", args, map[string]func([]string) error{
+		"status":  piiStatus,
+		"encrypt": encryptPII,
+		"decrypt": decryptPII,
+	}, "status", "encrypt", "decrypt")
+}
+
+func piiStatus(args []string) error {
+	fs := newFlagSet("pii status", "pii status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initPII(); err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	status, err := services.CountBookingPII(ctx)
+	if err != nil {
+		return err
+	}
+	if !pii.Enabled() {
+		fmt.Println("encryption: disabled (PII_ENCRYPTION_KEYS is not set)")
+	} else {
+		fmt.Printf("encryption: enabled, current key prefix %q\n", pii.Default().CurrentPrefix())
+	}
+	table([]string{"STORED AS", "BOOKINGS"}, [][]string{
+		{"plaintext", fmt.Sprint(status.Plaintext)},
+		{"current key", fmt.Sprint(status.CurrentKey)},
+		{"older key", fmt.Sprint(status.OldKey)},
+	})
+	return nil
+}
+
+func encryptPII(args []string) error {
+	fs := newFlagSet("pii encrypt", "pii encrypt [-batch N]")
+	batch := fs.Int("batch", 500, "rows per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return migratePII("encrypt", *batch, services.EncryptBookings)
+}
+
+func decryptPII(args []string) error {
+	fs := newFlagSet("pii decrypt", "pii decrypt -yes [-batch N]")
+	yes := fs.Bool("yes", false, "confirm storing names and emails as plaintext again")
+	batch := fs.Int("batch", 500, "rows per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*yes {
+		return errors.New("refusing to decrypt without -yes")
+	}
+	return migratePII("decrypt", *batch, services.DecryptBookings)
+}
+
+// migratePII runs an encrypt or decrypt pass over every booking. Rows are
+// changed batch by batch, so an interrupted run can simply be repeated.
+func migratePII(verb string, batch int, run func(context.Context, int) (int64, error)) error {
+	if batch < 1 || batch > 10000 {
+		return errors.New("-batch must be between 1 and 10000")
+	}
+	if err := initPII(); err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	// A first pass over every booking takes longer than commandTimeout.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	changed, err := run(ctx, batch)
+	if changed > 0 {
+		auditChange(ctx, "pii."+verb, "", "booking", map[string]interface{}{"bookings": changed})
+	}
+	if err != nil {
+		return fmt.Errorf("%sed %d bookings before failing: %w", verb, changed, err)
+	}
+	fmt.Printf("%sed %d bookings\n", verb, changed)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/config"
+	"github.com/Himadryy/hidden-depths-backend/internal/handlers"
+)
+
+// The booking policy is configured through BOOKING_* environment variables;
+// these commands show what the API will enforce with the current environment.
+// Change the policy by editing those variables and redeploying.
+
+func runPolicy(args []string) error {
+	return subcommand("policy", args, map[string]func([]string) error{
+		"show":  showPolicy,
+		"check": checkPolicyDate,
+	}, "show", "check")
+}
+
+// loadPolicy applies the configured policy the same way the API does at startup.
+func loadPolicy() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	handlers.SetBookingPolicy(handlers.BookingPolicy{
+		SafeMode:         cfg.BookingSafeMode,
+		AllowedWeekdays:  []time.Weekday{time.Sunday, time.Monday},
+		SearchWindowDays: cfg.BookingSearchWindowDays,
+		MaxBookableDates: cfg.BookingMaxBookableDates,
+		TimeSlots:        cfg.BookingTimeSlots,
+		SessionLength:    cfg.BookingSessionLength,
+		LateCancelWindow: cfg.BookingLateCancelWindow,
+		Holds: handlers.HoldLimits{
+			MaxActivePerUser:   cfg.BookingMaxActiveHolds,
+			MaxActivePerIP:     cfg.BookingMaxActiveHoldsPerIP,
+			MaxUpcomingPerUser: cfg.BookingMaxUpcomingPerUser,
+			AbandonLimit:       cfg.BookingAbandonLimit,
+			AbandonWindow:      cfg.BookingAbandonWindow,
+			Cooldown:           cfg.BookingHoldCooldown,
+		},
+	})
+	return nil
+}
+
+func showPolicy(args []string) error {
+	fs := newFlagSet("policy show", "policy show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+
+	policy := handlers.CurrentBookingPolicy()
+	weekdays := make([]string, 0, len(policy.AllowedWeekdays))
+	for _, day := range policy.AllowedWeekdays {
+		weekdays = append(weekdays, day.String())
+	}
+
+	table([]string{"SETTING", "VALUE"}, [][]string{
+		{"safe_mode", fmt.Sprint(policy.SafeMode)},
+		{"allowed_weekdays", strings.Join(weekdays, ", ")},
+		{"search_window_days", fmt.Sprint(policy.SearchWindowDays)},
+		{"max_bookable_dates", fmt.Sprint(policy.MaxBookableDates)},
+		{"time_slots", strings.Join(policy.TimeSlots, ", ")},
+		{"session_length", policy.SessionLength.String()},
+		{"late_cancel_window", policy.LateCancelWindow.String()},
+		{"max_active_holds", fmt.Sprint(policy.Holds.MaxActivePerUser)},
+		{"max_active_holds_per_ip", fmt.Sprint(policy.Holds.MaxActivePerIP)},
+		{"max_upcoming_per_user", fmt.Sprint(policy.Holds.MaxUpcomingPerUser)},
+		{"abandon_limit", fmt.Sprintf("%d in %s, then %s cooldown", policy.Holds.AbandonLimit, policy.Holds.AbandonWindow, policy.Holds.Cooldown)},
+		{"available_dates", strings.Join(handlers.EligibleBookingDates(time.Now()), ", ")},
+	})
+	return nil
+}
+
+func checkPolicyDate(args []string) error {
+	fs := newFlagSet("policy check", "policy check YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if err := loadPolicy(); err != nil {
+		return err
+	}
+
+	date := fs.Arg(0)
+	if err := handlers.CheckBookingDate(date, time.Now()); err != nil {
+		return fmt.Errorf("%s is not bookable: %w", date, err)
+	}
+	fmt.Printf("%s is bookable\n", date)
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/config"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+)
+
+// Retention ages come from the RETENTION environment variable (see
+// config.DefaultRetention); the API applies them daily.
+
+func runRetention(args []string) error {
+	return subcommand("retention", args, map[string]func([]string) error{
+		"show":    showRetention,
+		"run":     applyRetention,
+		"history": retentionHistory,
+	}, "show", "run", "history")
+}
+
+func showRetention(args []string) error {
+	fs := newFlagSet("retention show", "retention show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Retention))
+	for name := range cfg.Retention {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		age := "disabled"
+		if d := cfg.Retention[name]; d > 0 {
+			age = formatAge(d)
+		}
+		rows = append(rows, []string{name, age})
+	}
+	table([]string{"POLICY", "MAX AGE"}, rows)
+	if cfg.RetentionDryRun {
+		fmt.Println("note: RETENTION_DRY_RUN is set; scheduled runs only report")
+	}
+	return nil
+}
+
+func applyRetention(args []string) error {
+	fs := newFlagSet("retention run", "retention run [-dry-run]")
+	dryRun := fs.Bool("dry-run", false, "count affected rows without changing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	// A first run over a large backlog takes longer than commandTimeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := services.RunRetention(ctx, cfg.Retention, *dryRun)
+	if errors.Is(err, services.ErrRetentionRunning) {
+		return errors.New("retention is already running on another instance; try again later")
+	}
+	if err != nil {
+		return err
+	}
+
+	printRetentionResults(report.Results)
+	if *dryRun {
+		fmt.Println("dry run: nothing was changed")
+		return nil
+	}
+	affected := map[string]int64{}
+	for _, result := range report.Results {
+		affected[result.Policy] = result.Affected
+	}
+	auditChange(ctx, "retention.run", "", "retention", map[string]interface{}{"affected": affected})
+	return nil
+}
+
+func retentionHistory(args []string) error {
+	fs := newFlagSet("retention history", "retention history [-limit N]")
+	limit := fs.Int("limit", 10, "number of runs to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 1 || *limit > 100 {
+		return errors.New("-limit must be between 1 and 100")
+	}
+	if err := connectDB(); err != nil {
+		return err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	reports, err := services.RecentRetentionRuns(ctx, *limit)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{}
+	for _, report := range reports {
+		mode := "applied"
+		if report.DryRun {
+			mode = "dry run"
+		}
+		for _, result := range report.Results {
+			rows = append(rows, []string{
+				formatTime(&report.StartedAt), mode, result.Policy, result.Action,
+				fmt.Sprint(result.Affected), result.Error,
+			})
+		}
+	}
+	table([]string{"STARTED", "MODE", "POLICY", "ACTION", "ROWS", "ERROR"}, rows)
+	return nil
+}
+
+func printRetentionResults(results []services.RetentionResult) {
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, []string{
+			result.Policy, result.Table, result.Action,
+			result.Cutoff.Format("2006-01-02"), fmt.Sprint(result.Affected), result.Error,
+		})
+	}
+	table([]string{"POLICY", "TABLE", "ACTION", "CUTOFF", "ROWS", "ERROR"}, rows)
+}
+
+// formatAge prints whole days as "90d" and anything else as a duration.
+func formatAge(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+)
+
+// jobs are the scheduler tasks from cmd/api that can be run on demand. Each
+// works from current database state, so an extra run only does early what the
+// next scheduled run would have done.
+var jobs = map[string]struct {
+	summary string
+	run     func()
+}{
+	"reminders":           {"send reminder emails for tomorrow's paid bookings", services.CheckAndSendReminders},
+	"reconcile":           {"settle stale pending holds as failed and free their slots", services.CleanupAbandonedBookings},
+	"cleanup-revocations": {"delete revocations of tokens that have expired", services.CleanupExpiredRevocations},
+	"ledger-sync":         {"post payments, refunds and settlements missing from the ledger", services.SyncLedger},
+}
+
+func runJob(args []string) error {
+	fs := newFlagSet("run", "run <reminders|reconcile|cleanup-revocations|ledger-sync>")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: hdctl run <job>")
+		fmt.Fprintln(os.Stderr)
+		for _, name := range []string{"reminders", "reconcile", "cleanup-revocations", "ledger-sync"} {
+			fmt.Fprintf(os.Stderr, "  %-20s %s\n", name, jobs[name].summary)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	job, ok := jobs[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unknown job %q", fs.Arg(0))
+	}
+
+	if err := connectDB(); err != nil {
+		return err
+	}
+	if err := initPII(); err != nil {
+		return err
+	}
+	connectCache()
+	// Ledger postings are dated in the API's ledger timezone
+	services.SetLedgerConfig(services.LedgerConfig{Timezone: os.Getenv("LEDGER_TIMEZONE")})
+
+	// Jobs log their own progress and errors.
+	job.run()
+	fmt.Printf("%s finished\n", fs.Arg(0))
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxTokenTTL caps minted tokens; they are for debugging, not integrations
+// (use an API key for those).
+const maxTokenTTL = 24 * time.Hour
+
+// runToken mints an HS256 token the API accepts as the given user. Admin
+// access comes from the user's roles in the database, not from the token.
+func runToken(args []string) error {
+	fs := newFlagSet("token", "token (-sub <user-uuid> | -role <role>) [flags]")
+	sub := fs.String("sub", "", "user ID (UUID) to put in the sub claim")
+	role := fs.String("role", "", "mint for the earliest holder of this role instead of -sub (needs DATABASE_URL)")
+	email := fs.String("email", "", "email claim (defaults to the role holder's email with -role)")
+	ttl := fs.Duration("ttl", 15*time.Minute, "token lifetime (max 24h)")
+	aud := fs.String("aud", envOr("JWT_AUDIENCE", "authenticated"), "audience claim (must match JWT_AUDIENCE)")
+	iss := fs.String("iss", defaultIssuer(), "issuer claim (must match JWT_ISSUER, default <SUPABASE_URL>/auth/v1)")
+	secretFile := fs.String("secret-file", "", "read the signing secret from this file instead of JWT_SECRET")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if (*sub == "") == (*role == "") {
+		fs.Usage()
+		return errors.New("exactly one of -sub or -role is required")
+	}
+	if *ttl <= 0 || *ttl > maxTokenTTL {
+		return errors.New("-ttl must be between 1s and 24h")
+	}
+	if *iss == "" {
+		return errors.New("-iss is required when SUPABASE_URL is not set")
+	}
+
+	secret, err := signingSecret(*secretFile)
+	if err != nil {
+		return err
+	}
+
+	if *role != "" {
+		holderID, holderEmail, err := roleHolder(rbac.Role(*role))
+		if err != nil {
+			return err
+		}
+		*sub = holderID
+		if *email == "" {
+			*email = holderEmail
+		}
+	}
+	if _, err := uuid.Parse(*sub); err != nil {
+		return errors.New("-sub must be a user UUID")
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": *sub,
+		"jti": uuid.New().String(),
+		"aud": *aud,
+		"iss": *iss,
+		"exp": now.Add(*ttl).Unix(),
+		"iat": now.Unix(),
+	}
+	if *email != "" {
+		claims["email"] = *email
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return fmt.Errorf("sign token: %w", err)
+	}
+	fmt.Println(signed)
+	return nil
+}
+
+func signingSecret(secretFile string) ([]byte, error) {
+	if secretFile != "" {
+		secret, err := os.ReadFile(secretFile)
+		if err != nil {
+			return nil, fmt.Errorf("read secret file: %w", err)
+		}
+		return []byte(strings.TrimSpace(string(secret))), nil
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	return nil, errors.New("JWT_SECRET is not set (or pass -secret-file)")
+}
+
+// roleHolder returns the earliest-granted user holding role.
+func roleHolder(role rbac.Role) (string, string, error) {
+	if !rbac.IsValid(role) {
+		return "", "", fmt.Errorf("unknown role %q", role)
+	}
+	if err := connectDB(); err != nil {
+		return "", "", err
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	var userID, email string
+	err := database.Pool.QueryRow(ctx,
+		`SELECT user_id, email FROM user_roles WHERE role = $1 ORDER BY created_at LIMIT 1`,
+		string(role),
+	).Scan(&userID, &email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", fmt.Errorf("no user holds role %q", role)
+	}
+	return userID, email, err
+}
+
+func defaultIssuer() string {
+	if iss := os.Getenv("JWT_ISSUER"); iss != "" {
+		return iss
+	}
+	if base := strings.TrimRight(os.Getenv("SUPABASE_URL"), "/"); base != "" {
+		return base + "/auth/v1"
+	}
+	return ""
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -2,11 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
 	"github.com/joho/godotenv"
 )
 
@@ -20,15 +23,55 @@ type Config struct {
 	AdminEmails     []string
 	AllowedOrigins  []string
 
+	// JWT verification (issuer and JWKS URL default to the Supabase project)
+	JWTAudience         string
+	JWTIssuer           string
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+
 	// Redis Cache Config
 	RedisURL     string
 	CacheEnabled bool
 
+	// Supabase admin API key, used to delete auth users on account erasure
+	SupabaseServiceRoleKey string
+
+	// Field-level encryption of booking names and emails (disabled when no keys are set)
+	PIIKeys     []pii.Key // first key encrypts; the rest only decrypt, for rotation
+	PIIIndexKey []byte    // HMAC key for blind indexes; changing it requires re-indexing
+
+	// Data retention (see DefaultRetention)
+	Retention       map[string]time.Duration // max age per policy; 0 disables a policy
+	RetentionDryRun bool                     // report what would change without changing it
+
+	// Rate limiting
+	TrustedProxies []netip.Prefix       // peers whose X-Forwarded-For / X-Real-IP is believed
+	RateLimits     map[string]RateLimit // named per-route policies, see DefaultRateLimits
+
 	// Booking policy
 	BookingSafeMode         bool
 	BookingSearchWindowDays int
 	BookingMaxBookableDates int
 	BookingTimeSlots        []string
+	BookingSessionLength    time.Duration // how long a session runs (0 = 45m); bounds the join window
+	BookingLateCancelWindow time.Duration // cancellations this close to the start are late_cancelled
+
+	// Booking hold abuse protection (0 disables a limit)
+	BookingMaxActiveHolds      int           // unpaid holds per user at once
+	BookingMaxActiveHoldsPerIP int           // unpaid holds per client IP at once
+	BookingMaxUpcomingPerUser  int           // confirmed future bookings per user
+	BookingAbandonLimit        int           // abandoned holds within BookingAbandonWindow before a cooldown
+	BookingAbandonWindow       time.Duration // lookback for BookingAbandonLimit
+	BookingHoldCooldown        time.Duration // how long new holds are refused after the limit is hit
+	TurnstileSecretKey         string        // enables CAPTCHA verification on booking creation
+
+	// Crisis screening of client free text
+	CrisisAlertEmails   []string // on-call addresses alerted on a crisis signal; empty alerts mentors and owners
+	CrisisDefaultRegion string   // ISO country whose helplines are shown when the client's is unknown
+
+	// Accounting ledger (see DefaultLedgerAccounts)
+	LedgerAccounts map[string]string // ledger account names used in accounting exports
+	LedgerTimezone string            // IANA zone ledger dates and periods are cut in
 
 	// SMTP Config (legacy)
 	SMTPHost string
@@ -41,6 +84,49 @@ type Config struct {
 	ResendFromEmail string
 }
 
+// RateLimit allows Limit requests per Period per client. Tokens refill
+// continuously, so a client may burst up to Limit requests at once.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// DefaultRateLimits are the built-in policies. RATE_LIMITS overrides them by
+// name, e.g. "booking=5/1m,payment=3/1m".
+var DefaultRateLimits = map[string]RateLimit{
+	"global":  {Limit: 200, Period: time.Minute}, // every request, per client IP
+	"user":    {Limit: 120, Period: time.Minute}, // authenticated routes, per user or API key
+	"booking": {Limit: 10, Period: time.Minute},  // booking creation
+	"payment": {Limit: 5, Period: time.Minute},   // payment verification
+}
+
+// DefaultRetention is how long data is kept before each retention policy
+// removes or anonymises it. RETENTION overrides them by name, e.g.
+// "processed_webhooks=30d,audit_log_pii=0" (0 disables a policy).
+var DefaultRetention = map[string]time.Duration{
+	"processed_webhooks": 90 * 24 * time.Hour,  // delete webhook idempotency records
+	"audit_log_pii":      365 * 24 * time.Hour, // clear IP and user agent on audit entries
+	"failed_bookings":    30 * 24 * time.Hour,  // redact name and email on failed holds
+	"cancelled_bookings": 365 * 24 * time.Hour, // redact name and email on cancelled bookings
+}
+
+// DefaultLedgerAccounts names the ledger accounts in accounting exports.
+// LEDGER_ACCOUNTS overrides them by account, e.g.
+// "bank=HDFC Current A/c,gateway_fees=Razorpay Charges"; the names must match
+// the ledgers set up in Tally or the accounts in Zoho Books.
+var DefaultLedgerAccounts = map[string]string{
+	"razorpay_clearing": "Razorpay Clearing", // captured but not yet settled
+	"bank":              "Bank",              // settlements and offline payments
+	"session_revenue":   "Session Revenue",
+	"discounts_allowed": "Discounts Allowed", // coupon discounts
+	"gateway_fees":      "Payment Gateway Charges",
+	"gst_input_credit":  "GST Input Credit", // GST charged on gateway fees
+}
+
+// defaultTrustedProxies covers loopback and private networks, where the
+// hosting platform's load balancer connects from.
+const defaultTrustedProxies = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"
+
 // ValidationError contains details about missing or invalid configuration
 type ValidationError struct {
 	Missing []string
@@ -72,6 +158,39 @@ func Load() (*Config, error) {
 		defaultMaxBookableDates = 2
 	}
 
+	trustedProxies, proxiesErr := parseTrustedProxies(getEnv("TRUSTED_PROXIES", defaultTrustedProxies))
+	rateLimits, rateLimitsErr := parseRateLimits(getEnv("RATE_LIMITS", ""))
+	retention, retentionErr := parseRetention(getEnv("RETENTION", ""))
+	ledgerAccounts, ledgerAccountsErr := parseLedgerAccounts(getEnv("LEDGER_ACCOUNTS", ""))
+	piiKeys, piiKeysErr := pii.ParseKeys(getEnv("PII_ENCRYPTION_KEYS", ""))
+	var piiIndexKey []byte
+	var piiIndexKeyErr error
+	if raw := getEnv("PII_BLIND_INDEX_KEY", ""); raw != "" {
+		piiIndexKey, piiIndexKeyErr = pii.DecodeKey(raw)
+	}
+	if proxiesErr != nil || rateLimitsErr != nil || retentionErr != nil || ledgerAccountsErr != nil || piiKeysErr != nil || piiIndexKeyErr != nil {
+		valErr := &ValidationError{Invalid: make(map[string]string)}
+		if proxiesErr != nil {
+			valErr.Invalid["TRUSTED_PROXIES"] = proxiesErr.Error()
+		}
+		if rateLimitsErr != nil {
+			valErr.Invalid["RATE_LIMITS"] = rateLimitsErr.Error()
+		}
+		if retentionErr != nil {
+			valErr.Invalid["RETENTION"] = retentionErr.Error()
+		}
+		if ledgerAccountsErr != nil {
+			valErr.Invalid["LEDGER_ACCOUNTS"] = ledgerAccountsErr.Error()
+		}
+		if piiKeysErr != nil {
+			valErr.Invalid["PII_ENCRYPTION_KEYS"] = piiKeysErr.Error()
+		}
+		if piiIndexKeyErr != nil {
+			valErr.Invalid["PII_BLIND_INDEX_KEY"] = piiIndexKeyErr.Error()
+		}
+		return nil, valErr
+	}
+
 	cfg := &Config{
 		Port:            getEnv("PORT", "8080"),
 		Environment:     getEnv("ENVIRONMENT", "development"),
@@ -82,13 +201,45 @@ func Load() (*Config, error) {
 		AdminEmails:     getSliceEnv("ADMIN_EMAILS", ","),
 		AllowedOrigins:  getSliceEnv("ALLOWED_ORIGINS", ","),
 
+		JWTAudience:         getEnv("JWT_AUDIENCE", "authenticated"),
+		JWTIssuer:           getEnv("JWT_ISSUER", ""),
+		JWKSURL:             getEnv("SUPABASE_JWKS_URL", ""),
+		JWKSRefreshInterval: getDurationEnv("JWKS_REFRESH_INTERVAL", 10*time.Minute),
+
 		RedisURL:     getEnv("REDIS_URL", ""),
 		CacheEnabled: getBoolEnv("CACHE_ENABLED", true),
 
+		SupabaseServiceRoleKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
+
+		PIIKeys:     piiKeys,
+		PIIIndexKey: piiIndexKey,
+
+		Retention:       retention,
+		RetentionDryRun: getBoolEnv("RETENTION_DRY_RUN", false),
+
+		TrustedProxies: trustedProxies,
+		RateLimits:     rateLimits,
+
 		BookingSafeMode:         bookingSafeMode,
 		BookingSearchWindowDays: getIntEnv("BOOKING_SEARCH_WINDOW_DAYS", 21),
 		BookingMaxBookableDates: getIntEnv("BOOKING_MAX_BOOKABLE_DATES", defaultMaxBookableDates),
 		BookingTimeSlots:        getTrimmedSliceEnv("BOOKING_TIME_SLOTS", ","),
+		BookingSessionLength:    getDurationEnv("BOOKING_SESSION_LENGTH", 45*time.Minute),
+		BookingLateCancelWindow: getDurationEnv("BOOKING_LATE_CANCEL_WINDOW", 24*time.Hour),
+
+		BookingMaxActiveHolds:      getIntEnv("BOOKING_MAX_ACTIVE_HOLDS", 2),
+		BookingMaxActiveHoldsPerIP: getIntEnv("BOOKING_MAX_ACTIVE_HOLDS_PER_IP", 4),
+		BookingMaxUpcomingPerUser:  getIntEnv("BOOKING_MAX_UPCOMING_PER_USER", 2),
+		BookingAbandonLimit:        getIntEnv("BOOKING_ABANDON_LIMIT", 3),
+		BookingAbandonWindow:       getDurationEnv("BOOKING_ABANDON_WINDOW", 24*time.Hour),
+		BookingHoldCooldown:        getDurationEnv("BOOKING_HOLD_COOLDOWN", time.Hour),
+		TurnstileSecretKey:         getEnv("TURNSTILE_SECRET_KEY", ""),
+
+		CrisisAlertEmails:   getSliceEnv("CRISIS_ALERT_EMAILS", ","),
+		CrisisDefaultRegion: getEnv("CRISIS_DEFAULT_REGION", "IN"),
+
+		LedgerAccounts: ledgerAccounts,
+		LedgerTimezone: getEnv("LEDGER_TIMEZONE", "Asia/Kolkata"),
 
 		SMTPHost: getEnv("SMTP_HOST", ""),
 		SMTPPort: getIntEnv("SMTP_PORT", 587),
@@ -142,6 +293,35 @@ func (c *Config) Validate() error {
 		valErr.Invalid["JWT_SECRET"] = "must be at least 32 characters"
 	}
 
+	// Zero means "use the default"; anything shorter than a minute would hammer the JWKS endpoint
+	if c.JWKSRefreshInterval != 0 && c.JWKSRefreshInterval < time.Minute {
+		valErr.Invalid["JWKS_REFRESH_INTERVAL"] = "must be at least 1m"
+	}
+
+	// Zero-valued limits would reject (or divide by) everything
+	for _, name := range sortedRateLimitNames(c.RateLimits) {
+		limit := c.RateLimits[name]
+		if limit.Limit < 1 || limit.Period < time.Second {
+			valErr.Invalid["RATE_LIMITS"] = fmt.Sprintf("%s: limit must be positive and period at least 1s", name)
+		}
+	}
+
+	// A typo such as "30m" for 30 days would otherwise wipe recent data
+	for name, age := range c.Retention {
+		if age != 0 && age < 24*time.Hour {
+			valErr.Invalid["RETENTION"] = fmt.Sprintf("%s: age must be 0 (disabled) or at least 1d", name)
+		}
+	}
+
+	// Keys and the index key only make sense together
+	if len(c.PIIKeys) > 0 {
+		if _, err := pii.New(c.PIIKeys, c.PIIIndexKey); err != nil {
+			valErr.Invalid["PII_ENCRYPTION_KEYS"] = err.Error()
+		}
+	} else if len(c.PIIIndexKey) > 0 {
+		valErr.Invalid["PII_BLIND_INDEX_KEY"] = "set PII_ENCRYPTION_KEYS as well"
+	}
+
 	// Validate environment
 	validEnvs := map[string]bool{"development": true, "staging": true, "production": true}
 	if !validEnvs[c.Environment] {
@@ -180,6 +360,29 @@ func (c *Config) Validate() error {
 	if len(timeSlots) == 0 {
 		valErr.Invalid["BOOKING_TIME_SLOTS"] = "must contain at least one slot"
 	}
+	for key, value := range map[string]int{
+		"BOOKING_MAX_ACTIVE_HOLDS":        c.BookingMaxActiveHolds,
+		"BOOKING_MAX_ACTIVE_HOLDS_PER_IP": c.BookingMaxActiveHoldsPerIP,
+		"BOOKING_MAX_UPCOMING_PER_USER":   c.BookingMaxUpcomingPerUser,
+		"BOOKING_ABANDON_LIMIT":           c.BookingAbandonLimit,
+	} {
+		if value < 0 {
+			valErr.Invalid[key] = "must be 0 (unlimited) or positive"
+		}
+	}
+	if c.BookingSessionLength < 0 {
+		valErr.Invalid["BOOKING_SESSION_LENGTH"] = "must be positive"
+	}
+	if c.BookingLateCancelWindow < 0 {
+		valErr.Invalid["BOOKING_LATE_CANCEL_WINDOW"] = "must be 0 (disabled) or positive"
+	}
+	if c.BookingAbandonLimit > 0 && (c.BookingAbandonWindow <= 0 || c.BookingHoldCooldown <= 0) {
+		valErr.Invalid["BOOKING_HOLD_COOLDOWN"] = "BOOKING_ABANDON_WINDOW and BOOKING_HOLD_COOLDOWN must be positive when BOOKING_ABANDON_LIMIT is set"
+	}
+
+	if _, err := time.LoadLocation(c.LedgerTimezone); err != nil {
+		valErr.Invalid["LEDGER_TIMEZONE"] = "must be an IANA timezone name"
+	}
 
 	// Production-specific validation
 	if c.Environment == "production" {
@@ -244,6 +447,141 @@ func getTrimmedSliceEnv(key, separator string) []string {
 	return out
 }
 
+// parseTrustedProxies parses comma-separated CIDRs or single addresses.
+func parseTrustedProxies(raw string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// parseRateLimits merges "name=limit/period" overrides onto DefaultRateLimits.
+func parseRateLimits(raw string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit, len(DefaultRateLimits))
+	for name, limit := range DefaultRateLimits {
+		limits[name] = limit
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		rawLimit, rawPeriod, ok2 := strings.Cut(spec, "/")
+		if !ok || !ok2 || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%q must look like name=limit/period", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(rawLimit))
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid limit", entry)
+		}
+		period, err := time.ParseDuration(strings.TrimSpace(rawPeriod))
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid period", entry)
+		}
+		limits[strings.TrimSpace(name)] = RateLimit{Limit: limit, Period: period}
+	}
+	return limits, nil
+}
+
+// parseRetention merges "name=age" overrides onto DefaultRetention. Ages are
+// whole days ("90d") or Go durations ("2160h"); only known policies are accepted.
+func parseRetention(raw string) (map[string]time.Duration, error) {
+	retention := make(map[string]time.Duration, len(DefaultRetention))
+	for name, age := range DefaultRetention {
+		retention[name] = age
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rawAge, ok := strings.Cut(entry, "=")
+		name, rawAge = strings.TrimSpace(name), strings.TrimSpace(rawAge)
+		if !ok {
+			return nil, fmt.Errorf("%q must look like name=age", entry)
+		}
+		if _, known := DefaultRetention[name]; !known {
+			return nil, fmt.Errorf("unknown retention policy %q", name)
+		}
+		age, err := parseDays(rawAge)
+		if err != nil || age < 0 {
+			return nil, fmt.Errorf("%q: invalid age", entry)
+		}
+		retention[name] = age
+	}
+	return retention, nil
+}
+
+// parseLedgerAccounts merges "account=name" overrides onto
+// DefaultLedgerAccounts; only known accounts are accepted.
+func parseLedgerAccounts(raw string) (map[string]string, error) {
+	accounts := make(map[string]string, len(DefaultLedgerAccounts))
+	for account, name := range DefaultLedgerAccounts {
+		accounts[account] = name
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		account, name, ok := strings.Cut(entry, "=")
+		account, name = strings.TrimSpace(account), strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q must look like account=name", entry)
+		}
+		if _, known := DefaultLedgerAccounts[account]; !known {
+			return nil, fmt.Errorf("unknown ledger account %q", account)
+		}
+		accounts[account] = name
+	}
+	return accounts, nil
+}
+
+// parseDays parses "30d" as 30 days, "0" as zero, and anything else as a Go duration.
+func parseDays(raw string) (time.Duration, error) {
+	if raw == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+func sortedRateLimitNames(limits map[string]RateLimit) []string {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	valueStr := getEnv(key, "")
 	if duration, err := time.ParseDuration(valueStr); err == nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -206,6 +207,62 @@ func TestLoad_WithEnvVars(t *testing.T) {
 	assert.Equal(t, "test-key", cfg.SupabaseAnonKey)
 }
 
+func TestParseRateLimits(t *testing.T) {
+	limits, err := parseRateLimits("booking=3/30s, search=50/1m")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Limit: 3, Period: 30 * time.Second}, limits["booking"])
+	assert.Equal(t, RateLimit{Limit: 50, Period: time.Minute}, limits["search"])
+	assert.Equal(t, DefaultRateLimits["global"], limits["global"])
+
+	for _, raw := range []string{"booking", "booking=3", "booking=x/1m", "booking=3/soon", "=3/1m"} {
+		_, err := parseRateLimits(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := parseTrustedProxies("10.0.0.0/8, 203.0.113.7,,::1")
+	require.NoError(t, err)
+	require.Len(t, prefixes, 3)
+	assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+	assert.Equal(t, "203.0.113.7/32", prefixes[1].String())
+	assert.Equal(t, "::1/128", prefixes[2].String())
+
+	prefixes, err = parseTrustedProxies("")
+	require.NoError(t, err)
+	assert.Empty(t, prefixes)
+
+	_, err = parseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
+
+func TestParseRetention(t *testing.T) {
+	retention, err := parseRetention("processed_webhooks=30d, audit_log_pii=0, failed_bookings=720h")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, retention["processed_webhooks"])
+	assert.Equal(t, time.Duration(0), retention["audit_log_pii"])
+	assert.Equal(t, 30*24*time.Hour, retention["failed_bookings"])
+	assert.Equal(t, DefaultRetention["cancelled_bookings"], retention["cancelled_bookings"])
+
+	for _, raw := range []string{"unknown=30d", "processed_webhooks", "processed_webhooks=soon", "processed_webhooks=-1d"} {
+		_, err := parseRetention(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestParseLedgerAccounts(t *testing.T) {
+	accounts, err := parseLedgerAccounts("bank=HDFC Current A/c, gateway_fees = Razorpay Charges")
+	require.NoError(t, err)
+	assert.Equal(t, "HDFC Current A/c", accounts["bank"])
+	assert.Equal(t, "Razorpay Charges", accounts["gateway_fees"])
+	assert.Equal(t, DefaultLedgerAccounts["session_revenue"], accounts["session_revenue"])
+
+	for _, raw := range []string{"cash=Cash", "bank", "bank="} {
+		_, err := parseLedgerAccounts(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsStr(s, substr))
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Migrations use golang-migrate's file layout (NNNNNN_name.up.sql /
+// NNNNNN_name.down.sql) and its schema_migrations table, so a database
+// migrated with the migrate CLI and one migrated with hdctl are interchangeable.
+
+// NoMigrationVersion is the version of a database no migration has run on.
+const NoMigrationVersion int64 = -1
+
+// migrationLockID is held as an advisory lock while migrating so two runners
+// never apply the same files concurrently.
+const migrationLockID int64 = 7302148861
+
+// ErrDirtyMigration means a previous migration failed part-way. Repair the
+// schema by hand, then force the version.
+var ErrDirtyMigration = errors.New("database is in a dirty migration state")
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpFile   string
+	DownFile string
+}
+
+// MigrationStatus is the version recorded in schema_migrations.
+type MigrationStatus struct {
+	Version int64 // NoMigrationVersion when nothing has been applied
+	Dirty   bool
+}
+
+// migrationDB is satisfied by both the pool and a single acquired connection.
+type migrationDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// parseMigrationFilename splits "000012_add_x.up.sql" into its parts.
+func parseMigrationFilename(filename string) (version int64, name, direction string, ok bool) {
+	base, found := strings.CutSuffix(filename, ".sql")
+	if !found {
+		return 0, "", "", false
+	}
+	if b, found := strings.CutSuffix(base, ".up"); found {
+		base, direction = b, "up"
+	} else if b, found := strings.CutSuffix(base, ".down"); found {
+		base, direction = b, "down"
+	} else {
+		return 0, "", "", false
+	}
+
+	rawVersion, name, found := strings.Cut(base, "_")
+	if !found || name == "" {
+		return 0, "", "", false
+	}
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil || version < 0 {
+		return 0, "", "", false
+	}
+	return version, name, direction, true
+}
+
+// LoadMigrations reads the migrations in dir, sorted by version.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		version, name, direction, ok := parseMigrationFilename(entry.Name())
+		if !ok {
+			continue
+		}
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %06d has conflicting names %q and %q", version, m.Name, name)
+		}
+		path := filepath.Join(dir, entry.Name())
+		if direction == "up" {
+			m.UpFile = path
+		} else {
+			m.DownFile = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpFile == "" {
+			return nil, fmt.Errorf("migration %06d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// pendingMigrations returns the migrations after current, oldest first.
+func pendingMigrations(migrations []Migration, current int64) []Migration {
+	for i, m := range migrations {
+		if m.Version > current {
+			return migrations[i:]
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the migrations up to and including current,
+// newest first.
+func appliedMigrations(migrations []Migration, current int64) []Migration {
+	applied := make([]Migration, 0, len(migrations))
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version <= current {
+			applied = append(applied, migrations[i])
+		}
+	}
+	return applied
+}
+
+// GetMigrationStatus returns the recorded schema version.
+func GetMigrationStatus(ctx context.Context) (MigrationStatus, error) {
+	if err := ensureMigrationTable(ctx, Pool); err != nil {
+		return MigrationStatus{}, err
+	}
+	return readMigrationStatus(ctx, Pool)
+}
+
+// MigrateUp applies up to steps pending migrations (all when steps <= 0) and
+// returns the ones applied.
+func MigrateUp(ctx context.Context, migrations []Migration, steps int) ([]Migration, error) {
+	return withMigrationLock(ctx, func(db migrationDB, status MigrationStatus) ([]Migration, error) {
+		pending := pendingMigrations(migrations, status.Version)
+		if steps > 0 && steps < len(pending) {
+			pending = pending[:steps]
+		}
+
+		done := make([]Migration, 0, len(pending))
+		for _, m := range pending {
+			if err := runMigrationFile(ctx, db, m.UpFile, m.Version, m.Version); err != nil {
+				return done, fmt.Errorf("migration %06d_%s: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return done, nil
+	})
+}
+
+// MigrateDown rolls back steps applied migrations (one when steps <= 0) and
+// returns the ones reverted.
+func MigrateDown(ctx context.Context, migrations []Migration, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	return withMigrationLock(ctx, func(db migrationDB, status MigrationStatus) ([]Migration, error) {
+		if status.Version == NoMigrationVersion {
+			return nil, nil
+		}
+		applied := appliedMigrations(migrations, status.Version)
+		if len(applied) == 0 || applied[0].Version != status.Version {
+			return nil, fmt.Errorf("no migration file for current version %d", status.Version)
+		}
+
+		done := make([]Migration, 0, steps)
+		for i := 0; i < steps && i < len(applied); i++ {
+			m := applied[i]
+			if m.DownFile == "" {
+				return done, fmt.Errorf("migration %06d_%s has no down file", m.Version, m.Name)
+			}
+			previous := NoMigrationVersion
+			if i+1 < len(applied) {
+				previous = applied[i+1].Version
+			}
+			if err := runMigrationFile(ctx, db, m.DownFile, m.Version, previous); err != nil {
+				return done, fmt.Errorf("migration %06d_%s (down): %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return done, nil
+	})
+}
+
+// ForceMigrationVersion records version as cleanly applied without running
+// anything. Use it after repairing a dirty migration by hand.
+func ForceMigrationVersion(ctx context.Context, version int64) error {
+	if err := ensureMigrationTable(ctx, Pool); err != nil {
+		return err
+	}
+	return setMigrationVersion(ctx, Pool, version, false)
+}
+
+func withMigrationLock(ctx context.Context, fn func(db migrationDB, status MigrationStatus) ([]Migration, error)) ([]Migration, error) {
+	// Advisory locks belong to a session, so hold one connection throughout.
+	conn, err := Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if err := ensureMigrationTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	status, err := readMigrationStatus(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if status.Dirty {
+		return nil, fmt.Errorf("%w at version %d", ErrDirtyMigration, status.Version)
+	}
+	return fn(conn, status)
+}
+
+// runMigrationFile executes one migration file. The version is marked dirty
+// first so a failure part-way stays visible, then set to next on success.
+func runMigrationFile(ctx context.Context, db migrationDB, path string, version, next int64) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := setMigrationVersion(ctx, db, version, true); err != nil {
+		return err
+	}
+	// Simple protocol so a file may hold several statements.
+	if _, err := db.Exec(ctx, string(body), pgx.QueryExecModeSimpleProtocol); err != nil {
+		return err
+	}
+	return setMigrationVersion(ctx, db, next, false)
+}
+
+func ensureMigrationTable(ctx context.Context, db migrationDB) error {
+	_, err := db.Exec(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)`,
+	)
+	return err
+}
+
+func readMigrationStatus(ctx context.Context, db migrationDB) (MigrationStatus, error) {
+	status := MigrationStatus{Version: NoMigrationVersion}
+	err := db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&status.Version, &status.Dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status, nil
+	}
+	return status, err
+}
+
+// setMigrationVersion replaces the single schema_migrations row. The table is
+// left empty for NoMigrationVersion.
+func setMigrationVersion(ctx context.Context, db migrationDB, version int64, dirty bool) error {
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+			return err
+		}
+		if version == NoMigrationVersion {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty)
+		return err
+	})
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMigrationFilename(t *testing.T) {
+	tests := []struct {
+		filename  string
+		version   int64
+		name      string
+		direction string
+		ok        bool
+	}{
+		{"000012_reconcile_processed_webhooks.up.sql", 12, "reconcile_processed_webhooks", "up", true},
+		{"000001_init_schema.down.sql", 1, "init_schema", "down", true},
+		{"000003_add.sideways.sql", 0, "", "", false},
+		{"000004_no_direction.sql", 0, "", "", false},
+		{"README.md", 0, "", "", false},
+		{"abc_name.up.sql", 0, "", "", false},
+		{"000005.up.sql", 0, "", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.filename, func(t *testing.T) {
+			version, name, direction, ok := parseMigrationFilename(tc.filename)
+			if ok != tc.ok || version != tc.version || name != tc.name || direction != tc.direction {
+				t.Fatalf("parseMigrationFilename(%q) = %d, %q, %q, %v; want %d, %q, %q, %v",
+					tc.filename, version, name, direction, ok, tc.version, tc.name, tc.direction, tc.ok)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations("../../migrations")
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("no migrations loaded")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d has version %d; versions must be contiguous from 1", i, m.Version)
+		}
+		if m.UpFile == "" || m.DownFile == "" {
+			t.Fatalf("migration %06d_%s is missing an up or down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestPendingAndAppliedMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+
+	tests := []struct {
+		current int64
+		pending []int64
+		applied []int64
+	}{
+		{NoMigrationVersion, []int64{1, 2, 5}, nil},
+		{2, []int64{5}, []int64{2, 1}},
+		{5, nil, []int64{5, 2, 1}},
+	}
+
+	for _, tc := range tests {
+		if got := versions(pendingMigrations(migrations, tc.current)); !slices.Equal(got, tc.pending) {
+			t.Fatalf("pendingMigrations(%d) = %v, want %v", tc.current, got, tc.pending)
+		}
+		if got := versions(appliedMigrations(migrations, tc.current)); !slices.Equal(got, tc.applied) {
+			t.Fatalf("appliedMigrations(%d) = %v, want %v", tc.current, got, tc.applied)
+		}
+	}
+}
+
+func versions(migrations []Migration) []int64 {
+	out := make([]int64, 0, len(migrations))
+	for _, m := range migrations {
+		out = append(out, m.Version)
+	}
+	return out
+}
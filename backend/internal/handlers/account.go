@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// accountDataTimeout bounds export and erasure, which touch several tables.
+const accountDataTimeout = 30 * time.Second
+
+// DeleteAccountRequest is the body accepted by DeleteMyAccount.
+type DeleteAccountRequest struct {
+	Confirm bool `json:"confirm"`
+}
+
+// ExportMyData godoc
+// @Summary Export my data
+// @Description Downloads a ZIP archive of everything stored about the caller: profile, bookings, subscriptions, coupon uses, audit entries and roles, one JSON file each. Audited.
+// @Tags Users
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 401 {object} map[string]interface{}
+// @Router /me/export [get]
+// @Security BearerAuth
+func ExportMyData(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), accountDataTimeout)
+	defer cancel()
+
+	archive, err := services.ExportUserData(ctx, userID)
+	if err != nil {
+		logger.Log.Error("Data export failed", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("export user data", err))
+		return
+	}
+
+	audit.Log(r.Context(), "account.export", userID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"bytes": len(archive),
+	})
+
+	filename := fmt.Sprintf("hidden-depths-export-%s.zip", time.Now().UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+// DeleteMyAccount godoc
+// @Summary Delete my account
+// @Description Erases the caller's personal data. Bookings are anonymised but kept for financial records, unpaid holds are released, preferences and roles are deleted, and every session is revoked. Refused while confirmed sessions are upcoming. Audited.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body DeleteAccountRequest true "Must set confirm to true"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Upcoming sessions must be cancelled first"
+// @Router /me [delete]
+// @Security BearerAuth
+func DeleteMyAccount(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	if !req.Confirm {
+		response.AppErr(w, apperror.ValidationError("confirm", "Set confirm to true to delete your account"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), accountDataTimeout)
+	defer cancel()
+
+	summary, err := services.EraseUserData(ctx, userID)
+	if errors.Is(err, services.ErrUpcomingBookings) {
+		response.AppErr(w, apperror.AccountHasUpcomingBookings())
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Account erasure failed", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("erase user data", err))
+		return
+	}
+
+	for _, date := range summary.HoldsReleased {
+		InvalidateSlotsCache(ctx, date)
+	}
+
+	// No IP or user agent: the record must not reintroduce what was erased.
+	audit.Log(r.Context(), "account.erase", "", userID, "user", "", "", summary)
+
+	response.JSON(w, http.StatusOK, summary, "Account deleted")
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 	"time"
 
@@ -12,6 +12,7 @@ import (
 	"github.com/Himadryy/hidden-depths-backend/internal/services"
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
 	"github.com/Himadryy/hidden-depths-backend/pkg/response"
 	"go.uber.org/zap"
 )
@@ -20,6 +21,8 @@ type AdminStats struct {
 	TotalBookings    int     `json:"total_bookings"`
 	UpcomingBookings int     `json:"upcoming_bookings"`
 	Revenue          float64 `json:"estimated_revenue"`
+	// Bookings by session status (scheduled, completed, no_show_client, ...)
+	Sessions map[string]int `json:"sessions"`
 }
 
 type AdminBooking struct {
@@ -30,6 +33,8 @@ type AdminBooking struct {
 	Time              string    `json:"time"`
 	PaymentStatus     string    `json:"payment_status"`
 	RazorpayPaymentID string    `json:"razorpay_payment_id,omitempty"`
+	SessionStatus     *string   `json:"session_status,omitempty"`
+	RefundID          *string   `json:"refund_id,omitempty"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
@@ -61,10 +66,40 @@ func GetAdminStats(w http.ResponseWriter, r *http.Request) {
 		revenue = 0
 	}
 
+	sessions := make(map[string]int, len(sessionStatuses))
+	for _, status := range sessionStatuses {
+		if status != sessionStatusNone {
+			sessions[status] = 0
+		}
+	}
+	rows, err := database.Pool.Query(ctx,
+		"SELECT session_status, COUNT(*) FROM bookings WHERE session_status IS NOT NULL GROUP BY session_status")
+	if err != nil {
+		logger.Log.Error("Failed to count session statuses", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("count session statuses", err))
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			logger.Log.Warn("Failed to scan session status count", zap.Error(err))
+			continue
+		}
+		sessions[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Session status count query error", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("count session statuses", err))
+		return
+	}
+
 	stats := AdminStats{
 		TotalBookings:    total,
 		UpcomingBookings: upcoming,
 		Revenue:          revenue,
+		Sessions:         sessions,
 	}
 
 	response.JSON(w, http.StatusOK, stats, "Admin stats fetched")
@@ -118,15 +153,31 @@ func TestEmail(w http.ResponseWriter, r *http.Request) {
 	}, "Test email sent successfully")
 }
 
+// adminBookingsPage is how the admin booking list pages and sorts.
+var adminBookingsPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+		"date":       {Expr: "date", Type: pagination.TypeText},
+		"amount":     {Expr: "COALESCE(amount, 0)", Type: pagination.TypeNumeric},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(AdminBooking{}),
+}
+
 // GetAdminBookings godoc
 // @Summary List bookings (Admin)
-// @Description Returns paginated bookings for the admin dashboard.
+// @Description Returns a page of bookings for the admin dashboard. Follow pagination.next_cursor (or the Link header) for the next page.
 // @Tags Admin
 // @Produce json
-// @Param page query int false "Page number (1-based)"
-// @Param per_page query int false "Results per page (max 100)"
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: created_at, date or amount (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
 // @Param status query string false "Status filter (paid, pending, failed, cancelled, confirmed)"
-// @Param search query string false "Search by name, email, booking id, or user id"
+// @Param search query string false "Search by full name or email (exact, case-insensitive, when PII encryption is on), or part of a booking id or user id"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
@@ -138,76 +189,28 @@ func TestEmail(w http.ResponseWriter, r *http.Request) {
 func GetAdminBookings(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	page := 1
-	perPage := 20
-
-	if raw := strings.TrimSpace(r.URL.Query().Get("page")); raw != "" {
-		val, err := strconv.Atoi(raw)
-		if err != nil || val < 1 {
-			response.AppErr(w, apperror.ValidationError("page", "Page must be a positive integer"))
-			return
-		}
-		page = val
-	}
-
-	if raw := strings.TrimSpace(r.URL.Query().Get("per_page")); raw != "" {
-		val, err := strconv.Atoi(raw)
-		if err != nil || val < 1 {
-			response.AppErr(w, apperror.ValidationError("per_page", "per_page must be a positive integer"))
-			return
-		}
-		if val > 100 {
-			val = 100
-		}
-		perPage = val
-	}
-
-	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
-	if status == "confirmed" {
-		status = "paid"
-	}
-
-	if status != "" && status != "all" && status != "paid" && status != "pending" && status != "failed" && status != "cancelled" {
-		response.AppErr(w, apperror.ValidationError("status", "Invalid status filter"))
+	p, appErr := pagination.Parse(r.URL.Query(), adminBookingsPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
 		return
 	}
 
-	search := strings.TrimSpace(r.URL.Query().Get("search"))
-	searchValue := ""
-	if search != "" {
-		searchValue = "%" + strings.ToLower(search) + "%"
-	}
-
-	conditions := make([]string, 0, 2)
-	args := make([]interface{}, 0, 4)
-	argIndex := 1
-
-	if status != "" && status != "all" {
-		conditions = append(conditions, fmt.Sprintf("payment_status = $%d", argIndex))
-		args = append(args, status)
-		argIndex++
-	}
-
-	if searchValue != "" {
-		conditions = append(conditions, fmt.Sprintf(`(LOWER(email) LIKE $%d OR LOWER(name) LIKE $%d OR id::text ILIKE $%d OR COALESCE(user_id::text, '') ILIKE $%d)`, argIndex, argIndex, argIndex, argIndex))
-		args = append(args, searchValue)
-		argIndex++
-	}
-
-	whereClause := "TRUE"
-	if len(conditions) > 0 {
-		whereClause = strings.Join(conditions, " AND ")
+	whereClause, args, appErr := adminBookingConditions(r.URL.Query())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
 	}
+	after, afterArgs := p.Where(len(args) + 1)
+	args = append(args, afterArgs...)
+	args = append(args, p.Fetch())
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, email, date, time, payment_status, COALESCE(razorpay_payment_id, ''), created_at,
-		       COUNT(*) OVER() AS total
+		SELECT id, user_id, email, date, time, payment_status, COALESCE(razorpay_payment_id, ''), session_status, refund_id, created_at,
+		       %s
 		FROM bookings
-		WHERE %s
-		ORDER BY created_at DESC
-		LIMIT $%d OFFSET $%d`, whereClause, argIndex, argIndex+1)
-
-	args = append(args, perPage, (page-1)*perPage)
+		WHERE %s AND %s
+		ORDER BY %s
+		LIMIT $%d`, p.Select(), whereClause, after, p.OrderBy(), len(args))
 
 	rows, err := database.Pool.Query(ctx, query, args...)
 	if err != nil {
@@ -217,15 +220,15 @@ func GetAdminBookings(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	bookings := make([]AdminBooking, 0, perPage)
-	total := 0
+	bookings := make([]AdminBooking, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
 
 	for rows.Next() {
 		var (
-			booking       AdminBooking
-			userID        *string
-			paymentID     string
-			rowTotalCount int
+			booking   AdminBooking
+			userID    *string
+			paymentID string
+			pos       pagination.Position
 		)
 		if err := rows.Scan(
 			&booking.ID,
@@ -235,20 +238,26 @@ func GetAdminBookings(w http.ResponseWriter, r *http.Request) {
 			&booking.Time,
 			&booking.PaymentStatus,
 			&paymentID,
+			&booking.SessionStatus,
+			&booking.RefundID,
 			&booking.CreatedAt,
-			&rowTotalCount,
+			&pos.Value,
+			&pos.Key,
 		); err != nil {
 			logger.Log.Warn("Failed to scan admin booking row", zap.Error(err))
 			continue
 		}
+		if err := services.OpenBookingPII(nil, &booking.UserEmail); err != nil {
+			logger.Log.Warn("Failed to decrypt admin booking email", zap.String("booking_id", booking.ID), zap.Error(err))
+		}
 		if userID != nil {
 			booking.UserID = *userID
 		}
 		if paymentID != "" {
 			booking.RazorpayPaymentID = paymentID
 		}
-		total = rowTotalCount
 		bookings = append(bookings, booking)
+		positions = append(positions, pos)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -257,10 +266,43 @@ func GetAdminBookings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"bookings": bookings,
-		"total":    total,
-		"page":     page,
-		"per_page": perPage,
-	}, "Admin bookings fetched")
+	pagination.Respond(w, r, p, pagination.NewPage(p, bookings, positions), "Admin bookings fetched")
+}
+
+// adminBookingConditions reads the status and search filters shared by the
+// admin booking list and exports, and returns the WHERE clause and its
+// arguments.
+func adminBookingConditions(q url.Values) (string, []interface{}, *apperror.AppError) {
+	status := strings.TrimSpace(strings.ToLower(q.Get("status")))
+	if status == "confirmed" {
+		status = "paid"
+	}
+
+	if status != "" && status != "all" && status != "paid" && status != "pending" && status != "failed" && status != "cancelled" {
+		return "", nil, apperror.ValidationError("status", "Invalid status filter")
+	}
+
+	search := strings.TrimSpace(q.Get("search"))
+
+	conditions := make([]string, 0, 2)
+	args := make([]interface{}, 0, 4)
+	argIndex := 1
+
+	if status != "" && status != "all" {
+		conditions = append(conditions, fmt.Sprintf("payment_status = $%d", argIndex))
+		args = append(args, status)
+		argIndex++
+	}
+
+	if search != "" {
+		// Names and emails match exactly once encrypted (see BookingSearchCondition)
+		condition, searchArgs := services.BookingSearchCondition(search, argIndex)
+		conditions = append(conditions, condition)
+		args = append(args, searchArgs...)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", args, nil
+	}
+	return strings.Join(conditions, " AND "), args, nil
 }
@@ -0,0 +1,823 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/middleware"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/internal/ws"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/Himadryy/hidden-depths-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	razorpay "github.com/razorpay/razorpay-go"
+	"go.uber.org/zap"
+)
+
+// maxAdminReasonLength bounds the reason stored in the audit log.
+const maxAdminReasonLength = 500
+
+// AdminCancelBookingRequest cancels a pending or confirmed booking. Refund
+// returns the gateway payment in full and needs the refunds:issue permission.
+type AdminCancelBookingRequest struct {
+	Reason string `json:"reason"`
+	Refund bool   `json:"refund"`
+	Notify bool   `json:"notify"`
+}
+
+// AdminBookingOutcomeRequest records how a confirmed session went. Party
+// ("client", the default, or "mentor") says who missed a no-show.
+type AdminBookingOutcomeRequest struct {
+	Reason string `json:"reason"`
+	Party  string `json:"party,omitempty"`
+}
+
+// AdminConfirmPaymentRequest marks a pending or failed booking as paid
+// outside the gateway (bank transfer, UPI, cash). Amount defaults to the
+// booking's amount.
+type AdminConfirmPaymentRequest struct {
+	Reason    string   `json:"reason"`
+	Reference string   `json:"reference"`
+	Amount    *float64 `json:"amount,omitempty"`
+	Notify    bool     `json:"notify"`
+}
+
+// AdminCreateBookingRequest books a confirmed session on a client's behalf.
+// The weekday and time slot must still be open, but the date may be beyond
+// the dates currently offered to clients.
+type AdminCreateBookingRequest struct {
+	UserID           string  `json:"user_id"`
+	Name             string  `json:"name"`
+	Email            string  `json:"email"`
+	Date             string  `json:"date"`
+	Time             string  `json:"time"`
+	Amount           float64 `json:"amount"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+	Reason           string  `json:"reason"`
+	Notify           bool    `json:"notify"`
+}
+
+// AdminMeetingLinkRequest replaces a booking's meeting link.
+type AdminMeetingLinkRequest struct {
+	MeetingLink string `json:"meeting_link"`
+	Reason      string `json:"reason"`
+	Notify      bool   `json:"notify"`
+}
+
+// AdminCancelBooking godoc
+// @Summary Cancel a booking (Admin)
+// @Description Cancels a pending or confirmed booking and frees its slot. With refund, the Razorpay payment is refunded in full first (requires refunds:issue), at most once per booking; if the cancellation then fails, the 500 response still carries the refund_id. Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body AdminCancelBookingRequest true "Reason and options"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/cancel [post]
+// @Security BearerAuth
+func AdminCancelBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	var req AdminCancelBookingRequest
+	bookingID, reason, ok := decodeAdminBookingAction(w, r, &req, func() string { return req.Reason })
+	if !ok {
+		return
+	}
+	if req.Refund && !middleware.HasPermission(r.Context(), rbac.PermRefundsIssue) {
+		response.AppErr(w, apperror.PermissionDenied(string(rbac.PermRefundsIssue)))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	// Hold the row until the cancellation commits, so a concurrent action or a
+	// retried request cannot refund the payment twice
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("begin admin cancellation", err))
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	b, ok := queryAdminBooking(ctx, tx, w, r, bookingID, true)
+	if !ok {
+		return
+	}
+	if b.PaymentStatus != paymentStatusPending && b.PaymentStatus != paymentStatusPaid {
+		response.AppErr(w, apperror.ValidationError("booking", "Only pending or confirmed bookings can be cancelled"))
+		return
+	}
+	sessionStatus, ok := CancelledSessionStatus(b.SessionStatus, b.Date, b.Time, time.Now())
+	if !ok {
+		response.AppErr(w, apperror.ValidationError("booking", "The session already has an outcome"))
+		return
+	}
+
+	// Refund before cancelling so a gateway failure leaves the booking as it was
+	var refundID string
+	var refundAmount *float64
+	if req.Refund {
+		if b.RefundID != "" {
+			response.AppErr(w, apperror.ValidationError("refund", "This booking has already been refunded"))
+			return
+		}
+		if b.PaymentStatus != paymentStatusPaid || b.RazorpayPaymentID == "" || b.Amount <= 0 {
+			response.AppErr(w, apperror.ValidationError("refund", "This booking has no gateway payment to refund"))
+			return
+		}
+		id, err := issueRazorpayRefund(b.RazorpayPaymentID, b.Amount, bookingID, reason)
+		if err != nil {
+			logger.Error("Admin refund failed",
+				withRequestID(r, zap.String("booking_id", bookingID), zap.String("payment_id", b.RazorpayPaymentID), zap.Error(err))...,
+			)
+			if appErr, ok := apperror.AsAppError(err); ok {
+				response.AppErr(w, appErr)
+			} else {
+				response.AppErr(w, apperror.PaymentGatewayError(err))
+			}
+			return
+		}
+		refundID, refundAmount = id, &b.Amount
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	_, err = tx.Exec(ctx,
+		`UPDATE bookings
+		 SET payment_status = $2,
+		     status_reason = 'cancelled_by_admin',
+		     cancelled_at = COALESCE(cancelled_at, NOW()),
+		     released_at = COALESCE(released_at, NOW()),
+		     released_by = $3,
+		     session_status = $4,
+		     session_status_at = CASE WHEN session_status IS DISTINCT FROM $4 THEN NOW() ELSE session_status_at END,
+		     refund_id = COALESCE($5, refund_id),
+		     refund_amount = COALESCE($6, refund_amount),
+		     refunded_at = CASE WHEN $5::text IS NULL THEN refunded_at ELSE NOW() END
+		 WHERE id = $1`,
+		bookingID, paymentStatusCancelled, nilIfEmpty(adminID), sessionStatus, nilIfEmpty(refundID), refundAmount,
+	)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil && refundID != "" {
+		// The money has moved: record the refund on its own and report it, so
+		// the cancellation can be retried without refunding again
+		tx.Rollback(ctx)
+		recordCtx, recordCancel := context.WithTimeout(context.Background(), dbQueryTimeout)
+		_, recordErr := database.Pool.Exec(recordCtx,
+			`UPDATE bookings SET refund_id = $2, refund_amount = $3, refunded_at = NOW() WHERE id = $1`,
+			bookingID, refundID, refundAmount,
+		)
+		recordCancel()
+		if recordErr != nil {
+			logger.Error("Refund issued but not recorded",
+				withRequestID(r, zap.String("booking_id", bookingID), zap.String("refund_id", refundID), zap.Error(recordErr))...,
+			)
+		} else {
+			services.PostBookingLedger(r.Context(), bookingID)
+		}
+		logger.Error("Refund issued but booking not cancelled",
+			withRequestID(r, zap.String("booking_id", bookingID), zap.String("refund_id", refundID), zap.Error(err))...,
+		)
+		response.JSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"booking_id": bookingID,
+			"refund_id":  refundID,
+		}, "Refund issued but the booking was not cancelled; cancel it again without a refund")
+		return
+	}
+	if err != nil {
+		respondAdminBookingUpdateError(w, r, "cancel booking", bookingID, err)
+		return
+	}
+	if refundID != "" {
+		services.PostBookingLedger(r.Context(), bookingID)
+	}
+
+	if b.PaymentStatus == paymentStatusPending {
+		services.RecordFunnelStep(r.Context(), bookingID, services.FunnelReleased, "cancelled_by_admin")
+	}
+	InvalidateSlotsCache(r.Context(), b.Date)
+	hub.Broadcast("SLOT_CANCELLED", map[string]string{
+		"date": b.Date,
+		"time": b.Time,
+	})
+	details := map[string]interface{}{
+		"previous_status": b.PaymentStatus,
+		"session_status":  derefString(sessionStatus),
+		"reason":          reason,
+		"notified":        req.Notify,
+	}
+	if refundID != "" {
+		details["refund_id"] = refundID
+		details["refund_amount"] = *refundAmount
+	}
+	audit.Log(r.Context(), "booking.admin_cancel", adminID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), details)
+	logger.Info("Booking cancelled by admin",
+		withRequestID(r, zap.String("booking_id", bookingID), zap.String("admin_id", adminID), zap.Bool("refunded", refundID != ""))...,
+	)
+
+	if req.Notify {
+		sendAdminBookingEmail(bookingID, b, services.TemplateBookingCancellation, "")
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id": bookingID,
+		"refund_id":  refundID,
+	}, "Booking cancelled")
+}
+
+// AdminCompleteBooking godoc
+// @Summary Mark a session completed (Admin)
+// @Description Records that a confirmed session took place, once it has started. Also corrects a session recorded as a no-show. Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body AdminBookingOutcomeRequest true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/complete [post]
+// @Security BearerAuth
+func AdminCompleteBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	recordSessionOutcome(w, r, hub, audit, false)
+}
+
+// AdminMarkNoShow godoc
+// @Summary Mark a session as a no-show (Admin)
+// @Description Records that the client (or, with party "mentor", the mentor) missed a confirmed session, once it has started. Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body AdminBookingOutcomeRequest true "Reason and party"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/no-show [post]
+// @Security BearerAuth
+func AdminMarkNoShow(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	recordSessionOutcome(w, r, hub, audit, true)
+}
+
+func recordSessionOutcome(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService, noShow bool) {
+	var req AdminBookingOutcomeRequest
+	bookingID, reason, ok := decodeAdminBookingAction(w, r, &req, func() string { return req.Reason })
+	if !ok {
+		return
+	}
+	outcome, action := sessionStatusCompleted, "booking.admin_complete"
+	if noShow {
+		switch strings.TrimSpace(req.Party) {
+		case "", "client":
+			outcome = sessionStatusNoShowClient
+		case "mentor":
+			outcome = sessionStatusNoShowMentor
+		default:
+			response.AppErr(w, apperror.ValidationError("party", "party must be client or mentor"))
+			return
+		}
+		action = "booking.admin_no_show"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	b, ok := loadAdminBooking(ctx, w, r, bookingID)
+	if !ok {
+		return
+	}
+	if b.PaymentStatus != paymentStatusPaid {
+		response.AppErr(w, apperror.ValidationError("booking", "Only confirmed bookings have a session outcome"))
+		return
+	}
+	if start, err := sessionStart(b.Date, b.Time, time.Local); err != nil || time.Now().Before(start) {
+		response.AppErr(w, apperror.ValidationError("booking", "The session has not started yet"))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	previous, err := transitionSessionStatus(ctx, bookingID, outcome)
+	var transitionErr *SessionTransitionError
+	if errors.As(err, &transitionErr) {
+		response.AppErr(w, apperror.ValidationError("session_status", transitionErr.Error()))
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = errBookingChanged
+	}
+	if err != nil {
+		respondAdminBookingUpdateError(w, r, "record session outcome", bookingID, err)
+		return
+	}
+
+	InvalidateSlotsCache(r.Context(), b.Date)
+	hub.Broadcast("BOOKING_UPDATED", map[string]string{
+		"date": b.Date,
+		"time": b.Time,
+	})
+	audit.Log(r.Context(), action, adminID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"previous_session_status": previous,
+		"session_status":          outcome,
+		"reason":                  reason,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id":     bookingID,
+		"session_status": outcome,
+	}, "Session outcome recorded")
+}
+
+// AdminConfirmPayment godoc
+// @Summary Confirm an offline payment (Admin)
+// @Description Marks a pending or failed booking as paid for a payment taken outside Razorpay. Fails if the slot has since been taken. Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body AdminConfirmPaymentRequest true "Reason and payment reference"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/confirm-payment [post]
+// @Security BearerAuth
+func AdminConfirmPayment(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	var req AdminConfirmPaymentRequest
+	bookingID, reason, ok := decodeAdminBookingAction(w, r, &req, func() string { return req.Reason })
+	if !ok {
+		return
+	}
+	reference := strings.TrimSpace(req.Reference)
+	if reference == "" {
+		response.AppErr(w, apperror.ValidationError("reference", "A payment reference is required"))
+		return
+	}
+	if req.Amount != nil && (*req.Amount < 0 || math.IsNaN(*req.Amount)) {
+		response.AppErr(w, apperror.ValidationError("amount", "Amount cannot be negative"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	b, ok := loadAdminBooking(ctx, w, r, bookingID)
+	if !ok {
+		return
+	}
+	if b.PaymentStatus != paymentStatusPending && b.PaymentStatus != paymentStatusFailed {
+		response.AppErr(w, apperror.ValidationError("booking", "Only pending or failed bookings can be confirmed"))
+		return
+	}
+	amount := b.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	result, err := database.Pool.Exec(ctx,
+		`UPDATE bookings
+		 SET payment_status = $3,
+		     status_reason = 'payment_confirmed_offline',
+		     payment_reference = $4,
+		     amount = $5,
+		     confirmed_at = NOW(),
+		     session_status = 'scheduled',
+		     session_status_at = NOW()
+		 WHERE id = $1 AND payment_status = $2`,
+		bookingID, b.PaymentStatus, paymentStatusPaid, reference, amount,
+	)
+	if err == nil && result.RowsAffected() == 0 {
+		err = errBookingChanged
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			response.AppErr(w, apperror.SlotUnavailable(b.Date, b.Time))
+			return
+		}
+		respondAdminBookingUpdateError(w, r, "confirm payment", bookingID, err)
+		return
+	}
+
+	services.RecordFunnelStep(r.Context(), bookingID, services.FunnelConfirmed, "offline")
+	services.PostBookingLedger(r.Context(), bookingID)
+	InvalidateSlotsCache(r.Context(), b.Date)
+	hub.Broadcast("SLOT_BOOKED", map[string]string{
+		"date": b.Date,
+		"time": b.Time,
+	})
+	audit.Log(r.Context(), "booking.admin_confirm_payment", adminID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"previous_status": b.PaymentStatus,
+		"reference":       reference,
+		"amount":          amount,
+		"reason":          reason,
+		"notified":        req.Notify,
+	})
+
+	if req.Notify {
+		sendAdminBookingEmail(bookingID, b, services.TemplateBookingConfirmation, b.MeetingLink)
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id": bookingID,
+		"amount":     amount,
+	}, "Payment confirmed")
+}
+
+// AdminCreateBooking godoc
+// @Summary Book a session for a client (Admin)
+// @Description Creates a confirmed booking for an existing user. The weekday and time slot must be open, but the date may be beyond the dates offered to clients (up to 3 months ahead). Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body AdminCreateBookingRequest true "Client, slot and reason"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/bookings [post]
+// @Security BearerAuth
+func AdminCreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	var req AdminCreateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	reason, appErr := adminActionReason(req.Reason)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	clientID, err := uuid.Parse(strings.TrimSpace(req.UserID))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("user_id", "user_id must be a UUID"))
+		return
+	}
+	if req.Amount < 0 || math.IsNaN(req.Amount) {
+		response.AppErr(w, apperror.ValidationError("amount", "Amount cannot be negative"))
+		return
+	}
+
+	b := models.Booking{
+		Name:  validator.SanitizeString(req.Name),
+		Email: validator.SanitizeString(req.Email),
+		Date:  validator.SanitizeString(req.Date),
+		Time:  validator.SanitizeString(req.Time),
+	}
+	if errs := validator.ValidateBooking(validator.BookingInput{Date: b.Date, Time: b.Time, Name: b.Name, Email: b.Email}); len(errs) > 0 {
+		response.AppErr(w, apperror.ValidationError(errs[0].Field, errs[0].Message))
+		return
+	}
+	bookingDate, err := time.Parse("2006-01-02", b.Date)
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("date", "Invalid date format. Use YYYY-MM-DD."))
+		return
+	}
+	policy := getBookingPolicyConfig()
+	if !isWeekdayAllowed(bookingDate.Weekday(), policy.AllowedWeekdays) {
+		response.AppErr(w, apperror.ValidationError("date", "This date is not available for booking"))
+		return
+	}
+	if !isTimeSlotAllowed(b.Time, policy) {
+		response.AppErr(w, apperror.ValidationError("time", "This time slot is not available for booking"))
+		return
+	}
+
+	userID := clientID.String()
+	b.UserID = &userID
+	b.Amount = req.Amount
+	b.PaymentStatus = paymentStatusPaid
+	b.Locale = services.PreferredLocale(r.Context(), userID)
+	if b.Locale == "" {
+		b.Locale = i18n.Default
+	}
+	meetingID := uuid.New().String()[:8]
+	b.MeetingLink = fmt.Sprintf("https://hidden-depths-web.pages.dev/session?room=%s-%s", meetingID, b.Date)
+
+	if err := expireStalePendingHold(r.Context(), b.Date, b.Time); err != nil {
+		if appErr, ok := apperror.AsAppError(err); ok {
+			response.AppErr(w, appErr)
+		} else {
+			response.AppErr(w, apperror.DatabaseError("expire stale pending booking", err))
+		}
+		return
+	}
+
+	stored, err := services.SealBookingPII(b.Name, b.Email)
+	if err != nil {
+		logger.Error("Admin create booking failed: encrypt personal data", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	var reference *string
+	if ref := strings.TrimSpace(req.PaymentReference); ref != "" {
+		reference = &ref
+	}
+	// The active-slot unique index rejects the insert if the slot is taken
+	err = database.Pool.QueryRow(ctx,
+		`INSERT INTO bookings
+		(date, time, name, email, name_bidx, email_bidx, user_id, meeting_link, payment_status, amount, status_reason, confirmed_at, locale, payment_reference, created_by, session_status, session_status_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'created_by_admin', NOW(), $11, $12, $13, 'scheduled', NOW())
+		RETURNING id`,
+		b.Date, b.Time, stored.Name, stored.Email, stored.NameIndex, stored.EmailIndex, userID,
+		b.MeetingLink, b.PaymentStatus, b.Amount, b.Locale, reference, nilIfEmpty(adminID),
+	).Scan(&b.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			response.AppErr(w, apperror.SlotUnavailable(b.Date, b.Time))
+			return
+		}
+		logger.Error("Admin create booking failed",
+			withRequestID(r, zap.String("user_id", userID), zap.String("date", b.Date), zap.String("time", b.Time), zap.Error(err))...,
+		)
+		response.AppErr(w, apperror.DatabaseError("create booking", err))
+		return
+	}
+
+	InvalidateSlotsCache(r.Context(), b.Date)
+	hub.Broadcast("SLOT_BOOKED", map[string]string{
+		"date": b.Date,
+		"time": b.Time,
+	})
+	audit.Log(r.Context(), "booking.admin_create", adminID, b.ID, "booking", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"client_id": userID,
+		"date":      b.Date,
+		"time":      b.Time,
+		"amount":    b.Amount,
+		"reason":    reason,
+		"notified":  req.Notify,
+	})
+	logger.Info("Booking created by admin",
+		withRequestID(r, zap.String("booking_id", b.ID), zap.String("admin_id", adminID), zap.String("user_id", userID))...,
+	)
+
+	if req.Notify {
+		sendAdminBookingEmail(b.ID, b, services.TemplateBookingConfirmation, b.MeetingLink)
+	}
+
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
+		"booking_id":   b.ID,
+		"meeting_link": b.MeetingLink,
+	}, "Booking created")
+}
+
+// AdminUpdateMeetingLink godoc
+// @Summary Change a booking's meeting link (Admin)
+// @Description Replaces the meeting link of a pending or confirmed booking with an https URL. Requires bookings:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body AdminMeetingLinkRequest true "New link and reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/meeting-link [patch]
+// @Security BearerAuth
+func AdminUpdateMeetingLink(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	var req AdminMeetingLinkRequest
+	bookingID, reason, ok := decodeAdminBookingAction(w, r, &req, func() string { return req.Reason })
+	if !ok {
+		return
+	}
+	link, appErr := validateMeetingLink(req.MeetingLink)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	b, ok := loadAdminBooking(ctx, w, r, bookingID)
+	if !ok {
+		return
+	}
+	if b.PaymentStatus != paymentStatusPending && b.PaymentStatus != paymentStatusPaid {
+		response.AppErr(w, apperror.ValidationError("booking", "Only pending or confirmed bookings have a meeting link"))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	result, err := database.Pool.Exec(ctx,
+		`UPDATE bookings SET meeting_link = $2 WHERE id = $1 AND payment_status IN ($3, $4)`,
+		bookingID, link, paymentStatusPending, paymentStatusPaid,
+	)
+	if err == nil && result.RowsAffected() == 0 {
+		err = errBookingChanged
+	}
+	if err != nil {
+		respondAdminBookingUpdateError(w, r, "update meeting link", bookingID, err)
+		return
+	}
+
+	InvalidateSlotsCache(r.Context(), b.Date)
+	hub.Broadcast("BOOKING_UPDATED", map[string]string{
+		"date": b.Date,
+		"time": b.Time,
+	})
+	audit.Log(r.Context(), "booking.admin_meeting_link", adminID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"previous_link": b.MeetingLink,
+		"meeting_link":  link,
+		"reason":        reason,
+		"notified":      req.Notify,
+	})
+
+	if req.Notify && b.PaymentStatus == paymentStatusPaid {
+		sendAdminBookingEmail(bookingID, b, services.TemplateBookingConfirmation, link)
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id":   bookingID,
+		"meeting_link": link,
+	}, "Meeting link updated")
+}
+
+// errBookingChanged means a conditional update matched no row because the
+// booking's status changed after it was read.
+var errBookingChanged = errors.New("booking changed concurrently")
+
+// decodeAdminBookingAction reads the {id} path parameter and the JSON body
+// into req, then validates the reason returned by reasonOf. It writes the
+// error response and returns ok=false on failure.
+func decodeAdminBookingAction(w http.ResponseWriter, r *http.Request, req interface{}, reasonOf func() string) (bookingID, reason string, ok bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Booking ID must be a UUID"))
+		return "", "", false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return "", "", false
+	}
+	reason, appErr := adminActionReason(reasonOf())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return "", "", false
+	}
+	return id.String(), reason, true
+}
+
+// loadAdminBooking reads a booking with its name and email decrypted. It
+// writes the error response and returns ok=false on failure.
+func loadAdminBooking(ctx context.Context, w http.ResponseWriter, r *http.Request, bookingID string) (models.Booking, bool) {
+	return queryAdminBooking(ctx, database.Pool, w, r, bookingID, false)
+}
+
+// queryAdminBooking is loadAdminBooking on q, locking the row when forUpdate
+// is set and q is a transaction.
+func queryAdminBooking(ctx context.Context, q bookingQuerier, w http.ResponseWriter, r *http.Request, bookingID string, forUpdate bool) (models.Booking, bool) {
+	query := `SELECT id, date, time, name, email, user_id, payment_status, amount,
+		        COALESCE(razorpay_payment_id, ''), COALESCE(refund_id, ''), COALESCE(meeting_link, ''), locale, COALESCE(session_status, '')
+		 FROM bookings WHERE id = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	var b models.Booking
+	err := q.QueryRow(ctx, query, bookingID).Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.UserID, &b.PaymentStatus, &b.Amount,
+		&b.RazorpayPaymentID, &b.RefundID, &b.MeetingLink, &b.Locale, &b.SessionStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return b, false
+	}
+	if err != nil {
+		logger.Error("Failed to load booking", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return b, false
+	}
+	if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+		logger.Error("Failed to decrypt booking", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+		response.AppErr(w, apperror.InternalError(err))
+		return b, false
+	}
+	return b, true
+}
+
+func respondAdminBookingUpdateError(w http.ResponseWriter, r *http.Request, operation, bookingID string, err error) {
+	if errors.Is(err, errBookingChanged) {
+		response.AppErr(w, apperror.ValidationError("booking", "The booking changed while it was being updated; reload it and try again"))
+		return
+	}
+	logger.Error("Admin booking action failed",
+		withRequestID(r, zap.String("operation", operation), zap.String("booking_id", bookingID), zap.Error(err))...,
+	)
+	response.AppErr(w, apperror.DatabaseError(operation, err))
+}
+
+// issueRazorpayRefund refunds amount (rupees) of paymentID in full and
+// returns the gateway's refund ID.
+func issueRazorpayRefund(paymentID string, amount float64, bookingID, reason string) (string, error) {
+	keyID := os.Getenv("RAZORPAY_KEY_ID")
+	keySecret := os.Getenv("RAZORPAY_KEY_SECRET")
+	if keyID == "" || keySecret == "" {
+		return "", apperror.ExternalServiceError("razorpay", fmt.Errorf("payment configuration missing"))
+	}
+
+	var body map[string]interface{}
+	err := RazorpayBreaker.Execute(func() error {
+		client := razorpay.NewClient(keyID, keySecret)
+		var refundErr error
+		body, refundErr = client.Payment.Refund(paymentID, int(math.Round(amount*100)), map[string]interface{}{
+			"receipt": bookingID,
+			"notes": map[string]interface{}{
+				"booking_id": bookingID,
+				"reason":     reason,
+			},
+		}, nil)
+		if refundErr != nil {
+			return apperror.PaymentGatewayError(refundErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	refundID, _ := body["id"].(string)
+	if refundID == "" {
+		return "", apperror.PaymentGatewayError(fmt.Errorf("invalid refund response: missing id"))
+	}
+	return refundID, nil
+}
+
+// sendAdminBookingEmail sends template about b in the background; link
+// overrides the meeting link shown, and is withheld while intake is pending.
+func sendAdminBookingEmail(bookingID string, b models.Booking, template, link string) {
+	go func() {
+		link := services.BookingEmailLink(context.Background(), bookingID, link)
+		data := services.NewEmailTemplateData(b.Locale, b.Name, b.Date, b.Time, link)
+		if err := services.SendTemplatedEmail(context.Background(), b.Email, template, data); err != nil {
+			logger.Log.Error("Admin booking email failed",
+				zap.String("booking_id", bookingID),
+				zap.String("template", template),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// adminActionReason validates the reason every admin booking action records.
+func adminActionReason(raw string) (string, *apperror.AppError) {
+	reason := strings.TrimSpace(raw)
+	if reason == "" {
+		return "", apperror.ValidationError("reason", "A reason is required")
+	}
+	if utf8.RuneCountInString(reason) > maxAdminReasonLength {
+		return "", apperror.ValidationErrorf("reason", "Reason must be at most %d characters", maxAdminReasonLength)
+	}
+	return reason, nil
+}
+
+// validateMeetingLink accepts absolute https URLs.
+func validateMeetingLink(raw string) (string, *apperror.AppError) {
+	link := strings.TrimSpace(raw)
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "https" || u.Host == "" || len(link) > 2048 {
+		return "", apperror.ValidationError("meeting_link", "Meeting link must be an https URL")
+	}
+	return link, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
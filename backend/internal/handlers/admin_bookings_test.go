@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMeetingLink(t *testing.T) {
+	tests := []struct {
+		link string
+		ok   bool
+	}{
+		{" https://meet.example.com/abc ", true},
+		{"http://meet.example.com/abc", false},
+		{"https://", false},
+		{"javascript:alert(1)", false},
+		{"", false},
+	}
+	for _, tc := range tests {
+		got, appErr := validateMeetingLink(tc.link)
+		if (appErr == nil) != tc.ok {
+			t.Fatalf("validateMeetingLink(%q) error = %v, want ok=%v", tc.link, appErr, tc.ok)
+		}
+		if tc.ok && got != strings.TrimSpace(tc.link) {
+			t.Fatalf("validateMeetingLink(%q) = %q, want trimmed link", tc.link, got)
+		}
+	}
+}
+
+func TestAdminActionReason(t *testing.T) {
+	if _, appErr := adminActionReason("   "); appErr == nil {
+		t.Fatalf("expected blank reason to be rejected")
+	}
+	if _, appErr := adminActionReason(strings.Repeat("x", maxAdminReasonLength+1)); appErr == nil {
+		t.Fatalf("expected overlong reason to be rejected")
+	}
+	if got, appErr := adminActionReason("  client requested  "); appErr != nil || got != "client requested" {
+		t.Fatalf("adminActionReason() = %q, %v", got, appErr)
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/middleware"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AdminBlockUserRequest blocks or unblocks a user.
+type AdminBlockUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AdminListUsers godoc
+// @Summary List users (Admin)
+// @Description Returns users newest first with their paid booking counts. Requires users:read.
+// @Tags Admin
+// @Produce json
+// @Param search query string false "Part of an email, name or user id"
+// @Param status query string false "active or blocked"
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "created_at or -created_at (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/users [get]
+// @Security BearerAuth
+func AdminListUsers(w http.ResponseWriter, r *http.Request) {
+	filter := services.UserListFilter{
+		Search: strings.TrimSpace(r.URL.Query().Get("search")),
+		Status: strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status"))),
+	}
+	p, appErr := pagination.Parse(r.URL.Query(), services.UserListPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	switch filter.Status {
+	case "", "all":
+		filter.Status = ""
+	case "active", "blocked":
+	default:
+		response.AppErr(w, apperror.ValidationError("status", "Invalid status filter"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	page, err := services.ListUsers(ctx, filter, p)
+	if err != nil {
+		logger.Log.Error("Failed to fetch users", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("fetch users", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, page, "Users fetched")
+}
+
+// AdminGetUser godoc
+// @Summary Get a user (Admin)
+// @Description Returns a user's profile, block state and paid booking count. Requires users:read.
+// @Tags Admin
+// @Produce json
+// @Param userID path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{userID} [get]
+// @Security BearerAuth
+func AdminGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminUserIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	user, err := services.GetAdminUser(ctx, userID)
+	if errors.Is(err, services.ErrProfileNotFound) {
+		response.AppErr(w, apperror.ResourceNotFound("user", userID))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to fetch user", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch user", err))
+		return
+	}
+	response.JSON(w, http.StatusOK, user, "User fetched")
+}
+
+// adminUserBookingsPage is how one user's bookings page for admins: the
+// admin booking list's sorts, by session date by default.
+var adminUserBookingsPage = pagination.Spec{
+	Sorts:        adminBookingsPage.Sorts,
+	DefaultSort:  "-date",
+	Key:          adminBookingsPage.Key,
+	DefaultLimit: 50,
+	MaxLimit:     100,
+	Fields:       adminBookingsPage.Fields,
+}
+
+// AdminGetUserBookings godoc
+// @Summary List a user's bookings (Admin)
+// @Description Returns a page of the bookings the user made, latest session date first unless sorted otherwise. Requires users:read.
+// @Tags Admin
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param limit query int false "Results per page (default 50, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: date, created_at or amount (default -date)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/users/{userID}/bookings [get]
+// @Security BearerAuth
+func AdminGetUserBookings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminUserIDParam(w, r)
+	if !ok {
+		return
+	}
+	p, appErr := pagination.Parse(r.URL.Query(), adminUserBookingsPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	after, args := p.Where(2)
+	args = append([]any{userID}, args...)
+	args = append(args, p.Fetch())
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, email, date, time, payment_status, COALESCE(razorpay_payment_id, ''), session_status, refund_id, created_at, %s
+		 FROM bookings
+		 WHERE user_id = $1 AND %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		logger.Error("Failed to fetch user bookings", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch user bookings", err))
+		return
+	}
+	defer rows.Close()
+
+	bookings := make([]AdminBooking, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		booking := AdminBooking{UserID: userID}
+		var pos pagination.Position
+		if err := rows.Scan(&booking.ID, &booking.UserEmail, &booking.Date, &booking.Time, &booking.PaymentStatus,
+			&booking.RazorpayPaymentID, &booking.SessionStatus, &booking.RefundID, &booking.CreatedAt, &pos.Value, &pos.Key); err != nil {
+			logger.Log.Warn("Failed to scan user booking row", zap.Error(err))
+			continue
+		}
+		if err := services.OpenBookingPII(nil, &booking.UserEmail); err != nil {
+			logger.Log.Warn("Failed to decrypt user booking email", zap.String("booking_id", booking.ID), zap.Error(err))
+		}
+		bookings = append(bookings, booking)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("User bookings query error", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch user bookings", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, pagination.NewPage(p, bookings, positions), "User bookings fetched")
+}
+
+// AdminBlockUser godoc
+// @Summary Block a user (Admin)
+// @Description Rejects every authenticated request from the user until unblocked. Existing bookings are kept. Requires users:manage; blocking staff also requires roles:manage, and owners cannot be blocked. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body AdminBlockUserRequest true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{userID}/block [post]
+// @Security BearerAuth
+func AdminBlockUser(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	setUserBlocked(w, r, audit, true)
+}
+
+// AdminUnblockUser godoc
+// @Summary Unblock a user (Admin)
+// @Description Lets a blocked user sign in again. Requires users:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body AdminBlockUserRequest true "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{userID}/unblock [post]
+// @Security BearerAuth
+func AdminUnblockUser(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	setUserBlocked(w, r, audit, false)
+}
+
+func setUserBlocked(w http.ResponseWriter, r *http.Request, audit *services.AuditService, blocked bool) {
+	userID, ok := adminUserIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req AdminBlockUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	reason, appErr := adminActionReason(req.Reason)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	adminID, _ := r.Context().Value("user_id").(string)
+	if blocked && adminID == userID {
+		response.AppErr(w, apperror.ValidationError("userID", "You cannot block your own account"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	if blocked && !allowStaffTarget(ctx, w, r, userID) {
+		return
+	}
+
+	user, err := services.SetUserBlocked(ctx, userID, blocked, adminID, reason)
+	if errors.Is(err, services.ErrProfileNotFound) {
+		response.AppErr(w, apperror.ResourceNotFound("user", userID))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to change user block state", withRequestID(r, zap.String("user_id", userID), zap.Bool("blocked", blocked), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("update user", err))
+		return
+	}
+
+	action, msg := "user.block", "User blocked"
+	if !blocked {
+		action, msg = "user.unblock", "User unblocked"
+	}
+	audit.Log(r.Context(), action, adminID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"reason": reason,
+	})
+
+	response.JSON(w, http.StatusOK, user, msg)
+}
+
+// allowStaffTarget applies checkStaffTarget to userID's roles. It writes the
+// error response and returns false when the action is refused.
+func allowStaffTarget(ctx context.Context, w http.ResponseWriter, r *http.Request, userID string) bool {
+	roles, err := services.UserRoles(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to load user roles", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("load user roles", err))
+		return false
+	}
+	if appErr := checkStaffTarget(roles, middleware.HasPermission(r.Context(), rbac.PermRolesManage)); appErr != nil {
+		response.AppErr(w, appErr)
+		return false
+	}
+	return true
+}
+
+// checkStaffTarget refuses to block or log out owners, and other staff unless
+// the caller can manage roles, so support staff cannot lock out the people
+// who would have to undo it.
+func checkStaffTarget(targetRoles []rbac.Role, canManageRoles bool) *apperror.AppError {
+	for _, role := range targetRoles {
+		if role == rbac.RoleOwner {
+			return apperror.ValidationError("userID", "This action cannot be applied to an owner")
+		}
+	}
+	if len(targetRoles) > 0 && !canManageRoles {
+		return apperror.PermissionDenied(string(rbac.PermRolesManage))
+	}
+	return nil
+}
+
+// adminUserIDParam reads the {userID} path parameter. It writes the error
+// response and returns ok=false when it is not a UUID.
+func adminUserIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("userID", "Invalid user ID"))
+		return "", false
+	}
+	return id.String(), true
+}
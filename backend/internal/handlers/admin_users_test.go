@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+)
+
+func TestCheckStaffTarget(t *testing.T) {
+	tests := []struct {
+		name           string
+		roles          []rbac.Role
+		canManageRoles bool
+		wantCode       string // empty when blocking is allowed
+	}{
+		{"client", nil, false, ""},
+		{"staff by support", []rbac.Role{rbac.RoleMentor}, false, "PERMISSION_DENIED"},
+		{"staff by role manager", []rbac.Role{rbac.RoleSupport}, true, ""},
+		{"owner by support", []rbac.Role{rbac.RoleOwner}, false, "VALIDATION_ERROR"},
+		{"owner by owner", []rbac.Role{rbac.RoleContentEditor, rbac.RoleOwner}, true, "VALIDATION_ERROR"},
+		{"editor by support", []rbac.Role{rbac.RoleContentEditor}, false, "PERMISSION_DENIED"},
+		{"support by support", []rbac.Role{rbac.RoleSupport}, false, "PERMISSION_DENIED"},
+	}
+	for _, tt := range tests {
+		appErr := checkStaffTarget(tt.roles, tt.canManageRoles)
+		if tt.wantCode == "" {
+			if appErr != nil {
+				t.Fatalf("%s: checkStaffTarget() = %v, want nil", tt.name, appErr)
+			}
+			continue
+		}
+		if appErr == nil || appErr.Code != tt.wantCode {
+			t.Fatalf("%s: checkStaffTarget() = %v, want %s", tt.name, appErr, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// defaultAnalyticsTimezone is the zone analytics periods are cut in unless
+// the caller passes tz.
+const defaultAnalyticsTimezone = "Asia/Kolkata"
+
+// analyticsRanges holds, per granularity, the default range length in days
+// and the longest range accepted.
+var analyticsRanges = map[string]struct{ defaultDays, maxDays int }{
+	"day":   {30, 366},
+	"week":  {12 * 7, 2 * 366},
+	"month": {365, 5 * 366},
+}
+
+// parseAnalyticsQuery reads granularity, from, to and tz. Missing dates end
+// the range today (in tz) and use the granularity's default length.
+func parseAnalyticsQuery(q url.Values, now time.Time) (services.AnalyticsQuery, *apperror.AppError) {
+	query := services.AnalyticsQuery{Granularity: strings.ToLower(strings.TrimSpace(q.Get("granularity")))}
+	if query.Granularity == "" {
+		query.Granularity = "day"
+	}
+	ranges, ok := analyticsRanges[query.Granularity]
+	if !ok {
+		return query, apperror.ValidationError("granularity", "granularity must be day, week or month")
+	}
+	var appErr *apperror.AppError
+	query.From, query.To, query.Timezone, appErr = parseAnalyticsRange(q, now, ranges.defaultDays, ranges.maxDays)
+	return query, appErr
+}
+
+// parseAnalyticsRange reads from, to and tz. A missing to is today in tz; a
+// missing from makes the range defaultDays long. Ranges longer than maxDays
+// are rejected.
+func parseAnalyticsRange(q url.Values, now time.Time, defaultDays, maxDays int) (from, to time.Time, tz string, appErr *apperror.AppError) {
+	tz = strings.TrimSpace(q.Get("tz"))
+	if tz == "" {
+		tz = defaultAnalyticsTimezone
+	}
+	if !services.ValidTimezone(tz) {
+		return from, to, tz, apperror.ValidationError("tz", "tz must be an IANA timezone name")
+	}
+	loc, _ := time.LoadLocation(tz)
+
+	parseDate := func(field string) (time.Time, bool, *apperror.AppError) {
+		raw := strings.TrimSpace(q.Get(field))
+		if raw == "" {
+			return time.Time{}, false, nil
+		}
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return time.Time{}, false, apperror.ValidationError(field, field+" must be a date in YYYY-MM-DD format")
+		}
+		return d, true, nil
+	}
+	from, hasFrom, appErr := parseDate("from")
+	if appErr != nil {
+		return from, to, tz, appErr
+	}
+	to, hasTo, appErr := parseDate("to")
+	if appErr != nil {
+		return from, to, tz, appErr
+	}
+
+	if !hasTo {
+		y, m, d := now.In(loc).Date()
+		to = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		if hasFrom && from.After(to) {
+			to = from
+		}
+	}
+	if !hasFrom {
+		from = to.AddDate(0, 0, 1-defaultDays)
+	}
+	if from.After(to) {
+		return from, to, tz, apperror.ValidationError("from", "from must not be after to")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxDays {
+		return from, to, tz, apperror.ValidationErrorf("to", "Range must be at most %d days", maxDays)
+	}
+	return from, to, tz, nil
+}
+
+// AdminGetAnalytics godoc
+// @Summary Booking and revenue analytics (Admin)
+// @Description Returns a time series of holds, hold-to-paid conversion, abandonment, paid bookings, cancellations, revenue, refunds and new versus returning clients, with totals and slot fill rates for the range. Results are cached for a minute. Requires stats:read.
+// @Tags Admin
+// @Produce json
+// @Param granularity query string false "day (default), week or month"
+// @Param from query string false "First date, YYYY-MM-DD"
+// @Param to query string false "Last date, YYYY-MM-DD (default today)"
+// @Param tz query string false "IANA timezone periods are cut in (default Asia/Kolkata)"
+// @Success 200 {object} models.AdminAnalytics
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/analytics [get]
+// @Security BearerAuth
+func AdminGetAnalytics(w http.ResponseWriter, r *http.Request) {
+	query, appErr := parseAnalyticsQuery(r.URL.Query(), time.Now())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	policy := getBookingPolicyConfig()
+	query.TimeSlots = policy.TimeSlots
+	query.AllowedWeekdays = policy.AllowedWeekdays
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	analytics, err := services.GetAdminAnalytics(ctx, query)
+	if err != nil {
+		logger.Error("Failed to compute analytics", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("compute analytics", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, analytics, "Analytics fetched")
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseAnalyticsQuery(t *testing.T) {
+	// 20:00 UTC is already the next day in Asia/Kolkata
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query       string
+		granularity string
+		from, to    string
+		ok          bool
+	}{
+		{"", "day", "2026-09-20", "2026-10-19", true},
+		{"tz=UTC", "day", "2026-09-19", "2026-10-18", true},
+		{"granularity=Week&to=2026-10-04", "week", "2026-07-13", "2026-10-04", true},
+		{"granularity=month&from=2026-01-01&to=2026-03-31", "month", "2026-01-01", "2026-03-31", true},
+		{"from=2027-01-01", "day", "2027-01-01", "2027-01-01", true},
+		{"granularity=year", "", "", "", false},
+		{"from=2026-10-05&to=2026-10-01", "", "", "", false},
+		{"from=01/10/2026", "", "", "", false},
+		{"from=2025-01-01&to=2026-10-01", "", "", "", false},
+		{"tz=Local", "", "", "", false},
+	}
+	for _, tc := range tests {
+		values, _ := url.ParseQuery(tc.query)
+		got, appErr := parseAnalyticsQuery(values, now)
+		if (appErr == nil) != tc.ok {
+			t.Fatalf("parseAnalyticsQuery(%q) error = %v, want ok=%v", tc.query, appErr, tc.ok)
+		}
+		if !tc.ok {
+			continue
+		}
+		if got.Granularity != tc.granularity || got.From.Format("2006-01-02") != tc.from || got.To.Format("2006-01-02") != tc.to {
+			t.Fatalf("parseAnalyticsQuery(%q) = %s %s..%s, want %s %s..%s", tc.query,
+				got.Granularity, got.From.Format("2006-01-02"), got.To.Format("2006-01-02"), tc.granularity, tc.from, tc.to)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAPIKeyLifetimeDays = 90
+	maxAPIKeyLifetimeDays     = 365
+)
+
+// CreateAPIKeyRequest is the body accepted by CreateAPIKey.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // default 90, max 365
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys (Admin)
+// @Description Returns a page of service API keys with scopes, expiry and last use, newest first unless sorted otherwise. Secrets are never returned. Requires api_keys:manage.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: created_at or name (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/api-keys [get]
+// @Security BearerAuth
+func ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), services.APIKeyListPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	page, err := services.ListAPIKeys(r.Context(), p)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("list api keys", err))
+		return
+	}
+	pagination.Respond(w, r, p, page, "API keys retrieved")
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key (Admin)
+// @Description Creates a scoped service key and returns its secret once. Send it in the X-API-Key header. Requires api_keys:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "Key name, scopes and lifetime"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/api-keys [post]
+// @Security BearerAuth
+func CreateAPIKey(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		response.AppErr(w, apperror.ValidationError("name", "Name must be between 1 and 100 characters"))
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		response.AppErr(w, apperror.ValidationError("scopes", "At least one scope is required"))
+		return
+	}
+	scopes := make([]rbac.Permission, 0, len(req.Scopes))
+	for _, raw := range req.Scopes {
+		scope := rbac.Permission(strings.TrimSpace(raw))
+		if !rbac.IsAPIKeyScope(scope) {
+			response.AppErr(w, apperror.ValidationErrorf("scopes", "Scope %s cannot be granted to an API key", raw))
+			return
+		}
+		if !rbac.HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPIKeyLifetimeDays
+	}
+	if days < 1 || days > maxAPIKeyLifetimeDays {
+		response.AppErr(w, apperror.ValidationError("expires_in_days", "expires_in_days must be between 1 and 365"))
+		return
+	}
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	key, secret, err := services.CreateAPIKey(r.Context(), name, scopes, &expiresAt, adminID)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("create api key", err))
+		return
+	}
+
+	audit.Log(r.Context(), "api_key.create", adminID, key.ID, "api_key", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"expires_at": key.ExpiresAt,
+	})
+
+	response.JSON(w, http.StatusCreated, map[string]interface{}{
+		"api_key": key,
+		"secret":  secret,
+	}, "API key created. Store the secret now; it will not be shown again.")
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key (Admin)
+// @Description Disables a key immediately. Requires api_keys:manage. Audited.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/api-keys/{id} [delete]
+// @Security BearerAuth
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Invalid API key ID"))
+		return
+	}
+
+	revoked, err := services.RevokeAPIKey(r.Context(), id.String())
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("revoke api key", err))
+		return
+	}
+	if !revoked {
+		response.AppErr(w, apperror.ResourceNotFound("API key", id.String()))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "api_key.revoke", adminID, id.String(), "api_key", r.RemoteAddr, r.UserAgent(), nil)
+
+	response.JSON(w, http.StatusOK, map[string]string{"id": id.String()}, "API key revoked")
+}
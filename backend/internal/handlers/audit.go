@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetAuditLog godoc
+// @Summary Search the audit log
+// @Description Lists audit log entries, newest first. action matches exactly, or as a prefix when it ends in "." (e.g. "booking."). from and to are RFC 3339 times or YYYY-MM-DD dates; to is exclusive.
+// @Tags Admin
+// @Produce json
+// @Param user_id query string false "Actor user ID"
+// @Param entity_type query string false "Entity type, e.g. booking"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action or action prefix"
+// @Param from query string false "Earliest time"
+// @Param to query string false "Latest time (exclusive)"
+// @Param limit query int false "Entries per page (max 200)" default(50)
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "created_at or -created_at (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/audit [get]
+// @Security BearerAuth
+func GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, appErr := auditFilterParams(q)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	p, appErr := pagination.Parse(q, services.AuditLogPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	page, err := services.ListAuditLogs(r.Context(), filter, p)
+	if err != nil {
+		logger.Log.Error("Failed to fetch audit log", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch audit log", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, page, "Audit log fetched")
+}
+
+// auditFilterParams reads the audit log filters shared by the list and
+// export endpoints.
+func auditFilterParams(q url.Values) (services.AuditFilter, *apperror.AppError) {
+	filter := services.AuditFilter{
+		EntityType: strings.TrimSpace(q.Get("entity_type")),
+		Action:     strings.TrimSpace(q.Get("action")),
+	}
+
+	var appErr *apperror.AppError
+	if filter.UserID, appErr = uuidParam(q, "user_id"); appErr != nil {
+		return filter, appErr
+	}
+	if filter.EntityID, appErr = uuidParam(q, "entity_id"); appErr != nil {
+		return filter, appErr
+	}
+	if filter.From, appErr = timeParam(q, "from"); appErr != nil {
+		return filter, appErr
+	}
+	if filter.To, appErr = timeParam(q, "to"); appErr != nil {
+		return filter, appErr
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, apperror.ValidationError("to", "to must be after from")
+	}
+	return filter, nil
+}
+
+// uuidParam returns query parameter field in canonical UUID form, or "".
+func uuidParam(q url.Values, field string) (string, *apperror.AppError) {
+	raw := strings.TrimSpace(q.Get(field))
+	if raw == "" {
+		return "", nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return "", apperror.ValidationError(field, field+" must be a UUID")
+	}
+	return id.String(), nil
+}
+
+// timeParam parses query parameter field as an RFC 3339 time or a
+// YYYY-MM-DD date (midnight UTC).
+func timeParam(q url.Values, field string) (*time.Time, *apperror.AppError) {
+	raw := strings.TrimSpace(q.Get(field))
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", raw); err != nil {
+			return nil, apperror.ValidationError(field, field+" must be an RFC 3339 time or YYYY-MM-DD date")
+		}
+	}
+	return &t, nil
+}
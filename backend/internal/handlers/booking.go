@@ -22,7 +22,9 @@ import (
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
 	"github.com/Himadryy/hidden-depths-backend/pkg/circuitbreaker"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
 	"github.com/Himadryy/hidden-depths-backend/pkg/response"
 	"github.com/Himadryy/hidden-depths-backend/pkg/retry"
 	"github.com/Himadryy/hidden-depths-backend/pkg/validator"
@@ -84,8 +86,9 @@ func expireStalePendingHold(ctx context.Context, date, timeSlot string) error {
 	cleanupCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
 	defer cancel()
 
-	return retry.Do(cleanupCtx, retry.DefaultConfig(), "expire stale pending booking", func() error {
-		_, err := database.Pool.Exec(cleanupCtx,
+	var expired []string
+	err := retry.Do(cleanupCtx, retry.DefaultConfig(), "expire stale pending booking", func() error {
+		rows, err := database.Pool.Query(cleanupCtx,
 			`UPDATE bookings
 			 SET payment_status = $3,
 			     status_reason = 'hold_expired',
@@ -94,9 +97,13 @@ func expireStalePendingHold(ctx context.Context, date, timeSlot string) error {
 			 WHERE date = $1
 			   AND time = $2
 			   AND payment_status = $4
-			   AND created_at <= NOW() - INTERVAL '`+pendingHoldWindow+`'`,
+			   AND created_at <= NOW() - INTERVAL '`+pendingHoldWindow+`'
+			 RETURNING id`,
 			date, timeSlot, paymentStatusFailed, paymentStatusPending,
 		)
+		if err == nil {
+			expired, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		}
 		if err != nil {
 			if pgErr, ok := err.(*pgconn.PgError); ok {
 				logger.Error("Stale hold cleanup failed",
@@ -111,6 +118,10 @@ func expireStalePendingHold(ctx context.Context, date, timeSlot string) error {
 		}
 		return nil
 	})
+	for _, id := range expired {
+		services.RecordFunnelStep(cleanupCtx, id, services.FunnelExpired, "hold_expired")
+	}
+	return err
 }
 
 type slotAvailability struct {
@@ -270,18 +281,21 @@ func confirmBookingPaymentByID(ctx context.Context, bookingID, expectedOrderID,
 	defer tx.Rollback(ctx)
 
 	err = tx.QueryRow(ctx,
-		`SELECT id, date, time, name, email, meeting_link, user_id, payment_status, razorpay_order_id
+		`SELECT id, date, time, name, email, meeting_link, user_id, payment_status, razorpay_order_id, locale
 		 FROM bookings
 		 WHERE id = $1
 		 FOR UPDATE`,
 		bookingID,
-	).Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.UserID, &b.PaymentStatus, &b.RazorpayOrderID)
+	).Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.UserID, &b.PaymentStatus, &b.RazorpayOrderID, &b.Locale)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return b, false, apperror.BookingNotFound(bookingID)
 		}
 		return b, false, apperror.DatabaseError("fetch booking for confirmation", err)
 	}
+	if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+		return b, false, apperror.InternalError(err)
+	}
 
 	if expectedOrderID != "" && b.RazorpayOrderID != expectedOrderID {
 		return b, false, apperror.ValidationError("razorpay_order_id", "Order ID does not match booking")
@@ -302,7 +316,9 @@ func confirmBookingPaymentByID(ctx context.Context, bookingID, expectedOrderID,
 		 SET payment_status = $1,
 		     razorpay_payment_id = $2,
 		     status_reason = 'payment_confirmed',
-		     confirmed_at = COALESCE(confirmed_at, NOW())
+		     confirmed_at = COALESCE(confirmed_at, NOW()),
+		     session_status = 'scheduled',
+		     session_status_at = NOW()
 		 WHERE id = $3
 		   AND payment_status = $4`,
 		paymentStatusPaid, paymentID, b.ID, paymentStatusPending,
@@ -333,20 +349,23 @@ func confirmBookingPaymentByOrderID(ctx context.Context, orderID, paymentID stri
 	defer tx.Rollback(ctx)
 
 	err = tx.QueryRow(ctx,
-		`SELECT id, date, time, name, email, meeting_link, user_id, payment_status, razorpay_order_id
+		`SELECT id, date, time, name, email, meeting_link, user_id, payment_status, razorpay_order_id, locale
 		 FROM bookings
 		 WHERE razorpay_order_id = $1
 		 ORDER BY created_at DESC
 		 LIMIT 1
 		 FOR UPDATE`,
 		orderID,
-	).Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.UserID, &b.PaymentStatus, &b.RazorpayOrderID)
+	).Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.UserID, &b.PaymentStatus, &b.RazorpayOrderID, &b.Locale)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return b, false, nil
 		}
 		return b, false, err
 	}
+	if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+		return b, false, err
+	}
 
 	if b.PaymentStatus == paymentStatusPaid {
 		if err := tx.Commit(ctx); err != nil {
@@ -366,7 +385,9 @@ func confirmBookingPaymentByOrderID(ctx context.Context, orderID, paymentID stri
 		 SET payment_status = $1,
 		     razorpay_payment_id = $2,
 		     status_reason = 'payment_confirmed_webhook',
-		     confirmed_at = COALESCE(confirmed_at, NOW())
+		     confirmed_at = COALESCE(confirmed_at, NOW()),
+		     session_status = 'scheduled',
+		     session_status_at = NOW()
 		 WHERE id = $3
 		   AND payment_status = $4`,
 		paymentStatusPaid, paymentID, b.ID, paymentStatusPending,
@@ -412,6 +433,9 @@ func GetBookingPolicy(w http.ResponseWriter, r *http.Request) {
 		"allowed_weekdays":   allowedWeekdays,
 		"time_slots":         policy.TimeSlots,
 		"available_dates":    availableDates,
+		"max_active_holds":   policy.Holds.MaxActivePerUser,
+		"max_upcoming":       policy.Holds.MaxUpcomingPerUser,
+		"captcha_required":   services.CaptchaEnabled(),
 	}, "Booking policy fetched")
 }
 
@@ -422,10 +446,12 @@ func GetBookingPolicy(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param booking body models.Booking true "Booking details"
+// @Param X-Captcha-Token header string false "Turnstile token (required when CAPTCHA is enabled, see GET /bookings/policy)"
 // @Success 200 {object} map[string]interface{} "Payment initiated (paid sessions)"
 // @Success 201 {object} map[string]interface{} "Booking confirmed (free sessions)"
 // @Failure 400 {object} map[string]interface{}
-// @Failure 409 {object} map[string]interface{} "Slot unavailable"
+// @Failure 409 {object} map[string]interface{} "Slot unavailable or upcoming booking limit reached"
+// @Failure 429 {object} map[string]interface{} "Too many active holds, or cooling down after abandoned holds"
 // @Failure 500 {object} map[string]interface{}
 // @Router /bookings [post]
 // @Security BearerAuth
@@ -452,6 +478,9 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 	}
 	currentUserID := userIDString(booking.UserID)
 
+	// Emails about this booking are sent later in the language negotiated now
+	booking.Locale = i18n.FromContext(r.Context())
+
 	// 2. Validate all fields
 	validationErrors := validator.ValidateBooking(validator.BookingInput{
 		Date:  booking.Date,
@@ -474,6 +503,23 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		return
 	}
 
+	// CAPTCHA (when configured) before any gateway or database work
+	if err := services.VerifyCaptcha(r.Context(), r.Header.Get("X-Captcha-Token"), r.RemoteAddr); err != nil {
+		appmetrics.RecordBookingOperation("create", "captcha_failed")
+		logger.Warn("Create booking rejected: CAPTCHA verification failed",
+			withRequestID(r,
+				zap.String("user_id", currentUserID),
+				zap.Error(err),
+			)...,
+		)
+		if appErr, ok := apperror.AsAppError(err); ok {
+			response.AppErr(w, appErr)
+		} else {
+			response.AppErr(w, apperror.CaptchaFailed())
+		}
+		return
+	}
+
 	// 3. Booking policy checks (capacity-safe mode + allowed weekdays + known slots)
 	bookingDate, err := time.Parse("2006-01-02", booking.Date)
 	if err != nil {
@@ -502,7 +548,7 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		return
 	}
 
-	allowedByWindow, policyMessage, err := isBookingDateAllowed(booking.Date, time.Now(), policy)
+	allowedByWindow, policyRejection, err := isBookingDateAllowed(booking.Date, time.Now(), policy)
 	if err != nil {
 		appmetrics.RecordBookingOperation("create", "validation_error")
 		logger.Warn("Create booking rejected: date policy parse error",
@@ -524,7 +570,7 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 				zap.String("time", booking.Time),
 			)...,
 		)
-		response.AppErr(w, apperror.ValidationError("date", policyMessage))
+		response.AppErr(w, policyRejection)
 		return
 	}
 
@@ -556,6 +602,25 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		return
 	}
 
+	// 5. Hold limits — fail fast before creating a gateway order; re-checked
+	// under a per-user lock inside the transaction below.
+	if limitErr, err := checkHoldLimits(r.Context(), database.Pool, policy.Holds, currentUserID, r.RemoteAddr, booking.Date, booking.Time, isPaid); err != nil {
+		appmetrics.RecordBookingOperation("create", "db_error")
+		logger.Error("Create booking failed: hold limit query",
+			withRequestID(r,
+				zap.String("user_id", currentUserID),
+				zap.String("date", booking.Date),
+				zap.String("time", booking.Time),
+				zap.Error(err),
+			)...,
+		)
+		response.AppErr(w, apperror.DatabaseError("check booking limits", err))
+		return
+	} else if limitErr != nil {
+		rejectHoldLimit(w, r, limitErr, currentUserID, booking.Date, booking.Time)
+		return
+	}
+
 	// 2. Generate Meeting Link (before transaction)
 	// Use internal session page for branded experience
 	meetingID := uuid.New().String()[:8]
@@ -736,6 +801,24 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		}
 	}
 
+	if currentUserID != "" {
+		// Serialize this user's concurrent requests so parallel calls cannot
+		// each pass the hold limits.
+		if _, err := tx.Exec(txCtx, `SELECT pg_advisory_xact_lock(hashtext('booking_holds:' || $1))`, currentUserID); err != nil {
+			appmetrics.RecordBookingOperation("create", "db_error")
+			response.AppErr(w, apperror.DatabaseError("lock booking limits", err))
+			return
+		}
+	}
+	if limitErr, err := checkHoldLimits(txCtx, tx, policy.Holds, currentUserID, r.RemoteAddr, booking.Date, booking.Time, isPaid); err != nil {
+		appmetrics.RecordBookingOperation("create", "db_error")
+		response.AppErr(w, apperror.DatabaseError("check booking limits", err))
+		return
+	} else if limitErr != nil {
+		rejectHoldLimit(w, r, limitErr, currentUserID, booking.Date, booking.Time)
+		return
+	}
+
 	var paidCount, otherPendingCount int
 	if err := tx.QueryRow(txCtx,
 		`SELECT 
@@ -785,15 +868,30 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		return
 	}
 
+	// Free sessions are confirmed (and their session scheduled) on insert
+	var sessionStatus *string
+	if booking.PaymentStatus == paymentStatusPaid {
+		sessionStatus = nilIfEmpty(sessionStatusScheduled)
+	}
+
 	// 4. Insert into Database (within transaction)
+	stored, err := services.SealBookingPII(booking.Name, booking.Email)
+	if err != nil {
+		appmetrics.RecordBookingOperation("create", "internal_error")
+		logger.Error("Create booking failed: encrypt personal data",
+			withRequestID(r, zap.String("user_id", currentUserID), zap.Error(err))...,
+		)
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
 	var newID string
 	err = tx.QueryRow(txCtx,
 		`INSERT INTO bookings
-		(date, time, name, email, user_id, meeting_link, payment_status, razorpay_order_id, amount, status_reason, confirmed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(date, time, name, email, name_bidx, email_bidx, user_id, meeting_link, payment_status, razorpay_order_id, amount, status_reason, confirmed_at, locale, client_ip, session_status, session_status_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $13)
 		RETURNING id`,
-		booking.Date, booking.Time, booking.Name, booking.Email, booking.UserID,
-		booking.MeetingLink, booking.PaymentStatus, booking.RazorpayOrderID, booking.Amount, statusReason, confirmedAt,
+		booking.Date, booking.Time, stored.Name, stored.Email, stored.NameIndex, stored.EmailIndex, booking.UserID,
+		booking.MeetingLink, booking.PaymentStatus, booking.RazorpayOrderID, booking.Amount, statusReason, confirmedAt, booking.Locale, r.RemoteAddr, sessionStatus,
 	).Scan(&newID)
 
 	if err != nil {
@@ -840,26 +938,44 @@ func CreateBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		)...,
 	)
 
+	// Screen free text now the booking exists to link the event to
+	support := services.ScreenForCrisis(r.Context(), services.CrisisScreeningInput{
+		Source:    services.CrisisSourceBookingNotes,
+		UserID:    currentUserID,
+		BookingID: newID,
+		Region:    crisisRegion(r),
+		Texts:     map[string]string{"user_notes": booking.UserNotes},
+	})
+
 	// 6. Response Handling
 	if isPaid {
 		appmetrics.RecordBookingOperation("create", "initiated_pending")
+		services.RecordFunnelStep(r.Context(), newID, services.FunnelHoldCreated, "")
 		// Broadcast slot status change for active hold
 		hub.Broadcast("SLOT_PENDING", map[string]string{
 			"date": booking.Date,
 			"time": booking.Time,
 		})
 
-		response.JSON(w, http.StatusOK, map[string]interface{}{
+		payload := map[string]interface{}{
 			"booking_id": newID,
 			"order_id":   booking.RazorpayOrderID,
 			"amount":     booking.Amount * 100,
 			"currency":   "INR",
 			"key_id":     os.Getenv("RAZORPAY_KEY_ID"),
-		}, "Payment initiated")
+		}
+		if support != nil {
+			payload["crisis_support"] = support
+		}
+		response.JSON(w, http.StatusOK, payload, "Payment initiated")
 	} else {
 		appmetrics.RecordBookingOperation("create", "created_free")
 		finalizeBooking(r.Context(), hub, audit, newID, booking, "booking.confirmed", r.RemoteAddr, r.UserAgent())
-		response.JSON(w, http.StatusCreated, map[string]string{"booking_id": newID}, "Booking successful")
+		payload := map[string]interface{}{"booking_id": newID}
+		if support != nil {
+			payload["crisis_support"] = support
+		}
+		response.JSON(w, http.StatusCreated, payload, "Booking successful")
 	}
 }
 
@@ -938,6 +1054,7 @@ func VerifyPayment(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		response.AppErr(w, apperror.PaymentSignatureInvalid())
 		return
 	}
+	services.RecordFunnelStep(r.Context(), req.BookingID, services.FunnelVerifyCalled, "")
 
 	txCtx, txCancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
 	defer txCancel()
@@ -984,6 +1101,8 @@ func VerifyPayment(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 
 	// Invalidate slots cache (payment confirmed = slot truly taken)
 	appmetrics.RecordPaymentOperation("confirmed")
+	services.RecordFunnelStep(r.Context(), b.ID, services.FunnelConfirmed, "verify")
+	services.PostBookingLedger(r.Context(), b.ID)
 	InvalidateSlotsCache(r.Context(), b.Date)
 	logger.Info("Payment verified",
 		withRequestID(r,
@@ -1017,38 +1136,17 @@ func finalizeBooking(ctx context.Context, hub *ws.Hub, audit *services.AuditServ
 	}
 	audit.Log(ctx, action, userID, bookingID, "booking", ipAddress, userAgent, nil)
 
-	// Email (prefer Resend, fallback to SMTP)
+	// Email (Resend when configured, otherwise SMTP). Detached from the request
+	// context so the send outlives the HTTP response.
 	go func() {
-		emailSvc := services.GetEmailService()
-		if emailSvc != nil && emailSvc.IsEnabled() {
-			// Use Resend with professional templates
-			if err := emailSvc.SendBookingConfirmation(b.Email, b.Name, b.Date, b.Time, b.MeetingLink); err != nil {
-				logger.Log.Error("Resend confirmation email failed",
-					zap.String("email", b.Email),
-					zap.String("booking_id", bookingID),
-					zap.Error(err),
-				)
-			}
-		} else {
-			// Fallback to legacy SMTP
-			subject := "Confirmed: Your Journey Begins"
-			body := fmt.Sprintf(`
-				<h2>Welcome, %s.</h2>
-				<p>Your sanctuary session is confirmed for <strong>%s at %s</strong>.</p>
-				<p>We look forward to speaking with you.</p>
-				<p><strong>Your Secure Video Link:</strong></p>
-				<p><a href="%s" style="padding: 10px 20px; background-color: #E0B873; color: black; text-decoration: none; border-radius: 5px;">Join Session</a></p>
-				<br>
-				<p>You can also manage your bookings from your <a href="https://hidden-depths-web.pages.dev/profile">Profile</a>.</p>
-			`, b.Name, b.Date, b.Time, b.MeetingLink)
-
-			if err := services.SendEmail(b.Email, subject, body); err != nil {
-				logger.Log.Error("SMTP confirmation email failed after retries",
-					zap.String("email", b.Email),
-					zap.String("booking_id", bookingID),
-					zap.Error(err),
-				)
-			}
+		link := services.BookingEmailLink(context.Background(), bookingID, b.MeetingLink)
+		data := services.NewEmailTemplateData(b.Locale, b.Name, b.Date, b.Time, link)
+		if err := services.SendTemplatedEmail(context.Background(), b.Email, services.TemplateBookingConfirmation, data); err != nil {
+			logger.Log.Error("Confirmation email failed",
+				zap.String("email", b.Email),
+				zap.String("booking_id", bookingID),
+				zap.Error(err),
+			)
 		}
 	}()
 }
@@ -1112,12 +1210,30 @@ func GetRecommendedSlots(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, scores, "Recommendations calculated")
 }
 
+// userBookingsPage is how a user's own booking list pages and sorts.
+var userBookingsPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"date":       {Expr: "b.date", Type: pagination.TypeText},
+		"created_at": {Expr: "b.created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "-date",
+	Key:          pagination.Column{Expr: "b.id", Type: pagination.TypeUUID},
+	DefaultLimit: 50,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.Booking{}),
+}
+
 // GetUserBookings godoc
 // @Summary Get user's bookings
-// @Description Returns all bookings for the authenticated user, ordered by date descending.
+// @Description Returns a page of the authenticated user's bookings, by date descending unless sorted otherwise.
 // @Tags Bookings
 // @Produce json
+// @Param limit query int false "Results per page (default 50, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: date or created_at (default -date)"
+// @Param fields query string false "Comma-separated fields to return"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /bookings/my [get]
@@ -1129,13 +1245,24 @@ func GetUserBookings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	p, appErr := pagination.Parse(r.URL.Query(), userBookingsPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	after, args := p.Where(2)
+	args = append([]any{userID}, args...)
+	args = append(args, p.Fetch())
+
 	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
 	defer cancel()
 
-	rows, err := database.Pool.Query(ctx,
-		`SELECT id, date, time, name, email, meeting_link, payment_status, amount, created_at 
-		FROM bookings WHERE user_id = $1 ORDER BY date DESC`,
-		userID,
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT b.id, b.date, b.time, b.name, b.email, b.meeting_link, b.payment_status, COALESCE(b.session_status, ''), b.amount, b.created_at,
+		        `+services.IntakePendingCondition+`, %s
+		FROM bookings b WHERE b.user_id = $1 AND %s ORDER BY %s LIMIT $%d`,
+		p.Select(), after, p.OrderBy(), len(args)),
+		args...,
 	)
 	if err != nil {
 		logger.Error("Failed to fetch user bookings", zap.String("user_id", userID), zap.Error(err))
@@ -1144,17 +1271,27 @@ func GetUserBookings(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	var bookings []models.Booking
+	bookings := make([]models.Booking, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
 	for rows.Next() {
 		var b models.Booking
-		if err := rows.Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.PaymentStatus, &b.Amount, &b.CreatedAt); err != nil {
+		var pos pagination.Position
+		if err := rows.Scan(&b.ID, &b.Date, &b.Time, &b.Name, &b.Email, &b.MeetingLink, &b.PaymentStatus, &b.SessionStatus, &b.Amount, &b.CreatedAt, &b.IntakeRequired, &pos.Value, &pos.Key); err != nil {
 			logger.Error("Failed to scan user booking", zap.Error(err))
 			continue
 		}
+		if b.IntakeRequired {
+			b.MeetingLink = ""
+		}
+		if err := services.OpenBookingPII(&b.Name, &b.Email); err != nil {
+			logger.Error("Failed to decrypt user booking", zap.String("booking_id", b.ID), zap.Error(err))
+			continue
+		}
 		bookings = append(bookings, b)
+		positions = append(positions, pos)
 	}
 
-	response.JSON(w, http.StatusOK, bookings, "User bookings fetched")
+	pagination.Respond(w, r, p, pagination.NewPage(p, bookings, positions), "User bookings fetched")
 }
 
 // GetBookingStatus godoc
@@ -1292,6 +1429,7 @@ func ReleasePendingBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub,
 		return
 	}
 
+	services.RecordFunnelStep(r.Context(), bookingID, services.FunnelReleased, "released_by_user")
 	InvalidateSlotsCache(r.Context(), date)
 	hub.Broadcast("SLOT_CANCELLED", map[string]string{
 		"date": date,
@@ -1336,20 +1474,31 @@ func CancelBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 	defer cancel()
 
 	// Fetch booking details before status transition (for WebSocket broadcast & email)
-	var date, timeSlot, name, email, paymentStatus string
+	var date, timeSlot, name, email, paymentStatus, locale, previousSession string
 	err := database.Pool.QueryRow(ctx,
-		"SELECT date, time, name, email, payment_status FROM bookings WHERE id = $1 AND user_id = $2",
+		"SELECT date, time, name, email, payment_status, locale, COALESCE(session_status, '') FROM bookings WHERE id = $1 AND user_id = $2",
 		bookingID, userID,
-	).Scan(&date, &timeSlot, &name, &email, &paymentStatus)
+	).Scan(&date, &timeSlot, &name, &email, &paymentStatus, &locale, &previousSession)
 
 	if err != nil {
 		response.AppErr(w, apperror.BookingNotFound(bookingID).WithContext("reason", "not found or not authorized"))
 		return
 	}
+	if err := services.OpenBookingPII(&name, &email); err != nil {
+		logger.Error("Failed to decrypt booking", zap.String("booking_id", bookingID), zap.Error(err))
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
 	if paymentStatus != paymentStatusPaid {
 		response.AppErr(w, apperror.ValidationError("booking", "Only confirmed bookings can be cancelled here"))
 		return
 	}
+	if previousSession != sessionStatusNone && previousSession != sessionStatusScheduled {
+		response.AppErr(w, apperror.ValidationError("booking", "The session has already started"))
+		return
+	}
+	// Inside the late-cancellation window the session is recorded as late_cancelled
+	sessionStatus, _ := CancelledSessionStatus(previousSession, date, timeSlot, time.Now())
 
 	result, err := database.Pool.Exec(ctx,
 		`UPDATE bookings
@@ -1357,11 +1506,14 @@ func CancelBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 		     status_reason = 'cancelled_by_user',
 		     cancelled_at = COALESCE(cancelled_at, NOW()),
 		     released_at = COALESCE(released_at, NOW()),
-		     released_by = $2
+		     released_by = $2,
+		     session_status = $5,
+		     session_status_at = NOW()
 		 WHERE id = $1
 		   AND user_id = $2
-		   AND payment_status = $4`,
-		bookingID, userID, paymentStatusCancelled, paymentStatusPaid,
+		   AND payment_status = $4
+		   AND COALESCE(session_status, 'scheduled') = 'scheduled'`,
+		bookingID, userID, paymentStatusCancelled, paymentStatusPaid, sessionStatus,
 	)
 
 	if err != nil {
@@ -1386,19 +1538,21 @@ func CancelBooking(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *s
 	})
 
 	// Audit Log
-	audit.Log(r.Context(), "booking.cancel", userID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), nil)
+	var details map[string]interface{}
+	if sessionStatus != nil {
+		details = map[string]interface{}{"session_status": *sessionStatus}
+	}
+	audit.Log(r.Context(), "booking.cancel", userID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), details)
 
 	// Send cancellation email (async)
 	go func() {
-		emailSvc := services.GetEmailService()
-		if emailSvc != nil && emailSvc.IsEnabled() {
-			if err := emailSvc.SendBookingCancellation(email, name, date, timeSlot); err != nil {
-				logger.Log.Error("Cancellation email failed",
-					zap.String("email", email),
-					zap.String("booking_id", bookingID),
-					zap.Error(err),
-				)
-			}
+		data := services.NewEmailTemplateData(locale, name, date, timeSlot, "")
+		if err := services.SendTemplatedEmail(context.Background(), email, services.TemplateBookingCancellation, data); err != nil {
+			logger.Log.Error("Cancellation email failed",
+				zap.String("email", email),
+				zap.String("booking_id", bookingID),
+				zap.Error(err),
+			)
 		}
 	}()
 
@@ -1584,6 +1738,10 @@ func RazorpayWebhook(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit
 		return
 	}
 
+	if strings.HasPrefix(event.Event, "payment.") {
+		services.RecordFunnelStepForOrder(ctx, orderID, services.FunnelWebhookReceived, event.Event)
+	}
+
 	var processErr error
 	switch event.Event {
 	case "payment.captured":
@@ -1656,6 +1814,8 @@ func webhookConfirmPayment(ctx context.Context, orderID, paymentID string, hub *
 		return nil
 	}
 
+	services.RecordFunnelStep(ctx, b.ID, services.FunnelConfirmed, "webhook")
+	services.PostBookingLedger(ctx, b.ID)
 	InvalidateSlotsCache(ctx, b.Date)
 	logger.Log.Info("Webhook: booking confirmed",
 		zap.String("booking_id", b.ID),
@@ -1709,6 +1869,7 @@ func webhookReleaseSlot(ctx context.Context, orderID, paymentID string, hub *ws.
 		return err
 	}
 	if result.RowsAffected() > 0 {
+		services.RecordFunnelStepForOrder(ctx, orderID, services.FunnelFailed, "payment_failed_webhook")
 		InvalidateSlotsCache(ctx, date)
 		hub.Broadcast("SLOT_CANCELLED", map[string]string{
 			"date": date,
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	appmetrics "github.com/Himadryy/hidden-depths-backend/internal/middleware"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// HoldLimits bound how much of the calendar one client can tie up. A zero
+// value disables the corresponding limit.
+type HoldLimits struct {
+	MaxActivePerUser   int           // unpaid holds per user at once
+	MaxActivePerIP     int           // unpaid holds per client IP at once
+	MaxUpcomingPerUser int           // confirmed future bookings per user
+	AbandonLimit       int           // abandoned holds within AbandonWindow that trigger Cooldown
+	AbandonWindow      time.Duration // lookback for AbandonLimit
+	Cooldown           time.Duration // measured from the latest abandoned hold
+}
+
+// abandonedHoldReasons are the status reasons of holds the client walked away
+// from without paying. Gateway failures are not counted against the client.
+var abandonedHoldReasons = []string{"hold_expired", "hold_expired_scheduler", "released_by_user"}
+
+// holdUsage is what a client currently has on the calendar.
+type holdUsage struct {
+	ActiveHolds   int
+	ActiveHoldsIP int
+	Upcoming      int
+	Abandoned     int
+	LastAbandoned *time.Time
+}
+
+// bookingQuerier is satisfied by both the pool and a transaction.
+type bookingQuerier interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}
+
+// checkHoldLimits loads the client's usage and applies limits to it. The
+// *AppError is the rejection to return to the client; error is a query failure.
+func checkHoldLimits(ctx context.Context, q bookingQuerier, limits HoldLimits, userID, clientIP, date, timeSlot string, isPaid bool) (*apperror.AppError, error) {
+	if limits == (HoldLimits{}) || userID == "" {
+		return nil, nil
+	}
+	now := time.Now()
+	usage, err := loadHoldUsage(ctx, q, userID, clientIP, date, timeSlot, limits.AbandonWindow, now)
+	if err != nil {
+		return nil, err
+	}
+	return limits.check(usage, isPaid, now), nil
+}
+
+// rejectHoldLimit writes a hold-limit rejection, with Retry-After for cooldowns.
+func rejectHoldLimit(w http.ResponseWriter, r *http.Request, appErr *apperror.AppError, userID, date, timeSlot string) {
+	appmetrics.RecordBookingOperation("create", "hold_limited")
+	logger.Warn("Create booking rejected: hold limit",
+		withRequestID(r,
+			zap.String("user_id", userID),
+			zap.String("date", date),
+			zap.String("time", timeSlot),
+			zap.String("code", appErr.Code),
+		)...,
+	)
+	if retryAt, err := time.Parse(time.RFC3339, appErr.Context["retry_at"]); err == nil {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(retryAt).Seconds())+1))
+	}
+	response.AppErr(w, appErr)
+}
+
+// loadHoldUsage counts the user's (and client IP's) bookings relevant to
+// HoldLimits. Holds on date/timeSlot itself are excluded so that retrying the
+// same slot reuses the existing hold instead of being rejected.
+//
+// user_id is compared as a UUID so idx_bookings_user_status_created serves the
+// per-user rows, and the per-IP rows are limited to pending holds, the only
+// ones counted per IP, so idx_bookings_client_ip_pending serves those.
+func loadHoldUsage(ctx context.Context, q bookingQuerier, userID, clientIP, date, timeSlot string, abandonWindow time.Duration, now time.Time) (holdUsage, error) {
+	var u holdUsage
+	err := q.QueryRow(ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE user_id = $1::uuid AND payment_status = $5
+				AND created_at > NOW() - INTERVAL '`+pendingHoldWindow+`'
+				AND NOT (date = $3 AND time = $4)),
+			COUNT(*) FILTER (WHERE client_ip = $2 AND payment_status = $5
+				AND created_at > NOW() - INTERVAL '`+pendingHoldWindow+`'
+				AND NOT (date = $3 AND time = $4)),
+			COUNT(*) FILTER (WHERE user_id = $1::uuid AND payment_status = $6 AND date >= $7),
+			COUNT(*) FILTER (WHERE user_id = $1::uuid AND status_reason = ANY($8)
+				AND COALESCE(released_at, created_at) > NOW() - make_interval(secs => $9)),
+			MAX(COALESCE(released_at, created_at)) FILTER (WHERE user_id = $1::uuid AND status_reason = ANY($8))
+		 FROM bookings
+		 WHERE user_id = $1::uuid OR ($2 <> '' AND client_ip = $2 AND payment_status = $5)`,
+		userID, clientIP, date, timeSlot, paymentStatusPending, paymentStatusPaid,
+		now.Format("2006-01-02"), abandonedHoldReasons, abandonWindow.Seconds(),
+	).Scan(&u.ActiveHolds, &u.ActiveHoldsIP, &u.Upcoming, &u.Abandoned, &u.LastAbandoned)
+	return u, err
+}
+
+// check returns the error for the first limit usage would exceed by creating
+// one more booking, or nil. Only paid sessions place a hold; free sessions are
+// confirmed immediately and only count towards MaxUpcomingPerUser.
+func (l HoldLimits) check(u holdUsage, isPaid bool, now time.Time) *apperror.AppError {
+	if l.MaxUpcomingPerUser > 0 && u.Upcoming >= l.MaxUpcomingPerUser {
+		return apperror.BookingLimitReached(l.MaxUpcomingPerUser)
+	}
+	if !isPaid {
+		return nil
+	}
+	if l.AbandonLimit > 0 && u.Abandoned >= l.AbandonLimit && u.LastAbandoned != nil {
+		if until := u.LastAbandoned.Add(l.Cooldown); until.After(now) {
+			return apperror.BookingCooldown(until)
+		}
+	}
+	if l.MaxActivePerUser > 0 && u.ActiveHolds >= l.MaxActivePerUser {
+		return apperror.HoldLimitReached(l.MaxActivePerUser)
+	}
+	if l.MaxActivePerIP > 0 && u.ActiveHoldsIP >= l.MaxActivePerIP {
+		return apperror.HoldLimitReached(l.MaxActivePerIP)
+	}
+	return nil
+}
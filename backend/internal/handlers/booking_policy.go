@@ -1,15 +1,21 @@
 package handlers
 
 import (
-	"fmt"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 )
 
 var defaultAllowedWeekdays = []time.Weekday{time.Sunday, time.Monday}
 var defaultTimeSlots = []string{"11:00 AM", "11:45 AM", "12:30 PM", "08:00 PM", "08:45 PM"}
 
+const (
+	defaultSessionLength    = 45 * time.Minute
+	defaultLateCancelWindow = 24 * time.Hour
+)
+
 // BookingPolicy controls booking capacity and discoverability window.
 type BookingPolicy struct {
 	SafeMode         bool
@@ -17,6 +23,9 @@ type BookingPolicy struct {
 	SearchWindowDays int
 	MaxBookableDates int
 	TimeSlots        []string
+	Holds            HoldLimits
+	SessionLength    time.Duration
+	LateCancelWindow time.Duration
 }
 
 var (
@@ -27,6 +36,8 @@ var (
 		SearchWindowDays: 21,
 		MaxBookableDates: 2,
 		TimeSlots:        append([]string{}, defaultTimeSlots...),
+		SessionLength:    defaultSessionLength,
+		LateCancelWindow: defaultLateCancelWindow,
 	}
 )
 
@@ -66,6 +77,12 @@ func SetBookingPolicy(policy BookingPolicy) {
 	if len(policy.TimeSlots) == 0 {
 		policy.TimeSlots = append([]string{}, defaultTimeSlots...)
 	}
+	if policy.SessionLength <= 0 {
+		policy.SessionLength = defaultSessionLength
+	}
+	if policy.LateCancelWindow < 0 {
+		policy.LateCancelWindow = 0
+	}
 
 	bookingPolicyMu.Lock()
 	defer bookingPolicyMu.Unlock()
@@ -75,6 +92,9 @@ func SetBookingPolicy(policy BookingPolicy) {
 		SearchWindowDays: policy.SearchWindowDays,
 		MaxBookableDates: policy.MaxBookableDates,
 		TimeSlots:        append([]string{}, policy.TimeSlots...),
+		Holds:            policy.Holds,
+		SessionLength:    policy.SessionLength,
+		LateCancelWindow: policy.LateCancelWindow,
 	}
 }
 
@@ -87,7 +107,32 @@ func getBookingPolicyConfig() BookingPolicy {
 		SearchWindowDays: bookingPolicy.SearchWindowDays,
 		MaxBookableDates: bookingPolicy.MaxBookableDates,
 		TimeSlots:        append([]string{}, bookingPolicy.TimeSlots...),
+		Holds:            bookingPolicy.Holds,
+		SessionLength:    bookingPolicy.SessionLength,
+		LateCancelWindow: bookingPolicy.LateCancelWindow,
+	}
+}
+
+// CurrentBookingPolicy returns a copy of the process-wide booking policy.
+func CurrentBookingPolicy() BookingPolicy {
+	return getBookingPolicyConfig()
+}
+
+// EligibleBookingDates returns the dates open for booking as of now.
+func EligibleBookingDates(now time.Time) []string {
+	return computeEligibleBookingDates(now, getBookingPolicyConfig())
+}
+
+// CheckBookingDate reports why date cannot be booked as of now, or nil if it can.
+func CheckBookingDate(date string, now time.Time) error {
+	ok, appErr, err := isBookingDateAllowed(date, now, getBookingPolicyConfig())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return appErr
 	}
+	return nil
 }
 
 func isWeekdayAllowed(day time.Weekday, allowed []time.Weekday) bool {
@@ -121,22 +166,22 @@ func computeEligibleBookingDates(now time.Time, policy BookingPolicy) []string {
 	return dates
 }
 
-func isBookingDateAllowed(date string, now time.Time, policy BookingPolicy) (bool, string, error) {
+func isBookingDateAllowed(date string, now time.Time, policy BookingPolicy) (bool, *apperror.AppError, error) {
 	parsed, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return false, "", err
+		return false, nil, err
 	}
 
 	allowedDates := computeEligibleBookingDates(now, policy)
 	target := parsed.Format("2006-01-02")
 	for _, allowedDate := range allowedDates {
 		if allowedDate == target {
-			return true, "", nil
+			return true, nil, nil
 		}
 	}
 
 	if len(allowedDates) == 0 {
-		return false, "No booking dates are currently available", nil
+		return false, apperror.ValidationError("date", "No booking dates are currently available"), nil
 	}
-	return false, fmt.Sprintf("Bookings are currently limited to: %s", strings.Join(allowedDates, ", ")), nil
+	return false, apperror.ValidationErrorf("date", "Bookings are currently limited to: %s", strings.Join(allowedDates, ", ")), nil
 }
@@ -39,22 +39,67 @@ func TestIsBookingDateAllowed(t *testing.T) {
 	}
 	now := time.Date(2026, 4, 14, 10, 0, 0, 0, time.UTC)
 
-	allowed, message, err := isBookingDateAllowed("2026-04-19", now, policy)
+	allowed, rejection, err := isBookingDateAllowed("2026-04-19", now, policy)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if !allowed {
-		t.Fatalf("expected allowed date to be accepted, got message: %s", message)
+		t.Fatalf("expected allowed date to be accepted, got message: %s", rejection.Message)
 	}
 
-	allowed, message, err = isBookingDateAllowed("2026-04-26", now, policy)
+	allowed, rejection, err = isBookingDateAllowed("2026-04-26", now, policy)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if allowed {
 		t.Fatalf("expected outside-window date to be rejected")
 	}
-	if !strings.Contains(message, "2026-04-19") || !strings.Contains(message, "2026-04-20") {
-		t.Fatalf("expected rejection message to include allowed dates, got: %s", message)
+	if !strings.Contains(rejection.Message, "2026-04-19") || !strings.Contains(rejection.Message, "2026-04-20") {
+		t.Fatalf("expected rejection message to include allowed dates, got: %s", rejection.Message)
+	}
+}
+
+func TestHoldLimitsCheck(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+	old := now.Add(-2 * time.Hour)
+	limits := HoldLimits{
+		MaxActivePerUser:   2,
+		MaxActivePerIP:     4,
+		MaxUpcomingPerUser: 2,
+		AbandonLimit:       3,
+		AbandonWindow:      24 * time.Hour,
+		Cooldown:           time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		usage    holdUsage
+		isPaid   bool
+		wantCode string
+	}{
+		{"within limits", holdUsage{ActiveHolds: 1, Upcoming: 1}, true, ""},
+		{"upcoming cap", holdUsage{Upcoming: 2}, false, "BOOKING_LIMIT_REACHED"},
+		{"user holds", holdUsage{ActiveHolds: 2}, true, "HOLD_LIMIT_REACHED"},
+		{"ip holds", holdUsage{ActiveHoldsIP: 4}, true, "HOLD_LIMIT_REACHED"},
+		{"holds ignored for free sessions", holdUsage{ActiveHolds: 5}, false, ""},
+		{"cooldown", holdUsage{Abandoned: 3, LastAbandoned: &recent}, true, "BOOKING_COOLDOWN"},
+		{"cooldown over", holdUsage{Abandoned: 3, LastAbandoned: &old}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limits.check(tt.usage, tt.isPaid, now)
+			code := ""
+			if got != nil {
+				code = got.Code
+			}
+			if code != tt.wantCode {
+				t.Fatalf("check() = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+
+	if got := (HoldLimits{}).check(holdUsage{ActiveHolds: 99, Upcoming: 99}, true, now); got != nil {
+		t.Fatalf("zero limits rejected with %s", got.Code)
 	}
 }
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AcknowledgeCrisisEventRequest records how a crisis event was followed up.
+type AcknowledgeCrisisEventRequest struct {
+	Note string `json:"note"`
+}
+
+// crisisRegion is the client's country as reported by Cloudflare, if any.
+func crisisRegion(r *http.Request) string {
+	return r.Header.Get("CF-IPCountry")
+}
+
+// AdminListCrisisEvents godoc
+// @Summary List crisis events (Admin)
+// @Description Returns crisis signals found in client free text, newest first, with the flagged text. Requires crisis:read, which only mentors hold. Audited.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "open or acknowledged"
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "created_at or -created_at (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/crisis-events [get]
+// @Security BearerAuth
+func AdminListCrisisEvents(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	filter := services.CrisisEventFilter{
+		Status: strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status"))),
+	}
+	p, appErr := pagination.Parse(r.URL.Query(), services.CrisisEventPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	switch filter.Status {
+	case "", "all":
+		filter.Status = ""
+	case "open", "acknowledged":
+	default:
+		response.AppErr(w, apperror.ValidationError("status", "Invalid status filter"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	page, err := services.ListCrisisEvents(ctx, filter, p)
+	if err != nil {
+		logger.Error("Failed to fetch crisis events", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch crisis events", err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "crisis_event.list", adminID, "", "crisis_event", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"status": filter.Status,
+		"cursor": r.URL.Query().Get("cursor"),
+		"count":  len(page.Items),
+	})
+
+	pagination.Respond(w, r, p, page, "Crisis events fetched")
+}
+
+// AdminAcknowledgeCrisisEvent godoc
+// @Summary Acknowledge a crisis event (Admin)
+// @Description Records that the event was followed up, with a required note. Requires crisis:read. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Crisis event ID"
+// @Param request body AcknowledgeCrisisEventRequest true "Follow-up note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/crisis-events/{id}/acknowledge [post]
+// @Security BearerAuth
+func AdminAcknowledgeCrisisEvent(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Crisis event ID must be a UUID"))
+		return
+	}
+	eventID := id.String()
+
+	var req AcknowledgeCrisisEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		response.AppErr(w, apperror.ValidationError("note", "Describe how the event was followed up"))
+		return
+	}
+	if utf8.RuneCountInString(note) > 2000 {
+		response.AppErr(w, apperror.ValidationError("note", "Note must be at most 2000 characters"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	event, err := services.AcknowledgeCrisisEvent(ctx, eventID, adminID, note)
+	switch {
+	case errors.Is(err, services.ErrCrisisEventNotFound):
+		response.AppErr(w, apperror.ResourceNotFound("crisis event", eventID))
+		return
+	case errors.Is(err, services.ErrCrisisEventAcknowledged):
+		response.AppErr(w, apperror.ValidationError("id", "Crisis event has already been acknowledged"))
+		return
+	case err != nil:
+		logger.Error("Failed to acknowledge crisis event", withRequestID(r, zap.String("event_id", eventID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("acknowledge crisis event", err))
+		return
+	}
+
+	// The note may describe the client's situation; it stays in the crisis log
+	audit.Log(r.Context(), "crisis_event.acknowledge", adminID, eventID, "crisis_event", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"severity": event.Severity,
+		"source":   event.Source,
+	})
+
+	response.JSON(w, http.StatusOK, event, "Crisis event acknowledged")
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// resendWebhookTolerance bounds how old a signed webhook may be (replay protection).
+const resendWebhookTolerance = 5 * time.Minute
+
+var errResendSignature = errors.New("invalid resend webhook signature")
+
+// resendWebhookEvent is the subset of a Resend webhook payload we act on.
+type resendWebhookEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		EmailID string   `json:"email_id"`
+		To      []string `json:"to"`
+		Subject string   `json:"subject"`
+		Bounce  struct {
+			Type    string `json:"type"`
+			SubType string `json:"subType"`
+			Message string `json:"message"`
+		} `json:"bounce"`
+	} `json:"data"`
+}
+
+// verifyResendSignature checks a Svix-style signature as sent by Resend webhooks.
+// The secret is the "whsec_" value from the Resend dashboard; the signature header
+// may carry several space-separated "v1,<base64>" entries during secret rotation.
+func verifyResendSignature(secret, msgID, timestamp, signatures string, body []byte, now time.Time) error {
+	if msgID == "" || timestamp == "" || signatures == "" {
+		return errResendSignature
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errResendSignature
+	}
+	if age := now.Sub(time.Unix(sentAt, 0)); age > resendWebhookTolerance || age < -resendWebhookTolerance {
+		return errResendSignature
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return fmt.Errorf("invalid resend webhook secret: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msgID + "." + timestamp + "."))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	for _, candidate := range strings.Fields(signatures) {
+		version, sig, ok := strings.Cut(candidate, ",")
+		if ok && version == "v1" && hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return errResendSignature
+}
+
+// resendSuppressionReason maps a webhook event to a suppression reason. Transient
+// bounces (full mailbox, greylisting) are not suppressed.
+func resendSuppressionReason(event resendWebhookEvent) (string, bool) {
+	switch event.Type {
+	case "email.bounced":
+		if strings.EqualFold(event.Data.Bounce.Type, "Transient") {
+			return "", false
+		}
+		return services.SuppressionBounce, true
+	case "email.complained":
+		return services.SuppressionComplaint, true
+	default:
+		return "", false
+	}
+}
+
+// ResendWebhook godoc
+// @Summary Resend delivery webhook
+// @Description Records bounced and complained recipients in the email suppression list. Signature-verified (Svix headers).
+// @Tags Webhooks
+// @Accept json
+// @Success 200 "Processed"
+// @Failure 400 "Invalid payload"
+// @Failure 401 "Invalid signature"
+// @Router /webhook/resend [post]
+func ResendWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20)) // 1MB limit
+	if err != nil {
+		logger.Log.Error("Resend webhook: failed to read body",
+			withRequestID(r, zap.Error(err))...,
+		)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	webhookSecret := os.Getenv("RESEND_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		logger.Log.Error("Resend webhook: webhook secret not configured",
+			withRequestID(r)...,
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	msgID := r.Header.Get("svix-id")
+	if err := verifyResendSignature(webhookSecret, msgID, r.Header.Get("svix-timestamp"), r.Header.Get("svix-signature"), body, time.Now()); err != nil {
+		logger.Log.Warn("Resend webhook: invalid signature",
+			withRequestID(r, zap.Error(err))...,
+		)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var event resendWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		logger.Log.Error("Resend webhook: failed to parse event",
+			withRequestID(r, zap.Error(err))...,
+		)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reason, suppress := resendSuppressionReason(event)
+	if !suppress {
+		logger.Log.Info("Resend webhook: unhandled event",
+			withRequestID(r, zap.String("event", event.Type), zap.String("email_id", event.Data.EmailID))...,
+		)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	// Idempotency lock shared with the Razorpay webhook.
+	eventID := "resend:" + msgID
+	lockResult, err := database.Pool.Exec(ctx,
+		`INSERT INTO processed_webhooks (event_id, event_type)
+		 VALUES ($1, $2)
+		 ON CONFLICT (event_id) DO NOTHING`,
+		eventID, event.Type,
+	)
+	if err != nil {
+		logger.Log.Error("Resend webhook: failed to acquire idempotency lock",
+			withRequestID(r, zap.String("event_id", eventID), zap.Error(err))...,
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if lockResult.RowsAffected() == 0 {
+		logger.Log.Info("Resend webhook already processed",
+			withRequestID(r, zap.String("event_id", eventID))...,
+		)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	details := strings.TrimSpace(strings.Join([]string{event.Data.Bounce.Type, event.Data.Bounce.SubType, event.Data.Bounce.Message}, " "))
+	for _, recipient := range event.Data.To {
+		if err := services.SuppressEmail(ctx, recipient, reason, "resend_webhook", event.Data.EmailID, details); err != nil {
+			logger.Log.Error("Resend webhook: failed to record suppression",
+				withRequestID(r,
+					zap.String("event_id", eventID),
+					zap.String("reason", reason),
+					zap.Error(err),
+				)...,
+			)
+			_, _ = database.Pool.Exec(ctx, "DELETE FROM processed_webhooks WHERE event_id = $1", eventID)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	logger.Log.Info("Resend webhook: recipients suppressed",
+		withRequestID(r,
+			zap.String("event", event.Type),
+			zap.String("email_id", event.Data.EmailID),
+			zap.Int("recipients", len(event.Data.To)),
+		)...,
+	)
+	w.WriteHeader(http.StatusOK)
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Hidden Depths</title></head>
+<body style="margin: 0; padding: 40px 20px; background-color: #0a0a0a; color: #e0e0e0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; text-align: center;">
+<h1 style="color: #14B8A6; font-size: 24px;">{{.Title}}</h1>
+<p style="font-size: 16px;">{{.Message}}</p>
+{{if .Token}}<form method="post" action="?token={{.Token}}"><button type="submit" style="background: #14B8A6; color: #0a0a0a; border: 0; border-radius: 8px; padding: 12px 24px; font-size: 16px; cursor: pointer;">{{.Button}}</button></form>{{end}}
+</body>
+</html>`))
+
+func writeUnsubscribePage(w http.ResponseWriter, r *http.Request, status int, title, message, token string) {
+	locale := i18n.FromContext(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = unsubscribePage.Execute(w, map[string]string{
+		"Locale":  locale,
+		"Title":   i18n.T(locale, title),
+		"Message": i18n.T(locale, message),
+		"Button":  i18n.T(locale, "Unsubscribe"),
+		"Token":   token,
+	})
+}
+
+// UnsubscribeEmail godoc
+// @Summary Unsubscribe from optional emails
+// @Description GET shows a confirmation page; POST (including RFC 8058 one-click) records the unsubscribe. Booking confirmations and cancellations are still sent.
+// @Tags Webhooks
+// @Produce html
+// @Param token query string true "Signed unsubscribe token from the email"
+// @Success 200 "Confirmation page"
+// @Failure 400 "Invalid token"
+// @Router /email/unsubscribe [get]
+// @Router /email/unsubscribe [post]
+func UnsubscribeEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	email, err := services.ParseUnsubscribeToken(token)
+	if err != nil {
+		writeUnsubscribePage(w, r, http.StatusBadRequest, "Link not valid", "This unsubscribe link is invalid or has been altered.", "")
+		return
+	}
+
+	// GET only confirms, so link scanners that prefetch URLs cannot unsubscribe anyone.
+	if r.Method == http.MethodGet {
+		writeUnsubscribePage(w, r, http.StatusOK, "Unsubscribe from reminders?", "You will stop receiving session reminder emails. Booking confirmations will still be sent.", token)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	if err := services.SuppressEmail(ctx, email, services.SuppressionUnsubscribe, "unsubscribe_link", "", ""); err != nil {
+		logger.Log.Error("Failed to record unsubscribe",
+			withRequestID(r, zap.Error(err))...,
+		)
+		writeUnsubscribePage(w, r, http.StatusInternalServerError, "Something went wrong", "We could not process your request. Please try again later.", "")
+		return
+	}
+
+	logger.Log.Info("Recipient unsubscribed from notification emails",
+		withRequestID(r)...,
+	)
+	writeUnsubscribePage(w, r, http.StatusOK, "You're unsubscribed", "You will no longer receive session reminder emails.", "")
+}
+
+// ListEmailSuppressions godoc
+// @Summary List suppressed email addresses (Admin)
+// @Description Returns a page of addresses blocked by bounces, complaints or unsubscribes, newest first unless sorted otherwise.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Results per page (default 100, max 500)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: created_at or email (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/email-suppressions [get]
+// @Security BearerAuth
+func ListEmailSuppressions(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), services.EmailSuppressionPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	page, err := services.ListEmailSuppressions(r.Context(), p)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("list email suppressions", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, page, "Email suppressions retrieved")
+}
+
+// DeleteEmailSuppression godoc
+// @Summary Remove an address from the suppression list (Admin)
+// @Description Re-enables delivery to an address, e.g. after a mailbox was fixed.
+// @Tags Admin
+// @Produce json
+// @Param email path string true "Email address"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/email-suppressions/{email} [delete]
+// @Security BearerAuth
+func DeleteEmailSuppression(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	email := services.NormalizeEmailAddress(chi.URLParam(r, "email"))
+	if email == "" {
+		response.AppErr(w, apperror.ValidationError("email", "Email address is required"))
+		return
+	}
+
+	removed, err := services.RemoveEmailSuppression(r.Context(), email)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("remove email suppression", err))
+		return
+	}
+	if removed == 0 {
+		response.AppErr(w, apperror.ResourceNotFound("email suppression", email))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "email_suppression.remove", adminID, "", "email_suppression", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"email":   email,
+		"removed": removed,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"email":   email,
+		"removed": removed,
+	}, "Email suppression removed")
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyResendSignature(t *testing.T) {
+	key := []byte("resend-test-signing-key")
+	secret := "whsec_" + base64.StdEncoding.EncodeToString(key)
+	body := []byte(`{"type":"email.bounced"}`)
+	now := time.Unix(1760000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	sign := func(msgID, ts string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(msgID + "." + ts + "." + string(body)))
+		return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	oldTimestamp := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name       string
+		msgID      string
+		timestamp  string
+		signatures string
+		wantErr    bool
+	}{
+		{name: "valid", msgID: "msg_1", timestamp: timestamp, signatures: sign("msg_1", timestamp)},
+		{name: "valid among rotated signatures", msgID: "msg_1", timestamp: timestamp, signatures: "v1,AAAA " + sign("msg_1", timestamp)},
+		{name: "signature for another message", msgID: "msg_2", timestamp: timestamp, signatures: sign("msg_1", timestamp), wantErr: true},
+		{name: "stale timestamp", msgID: "msg_1", timestamp: oldTimestamp, signatures: sign("msg_1", oldTimestamp), wantErr: true},
+		{name: "missing headers", msgID: "", timestamp: timestamp, signatures: sign("", timestamp), wantErr: true},
+		{name: "unknown version", msgID: "msg_1", timestamp: timestamp, signatures: "v2" + sign("msg_1", timestamp)[2:], wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyResendSignature(secret, tc.msgID, tc.timestamp, tc.signatures, body, now)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// EmailTemplateInput is the editable part of an email template.
+type EmailTemplateInput struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+}
+
+// EmailTemplatePreviewRequest selects a template version to render and optionally
+// overrides parts of it with unsaved draft content and custom sample data.
+type EmailTemplatePreviewRequest struct {
+	Version  *int                        `json:"version,omitempty"`
+	Subject  *string                     `json:"subject,omitempty"`
+	HTMLBody *string                     `json:"html_body,omitempty"`
+	TextBody *string                     `json:"text_body,omitempty"`
+	Data     *services.EmailTemplateData `json:"data,omitempty"`
+	Locale   string                      `json:"locale,omitempty"` // en, hi or bn; defaults to en
+}
+
+// EmailTemplateTestRequest sends a preview to a real inbox.
+type EmailTemplateTestRequest struct {
+	EmailTemplatePreviewRequest
+	To string `json:"to"`
+}
+
+// EmailTemplateRollbackRequest selects the version to reactivate (0 = embedded default).
+type EmailTemplateRollbackRequest struct {
+	Version *int `json:"version"`
+}
+
+func emailTemplateName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := chi.URLParam(r, "name")
+	if !services.IsKnownEmailTemplate(name) {
+		response.AppErr(w, apperror.ResourceNotFound("email template", name))
+		return "", false
+	}
+	return name, true
+}
+
+func emailTemplateLookupError(name string, version int, err error) *apperror.AppError {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.ResourceNotFound("email template version", name).WithContext("version", strconv.Itoa(version))
+	}
+	if appErr, ok := apperror.AsAppError(err); ok {
+		return appErr
+	}
+	return apperror.DatabaseError("fetch email template", err)
+}
+
+// resolveEmailTemplatePreview loads the requested version (or the active one) and
+// applies any draft overrides from the request.
+func resolveEmailTemplatePreview(ctx context.Context, name string, req EmailTemplatePreviewRequest) (models.EmailTemplate, services.EmailTemplateData, *apperror.AppError) {
+	var (
+		tmpl models.EmailTemplate
+		err  error
+	)
+	if req.Version != nil {
+		tmpl, err = services.GetEmailTemplateVersion(ctx, name, *req.Version)
+		if err != nil {
+			return tmpl, services.EmailTemplateData{}, emailTemplateLookupError(name, *req.Version, err)
+		}
+	} else {
+		tmpl, err = services.ActiveEmailTemplate(ctx, name)
+		if err != nil {
+			return tmpl, services.EmailTemplateData{}, apperror.InternalError(err)
+		}
+	}
+
+	if req.Subject != nil {
+		tmpl.Subject = *req.Subject
+	}
+	if req.HTMLBody != nil {
+		tmpl.HTMLBody = *req.HTMLBody
+	}
+	if req.TextBody != nil {
+		tmpl.TextBody = *req.TextBody
+	}
+
+	locale := i18n.Default
+	if req.Locale != "" {
+		var ok bool
+		if locale, ok = i18n.Normalize(req.Locale); !ok {
+			return tmpl, services.EmailTemplateData{}, apperror.ValidationError("locale", "Unsupported locale")
+		}
+	}
+
+	data := services.SampleEmailTemplateData(locale)
+	if req.Data != nil {
+		data = *req.Data
+		if data.Locale == "" {
+			data.Locale = locale
+		}
+	}
+	return tmpl, data, nil
+}
+
+// ListEmailTemplates godoc
+// @Summary List email templates (Admin)
+// @Description Returns the active version of every editable email template. Version 0 is the embedded default. Not paginated: the template names are fixed in code, so the list is small and bounded.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/email-templates [get]
+// @Security BearerAuth
+func ListEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	names := services.EmailTemplateNames()
+	active := make([]models.EmailTemplate, 0, len(names))
+	for _, name := range names {
+		tmpl, err := services.ActiveEmailTemplate(r.Context(), name)
+		if err != nil {
+			logger.Log.Error("Failed to load email template", zap.String("template", name), zap.Error(err))
+			response.AppErr(w, apperror.InternalError(err))
+			return
+		}
+		active = append(active, tmpl)
+	}
+
+	response.JSON(w, http.StatusOK, active, "Email templates fetched")
+}
+
+// GetEmailTemplateVersions godoc
+// @Summary List email template versions (Admin)
+// @Description Returns every stored version of a template, newest first, followed by the embedded default.
+// @Tags Admin
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/email-templates/{name}/versions [get]
+// @Security BearerAuth
+func GetEmailTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	name, ok := emailTemplateName(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := services.ListEmailTemplateVersions(r.Context(), name)
+	if err != nil {
+		logger.Log.Error("Failed to list email template versions", zap.String("template", name), zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("list email template versions", err))
+		return
+	}
+
+	fallback, err := services.DefaultEmailTemplate(name)
+	if err != nil {
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+	fallback.IsActive = true
+	for _, v := range versions {
+		if v.IsActive {
+			fallback.IsActive = false
+			break
+		}
+	}
+
+	response.JSON(w, http.StatusOK, append(versions, fallback), "Email template versions fetched")
+}
+
+// UpdateEmailTemplate godoc
+// @Summary Save a new email template version (Admin)
+// @Description Validates the template against sample data, stores it as a new version and activates it.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param template body EmailTemplateInput true "Template content"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/email-templates/{name} [put]
+// @Security BearerAuth
+func UpdateEmailTemplate(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	name, ok := emailTemplateName(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	if strings.TrimSpace(req.Subject) == "" {
+		response.AppErr(w, apperror.ValidationError("subject", "Subject is required"))
+		return
+	}
+	if strings.TrimSpace(req.HTMLBody) == "" {
+		response.AppErr(w, apperror.ValidationError("html_body", "HTML body is required"))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	stored, err := services.CreateEmailTemplateVersion(r.Context(), models.EmailTemplate{
+		Name:     name,
+		Subject:  req.Subject,
+		HTMLBody: req.HTMLBody,
+		TextBody: req.TextBody,
+	}, adminID)
+	if err != nil {
+		if appErr, ok := apperror.AsAppError(err); ok {
+			response.AppErr(w, appErr)
+			return
+		}
+		logger.Log.Error("Failed to save email template", zap.String("template", name), zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("save email template", err))
+		return
+	}
+
+	audit.Log(r.Context(), "email_template.update", adminID, stored.ID, "email_template", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"name":    stored.Name,
+		"version": stored.Version,
+	})
+
+	response.JSON(w, http.StatusCreated, stored, "Email template saved")
+}
+
+// PreviewEmailTemplate godoc
+// @Summary Preview an email template (Admin)
+// @Description Renders the active version, a specific version or an unsaved draft with sample data.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body EmailTemplatePreviewRequest false "Version, draft overrides and sample data"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/email-templates/{name}/preview [post]
+// @Security BearerAuth
+func PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	name, ok := emailTemplateName(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailTemplatePreviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.AppErr(w, apperror.InvalidPayload(err))
+			return
+		}
+	}
+
+	tmpl, data, appErr := resolveEmailTemplatePreview(r.Context(), name, req)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	rendered, err := services.RenderEmailTemplate(tmpl, data)
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("template", err.Error()))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"name":     name,
+		"version":  tmpl.Version,
+		"rendered": rendered,
+	}, "Email template rendered")
+}
+
+// SendEmailTemplateTest godoc
+// @Summary Send a test email from a template (Admin)
+// @Description Renders a template version or draft with sample data and sends it to the given address.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body EmailTemplateTestRequest true "Recipient and optional version or draft"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /admin/email-templates/{name}/test [post]
+// @Security BearerAuth
+func SendEmailTemplateTest(w http.ResponseWriter, r *http.Request) {
+	name, ok := emailTemplateName(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailTemplateTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	if strings.TrimSpace(req.To) == "" {
+		response.AppErr(w, apperror.ValidationError("to", "Email address is required"))
+		return
+	}
+
+	tmpl, data, appErr := resolveEmailTemplatePreview(r.Context(), name, req.EmailTemplatePreviewRequest)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	rendered, err := services.RenderEmailTemplate(tmpl, data)
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("template", err.Error()))
+		return
+	}
+	rendered.Subject = "[TEST] " + rendered.Subject
+
+	msg := services.NewOutgoingEmail(req.To, rendered, services.EmailTemplateCategory(name))
+	if err := services.DeliverEmail(msg); err != nil {
+		logger.Log.Error("Template test email failed", zap.String("template", name), zap.String("to", req.To), zap.Error(err))
+		if appErr, ok := apperror.AsAppError(err); ok {
+			response.AppErr(w, appErr)
+			return
+		}
+		response.AppErr(w, apperror.ExternalServiceError("email", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"sent_to": req.To,
+		"name":    name,
+		"version": tmpl.Version,
+	}, "Test email sent successfully")
+}
+
+// RollbackEmailTemplate godoc
+// @Summary Roll back an email template (Admin)
+// @Description Reactivates a previous version. Version 0 restores the embedded default.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body EmailTemplateRollbackRequest true "Version to activate"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/email-templates/{name}/rollback [post]
+// @Security BearerAuth
+func RollbackEmailTemplate(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	name, ok := emailTemplateName(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailTemplateRollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	if req.Version == nil || *req.Version < 0 {
+		response.AppErr(w, apperror.ValidationError("version", "Version must be 0 (default) or a stored version"))
+		return
+	}
+
+	activated, err := services.ActivateEmailTemplateVersion(r.Context(), name, *req.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.AppErr(w, emailTemplateLookupError(name, *req.Version, err))
+			return
+		}
+		logger.Log.Error("Failed to roll back email template", zap.String("template", name), zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("roll back email template", err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "email_template.rollback", adminID, activated.ID, "email_template", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"name":    name,
+		"version": activated.Version,
+	})
+
+	response.JSON(w, http.StatusOK, activated, "Email template rolled back")
+}
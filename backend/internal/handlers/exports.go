@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/export"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// exportTimeout bounds one export, including the time the client takes to
+// download it. It replaces the server's write timeout for export responses;
+// export routes are mounted without the 60-second request timeout.
+const exportTimeout = 10 * time.Minute
+
+// emitRow writes one exported row.
+type emitRow func(cells ...any) error
+
+// streamExport validates the format parameter, then runs run, writing the
+// column header and every row it emits to the response as it goes. The
+// response headers are sent with the first row, so an error before then is
+// still returned as JSON; after that the file is cut short and the failure
+// logged. Every export is audited as "export.<kind>" with its filters and
+// row count.
+func streamExport(w http.ResponseWriter, r *http.Request, audit *services.AuditService, kind string, filters map[string]interface{}, columns []string, run func(ctx context.Context, emit emitRow) error) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !export.IsFormat(format) {
+		response.AppErr(w, apperror.ValidationError("format", "format must be csv or xlsx"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+	defer cancel()
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportTimeout)); err != nil {
+		logger.Warn("Export cannot extend write deadline", withRequestID(r, zap.Error(err))...)
+	}
+
+	var out export.Writer
+	start := func() error {
+		filename := fmt.Sprintf("%s-%s.%s", kind, time.Now().UTC().Format("20060102-150405"), format)
+		w.Header().Set("Content-Type", export.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Cache-Control", "no-store")
+		var err error
+		if out, err = export.New(format, w, kind); err != nil {
+			return err
+		}
+		header := make([]any, len(columns))
+		for i, column := range columns {
+			header[i] = column
+		}
+		return out.WriteRow(header...)
+	}
+
+	rows := 0
+	err := run(ctx, func(cells ...any) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return out.WriteRow(cells...)
+	})
+	started := out != nil
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = out.Close()
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "export."+kind, adminID, "", kind, r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"format":    format,
+		"filters":   filters,
+		"rows":      rows,
+		"completed": err == nil,
+	})
+
+	switch {
+	case err == nil:
+	case !started:
+		logger.Error("Export failed", withRequestID(r, zap.String("export", kind), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("export "+kind, err))
+	default:
+		logger.Error("Export interrupted; file is incomplete",
+			withRequestID(r, zap.String("export", kind), zap.Int("rows", rows), zap.Error(err))...,
+		)
+	}
+}
+
+// ExportAdminBookings godoc
+// @Summary Export bookings (Admin)
+// @Description Streams every booking matching the filters of GET /admin/bookings as CSV or XLSX, newest first, with names and emails decrypted. Requires bookings:read. Audited.
+// @Tags Admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Param status query string false "Status filter (paid, pending, failed, cancelled, confirmed)"
+// @Param search query string false "Search as in GET /admin/bookings"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/exports/bookings [get]
+// @Security BearerAuth
+func ExportAdminBookings(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	whereClause, args, appErr := adminBookingConditions(r.URL.Query())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	columns := []string{
+		"booking_id", "user_id", "name", "email", "date", "time", "payment_status", "session_status",
+		"amount", "status_reason", "razorpay_order_id", "razorpay_payment_id", "payment_reference",
+		"refund_id", "created_at", "confirmed_at", "cancelled_at",
+	}
+	streamExport(w, r, audit, "bookings", bookingExportFilters(r), columns, func(ctx context.Context, emit emitRow) error {
+		rows, err := database.Pool.Query(ctx, `
+			SELECT id, user_id, name, email, date, time, payment_status, session_status,
+			       COALESCE(amount, 0), status_reason, razorpay_order_id, razorpay_payment_id, payment_reference,
+			       refund_id, created_at, confirmed_at, cancelled_at
+			FROM bookings
+			WHERE `+whereClause+`
+			ORDER BY created_at DESC`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				id, name, email, date, slot, status string
+				userID, sessionStatus, reason       *string
+				orderID, paymentID, reference       *string
+				refundID                            *string
+				amount                              float64
+				createdAt                           time.Time
+				confirmedAt, cancelledAt            *time.Time
+			)
+			if err := rows.Scan(&id, &userID, &name, &email, &date, &slot, &status, &sessionStatus,
+				&amount, &reason, &orderID, &paymentID, &reference,
+				&refundID, &createdAt, &confirmedAt, &cancelledAt); err != nil {
+				return err
+			}
+			if err := services.OpenBookingPII(&name, &email); err != nil {
+				return err
+			}
+			if err := emit(id, userID, name, email, date, slot, status, sessionStatus,
+				amount, reason, orderID, paymentID, reference,
+				refundID, createdAt, confirmedAt, cancelledAt); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// ExportAdminPayments godoc
+// @Summary Export payments and refunds (Admin)
+// @Description Streams every booking that was paid or refunded, matching the filters of GET /admin/bookings, as CSV or XLSX, most recently confirmed first. method is razorpay, offline (confirmed by an admin with a reference) or free. Requires bookings:read. Audited.
+// @Tags Admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Param status query string false "Status filter (paid, pending, failed, cancelled, confirmed)"
+// @Param search query string false "Search as in GET /admin/bookings"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/exports/payments [get]
+// @Security BearerAuth
+func ExportAdminPayments(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	whereClause, args, appErr := adminBookingConditions(r.URL.Query())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	columns := []string{
+		"booking_id", "user_id", "email", "session_date", "session_time", "payment_status", "method",
+		"amount", "razorpay_order_id", "razorpay_payment_id", "payment_reference", "confirmed_at",
+		"refund_id", "refund_amount", "refunded_at",
+	}
+	streamExport(w, r, audit, "payments", bookingExportFilters(r), columns, func(ctx context.Context, emit emitRow) error {
+		rows, err := database.Pool.Query(ctx, `
+			SELECT id, user_id, email, date, time, payment_status,
+			       CASE WHEN razorpay_payment_id IS NOT NULL THEN 'razorpay'
+			            WHEN payment_reference IS NOT NULL THEN 'offline'
+			            ELSE 'free' END,
+			       COALESCE(amount, 0), razorpay_order_id, razorpay_payment_id, payment_reference, confirmed_at,
+			       refund_id, refund_amount, refunded_at
+			FROM bookings
+			WHERE (confirmed_at IS NOT NULL OR refunded_at IS NOT NULL) AND `+whereClause+`
+			ORDER BY confirmed_at DESC NULLS LAST, created_at DESC`,
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				id, email, date, slot, status, method string
+				userID, orderID, paymentID, reference *string
+				refundID                              *string
+				amount                                float64
+				refundAmount                          *float64
+				confirmedAt, refundedAt               *time.Time
+			)
+			if err := rows.Scan(&id, &userID, &email, &date, &slot, &status, &method,
+				&amount, &orderID, &paymentID, &reference, &confirmedAt,
+				&refundID, &refundAmount, &refundedAt); err != nil {
+				return err
+			}
+			if err := services.OpenBookingPII(nil, &email); err != nil {
+				return err
+			}
+			if err := emit(id, userID, email, date, slot, status, method,
+				amount, orderID, paymentID, reference, confirmedAt,
+				refundID, refundAmount, refundedAt); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// ExportAdminCouponUses godoc
+// @Summary Export coupon uses (Admin)
+// @Description Streams coupon redemptions as CSV or XLSX, newest first. from and to are RFC 3339 times or YYYY-MM-DD dates; to is exclusive. Requires bookings:read. Audited.
+// @Tags Admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Param code query string false "Coupon code"
+// @Param from query string false "Earliest time"
+// @Param to query string false "Latest time (exclusive)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/exports/coupon-uses [get]
+// @Security BearerAuth
+func ExportAdminCouponUses(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	q := r.URL.Query()
+	code := strings.TrimSpace(q.Get("code"))
+	from, appErr := timeParam(q, "from")
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	to, appErr := timeParam(q, "to")
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		response.AppErr(w, apperror.ValidationError("to", "to must be after from"))
+		return
+	}
+
+	filters := map[string]interface{}{"code": code, "from": from, "to": to}
+	columns := []string{"id", "coupon_code", "user_id", "booking_id", "discount_applied", "created_at"}
+	streamExport(w, r, audit, "coupon_uses", filters, columns, func(ctx context.Context, emit emitRow) error {
+		rows, err := database.Pool.Query(ctx, `
+			SELECT u.id, COALESCE(c.code, ''), u.user_id, u.booking_id, u.discount_applied, u.created_at
+			FROM coupon_uses u
+			LEFT JOIN coupons c ON c.id = u.coupon_id
+			WHERE ($1 = '' OR c.code = $1)
+			  AND ($2::timestamptz IS NULL OR u.created_at >= $2)
+			  AND ($3::timestamptz IS NULL OR u.created_at < $3)
+			ORDER BY u.created_at DESC`,
+			code, from, to,
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				id, couponCode    string
+				userID, bookingID *string
+				discount          *float64
+				createdAt         *time.Time
+			)
+			if err := rows.Scan(&id, &couponCode, &userID, &bookingID, &discount, &createdAt); err != nil {
+				return err
+			}
+			if err := emit(id, couponCode, userID, bookingID, discount, createdAt); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// ExportAuditLog godoc
+// @Summary Export the audit log (Admin)
+// @Description Streams audit log entries matching the filters of GET /admin/audit as CSV or XLSX, newest first. Requires audit:read. Audited.
+// @Tags Admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Param user_id query string false "Actor user ID"
+// @Param entity_type query string false "Entity type, e.g. booking"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action or action prefix"
+// @Param from query string false "Earliest time"
+// @Param to query string false "Latest time (exclusive)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/exports/audit [get]
+// @Security BearerAuth
+func ExportAuditLog(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	filter, appErr := auditFilterParams(r.URL.Query())
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"user_id":     filter.UserID,
+		"entity_type": filter.EntityType,
+		"entity_id":   filter.EntityID,
+		"action":      filter.Action,
+		"from":        filter.From,
+		"to":          filter.To,
+	}
+	columns := []string{
+		"id", "seq", "created_at", "action", "user_id", "entity_type", "entity_id",
+		"ip_address", "user_agent", "details", "hash", "redacted_at",
+	}
+	streamExport(w, r, audit, "audit_logs", filters, columns, func(ctx context.Context, emit emitRow) error {
+		return services.StreamAuditLogs(ctx, filter, func(e models.AuditLog) error {
+			return emit(e.ID, e.Seq, e.CreatedAt, e.Action, e.UserID, e.EntityType, e.EntityID,
+				e.IPAddress, e.UserAgent, string(e.Details), e.Hash, e.RedactedAt)
+		})
+	})
+}
+
+// bookingExportFilters records the booking filters of an export for the
+// audit log.
+func bookingExportFilters(r *http.Request) map[string]interface{} {
+	return map[string]interface{}{
+		"status": strings.TrimSpace(r.URL.Query().Get("status")),
+		// The search term may be a client's name or email; only its use is kept
+		"search": strings.TrimSpace(r.URL.Query().Get("search")) != "",
+	}
+}
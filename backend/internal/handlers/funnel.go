@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ReportCheckoutOpened godoc
+// @Summary Report that the payment checkout opened
+// @Description Called by the client when the Razorpay checkout is shown for a pending booking, for booking funnel tracking. Repeated calls are ignored.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /bookings/{id}/checkout-opened [post]
+// @Security BearerAuth
+func ReportCheckoutOpened(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	bookingID, ok := bookingIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	var payStatus string
+	err := database.Pool.QueryRow(ctx,
+		"SELECT payment_status FROM bookings WHERE id = $1 AND user_id = $2",
+		bookingID, userID,
+	).Scan(&payStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return
+	}
+	if payStatus != paymentStatusPending {
+		response.AppErr(w, apperror.ValidationError("booking", "Booking is no longer pending"))
+		return
+	}
+
+	services.RecordFunnelStep(ctx, bookingID, services.FunnelCheckoutOpened, "")
+	response.JSON(w, http.StatusOK, nil, "Checkout recorded")
+}
+
+// AdminGetBookingFunnel godoc
+// @Summary Booking funnel from hold to payment (Admin)
+// @Description For slot holds created in the date range, returns how many reached each step (checkout opened, verify called, webhook received, confirmed, released, expired, failed) and latency percentiles in seconds since the hold. Results are cached for a minute. Requires stats:read.
+// @Tags Admin
+// @Produce json
+// @Param from query string false "First hold date, YYYY-MM-DD (default 30 days before to)"
+// @Param to query string false "Last hold date, YYYY-MM-DD (default today)"
+// @Param tz query string false "IANA timezone dates are read in (default Asia/Kolkata)"
+// @Success 200 {object} models.BookingFunnel
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/funnel [get]
+// @Security BearerAuth
+func AdminGetBookingFunnel(w http.ResponseWriter, r *http.Request) {
+	from, to, tz, appErr := parseAnalyticsRange(r.URL.Query(), time.Now(), 30, 366)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	funnel, err := services.GetBookingFunnel(ctx, from, to, tz)
+	if err != nil {
+		logger.Error("Failed to compute booking funnel", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("compute booking funnel", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, funnel, "Booking funnel fetched")
+}
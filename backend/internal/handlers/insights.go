@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/Himadryy/hidden-depths-backend/internal/database"
@@ -11,33 +12,62 @@ import (
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
 	"github.com/Himadryy/hidden-depths-backend/pkg/response"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
+// insightsPage is how the public insight list pages and sorts.
+var insightsPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"order":      {Expr: "sort_order", Type: pagination.TypeInt},
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "order",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 50,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.Insight{}),
+}
+
 // GetAllInsights godoc
-// @Summary Get all insight cards
-// @Description Returns all insights ordered by sort_order for homepage carousel. Uses cache-aside pattern.
+// @Summary Get insight cards
+// @Description Returns a page of insights, ordered by sort_order unless sorted otherwise, for the homepage carousel. Uses cache-aside pattern.
 // @Tags Insights
 // @Produce json
+// @Param limit query int false "Results per page (default 50, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: order or created_at (default order)"
+// @Param fields query string false "Comma-separated fields to return"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /insights [get]
 func GetAllInsights(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	cacheKey := cache.InsightsKey()
+
+	p, appErr := pagination.Parse(r.URL.Query(), insightsPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	cacheKey := cache.InsightsKey(p.CacheKey())
 
 	// Try cache first
-	if insights, err := cache.Get[[]models.Insight](ctx, cacheKey); err == nil {
+	if page, err := cache.Get[pagination.Page[models.Insight]](ctx, cacheKey); err == nil {
 		logger.Log.Debug("Cache hit for insights")
-		response.JSON(w, http.StatusOK, insights, "Insights fetched successfully")
+		pagination.Respond(w, r, p, page, "Insights fetched successfully")
 		return
 	}
 
 	// Cache miss — query DB
-	rows, err := database.Pool.Query(ctx,
-		"SELECT id, title, description, media_url, media_type, sort_order FROM insights ORDER BY sort_order ASC",
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		"SELECT id, title, description, media_url, media_type, sort_order, created_at, %s FROM insights WHERE %s ORDER BY %s LIMIT $%d",
+		p.Select(), after, p.OrderBy(), len(args)),
+		args...,
 	)
 	if err != nil {
 		logger.Log.Error("Failed to fetch insights", zap.Error(err))
@@ -47,28 +77,29 @@ func GetAllInsights(w http.ResponseWriter, r *http.Request) {
 	defer rows.Close()
 
 	var insights []models.Insight
+	var positions []pagination.Position
 	for rows.Next() {
 		var i models.Insight
-		if err := rows.Scan(&i.ID, &i.Title, &i.Description, &i.MediaURL, &i.MediaType, &i.SortOrder); err != nil {
+		var pos pagination.Position
+		if err := rows.Scan(&i.ID, &i.Title, &i.Description, &i.MediaURL, &i.MediaType, &i.SortOrder, &i.CreatedAt, &pos.Value, &pos.Key); err != nil {
 			logger.Log.Error("Failed to scan insight", zap.Error(err))
 			continue
 		}
 		insights = append(insights, i)
+		positions = append(positions, pos)
 	}
 
-	// Ensure we cache empty array, not nil
-	if insights == nil {
-		insights = []models.Insight{}
-	}
-	_ = cache.Set(ctx, cacheKey, insights, cache.InsightsTTL)
+	// NewPage turns nil into an empty array, so empty pages are cached too
+	page := pagination.NewPage(p, insights, positions)
+	_ = cache.Set(ctx, cacheKey, page, cache.InsightsTTL)
 
-	response.JSON(w, http.StatusOK, insights, "Insights fetched successfully")
+	pagination.Respond(w, r, p, page, "Insights fetched successfully")
 }
 
-// InvalidateInsightsCache removes cached insights data.
+// InvalidateInsightsCache removes every cached page of insights.
 // Call this after any insight modification (create, update, delete).
 func InvalidateInsightsCache(ctx context.Context) {
-	if err := cache.Delete(ctx, cache.InsightsKey()); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+	if err := cache.DeletePattern(ctx, cache.PrefixInsights+"*"); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
 		logger.Log.Warn("Failed to invalidate insights cache", zap.Error(err))
 	}
 }
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// IntakeSubmitRequest carries answers keyed by question ID. Value types
+// follow the question type: string, list of strings, number or boolean.
+type IntakeSubmitRequest struct {
+	Answers map[string]json.RawMessage `json:"answers"`
+}
+
+// IntakeFormInput is a new intake form version.
+type IntakeFormInput struct {
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	Questions   []models.IntakeQuestion `json:"questions"`
+}
+
+// IntakeFormActivateRequest selects the version to activate (0 = no form).
+type IntakeFormActivateRequest struct {
+	Version *int `json:"version"`
+}
+
+// GetBookingIntake godoc
+// @Summary Get the intake form for a booking
+// @Description Returns the active intake form and whether it must be answered before the session link is released. Answers are not returned.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /bookings/{id}/intake [get]
+// @Security BearerAuth
+func GetBookingIntake(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	bookingID, ok := bookingIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	var required, submitted bool
+	err := database.Pool.QueryRow(ctx,
+		`SELECT `+services.IntakePendingCondition+`,
+		        EXISTS (SELECT 1 FROM intake_responses WHERE booking_id = b.id)
+		 FROM bookings b
+		 WHERE b.id = $1 AND b.user_id = $2`,
+		bookingID, userID,
+	).Scan(&required, &submitted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return
+	}
+
+	payload := map[string]interface{}{
+		"booking_id": bookingID,
+		"required":   required,
+		"submitted":  submitted,
+	}
+	form, err := services.ActiveIntakeForm(ctx)
+	switch {
+	case err == nil:
+		payload["form"] = form
+	case !errors.Is(err, services.ErrNoActiveIntakeForm):
+		logger.Error("Failed to load intake form", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch intake form", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, payload, "Intake form fetched")
+}
+
+// SubmitBookingIntake godoc
+// @Summary Answer the intake form for a booking
+// @Description Validates answers against the active intake form and stores them encrypted, replacing earlier answers. Open until the session starts.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body IntakeSubmitRequest true "Answers keyed by question ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /bookings/{id}/intake [put]
+// @Security BearerAuth
+func SubmitBookingIntake(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	bookingID, ok := bookingIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req IntakeSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	var sessionStatus string
+	err := database.Pool.QueryRow(ctx,
+		`SELECT COALESCE(session_status, '')
+		 FROM bookings
+		 WHERE id = $1 AND user_id = $2 AND payment_status = $3`,
+		bookingID, userID, paymentStatusPaid,
+	).Scan(&sessionStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return
+	}
+	if sessionStatus != sessionStatusScheduled {
+		response.AppErr(w, apperror.ValidationError("booking", "Intake answers can no longer be changed"))
+		return
+	}
+
+	form, err := services.ActiveIntakeForm(ctx)
+	if errors.Is(err, services.ErrNoActiveIntakeForm) {
+		response.AppErr(w, apperror.ValidationError("intake", "No intake form is needed for this session"))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load intake form", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch intake form", err))
+		return
+	}
+	answers, appErr := services.ValidateIntakeAnswers(form.Questions, req.Answers)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	saved, err := services.SaveIntakeResponse(ctx, bookingID, userID, form, answers)
+	if err != nil {
+		logger.Error("Failed to save intake response", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("save intake response", err))
+		return
+	}
+
+	// Answers are health-adjacent; only their shape is audited
+	audit.Log(r.Context(), "intake.submit", userID, saved.ID, "intake_response", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"booking_id":   bookingID,
+		"form_version": form.Version,
+		"answered":     len(answers),
+	})
+
+	payload := map[string]interface{}{
+		"booking_id":   bookingID,
+		"form_version": form.Version,
+		"submitted_at": saved.SubmittedAt,
+		"updated_at":   saved.UpdatedAt,
+	}
+	if support := services.ScreenForCrisis(r.Context(), services.CrisisScreeningInput{
+		Source:    services.CrisisSourceIntake,
+		UserID:    userID,
+		BookingID: bookingID,
+		Region:    crisisRegion(r),
+		Texts:     services.IntakeFreeText(form.Questions, answers),
+	}); support != nil {
+		payload["crisis_support"] = support
+	}
+
+	response.JSON(w, http.StatusOK, payload, "Intake form submitted")
+}
+
+// AdminListIntakeForms godoc
+// @Summary List intake form versions (Admin)
+// @Description Returns a page of stored intake form versions, newest first. Requires intake_forms:write.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: version (default -version)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/intake-forms [get]
+// @Security BearerAuth
+func AdminListIntakeForms(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), services.IntakeFormListPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	page, err := services.ListIntakeForms(ctx, p)
+	if err != nil {
+		logger.Log.Error("Failed to list intake forms", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("list intake forms", err))
+		return
+	}
+	pagination.Respond(w, r, p, page, "Intake forms fetched")
+}
+
+// AdminCreateIntakeForm godoc
+// @Summary Save a new intake form version (Admin)
+// @Description Validates the questions, stores them as a new version and activates it. Clients who have not answered any version must answer it before their first session. Requires intake_forms:write. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body IntakeFormInput true "Form definition"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/intake-forms [post]
+// @Security BearerAuth
+func AdminCreateIntakeForm(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	var req IntakeFormInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	stored, err := services.CreateIntakeForm(r.Context(), models.IntakeForm{
+		Title:       req.Title,
+		Description: req.Description,
+		Questions:   req.Questions,
+	}, adminID)
+	if err != nil {
+		if appErr, ok := apperror.AsAppError(err); ok {
+			response.AppErr(w, appErr)
+			return
+		}
+		logger.Log.Error("Failed to save intake form", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("save intake form", err))
+		return
+	}
+
+	audit.Log(r.Context(), "intake_form.create", adminID, stored.ID, "intake_form", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"version":   stored.Version,
+		"questions": len(stored.Questions),
+	})
+
+	response.JSON(w, http.StatusCreated, stored, "Intake form saved")
+}
+
+// AdminActivateIntakeForm godoc
+// @Summary Activate an intake form version (Admin)
+// @Description Makes a stored version the active form. Version 0 turns the intake requirement off. Requires intake_forms:write. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body IntakeFormActivateRequest true "Version to activate"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/intake-forms/activate [post]
+// @Security BearerAuth
+func AdminActivateIntakeForm(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	var req IntakeFormActivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	if req.Version == nil || *req.Version < 0 {
+		response.AppErr(w, apperror.ValidationError("version", "Version must be 0 (no form) or a stored version"))
+		return
+	}
+
+	activated, err := services.ActivateIntakeForm(r.Context(), *req.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.ResourceNotFound("intake form version", strconv.Itoa(*req.Version)))
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Failed to activate intake form", zap.Int("version", *req.Version), zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("activate intake form", err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "intake_form.activate", adminID, activated.ID, "intake_form", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"version": *req.Version,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"version": *req.Version,
+		"form":    activated,
+	}, "Intake form activated")
+}
+
+// AdminGetBookingIntake godoc
+// @Summary Read a booking's intake answers (Admin)
+// @Description Returns the client's decrypted answers with the form version they answered. Requires intake:read, which only mentors hold. Audited.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/intake [get]
+// @Security BearerAuth
+func AdminGetBookingIntake(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	bookingID, ok := bookingIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	resp, form, err := services.GetIntakeResponse(ctx, bookingID)
+	if errors.Is(err, services.ErrIntakeResponseNotFound) {
+		response.AppErr(w, apperror.ResourceNotFound("intake response", bookingID))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load intake response", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch intake response", err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "intake.view", adminID, resp.ID, "intake_response", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"booking_id": bookingID,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"response": resp,
+		"form":     form,
+	}, "Intake response fetched")
+}
+
+// bookingIDParam reads the {id} path parameter. It writes the error
+// response and returns ok=false when it is not a UUID.
+func bookingIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Booking ID must be a UUID"))
+		return "", false
+	}
+	return id.String(), true
+}
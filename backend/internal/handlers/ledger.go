@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSettlementReportBytes bounds an uploaded settlement report.
+const maxSettlementReportBytes = 10 << 20
+
+// ledgerPeriodParam reads and validates the {period} URL parameter.
+func ledgerPeriodParam(r *http.Request) (string, *apperror.AppError) {
+	period := chi.URLParam(r, "period")
+	if !services.ValidLedgerPeriod(period) {
+		return period, apperror.ValidationError("period", "Period must be a month in YYYY-MM format")
+	}
+	return period, nil
+}
+
+// AdminListLedgerPeriods godoc
+// @Summary List ledger periods (Admin)
+// @Description Returns every month with ledger postings or a close, newest first, with whether it is closed and a trial balance per account. Requires ledger:manage.
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} models.LedgerPeriod
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ledger/periods [get]
+// @Security BearerAuth
+func AdminListLedgerPeriods(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	periods, err := services.ListLedgerPeriods(ctx)
+	if err != nil {
+		logger.Error("Failed to fetch ledger periods", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch ledger periods", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, periods, "Ledger periods fetched")
+}
+
+// AdminCloseLedgerPeriod godoc
+// @Summary Close a ledger period (Admin)
+// @Description Posts any missing payments, discounts, refunds and settlements, then closes a month that has ended. A closed month can be exported and never changes: later postings dated in it are booked on the day they are made. Requires ledger:manage. Audited.
+// @Tags Admin
+// @Produce json
+// @Param period path string true "Month, YYYY-MM"
+// @Success 200 {object} models.LedgerPeriod
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ledger/periods/{period}/close [post]
+// @Security BearerAuth
+func AdminCloseLedgerPeriod(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	period, appErr := ledgerPeriodParam(r)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	closed, err := services.ClosePeriod(ctx, period, adminID)
+	switch {
+	case errors.Is(err, services.ErrPeriodNotEnded):
+		response.AppErr(w, apperror.ValidationError("period", "Only months that have ended can be closed"))
+		return
+	case errors.Is(err, services.ErrPeriodClosed):
+		response.AppErr(w, apperror.ValidationError("period", "This period is already closed"))
+		return
+	case err != nil:
+		logger.Error("Failed to close ledger period", withRequestID(r, zap.String("period", period), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("close ledger period", err))
+		return
+	}
+
+	audit.Log(r.Context(), "ledger.close_period", adminID, period, "ledger_period", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"transactions": closed.Transactions,
+	})
+
+	response.JSON(w, http.StatusOK, closed, "Ledger period closed")
+}
+
+// AdminExportLedgerPeriod godoc
+// @Summary Export a closed ledger period (Admin)
+// @Description Downloads a closed month's journals as Tally XML (journal vouchers) or a Zoho Books manual journal CSV. Account names come from LEDGER_ACCOUNTS and must match the accounting system. Requires ledger:manage. Audited.
+// @Tags Admin
+// @Produce application/xml
+// @Produce text/csv
+// @Param period path string true "Month, YYYY-MM"
+// @Param format query string true "tally or zoho"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ledger/periods/{period}/export [get]
+// @Security BearerAuth
+func AdminExportLedgerPeriod(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	period, appErr := ledgerPeriodParam(r)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if !services.IsLedgerFormat(format) {
+		response.AppErr(w, apperror.ValidationError("format", "format must be tally or zoho"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	ledger, err := services.LoadLedgerExport(ctx, period)
+	if errors.Is(err, services.ErrPeriodOpen) {
+		response.AppErr(w, apperror.ValidationError("period", "Close the period before exporting it"))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to load ledger period", withRequestID(r, zap.String("period", period), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("export ledger period", err))
+		return
+	}
+
+	// Render first so a failure is still reported as JSON
+	var body bytes.Buffer
+	if err := ledger.Write(&body, format); err != nil {
+		logger.Error("Failed to render ledger export", withRequestID(r, zap.String("period", period), zap.Error(err))...)
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "ledger.export", adminID, period, "ledger_period", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"format":       format,
+		"transactions": ledger.Transactions(),
+	})
+
+	w.Header().Set("Content-Type", ledger.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+ledger.Filename(format)+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := body.WriteTo(w); err != nil {
+		logger.Warn("Ledger export interrupted", withRequestID(r, zap.String("period", period), zap.Error(err))...)
+	}
+}
+
+// AdminUploadSettlementReport godoc
+// @Summary Upload a Razorpay settlement report (Admin)
+// @Description Imports a Razorpay settlement recon CSV (multipart field "file", at most 10 MB). Each payment and refund is matched to its booking by Razorpay ID and amount; matched lines post the gateway fee and payout to the ledger, unmatched and mismatched lines are kept for review. Rows already imported are skipped. Requires ledger:manage. Audited.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Settlement recon report (CSV)"
+// @Success 201 {object} models.SettlementUpload
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ledger/settlements [post]
+// @Security BearerAuth
+func AdminUploadSettlementReport(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSettlementReportBytes+1<<20)
+	if err := r.ParseMultipartForm(maxSettlementReportBytes); err != nil {
+		response.AppErr(w, apperror.ValidationError("file", "Upload the report as multipart form field file, at most 10 MB"))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("file", "Upload the report as multipart form field file, at most 10 MB"))
+		return
+	}
+	defer file.Close()
+	if header.Size > maxSettlementReportBytes {
+		response.AppErr(w, apperror.ValidationError("file", "Upload the report as multipart form field file, at most 10 MB"))
+		return
+	}
+	filename := filepath.Base(header.Filename)
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbTransactionTimeout)
+	defer cancel()
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	upload, err := services.ImportSettlementReport(ctx, filename, adminID, file)
+	var reportErr *services.SettlementReportError
+	switch {
+	case errors.As(err, &reportErr):
+		response.AppErr(w, apperror.ValidationError("file", "Invalid settlement report: "+reportErr.Error()))
+		return
+	case err != nil:
+		logger.Error("Failed to import settlement report", withRequestID(r, zap.String("filename", filename), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("import settlement report", err))
+		return
+	}
+
+	audit.Log(r.Context(), "ledger.settlement_upload", adminID, upload.ID, "settlement_upload", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"filename":   filename,
+		"rows":       upload.Rows,
+		"matched":    upload.Matched,
+		"mismatched": upload.Mismatched,
+		"unmatched":  upload.Unmatched,
+		"duplicates": upload.Duplicates,
+	})
+
+	response.JSON(w, http.StatusCreated, upload, "Settlement report imported")
+}
+
+// AdminListSettlementUploads godoc
+// @Summary List settlement report uploads (Admin)
+// @Description Returns imported settlement reports, newest first, with how their rows matched. Requires ledger:manage.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Items per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "created_at or -created_at (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ledger/settlements [get]
+// @Security BearerAuth
+func AdminListSettlementUploads(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), services.SettlementUploadPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	page, err := services.ListSettlementUploads(ctx, p)
+	if err != nil {
+		logger.Error("Failed to fetch settlement uploads", withRequestID(r, zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch settlement uploads", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, page, "Settlement uploads fetched")
+}
+
+// AdminGetSettlementUpload godoc
+// @Summary Get a settlement report upload (Admin)
+// @Description Returns an imported settlement report with its lines, optionally only those with one match status. Requires ledger:manage.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param status query string false "matched, amount_mismatch, unmatched or ignored"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/ledger/settlements/{id} [get]
+// @Security BearerAuth
+func AdminGetSettlementUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Upload ID must be a UUID"))
+		return
+	}
+	uploadID := id.String()
+
+	status := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))
+	switch status {
+	case "", services.SettlementMatched, services.SettlementAmountMismatch, services.SettlementUnmatched, services.SettlementIgnored:
+	default:
+		response.AppErr(w, apperror.ValidationError("status", "Invalid status filter"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	upload, lines, err := services.GetSettlementUpload(ctx, uploadID, status)
+	switch {
+	case errors.Is(err, services.ErrSettlementUploadNotFound):
+		response.AppErr(w, apperror.ResourceNotFound("settlement upload", uploadID))
+		return
+	case err != nil:
+		logger.Error("Failed to fetch settlement upload", withRequestID(r, zap.String("upload_id", uploadID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch settlement upload", err))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"upload": upload,
+		"lines":  lines,
+	}, "Settlement upload fetched")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+// LocalePreferenceRequest is the body accepted by UpdateLocalePreference.
+// An empty locale clears the saved preference.
+type LocalePreferenceRequest struct {
+	Locale string `json:"locale"`
+}
+
+// GetLocalePreference godoc
+// @Summary Get language preference
+// @Description Returns the caller's saved language ("" when none) together with the locale negotiated for this request and the supported locales.
+// @Tags Users
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me/locale [get]
+// @Security BearerAuth
+func GetLocalePreference(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{
+		"preferred": services.PreferredLocale(r.Context(), userID),
+		"current":   i18n.FromContext(r.Context()),
+		"supported": i18n.Supported(),
+	}, "")
+}
+
+// UpdateLocalePreference godoc
+// @Summary Set language preference
+// @Description Saves the language used for API messages and emails. Send an empty locale to fall back to Accept-Language.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body LocalePreferenceRequest true "Preferred locale (en, hi, bn)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me/locale [put]
+// @Security BearerAuth
+func UpdateLocalePreference(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+
+	var req LocalePreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	locale := ""
+	if req.Locale != "" {
+		normalized, ok := i18n.Normalize(req.Locale)
+		if !ok {
+			response.AppErr(w, apperror.ValidationError("locale", "Unsupported locale"))
+			return
+		}
+		locale = normalized
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	if err := services.SetPreferredLocale(ctx, userID, locale); err != nil {
+		logger.Log.Error("Failed to save locale preference", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("save locale preference", err))
+		return
+	}
+
+	// Answer in the language just chosen
+	if locale != "" {
+		w.Header().Set("Content-Language", locale)
+	}
+	response.JSON(w, http.StatusOK, map[string]string{"preferred": locale}, "Language preference updated")
+}
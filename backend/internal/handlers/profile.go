@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/Himadryy/hidden-depths-backend/pkg/validator"
+	"go.uber.org/zap"
+)
+
+const maxDisplayNameLength = 100
+
+// phonePattern accepts international numbers once spaces, dashes, dots and
+// brackets are removed.
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+// ProfileUpdateRequest is the body accepted by UpdateMyProfile. Omitted
+// fields are left unchanged; an empty string clears a field.
+type ProfileUpdateRequest struct {
+	DisplayName             *string                         `json:"display_name,omitempty"`
+	Phone                   *string                         `json:"phone,omitempty"`
+	Timezone                *string                         `json:"timezone,omitempty"`
+	Locale                  *string                         `json:"locale,omitempty"`
+	NotificationPreferences *models.NotificationPreferences `json:"notification_preferences,omitempty"`
+}
+
+// GetMyProfile godoc
+// @Summary Get my profile
+// @Description Returns the caller's display name, phone, timezone, language and notification preferences.
+// @Tags Users
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me [get]
+// @Security BearerAuth
+func GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	email, _ := r.Context().Value("user_email").(string)
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	profile, err := services.GetUserProfile(ctx, userID, email)
+	if err != nil {
+		logger.Error("Failed to load profile", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("fetch profile", err))
+		return
+	}
+	response.JSON(w, http.StatusOK, profile, "Profile fetched")
+}
+
+// UpdateMyProfile godoc
+// @Summary Update my profile
+// @Description Updates the fields present in the body. Timezones are IANA names (e.g. Asia/Kolkata); locale is en, hi or bn.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body ProfileUpdateRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /me [patch]
+// @Security BearerAuth
+func UpdateMyProfile(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	email, _ := r.Context().Value("user_email").(string)
+
+	var req ProfileUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	update, changed, appErr := validateProfileUpdate(req)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	profile, err := services.UpdateUserProfile(ctx, userID, email, update)
+	if errors.Is(err, services.ErrProfileNotFound) {
+		response.AppErr(w, apperror.ResourceNotFound("profile", userID))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to update profile", withRequestID(r, zap.String("user_id", userID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("update profile", err))
+		return
+	}
+
+	audit.Log(r.Context(), "profile.update", userID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"fields": changed,
+	})
+
+	// Answer in the language just chosen
+	if profile.Locale != "" {
+		w.Header().Set("Content-Language", profile.Locale)
+	}
+	response.JSON(w, http.StatusOK, profile, "Profile updated")
+}
+
+// validateProfileUpdate checks and normalises a profile update, returning the
+// names of the fields it changes.
+func validateProfileUpdate(req ProfileUpdateRequest) (services.ProfileUpdate, []string, *apperror.AppError) {
+	var update services.ProfileUpdate
+	changed := []string{}
+
+	if req.DisplayName != nil {
+		name := validator.SanitizeString(*req.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return update, nil, apperror.ValidationError("display_name", "Name must be less than 100 characters")
+		}
+		update.DisplayName = &name
+		changed = append(changed, "display_name")
+	}
+	if req.Phone != nil {
+		phone, ok := normalizePhone(*req.Phone)
+		if !ok {
+			return update, nil, apperror.ValidationError("phone", "Invalid phone number")
+		}
+		update.Phone = &phone
+		changed = append(changed, "phone")
+	}
+	if req.Timezone != nil {
+		tz := strings.TrimSpace(*req.Timezone)
+		if tz != "" && !services.ValidTimezone(tz) {
+			return update, nil, apperror.ValidationError("timezone", "Unknown timezone")
+		}
+		update.Timezone = &tz
+		changed = append(changed, "timezone")
+	}
+	if req.Locale != nil {
+		locale := ""
+		if *req.Locale != "" {
+			normalized, ok := i18n.Normalize(*req.Locale)
+			if !ok {
+				return update, nil, apperror.ValidationError("locale", "Unsupported locale")
+			}
+			locale = normalized
+		}
+		update.Locale = &locale
+		changed = append(changed, "locale")
+	}
+	if req.NotificationPreferences != nil {
+		update.NotificationPreferences = req.NotificationPreferences
+		changed = append(changed, "notification_preferences")
+	}
+
+	if len(changed) == 0 {
+		return update, nil, apperror.ValidationError("profile", "No profile fields to update")
+	}
+	return update, changed, nil
+}
+
+// normalizePhone strips formatting from a phone number; "" clears it.
+func normalizePhone(raw string) (string, bool) {
+	phone := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+	if phone == "" {
+		return "", true
+	}
+	return phone, phonePattern.MatchString(phone)
+}
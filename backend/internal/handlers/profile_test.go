@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"+91 98765-43210", "+919876543210", true},
+		{"(033) 2345.6789", "03323456789", true},
+		{"  ", "", true},
+		{"12345", "", false},
+		{"+91 98765 4321x", "", false},
+	}
+	for _, tc := range tests {
+		got, ok := normalizePhone(tc.raw)
+		if ok != tc.ok || (ok && got != tc.want) {
+			t.Fatalf("normalizePhone(%q) = %q, %v, want %q, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestValidateProfileUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		req     ProfileUpdateRequest
+		changed int
+		ok      bool
+	}{
+		{"empty", ProfileUpdateRequest{}, 0, false},
+		{"display name and timezone", ProfileUpdateRequest{DisplayName: str("  Asha  "), Timezone: str("Asia/Kolkata")}, 2, true},
+		{"clear timezone", ProfileUpdateRequest{Timezone: str("")}, 1, true},
+		{"unknown timezone", ProfileUpdateRequest{Timezone: str("Mars/Olympus")}, 0, false},
+		{"unsupported locale", ProfileUpdateRequest{Locale: str("fr")}, 0, false},
+		{"long display name", ProfileUpdateRequest{DisplayName: str(strings.Repeat("x", maxDisplayNameLength+1))}, 0, false},
+	}
+	for _, tc := range tests {
+		update, changed, appErr := validateProfileUpdate(tc.req)
+		if (appErr == nil) != tc.ok {
+			t.Fatalf("%s: validateProfileUpdate() error = %v, want ok=%v", tc.name, appErr, tc.ok)
+		}
+		if tc.ok && len(changed) != tc.changed {
+			t.Fatalf("%s: changed = %v, want %d fields", tc.name, changed, tc.changed)
+		}
+		if update.DisplayName != nil && *update.DisplayName != strings.TrimSpace(*tc.req.DisplayName) {
+			t.Fatalf("%s: display name = %q, want trimmed", tc.name, *update.DisplayName)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/middleware"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RoleGrantRequest is the body accepted by GrantRole.
+type RoleGrantRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// GetMyRoles godoc
+// @Summary Get the caller's admin roles (Admin)
+// @Description Returns the caller's roles and the permissions they grant, so the dashboard can hide sections the caller cannot use.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/roles/me [get]
+// @Security BearerAuth
+func GetMyRoles(w http.ResponseWriter, r *http.Request) {
+	roles := middleware.RolesFromContext(r.Context())
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"roles":       roles,
+		"permissions": rbac.Permissions(roles),
+	}, "")
+}
+
+// ListRoleAssignments godoc
+// @Summary List admin role assignments (Admin)
+// @Description Returns a page of admin role assignments, grouped by role unless sorted otherwise. Requires roles:manage.
+// @Tags Admin
+// @Produce json
+// @Param limit query int false "Results per page (default 50, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: role or created_at (default role)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/roles [get]
+// @Security BearerAuth
+func ListRoleAssignments(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), services.RoleListPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	page, err := services.ListUserRoles(r.Context(), p)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("list role assignments", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, page, "Role assignments retrieved")
+}
+
+// GrantRole godoc
+// @Summary Grant an admin role (Admin)
+// @Description Assigns a role (owner, mentor, support, content-editor) to a user. Requires roles:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body RoleGrantRequest true "User and role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/roles [post]
+// @Security BearerAuth
+func GrantRole(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	var req RoleGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	userID, role, appErr := parseRoleAssignment(req.UserID, req.Role)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	created, err := services.GrantRole(r.Context(), userID, req.Email, role, adminID)
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("grant role", err))
+		return
+	}
+
+	if created {
+		audit.Log(r.Context(), "role.grant", adminID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+			"role":  role,
+			"email": services.NormalizeEmailAddress(req.Email),
+		})
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"role":    role,
+		"created": created,
+	}, "Role granted")
+}
+
+// RevokeRole godoc
+// @Summary Revoke an admin role (Admin)
+// @Description Removes a role from a user. The last owner cannot be revoked. Requires roles:manage. Audited.
+// @Tags Admin
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param role path string true "Role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/roles/{userID}/{role} [delete]
+// @Security BearerAuth
+func RevokeRole(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, role, appErr := parseRoleAssignment(chi.URLParam(r, "userID"), chi.URLParam(r, "role"))
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	removed, err := services.RevokeRole(r.Context(), userID, role)
+	if errors.Is(err, services.ErrLastOwner) {
+		response.AppErr(w, apperror.ValidationError("role", "Cannot remove the last owner"))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("revoke role", err))
+		return
+	}
+	if !removed {
+		response.AppErr(w, apperror.ResourceNotFound("role assignment", userID+"/"+string(role)))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "role.revoke", adminID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"role": role,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"role":    role,
+	}, "Role revoked")
+}
+
+// parseRoleAssignment validates a user ID and role name from a request.
+func parseRoleAssignment(rawUserID, rawRole string) (string, rbac.Role, *apperror.AppError) {
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return "", "", apperror.ValidationError("user_id", "Invalid user ID")
+	}
+	role := rbac.Role(rawRole)
+	if !rbac.IsValid(role) {
+		return "", "", apperror.ValidationError("role", "Unknown role")
+	}
+	return userID.String(), role, nil
+}
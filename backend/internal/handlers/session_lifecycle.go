@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/internal/ws"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Session statuses (bookings.session_status). They track whether a paid
+// session happened, independently of payment_status. "" (NULL) means the
+// booking has no session: it was never paid, or was cancelled in good time.
+const (
+	sessionStatusNone          = ""
+	sessionStatusScheduled     = "scheduled"
+	sessionStatusInProgress    = "in_progress"
+	sessionStatusCompleted     = "completed"
+	sessionStatusNoShowClient  = "no_show_client"
+	sessionStatusNoShowMentor  = "no_show_mentor"
+	sessionStatusLateCancelled = "late_cancelled"
+)
+
+// sessionJoinLead is how early before the start a session can be joined.
+const sessionJoinLead = 15 * time.Minute
+
+// sessionStatuses lists every status in lifecycle order.
+var sessionStatuses = []string{
+	sessionStatusNone,
+	sessionStatusScheduled,
+	sessionStatusInProgress,
+	sessionStatusCompleted,
+	sessionStatusNoShowClient,
+	sessionStatusNoShowMentor,
+	sessionStatusLateCancelled,
+}
+
+// sessionTransitions lists the statuses each status may move to. Outcomes
+// (completed and the no-shows) can be corrected between each other by an
+// admin; late_cancelled is final.
+var sessionTransitions = map[string][]string{
+	sessionStatusNone:         {sessionStatusScheduled},
+	sessionStatusScheduled:    {sessionStatusInProgress, sessionStatusCompleted, sessionStatusNoShowClient, sessionStatusNoShowMentor, sessionStatusLateCancelled, sessionStatusNone},
+	sessionStatusInProgress:   {sessionStatusCompleted, sessionStatusNoShowClient, sessionStatusNoShowMentor, sessionStatusLateCancelled},
+	sessionStatusCompleted:    {sessionStatusNoShowClient, sessionStatusNoShowMentor},
+	sessionStatusNoShowClient: {sessionStatusCompleted, sessionStatusNoShowMentor},
+	sessionStatusNoShowMentor: {sessionStatusCompleted, sessionStatusNoShowClient},
+}
+
+func canTransitionSession(from, to string) bool {
+	for _, next := range sessionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionStatusSources returns the statuses that may move to to.
+func sessionStatusSources(to string) []string {
+	var sources []string
+	for _, from := range sessionStatuses {
+		if canTransitionSession(from, to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// SessionTransitionError reports a session status change the lifecycle
+// does not allow.
+type SessionTransitionError struct {
+	From, To string
+}
+
+func (e *SessionTransitionError) Error() string {
+	from := e.From
+	if from == sessionStatusNone {
+		from = "none"
+	}
+	return fmt.Sprintf("session cannot move from %s to %s", from, e.To)
+}
+
+// transitionSessionStatus moves a paid booking's session to to, stamping
+// session_status_at plus the start or end time it implies, and returns the
+// previous status. It returns pgx.ErrNoRows for an unknown or unpaid booking
+// and a *SessionTransitionError if the current status does not allow it.
+func transitionSessionStatus(ctx context.Context, bookingID, to string) (string, error) {
+	var from string
+	err := database.Pool.QueryRow(ctx,
+		`WITH target AS (
+			SELECT id, COALESCE(session_status, '') AS status
+			FROM bookings
+			WHERE id = $1 AND payment_status = $4
+			FOR UPDATE
+		)
+		UPDATE bookings b
+		SET session_status = NULLIF($2, ''),
+		    session_status_at = NOW(),
+		    session_started_at = CASE WHEN $2 = $5 THEN COALESCE(b.session_started_at, NOW()) ELSE b.session_started_at END,
+		    session_ended_at = CASE WHEN $2 = ANY($6) THEN COALESCE(b.session_ended_at, NOW()) ELSE b.session_ended_at END
+		FROM target
+		WHERE b.id = target.id AND target.status = ANY($3)
+		RETURNING target.status`,
+		bookingID, to, sessionStatusSources(to), paymentStatusPaid, sessionStatusInProgress,
+		[]string{sessionStatusCompleted, sessionStatusNoShowClient, sessionStatusNoShowMentor},
+	).Scan(&from)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return from, err
+	}
+
+	// Nothing changed: tell an unknown booking from a disallowed transition
+	err = database.Pool.QueryRow(ctx,
+		`SELECT COALESCE(session_status, '') FROM bookings WHERE id = $1 AND payment_status = $2`,
+		bookingID, paymentStatusPaid,
+	).Scan(&from)
+	if err != nil {
+		return "", err
+	}
+	return from, &SessionTransitionError{From: from, To: to}
+}
+
+// sessionStart returns when a session on date (YYYY-MM-DD) at slot
+// ("08:00 PM") begins, in loc.
+func sessionStart(date, slot string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 3:04 PM", date+" "+slot, loc)
+}
+
+// sessionStatusOnCancel is the session status a scheduled session gets when
+// it is cancelled at now: late_cancelled inside the window before the start,
+// none otherwise.
+func sessionStatusOnCancel(start, now time.Time, lateWindow time.Duration) string {
+	if lateWindow > 0 && !now.Before(start.Add(-lateWindow)) {
+		return sessionStatusLateCancelled
+	}
+	return sessionStatusNone
+}
+
+// CancelledSessionStatus returns the session status a booking whose session
+// is current moves to when it is cancelled at now (nil for none), or false
+// if the session already has an outcome. Unpaid bookings keep no session,
+// started ones are late, and scheduled ones are late inside the policy's
+// late-cancellation window. Unparseable slots count as on time.
+func CancelledSessionStatus(current, date, slot string, now time.Time) (*string, bool) {
+	next := sessionStatusNone
+	switch current {
+	case sessionStatusNone:
+		return nil, true
+	case sessionStatusInProgress:
+		next = sessionStatusLateCancelled
+	case sessionStatusScheduled:
+		if start, err := sessionStart(date, slot, now.Location()); err == nil {
+			next = sessionStatusOnCancel(start, now, getBookingPolicyConfig().LateCancelWindow)
+		}
+	}
+	if !canTransitionSession(current, next) {
+		return nil, false
+	}
+	return nilIfEmpty(next), true
+}
+
+// sessionJoinWindowError explains why a session cannot be joined at now, or
+// returns nil while it is open (from sessionJoinLead before the start until
+// length after it).
+func sessionJoinWindowError(start, now time.Time, length time.Duration) *apperror.AppError {
+	if now.Before(start.Add(-sessionJoinLead)) {
+		return apperror.ValidationError("booking", "This session is not open to join yet")
+	}
+	if now.After(start.Add(length)) {
+		return apperror.ValidationError("booking", "This session has ended")
+	}
+	return nil
+}
+
+// JoinSession godoc
+// @Summary Record joining a session
+// @Description Records that the client opened their session and marks it in progress. Open from 15 minutes before the start until the session ends. Returns the meeting link.
+// @Tags Bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /bookings/{id}/join [post]
+// @Security BearerAuth
+func JoinSession(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	recordSessionJoin(w, r, hub, audit, "client", userID)
+}
+
+// AdminJoinSession godoc
+// @Summary Record the mentor joining a session (Admin)
+// @Description Records that the mentor opened the session and marks it in progress. Same window as clients. Requires bookings:manage.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bookings/{id}/join [post]
+// @Security BearerAuth
+func AdminJoinSession(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService) {
+	recordSessionJoin(w, r, hub, audit, "mentor", "")
+}
+
+// recordSessionJoin stamps party's join time and moves a scheduled session to
+// in_progress. ownerID restricts the booking to one user when set.
+func recordSessionJoin(w http.ResponseWriter, r *http.Request, hub *ws.Hub, audit *services.AuditService, party, ownerID string) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Booking ID must be a UUID"))
+		return
+	}
+	bookingID := id.String()
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	// Clients get the link only once any pending intake form is answered
+	var date, slot, meetingLink, status string
+	var intakePending bool
+	err = database.Pool.QueryRow(ctx,
+		`SELECT b.date, b.time, COALESCE(b.meeting_link, ''), COALESCE(b.session_status, ''),
+		        $3 <> '' AND `+services.IntakePendingCondition+`
+		 FROM bookings b
+		 WHERE b.id = $1 AND b.payment_status = $2 AND ($3 = '' OR b.user_id::text = $3)`,
+		bookingID, paymentStatusPaid, ownerID,
+	).Scan(&date, &slot, &meetingLink, &status, &intakePending)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return
+	}
+	if status != sessionStatusScheduled && status != sessionStatusInProgress {
+		response.AppErr(w, apperror.ValidationError("booking", "This session has ended"))
+		return
+	}
+	if intakePending {
+		response.AppErr(w, apperror.ValidationError("intake", "Complete the intake form before joining your session"))
+		return
+	}
+	start, err := sessionStart(date, slot, time.Local)
+	if err != nil {
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+	if appErr := sessionJoinWindowError(start, time.Now(), getBookingPolicyConfig().SessionLength); appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	joinedColumn := "client_joined_at"
+	if party == "mentor" {
+		joinedColumn = "mentor_joined_at"
+	}
+	if _, err := database.Pool.Exec(ctx,
+		`UPDATE bookings SET `+joinedColumn+` = COALESCE(`+joinedColumn+`, NOW()) WHERE id = $1`,
+		bookingID,
+	); err != nil {
+		response.AppErr(w, apperror.DatabaseError("record session join", err))
+		return
+	}
+
+	if status == sessionStatusScheduled {
+		_, err := transitionSessionStatus(ctx, bookingID, sessionStatusInProgress)
+		var transitionErr *SessionTransitionError
+		switch {
+		case err == nil:
+			status = sessionStatusInProgress
+			hub.Broadcast("BOOKING_UPDATED", map[string]string{
+				"date": date,
+				"time": slot,
+			})
+			actorID, _ := r.Context().Value("user_id").(string)
+			audit.Log(r.Context(), "booking.session_started", actorID, bookingID, "booking", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+				"joined_by": party,
+			})
+		case errors.As(err, &transitionErr):
+			// The other party joined first, or an admin recorded an outcome
+			status = transitionErr.From
+		default:
+			logger.Error("Failed to start session", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+			response.AppErr(w, apperror.DatabaseError("start session", err))
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"booking_id":     bookingID,
+		"session_status": status,
+		"meeting_link":   meetingLink,
+	}, "Session joined")
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCanTransitionSession(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{sessionStatusNone, sessionStatusScheduled, true},
+		{sessionStatusNone, sessionStatusCompleted, false},
+		{sessionStatusScheduled, sessionStatusInProgress, true},
+		{sessionStatusScheduled, sessionStatusNone, true},
+		{sessionStatusInProgress, sessionStatusScheduled, false},
+		{sessionStatusInProgress, sessionStatusNoShowMentor, true},
+		{sessionStatusNoShowClient, sessionStatusCompleted, true},
+		{sessionStatusCompleted, sessionStatusInProgress, false},
+		{sessionStatusLateCancelled, sessionStatusCompleted, false},
+	}
+	for _, tc := range tests {
+		if got := canTransitionSession(tc.from, tc.to); got != tc.want {
+			t.Fatalf("canTransitionSession(%q, %q) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestSessionStatusSources(t *testing.T) {
+	got := sessionStatusSources(sessionStatusCompleted)
+	want := []string{sessionStatusScheduled, sessionStatusInProgress, sessionStatusNoShowClient, sessionStatusNoShowMentor}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sessionStatusSources(completed) = %v, want %v", got, want)
+	}
+}
+
+func TestSessionStatusOnCancel(t *testing.T) {
+	start, err := sessionStart("2026-03-15", "08:00 PM", time.UTC)
+	if err != nil {
+		t.Fatalf("sessionStart returned error: %v", err)
+	}
+	if want := time.Date(2026, 3, 15, 20, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Fatalf("sessionStart = %v, want %v", start, want)
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		window time.Duration
+		want   string
+	}{
+		{"well ahead", start.Add(-48 * time.Hour), 24 * time.Hour, sessionStatusNone},
+		{"at the window edge", start.Add(-24 * time.Hour), 24 * time.Hour, sessionStatusLateCancelled},
+		{"inside the window", start.Add(-time.Hour), 24 * time.Hour, sessionStatusLateCancelled},
+		{"window disabled", start.Add(-time.Hour), 0, sessionStatusNone},
+	}
+	for _, tc := range tests {
+		if got := sessionStatusOnCancel(start, tc.now, tc.window); got != tc.want {
+			t.Fatalf("%s: sessionStatusOnCancel = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSessionJoinWindowError(t *testing.T) {
+	start := time.Date(2026, 3, 15, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		open bool
+	}{
+		{start.Add(-16 * time.Minute), false},
+		{start.Add(-15 * time.Minute), true},
+		{start.Add(30 * time.Minute), true},
+		{start.Add(46 * time.Minute), false},
+	}
+	for _, tc := range tests {
+		if got := sessionJoinWindowError(start, tc.now, 45*time.Minute); (got == nil) != tc.open {
+			t.Fatalf("sessionJoinWindowError at %v = %v, want open=%v", tc.now, got, tc.open)
+		}
+	}
+}
+
+func TestCancelledSessionStatus(t *testing.T) {
+	now := time.Date(2026, 3, 15, 19, 0, 0, 0, time.UTC)
+	tests := []struct {
+		current string
+		want    string
+		ok      bool
+	}{
+		{sessionStatusNone, sessionStatusNone, true},
+		{sessionStatusScheduled, sessionStatusLateCancelled, true},
+		{sessionStatusInProgress, sessionStatusLateCancelled, true},
+		{sessionStatusCompleted, sessionStatusNone, false},
+		{sessionStatusLateCancelled, sessionStatusNone, false},
+	}
+	for _, tc := range tests {
+		got, ok := CancelledSessionStatus(tc.current, "2026-03-15", "08:00 PM", now)
+		if ok != tc.ok || derefString(got) != tc.want {
+			t.Fatalf("CancelledSessionStatus(%q) = %q, %v; want %q, %v", tc.current, derefString(got), ok, tc.want, tc.ok)
+		}
+	}
+}
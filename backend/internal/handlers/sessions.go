@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ForceLogoutRequest is the optional body accepted by ForceLogoutUser.
+type ForceLogoutRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RevokeTokenRequest identifies a token to revoke, either by its jti claim or
+// by the raw token (e.g. one found leaked in a log).
+type RevokeTokenRequest struct {
+	JTI    string `json:"jti"`
+	Token  string `json:"token"`
+	Reason string `json:"reason"`
+}
+
+// ForceLogoutUser godoc
+// @Summary Force-logout a user (Admin)
+// @Description Revokes every token issued to the user before now and purges their cached sessions. Requires sessions:manage; staff other than yourself also require roles:manage, and owners cannot be targeted. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID"
+// @Param request body ForceLogoutRequest false "Reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/users/{userID}/logout [post]
+// @Security BearerAuth
+func ForceLogoutUser(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("user_id", "Invalid user ID"))
+		return
+	}
+
+	var req ForceLogoutRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.AppErr(w, apperror.InvalidPayload(err))
+			return
+		}
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	if userID.String() != adminID && !allowStaffTarget(r.Context(), w, r, userID.String()) {
+		return
+	}
+	notBefore, err := services.RevokeUserSessions(r.Context(), userID.String(), adminID, strings.TrimSpace(req.Reason))
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("revoke user sessions", err))
+		return
+	}
+
+	audit.Log(r.Context(), "session.force_logout", adminID, userID.String(), "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"not_before": notBefore,
+		"reason":     req.Reason,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":    userID.String(),
+		"not_before": notBefore,
+	}, "User logged out")
+}
+
+// PurgeUserSessions godoc
+// @Summary Purge a user's cached sessions (Admin)
+// @Description Drops cached session validations so the user's next request is re-verified (including revocation). Tokens stay valid; use /logout to revoke them. Requires sessions:manage; staff other than yourself also require roles:manage, and owners cannot be targeted. Audited.
+// @Tags Admin
+// @Produce json
+// @Param userID path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /admin/users/{userID}/sessions [delete]
+// @Security BearerAuth
+func PurgeUserSessions(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("user_id", "Invalid user ID"))
+		return
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	if userID.String() != adminID && !allowStaffTarget(r.Context(), w, r, userID.String()) {
+		return
+	}
+
+	purged, err := services.PurgeUserSessions(r.Context(), userID.String())
+	if errors.Is(err, cache.ErrCacheDisabled) {
+		// Nothing is cached without Redis; every request is already verified.
+		purged, err = 0, nil
+	}
+	if err != nil {
+		response.AppErr(w, apperror.ExternalServiceError("cache", err))
+		return
+	}
+
+	audit.Log(r.Context(), "session.purge", adminID, userID.String(), "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"purged": purged,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID.String(),
+		"purged":  purged,
+	}, "Cached sessions purged")
+}
+
+// RevokeToken godoc
+// @Summary Revoke a single access token (Admin)
+// @Description Blocks one token by jti until it expires. Pass the raw token to revoke a leaked token without decoding it first. Requires sessions:manage. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body RevokeTokenRequest true "Token or jti"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/tokens/revoke [post]
+// @Security BearerAuth
+func RevokeToken(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	var req RevokeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+
+	jti := strings.TrimSpace(req.JTI)
+	userID := ""
+	var expiresAt time.Time
+	if req.Token != "" {
+		// The signature is irrelevant here: revoking a forged token is harmless.
+		claims := jwt.MapClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(req.Token, claims); err != nil {
+			response.AppErr(w, apperror.ValidationError("token", "Token could not be decoded"))
+			return
+		}
+		jti, _ = claims["jti"].(string)
+		userID, _ = claims["sub"].(string)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+	}
+	if jti == "" {
+		response.AppErr(w, apperror.ValidationError("jti", "Token ID (jti) is required"))
+		return
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		userID = ""
+	}
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	if err := services.RevokeToken(r.Context(), jti, userID, expiresAt, adminID, strings.TrimSpace(req.Reason)); err != nil {
+		response.AppErr(w, apperror.DatabaseError("revoke token", err))
+		return
+	}
+
+	audit.Log(r.Context(), "token.revoke", adminID, userID, "user", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"jti":    jti,
+		"reason": req.Reason,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"jti":     jti,
+		"user_id": userID,
+	}, "Token revoked")
+}
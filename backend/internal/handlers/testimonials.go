@@ -0,0 +1,518 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/services"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/Himadryy/hidden-depths-backend/pkg/validator"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Testimonial moderation statuses (testimonials.status). Approved and
+// featured testimonials are published; featured ones are listed first.
+const (
+	testimonialStatusPending  = "pending"
+	testimonialStatusApproved = "approved"
+	testimonialStatusRejected = "rejected"
+	testimonialStatusFeatured = "featured"
+)
+
+const (
+	minFeedbackLength        = 10
+	maxFeedbackLength        = 2000
+	maxFeedbackNameLength    = 100
+	maxPublicTestimonials    = 50
+	anonymousTestimonialName = "Anonymous"
+)
+
+// SessionFeedbackRequest is a client's feedback on a finished session.
+// AllowPublish consents to it being shown on the site once approved.
+type SessionFeedbackRequest struct {
+	Rating       int    `json:"rating"`
+	Content      string `json:"content"`
+	IsAnonymous  bool   `json:"is_anonymous"`
+	Name         string `json:"name,omitempty"`
+	AllowPublish bool   `json:"allow_publish"`
+}
+
+// ModerateTestimonialRequest moves a testimonial to a moderation status.
+type ModerateTestimonialRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note,omitempty"`
+}
+
+// SubmitSessionFeedback godoc
+// @Summary Leave feedback on a session
+// @Description Records the client's rating and feedback for one of their confirmed sessions once it has ended. One per booking. Published only after moderation and only with allow_publish.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body SessionFeedbackRequest true "Feedback"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /bookings/{id}/feedback [post]
+// @Security BearerAuth
+func SubmitSessionFeedback(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok || userID == "" {
+		response.AppErr(w, apperror.AuthRequired())
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Booking ID must be a UUID"))
+		return
+	}
+	bookingID := id.String()
+
+	var req SessionFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	content, name, appErr := validateSessionFeedback(req)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	var date, slot, bookingName, sessionStatus string
+	err = database.Pool.QueryRow(ctx,
+		`SELECT date, time, name, COALESCE(session_status, '')
+		 FROM bookings
+		 WHERE id = $1 AND user_id = $2 AND payment_status = $3`,
+		bookingID, userID, paymentStatusPaid,
+	).Scan(&date, &slot, &bookingName, &sessionStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.BookingNotFound(bookingID))
+		return
+	}
+	if err != nil {
+		response.AppErr(w, apperror.DatabaseError("fetch booking", err))
+		return
+	}
+	if sessionStatus == sessionStatusNoShowClient || sessionStatus == sessionStatusLateCancelled {
+		response.AppErr(w, apperror.ValidationError("booking", "Feedback is only open for sessions that took place"))
+		return
+	}
+	start, err := sessionStart(date, slot, time.Local)
+	if err != nil {
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+	if !feedbackOpen(start, time.Now(), getBookingPolicyConfig().SessionLength) {
+		response.AppErr(w, apperror.ValidationError("booking", "Feedback opens once the session has ended"))
+		return
+	}
+
+	if req.IsAnonymous {
+		name = anonymousTestimonialName
+	} else if name == "" {
+		if err := services.OpenBookingPII(&bookingName, nil); err != nil {
+			logger.Error("Failed to decrypt booking", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+			response.AppErr(w, apperror.InternalError(err))
+			return
+		}
+		name = firstName(bookingName)
+	}
+
+	var t models.Testimonial
+	err = database.Pool.QueryRow(ctx,
+		`INSERT INTO testimonials (user_id, booking_id, name, content, rating, is_anonymous, allow_publish, session_date, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::date, $9)
+		 RETURNING id, status, created_at`,
+		userID, bookingID, name, content, req.Rating, req.IsAnonymous, req.AllowPublish, date, testimonialStatusPending,
+	).Scan(&t.ID, &t.Status, &t.CreatedAt)
+	if isUniqueViolation(err) {
+		response.AppErr(w, apperror.ValidationError("booking", "Feedback has already been submitted for this session"))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to save session feedback", withRequestID(r, zap.String("booking_id", bookingID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("save feedback", err))
+		return
+	}
+
+	audit.Log(r.Context(), "testimonial.submit", userID, t.ID, "testimonial", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"booking_id":    bookingID,
+		"rating":        req.Rating,
+		"allow_publish": req.AllowPublish,
+	})
+
+	payload := map[string]interface{}{
+		"id":         t.ID,
+		"booking_id": bookingID,
+		"status":     t.Status,
+		"created_at": t.CreatedAt,
+	}
+	if support := services.ScreenForCrisis(r.Context(), services.CrisisScreeningInput{
+		Source:    services.CrisisSourceFeedback,
+		UserID:    userID,
+		BookingID: bookingID,
+		Region:    crisisRegion(r),
+		Texts:     map[string]string{"content": content},
+	}); support != nil {
+		payload["crisis_support"] = support
+	}
+
+	response.JSON(w, http.StatusCreated, payload, "Thank you for your feedback")
+}
+
+// GetTestimonials godoc
+// @Summary List published testimonials
+// @Description Returns approved testimonials, featured first, newest next. Cached.
+// @Tags Testimonials
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /testimonials [get]
+func GetTestimonials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cacheKey := cache.TestimonialsKey()
+
+	if testimonials, err := cache.Get[[]models.PublicTestimonial](ctx, cacheKey); err == nil {
+		response.JSON(w, http.StatusOK, testimonials, "Testimonials fetched successfully")
+		return
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, CASE WHEN is_anonymous THEN $1 ELSE name END, content, rating, session_date::text, status = $2, created_at
+		 FROM testimonials
+		 WHERE status IN ($2, $3) AND allow_publish
+		 ORDER BY status = $2 DESC, created_at DESC
+		 LIMIT $4`,
+		anonymousTestimonialName, testimonialStatusFeatured, testimonialStatusApproved, maxPublicTestimonials,
+	)
+	if err != nil {
+		logger.Log.Error("Failed to fetch testimonials", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("fetch testimonials", err))
+		return
+	}
+	defer rows.Close()
+
+	testimonials := []models.PublicTestimonial{}
+	for rows.Next() {
+		var t models.PublicTestimonial
+		if err := rows.Scan(&t.ID, &t.Name, &t.Content, &t.Rating, &t.SessionDate, &t.Featured, &t.CreatedAt); err != nil {
+			logger.Log.Error("Failed to scan testimonial", zap.Error(err))
+			continue
+		}
+		testimonials = append(testimonials, t)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Testimonials query error", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("fetch testimonials", err))
+		return
+	}
+	_ = cache.Set(ctx, cacheKey, testimonials, cache.TestimonialsTTL)
+
+	response.JSON(w, http.StatusOK, testimonials, "Testimonials fetched successfully")
+}
+
+// InvalidateTestimonialsCache removes the cached published testimonials.
+// Call this after any moderation change.
+func InvalidateTestimonialsCache(ctx context.Context) {
+	if err := cache.Delete(ctx, cache.TestimonialsKey()); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Log.Warn("Failed to invalidate testimonials cache", zap.Error(err))
+	}
+}
+
+// adminTestimonialsPage is how the moderation list pages: newest first by
+// default.
+var adminTestimonialsPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+		"rating":     {Expr: "rating", Type: pagination.TypeInt},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.Testimonial{}),
+}
+
+// AdminListTestimonials godoc
+// @Summary List testimonials for moderation (Admin)
+// @Description Returns a page of testimonials, newest first unless sorted otherwise, optionally filtered by status. Requires testimonials:moderate.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Status filter (pending, approved, rejected, featured)"
+// @Param limit query int false "Results per page (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Param sort query string false "Sort field, prefixed with - for descending: created_at or rating (default -created_at)"
+// @Param fields query string false "Comma-separated fields to return"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/testimonials [get]
+// @Security BearerAuth
+func AdminListTestimonials(w http.ResponseWriter, r *http.Request) {
+	p, appErr := pagination.Parse(r.URL.Query(), adminTestimonialsPage)
+	if appErr != nil {
+		response.AppErr(w, appErr)
+		return
+	}
+	status := strings.TrimSpace(strings.ToLower(r.URL.Query().Get("status")))
+	if status == "all" {
+		status = ""
+	}
+	if status != "" && !isTestimonialStatus(status) {
+		response.AppErr(w, apperror.ValidationError("status", "Invalid status filter"))
+		return
+	}
+	after, args := p.Where(2)
+	args = append([]any{status}, args...)
+	args = append(args, p.Fetch())
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, user_id::text, booking_id::text, name, content, rating, is_anonymous, allow_publish,
+		        session_date::text, status, moderated_by::text, moderated_at, moderation_note, created_at,
+		        %s
+		 FROM testimonials
+		 WHERE ($1 = '' OR status = $1) AND %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		logger.Log.Error("Failed to fetch admin testimonials", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("fetch testimonials", err))
+		return
+	}
+	defer rows.Close()
+
+	testimonials := make([]models.Testimonial, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var t models.Testimonial
+		var pos pagination.Position
+		if err := rows.Scan(&t.ID, &t.UserID, &t.BookingID, &t.Name, &t.Content, &t.Rating, &t.IsAnonymous, &t.AllowPublish,
+			&t.SessionDate, &t.Status, &t.ModeratedBy, &t.ModeratedAt, &t.ModerationNote, &t.CreatedAt, &pos.Value, &pos.Key); err != nil {
+			logger.Log.Warn("Failed to scan testimonial row", zap.Error(err))
+			continue
+		}
+		testimonials = append(testimonials, t)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Log.Error("Admin testimonials query error", zap.Error(err))
+		response.AppErr(w, apperror.DatabaseError("fetch testimonials", err))
+		return
+	}
+
+	pagination.Respond(w, r, p, pagination.NewPage(p, testimonials, positions), "Testimonials fetched")
+}
+
+// AdminModerateTestimonial godoc
+// @Summary Moderate a testimonial (Admin)
+// @Description Moves a testimonial to pending, approved, rejected or featured. Only testimonials whose author allowed publishing can be approved or featured. Requires testimonials:moderate. Audited.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Testimonial ID"
+// @Param request body ModerateTestimonialRequest true "New status and optional note"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/testimonials/{id} [patch]
+// @Security BearerAuth
+func AdminModerateTestimonial(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Testimonial ID must be a UUID"))
+		return
+	}
+	testimonialID := id.String()
+
+	var req ModerateTestimonialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.AppErr(w, apperror.InvalidPayload(err))
+		return
+	}
+	status := strings.TrimSpace(strings.ToLower(req.Status))
+	if !isTestimonialStatus(status) {
+		response.AppErr(w, apperror.ValidationError("status", "status must be pending, approved, rejected or featured"))
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > maxAdminReasonLength {
+		response.AppErr(w, apperror.ValidationErrorf("note", "Note must be at most %d characters", maxAdminReasonLength))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	adminID, _ := r.Context().Value("user_id").(string)
+	approved, featured := testimonialFlags(status)
+
+	var previous string
+	var allowPublish bool
+	err = database.Pool.QueryRow(ctx,
+		`WITH target AS (
+			SELECT id, status, allow_publish FROM testimonials WHERE id = $1 FOR UPDATE
+		)
+		UPDATE testimonials t
+		SET status = $2,
+		    approved = $3,
+		    featured = $4,
+		    moderated_by = NULLIF($5, '')::uuid,
+		    moderated_at = NOW(),
+		    moderation_note = NULLIF($6, ''),
+		    updated_at = NOW()
+		FROM target
+		WHERE t.id = target.id AND (target.allow_publish OR NOT $3)
+		RETURNING target.status, target.allow_publish`,
+		testimonialID, status, approved, featured, adminID, note,
+	).Scan(&previous, &allowPublish)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tell an unknown testimonial from one its author kept private
+		err = database.Pool.QueryRow(ctx, `SELECT allow_publish FROM testimonials WHERE id = $1`, testimonialID).Scan(&allowPublish)
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.AppErr(w, apperror.ResourceNotFound("testimonial", testimonialID))
+			return
+		}
+		if err == nil {
+			response.AppErr(w, apperror.ValidationError("status", "The author did not allow this testimonial to be published"))
+			return
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to moderate testimonial", withRequestID(r, zap.String("testimonial_id", testimonialID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("moderate testimonial", err))
+		return
+	}
+
+	InvalidateTestimonialsCache(r.Context())
+	audit.Log(r.Context(), "testimonial.moderate", adminID, testimonialID, "testimonial", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"previous_status": previous,
+		"status":          status,
+		"note":            note,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"id":     testimonialID,
+		"status": status,
+	}, "Testimonial updated")
+}
+
+// AdminDeleteTestimonial godoc
+// @Summary Delete a testimonial (Admin)
+// @Description Permanently deletes a testimonial. Requires testimonials:moderate. Audited.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Testimonial ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/testimonials/{id} [delete]
+// @Security BearerAuth
+func AdminDeleteTestimonial(w http.ResponseWriter, r *http.Request, audit *services.AuditService) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.AppErr(w, apperror.ValidationError("id", "Testimonial ID must be a UUID"))
+		return
+	}
+	testimonialID := id.String()
+
+	ctx, cancel := context.WithTimeout(r.Context(), dbQueryTimeout)
+	defer cancel()
+
+	var status string
+	var bookingID *string
+	err = database.Pool.QueryRow(ctx,
+		`DELETE FROM testimonials WHERE id = $1 RETURNING status, booking_id::text`,
+		testimonialID,
+	).Scan(&status, &bookingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.AppErr(w, apperror.ResourceNotFound("testimonial", testimonialID))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to delete testimonial", withRequestID(r, zap.String("testimonial_id", testimonialID), zap.Error(err))...)
+		response.AppErr(w, apperror.DatabaseError("delete testimonial", err))
+		return
+	}
+
+	InvalidateTestimonialsCache(r.Context())
+	adminID, _ := r.Context().Value("user_id").(string)
+	audit.Log(r.Context(), "testimonial.delete", adminID, testimonialID, "testimonial", r.RemoteAddr, r.UserAgent(), map[string]interface{}{
+		"status":     status,
+		"booking_id": derefString(bookingID),
+	})
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"id": testimonialID,
+	}, "Testimonial deleted")
+}
+
+// validateSessionFeedback checks a feedback request and returns its content
+// and display name, whitespace-normalised.
+func validateSessionFeedback(req SessionFeedbackRequest) (content, name string, appErr *apperror.AppError) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return "", "", apperror.ValidationError("rating", "Rating must be between 1 and 5")
+	}
+	content = strings.TrimSpace(req.Content)
+	length := utf8.RuneCountInString(content)
+	if length < minFeedbackLength || length > maxFeedbackLength {
+		return "", "", apperror.ValidationErrorf("content", "Feedback must be between %d and %d characters", minFeedbackLength, maxFeedbackLength)
+	}
+	name = validator.SanitizeString(req.Name)
+	if utf8.RuneCountInString(name) > maxFeedbackNameLength {
+		return "", "", apperror.ValidationError("name", "Name must be less than 100 characters")
+	}
+	return content, name, nil
+}
+
+// feedbackOpen reports whether a session starting at start has ended by now.
+func feedbackOpen(start, now time.Time, length time.Duration) bool {
+	return !now.Before(start.Add(length))
+}
+
+// testimonialFlags returns the legacy approved and featured columns for status.
+func testimonialFlags(status string) (approved, featured bool) {
+	return status == testimonialStatusApproved || status == testimonialStatusFeatured, status == testimonialStatusFeatured
+}
+
+func isTestimonialStatus(status string) bool {
+	switch status {
+	case testimonialStatusPending, testimonialStatusApproved, testimonialStatusRejected, testimonialStatusFeatured:
+		return true
+	}
+	return false
+}
+
+// firstName returns the first word of a booking name, so published
+// testimonials do not carry full names by default.
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return anonymousTestimonialName
+}
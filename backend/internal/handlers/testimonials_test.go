@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSessionFeedback(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SessionFeedbackRequest
+		ok   bool
+	}{
+		{"valid", SessionFeedbackRequest{Rating: 5, Content: "  Really helpful session  "}, true},
+		{"rating too low", SessionFeedbackRequest{Rating: 0, Content: "Really helpful session"}, false},
+		{"rating too high", SessionFeedbackRequest{Rating: 6, Content: "Really helpful session"}, false},
+		{"content too short", SessionFeedbackRequest{Rating: 4, Content: "  ok  "}, false},
+		{"content too long", SessionFeedbackRequest{Rating: 4, Content: strings.Repeat("x", maxFeedbackLength+1)}, false},
+		{"name too long", SessionFeedbackRequest{Rating: 4, Content: "Really helpful session", Name: strings.Repeat("x", maxFeedbackNameLength+1)}, false},
+	}
+	for _, tc := range tests {
+		content, _, appErr := validateSessionFeedback(tc.req)
+		if (appErr == nil) != tc.ok {
+			t.Fatalf("%s: validateSessionFeedback() error = %v, want ok=%v", tc.name, appErr, tc.ok)
+		}
+		if tc.ok && content != strings.TrimSpace(tc.req.Content) {
+			t.Fatalf("%s: content = %q, want trimmed", tc.name, content)
+		}
+	}
+}
+
+func TestFeedbackOpen(t *testing.T) {
+	start := time.Date(2026, 3, 2, 20, 0, 0, 0, time.UTC)
+	length := 45 * time.Minute
+	if feedbackOpen(start, start.Add(30*time.Minute), length) {
+		t.Fatalf("feedback open during the session")
+	}
+	if !feedbackOpen(start, start.Add(length), length) {
+		t.Fatalf("feedback closed once the session ended")
+	}
+}
+
+func TestTestimonialFlags(t *testing.T) {
+	tests := []struct {
+		status             string
+		approved, featured bool
+	}{
+		{testimonialStatusPending, false, false},
+		{testimonialStatusApproved, true, false},
+		{testimonialStatusRejected, false, false},
+		{testimonialStatusFeatured, true, true},
+	}
+	for _, tc := range tests {
+		approved, featured := testimonialFlags(tc.status)
+		if approved != tc.approved || featured != tc.featured {
+			t.Fatalf("testimonialFlags(%q) = %v, %v, want %v, %v", tc.status, approved, featured, tc.approved, tc.featured)
+		}
+	}
+}
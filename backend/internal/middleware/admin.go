@@ -1,41 +1,83 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
-	"strings"
 
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
 	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
 )
 
-func AdminMiddleware(adminEmails []string) func(http.Handler) http.Handler {
+const UserRolesKey = "user_roles"
+
+// RoleLookup returns the admin roles assigned to an authenticated user.
+type RoleLookup func(ctx context.Context, userID, email string) ([]rbac.Role, error)
+
+// AdminMiddleware admits users holding at least one admin role and stores the
+// roles in the request context for RequirePermission. API key requests pass
+// through; their scopes are checked by RequirePermission instead. Must run
+// after AuthMiddleware (or UserOrAPIKey).
+func AdminMiddleware(lookup RoleLookup) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			email, ok := r.Context().Value(UserEmailKey).(string)
-			if !ok || email == "" {
-				response.AppErr(w, apperror.AuthRequired().WithContext("reason", "email missing from token"))
+			if _, _, ok := APIKeyFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			if len(adminEmails) == 0 {
-				response.AppErr(w, apperror.AdminAccessDenied().WithContext("reason", "no admins configured"))
+			userID, ok := r.Context().Value(UserIDKey).(string)
+			if !ok || userID == "" {
+				response.AppErr(w, apperror.AuthRequired().WithContext("reason", "user missing from token"))
 				return
 			}
+			email, _ := r.Context().Value(UserEmailKey).(string)
 
-			isAllowed := false
-			for _, allowed := range adminEmails {
-				if strings.TrimSpace(allowed) == email {
-					isAllowed = true
-					break
-				}
+			roles, err := lookup(r.Context(), userID, email)
+			if err != nil {
+				// Fail closed: without roles nothing admin-side is allowed.
+				logger.Log.Error("Failed to load admin roles", zap.String("user_id", userID), zap.Error(err))
+				response.AppErr(w, apperror.DatabaseError("load roles", err))
+				return
 			}
-
-			if !isAllowed {
+			if len(roles) == 0 {
 				response.AppErr(w, apperror.AdminAccessDenied())
 				return
 			}
 
+			ctx := context.WithValue(r.Context(), UserRolesKey, roles)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission rejects requests whose roles (or API key scopes) do not
+// grant perm. Must run after AdminMiddleware.
+func RequirePermission(perm rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasPermission(r.Context(), perm) {
+				response.AppErr(w, apperror.PermissionDenied(string(perm)))
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// HasPermission reports whether the request's roles (or API key scopes)
+// grant perm, for handlers whose requirements depend on the request body.
+func HasPermission(ctx context.Context, perm rbac.Permission) bool {
+	if _, scopes, ok := APIKeyFromContext(ctx); ok {
+		return rbac.HasScope(scopes, perm)
+	}
+	return rbac.Allows(RolesFromContext(ctx), perm)
+}
+
+// RolesFromContext returns the roles stored by AdminMiddleware.
+func RolesFromContext(ctx context.Context) []rbac.Role {
+	roles, _ := ctx.Value(UserRolesKey).([]rbac.Role)
+	return roles
+}
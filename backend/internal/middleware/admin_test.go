@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []rbac.Role
+		scopes []rbac.Permission
+		apiKey bool
+		want   int
+	}{
+		{"owner", []rbac.Role{rbac.RoleOwner}, nil, false, http.StatusOK},
+		{"role without permission", []rbac.Role{rbac.RoleContentEditor}, nil, false, http.StatusForbidden},
+		{"no roles", nil, nil, false, http.StatusForbidden},
+		{"api key with scope", nil, []rbac.Permission{rbac.PermBookingsRead}, true, http.StatusOK},
+		{"api key without scope", nil, []rbac.Permission{rbac.PermStatsRead}, true, http.StatusForbidden},
+		{"api key ignores roles", []rbac.Role{rbac.RoleOwner}, nil, true, http.StatusForbidden},
+	}
+
+	handler := RequirePermission(rbac.PermBookingsRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), UserRolesKey, tc.roles)
+			if tc.apiKey {
+				ctx = context.WithValue(ctx, APIKeyIDKey, "key-1")
+				ctx = context.WithValue(ctx, APIKeyScopesKey, tc.scopes)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/admin/bookings", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"go.uber.org/zap"
+)
+
+const (
+	APIKeyIDKey     = "api_key_id"
+	APIKeyScopesKey = "api_key_scopes"
+
+	// APIKeyHeader carries service API keys. It is deliberately not in the CORS
+	// allow-list: keys are for servers, not browsers.
+	APIKeyHeader = "X-API-Key"
+)
+
+// APIKeyAuthenticator resolves a presented key to its ID and scopes.
+type APIKeyAuthenticator func(ctx context.Context, key, ip string) (string, []rbac.Permission, error)
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header and
+// stores the key ID and scopes in the context. authenticate returns invalidKey
+// for unknown, expired or revoked keys; any other error is a lookup failure.
+func APIKeyMiddleware(authenticate APIKeyAuthenticator, invalidKey error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+			if key == "" {
+				response.AppErr(w, apperror.AuthRequired())
+				return
+			}
+
+			id, scopes, err := authenticate(r.Context(), key, r.RemoteAddr)
+			if errors.Is(err, invalidKey) {
+				response.AppErr(w, apperror.APIKeyInvalid())
+				return
+			}
+			if err != nil {
+				logger.Log.Error("API key lookup failed", zap.Error(err))
+				response.AppErr(w, apperror.DatabaseError("authenticate api key", err))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), APIKeyIDKey, id)
+			ctx = context.WithValue(ctx, APIKeyScopesKey, scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserOrAPIKey dispatches to apiKeyAuth when the request carries an API key
+// and to userAuth (AuthMiddleware) otherwise.
+func UserOrAPIKey(userAuth, apiKeyAuth func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		viaUser := userAuth(next)
+		viaKey := apiKeyAuth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(APIKeyHeader) != "" {
+				viaKey.ServeHTTP(w, r)
+				return
+			}
+			viaUser.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIKeyFromContext returns the authenticated API key ID and scopes, if any.
+func APIKeyFromContext(ctx context.Context) (string, []rbac.Permission, bool) {
+	id, ok := ctx.Value(APIKeyIDKey).(string)
+	if !ok || id == "" {
+		return "", nil, false
+	}
+	scopes, _ := ctx.Value(APIKeyScopesKey).([]rbac.Permission)
+	return id, scopes, true
+}
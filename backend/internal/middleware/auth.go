@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -23,8 +24,10 @@ const UserEmailKey = "user_email"
 
 // cachedSession stores validated session claims in Redis
 type cachedSession struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	TokenID  string `json:"jti,omitempty"`
+	IssuedAt int64  `json:"iat,omitempty"`
 }
 
 // hashToken creates a SHA256 hash of the token for cache key
@@ -33,17 +36,146 @@ func hashToken(token string) string {
 	return hex.EncodeToString(h[:])
 }
 
-func resolveSupabaseAuthURL(supabaseURL string) string {
+// AuthConfig configures AuthMiddleware.
+type AuthConfig struct {
+	JWTSecret       string // HS256 secret (legacy Supabase tokens and cmd/token)
+	SupabaseAnonKey string
+	SupabaseURL     string
+
+	// Audience is the required "aud" claim; empty skips the check.
+	Audience string
+	// Issuer is the required "iss" claim. Defaults to <SupabaseURL>/auth/v1.
+	Issuer string
+	// JWKSURL publishes the ES256/RS256 verification keys. Defaults to
+	// <SupabaseURL>/auth/v1/.well-known/jwks.json.
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+
+	// Revoked reports whether a token was revoked; nil disables the check.
+	Revoked RevocationCheck
+	// Blocked reports whether a user was blocked; nil disables the check.
+	Blocked BlockCheck
+}
+
+// RevocationCheck reports whether a token (by jti) or every token a user was
+// issued before some time has been revoked.
+type RevocationCheck func(ctx context.Context, userID, jti string, issuedAt time.Time) (bool, error)
+
+// BlockCheck reports whether an admin has blocked a user.
+type BlockCheck func(ctx context.Context, userID string) (bool, error)
+
+// asymmetricMethods are the algorithms verified against the JWKS.
+var asymmetricMethods = []string{"ES256", "RS256"}
+
+func resolveSupabaseBaseURL(supabaseURL string) string {
 	baseURL := strings.TrimRight(strings.TrimSpace(supabaseURL), "/")
 	if baseURL == "" {
 		// Backward-compatible fallback to existing project URL.
 		baseURL = "https://msriduejyxcdpvcawacj.supabase.co"
 	}
-	return baseURL + "/auth/v1/user"
+	return baseURL
+}
+
+func resolveSupabaseAuthURL(supabaseURL string) string {
+	return resolveSupabaseBaseURL(supabaseURL) + "/auth/v1/user"
+}
+
+// claimsOptions returns the registered-claim checks shared by HS256 and JWKS
+// verification: exp is mandatory, aud and iss when configured.
+func claimsOptions(audience, issuer string) []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	return opts
+}
+
+// verifyLocal checks a token's signature and claims without a network call
+// (except for JWKS refreshes). HS256 tokens use the shared secret; ES256 and
+// RS256 tokens use the cached JWKS.
+func verifyLocal(tokenString, jwtSecret string, jwks *JWKS, opts []jwt.ParserOption) (jwt.MapClaims, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	var token *jwt.Token
+	switch unverified.Method.Alg() {
+	case "HS256":
+		token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtSecret), nil
+		}, append(opts, jwt.WithValidMethods([]string{"HS256"}))...)
+	case "ES256", "RS256":
+		token, err = jwt.Parse(tokenString, jwks.Keyfunc, append(opts, jwt.WithValidMethods(asymmetricMethods))...)
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", unverified.Header["alg"])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
 }
 
-func AuthMiddleware(jwtSecret, supabaseAnonKey, supabaseURL string) func(http.Handler) http.Handler {
-	supabaseAuthURL := resolveSupabaseAuthURL(supabaseURL)
+// sessionFromClaims extracts the fields AuthMiddleware caches and checks.
+func sessionFromClaims(claims jwt.MapClaims) cachedSession {
+	session := cachedSession{}
+	session.UserID, _ = claims["sub"].(string)
+	session.Email, _ = claims["email"].(string)
+	session.TokenID, _ = claims["jti"].(string)
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		session.IssuedAt = iat.Unix()
+	}
+	return session
+}
+
+// sessionTTL caps the session cache lifetime at the token's expiry so a cached
+// session never outlives its token.
+func sessionTTL(claims jwt.MapClaims) time.Duration {
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return cache.SessionTTL
+	}
+	if remaining := time.Until(exp.Time); remaining < cache.SessionTTL {
+		return remaining
+	}
+	return cache.SessionTTL
+}
+
+// AuthMiddleware authenticates Bearer tokens. Tokens are verified locally
+// (HS256 secret or JWKS); the Supabase /auth/v1/user endpoint is only called
+// when the JWKS cannot be loaded at all.
+func AuthMiddleware(cfg AuthConfig) func(http.Handler) http.Handler {
+	baseURL := resolveSupabaseBaseURL(cfg.SupabaseURL)
+	supabaseAuthURL := resolveSupabaseAuthURL(cfg.SupabaseURL)
+
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = baseURL + "/auth/v1"
+	}
+	jwksURL := cfg.JWKSURL
+	if jwksURL == "" {
+		jwksURL = baseURL + "/auth/v1/.well-known/jwks.json"
+	}
+	refreshInterval := cfg.JWKSRefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = 10 * time.Minute
+	}
+
+	jwks := NewJWKS(jwksURL, refreshInterval)
+	jwks.Warm()
+	opts := claimsOptions(cfg.Audience, issuer)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,75 +196,64 @@ func AuthMiddleware(jwtSecret, supabaseAnonKey, supabaseURL string) func(http.Ha
 			cacheKey := cache.SessionKey(tokenHash)
 			ctx := r.Context()
 
-			var userID, email string
+			var session cachedSession
 
 			// 0. Try session cache first (fastest path)
-			if session, err := cache.Get[cachedSession](ctx, cacheKey); err == nil {
-				userID = session.UserID
-				email = session.Email
-				logger.Log.Debug("Session cache hit", zap.String("user_id", userID))
+			if cached, err := cache.Get[cachedSession](ctx, cacheKey); err == nil {
+				session = cached
+				logger.Log.Debug("Session cache hit", zap.String("user_id", session.UserID))
 			} else {
-				// 1. Try Local HMAC Verification (Fast)
-				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-						return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-					}
-					return []byte(jwtSecret), nil
-				})
-
-				if err == nil && token.Valid {
-					if claims, ok := token.Claims.(jwt.MapClaims); ok {
-						if sub, ok := claims["sub"].(string); ok {
-							userID = sub
-							email, _ = claims["email"].(string)
-						}
-					}
-				}
+				ttl := cache.SessionTTL
 
-				// 2. Fallback: Remote Supabase Verification (Slow but supports ES256/Google)
-				if userID == "" {
-					verifyCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-					defer cancel()
+				// 1. Local verification: HS256 secret or cached JWKS
+				claims, err := verifyLocal(tokenString, cfg.JWTSecret, jwks, opts)
+				switch {
+				case err == nil:
+					ttl = sessionTTL(claims)
 
-					req, err := http.NewRequestWithContext(verifyCtx, "GET", supabaseAuthURL, nil)
+				case errors.Is(err, ErrJWKSUnavailable):
+					// 2. Fallback: remote Supabase verification, only while no signing keys are available
+					logger.Warn("JWKS unavailable, verifying token remotely", zap.Error(err))
+					var userID, email string
+					userID, email, err = verifyRemote(ctx, supabaseAuthURL, cfg.SupabaseAnonKey, tokenString)
 					if err != nil {
-						logger.Error("Failed to create Supabase auth request", zap.Error(err))
 						response.AppErr(w, apperror.AuthTokenInvalid(err))
 						return
 					}
-					req.Header.Set("Authorization", "Bearer "+tokenString)
-					req.Header.Set("apikey", supabaseAnonKey)
+					// Supabase vouched for the token; read jti/iat for the revocation check.
+					claims = jwt.MapClaims{}
+					_, _, _ = jwt.NewParser().ParseUnverified(tokenString, claims)
+					claims["sub"], claims["email"] = userID, email
 
-					client := &http.Client{Timeout: 5 * time.Second}
-					resp, err := client.Do(req)
-					if err != nil {
-						logger.Error("Supabase auth request failed", zap.Error(err))
-						response.AppErr(w, apperror.AuthTokenInvalid(err))
-						return
-					}
-					defer resp.Body.Close()
+				default:
+					response.AppErr(w, apperror.AuthTokenInvalid(err))
+					return
+				}
 
-					if resp.StatusCode != 200 {
-						response.AppErr(w, apperror.AuthTokenInvalid(fmt.Errorf("supabase returned %d", resp.StatusCode)))
-						return
-					}
+				session = sessionFromClaims(claims)
 
-					var userResp struct {
-						ID    string `json:"id"`
-						Email string `json:"email"`
-					}
-					if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
-						logger.Error("Failed to decode Supabase auth response", zap.Error(err))
-						response.AppErr(w, apperror.AuthTokenInvalid(err))
-						return
-					}
-					userID = userResp.ID
-					email = userResp.Email
+				// Cache the validated session for future requests, indexed by user so it can be purged
+				if session.UserID != "" && ttl > 0 {
+					_ = cache.Set(ctx, cacheKey, session, ttl)
+					_ = cache.SetAdd(ctx, cache.UserSessionsKey(session.UserID), cache.SessionTTL, tokenHash)
 				}
+			}
+			userID, email := session.UserID, session.Email
 
-				// Cache the validated session for future requests
-				if userID != "" {
-					_ = cache.Set(ctx, cacheKey, cachedSession{UserID: userID, Email: email}, cache.SessionTTL)
+			// 3. Revocation: checked on cached and freshly verified sessions alike
+			if cfg.Revoked != nil && userID != "" {
+				var issuedAt time.Time
+				if session.IssuedAt > 0 {
+					issuedAt = time.Unix(session.IssuedAt, 0)
+				}
+				revoked, err := cfg.Revoked(ctx, userID, session.TokenID, issuedAt)
+				if err != nil {
+					logger.Warn("Token revocation check failed; allowing request", zap.String("user_id", userID), zap.Error(err))
+				}
+				if revoked {
+					_ = cache.Delete(ctx, cacheKey)
+					response.AppErr(w, apperror.AuthTokenRevoked())
+					return
 				}
 			}
 
@@ -141,6 +262,18 @@ func AuthMiddleware(jwtSecret, supabaseAnonKey, supabaseURL string) func(http.Ha
 				return
 			}
 
+			// 4. Blocked accounts: the token is valid but the user may not use it
+			if cfg.Blocked != nil {
+				blocked, err := cfg.Blocked(ctx, userID)
+				if err != nil {
+					logger.Warn("User block check failed; allowing request", zap.String("user_id", userID), zap.Error(err))
+				}
+				if blocked {
+					response.AppErr(w, apperror.AccountBlocked())
+					return
+				}
+			}
+
 			// Add User ID and Email to context
 			ctx = context.WithValue(ctx, UserIDKey, userID)
 			ctx = context.WithValue(ctx, UserEmailKey, email)
@@ -148,3 +281,39 @@ func AuthMiddleware(jwtSecret, supabaseAnonKey, supabaseURL string) func(http.Ha
 		})
 	}
 }
+
+// verifyRemote asks Supabase to validate the token and returns its user.
+func verifyRemote(ctx context.Context, supabaseAuthURL, supabaseAnonKey, tokenString string) (string, string, error) {
+	verifyCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(verifyCtx, "GET", supabaseAuthURL, nil)
+	if err != nil {
+		logger.Error("Failed to create Supabase auth request", zap.Error(err))
+		return "", "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	req.Header.Set("apikey", supabaseAnonKey)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error("Supabase auth request failed", zap.Error(err))
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", "", fmt.Errorf("supabase returned %d", resp.StatusCode)
+	}
+
+	var userResp struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
+		logger.Error("Failed to decode Supabase auth response", zap.Error(err))
+		return "", "", err
+	}
+	return userResp.ID, userResp.Email, nil
+}
@@ -1,6 +1,12 @@
 package middleware
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/golang-jwt/jwt/v5"
+)
 
 func TestResolveSupabaseAuthURL(t *testing.T) {
 	tests := []struct {
@@ -39,3 +45,48 @@ func TestResolveSupabaseAuthURL(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionFromClaims(t *testing.T) {
+	issued := time.Now().Add(-time.Minute).Truncate(time.Second)
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   cachedSession
+	}{
+		{
+			name: "all claims",
+			claims: jwt.MapClaims{
+				"sub":   "user-1",
+				"email": "user@example.com",
+				"jti":   "token-1",
+				"iat":   float64(issued.Unix()),
+			},
+			want: cachedSession{UserID: "user-1", Email: "user@example.com", TokenID: "token-1", IssuedAt: issued.Unix()},
+		},
+		{
+			name:   "missing jti and iat",
+			claims: jwt.MapClaims{"sub": "user-2"},
+			want:   cachedSession{UserID: "user-2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sessionFromClaims(tc.claims); got != tc.want {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSessionTTL(t *testing.T) {
+	soon := jwt.MapClaims{"exp": float64(time.Now().Add(2 * time.Minute).Unix())}
+	if ttl := sessionTTL(soon); ttl > 2*time.Minute || ttl < time.Minute {
+		t.Fatalf("expected TTL capped near token expiry, got %v", ttl)
+	}
+
+	later := jwt.MapClaims{"exp": float64(time.Now().Add(time.Hour).Unix())}
+	if ttl := sessionTTL(later); ttl != cache.SessionTTL {
+		t.Fatalf("expected default session TTL, got %v", ttl)
+	}
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// jwksMinRefreshInterval rate-limits refreshes triggered by unknown key IDs,
+	// so tokens with made-up kids cannot hammer the JWKS endpoint.
+	jwksMinRefreshInterval = 30 * time.Second
+	jwksFetchTimeout       = 5 * time.Second
+)
+
+// ErrJWKSUnavailable means no signing keys could be loaded, as opposed to a
+// token that failed verification against loaded keys.
+var ErrJWKSUnavailable = errors.New("jwks unavailable")
+
+// jwk is one entry of a JSON Web Key Set (RFC 7517). Only the fields needed
+// for EC and RSA signature keys are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS caches the public keys published at a JWKS URL. Keys are refreshed in
+// the background once older than the refresh interval, and on demand when a
+// token names an unknown kid, so signing key rotation needs no restart. If a
+// refresh fails the previously loaded keys keep being used.
+type JWKS struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+
+	// fetchMu serializes fetches so concurrent callers wait for one request.
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+}
+
+// NewJWKS returns a key cache for url. Keys are fetched on first use or by Warm.
+func NewJWKS(url string, refreshInterval time.Duration) *JWKS {
+	return &JWKS{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// Keyfunc resolves the verification key for a token by its kid header.
+func (j *JWKS) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, err := j.key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodECDSA:
+		if _, ok := key.(*ecdsa.PublicKey); ok {
+			return key, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if _, ok := key.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key %q does not match algorithm %v", kid, token.Header["alg"])
+}
+
+func (j *JWKS) key(kid string) (interface{}, error) {
+	j.mu.RLock()
+	key, found := j.keys[kid]
+	loaded := j.keys != nil
+	stale := time.Since(j.fetchedAt) > j.refreshInterval
+	j.mu.RUnlock()
+
+	if found {
+		if stale {
+			go j.refreshAsync()
+		}
+		return key, nil
+	}
+
+	// Unknown kid: the keys may have rotated since the last fetch.
+	if err := j.refresh(context.Background()); err != nil && !loaded {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	if j.keys == nil {
+		return nil, ErrJWKSUnavailable
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// Warm loads the key set in the background, e.g. at startup.
+func (j *JWKS) Warm() {
+	go j.refreshAsync()
+}
+
+func (j *JWKS) refreshAsync() {
+	// Skip when a fetch is already in flight.
+	if !j.fetchMu.TryLock() {
+		return
+	}
+	defer j.fetchMu.Unlock()
+
+	if err := j.refreshLocked(context.Background()); err != nil {
+		logger.Log.Warn("JWKS background refresh failed; keeping cached keys", zap.String("url", j.url), zap.Error(err))
+	}
+}
+
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.fetchMu.Lock()
+	defer j.fetchMu.Unlock()
+	return j.refreshLocked(ctx)
+}
+
+// refreshLocked fetches the key set unless a fetch was attempted within
+// jwksMinRefreshInterval. Callers must hold fetchMu.
+func (j *JWKS) refreshLocked(ctx context.Context) error {
+	if time.Since(j.lastAttempt) < jwksMinRefreshInterval {
+		return nil
+	}
+	j.lastAttempt = time.Now()
+
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+
+	logger.Log.Debug("JWKS refreshed", zap.String("url", j.url), zap.Int("keys", len(keys)))
+	return nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]interface{}, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(fetchCtx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	return parseJWKS(set.Keys)
+}
+
+// parseJWKS converts signature keys to crypto public keys, skipping entries
+// it cannot use. An empty result is an error so a bad response never wipes
+// previously loaded keys.
+func parseJWKS(entries []jwk) (map[string]interface{}, error) {
+	keys := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		if entry.Kid == "" || (entry.Use != "" && entry.Use != "sig") {
+			continue
+		}
+		key, err := entry.publicKey()
+		if err != nil {
+			logger.Log.Warn("Skipping unusable JWKS key", zap.String("kid", entry.Kid), zap.Error(err))
+			continue
+		}
+		keys[entry.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != size {
+			return nil, errors.New("invalid x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil || len(y) != size {
+			return nil, errors.New("invalid y coordinate")
+		}
+		// Parsing the uncompressed point also rejects points not on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || n.BitLen() < 2048 {
+			return nil, errors.New("weak or malformed RSA key")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
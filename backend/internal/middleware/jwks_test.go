@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+const testIssuer = "https://example.supabase.co/auth/v1"
+
+func ecJWK(t *testing.T, kid string, key *ecdsa.PrivateKey) map[string]string {
+	t.Helper()
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"use": "sig",
+		"alg": "ES256",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signES256(t *testing.T, kid string, key *ecdsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "7f1c8f0e-4a3b-4c1e-9d61-8a7d1b2c3d4e",
+		"email": "user@example.com",
+		"aud":   "authenticated",
+		"iss":   testIssuer,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyLocal(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	current, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rotated, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	unknown, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	// The server starts with one key and adds a second after the first fetch,
+	// simulating a signing key rotation.
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []map[string]string{ecJWK(t, "current", current)}
+		if fetches.Add(1) > 1 {
+			keys = append(keys, ecJWK(t, "rotated", rotated))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL, time.Hour)
+	opts := claimsOptions("authenticated", testIssuer)
+	secret := "0123456789abcdef0123456789abcdef"
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongAud := validClaims()
+	wrongAud["aud"] = "anon"
+	wrongIss := validClaims()
+	wrongIss["iss"] = "https://attacker.example/auth/v1"
+	noExp := validClaims()
+	delete(noExp, "exp")
+
+	hsToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte(secret))
+	hsWrongSecret, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("wrong-secret-wrong-secret-wrong!"))
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"ES256 with current key", signES256(t, "current", current, validClaims()), false},
+		{"HS256 with shared secret", hsToken, false},
+		{"HS256 with wrong secret", hsWrongSecret, true},
+		{"expired", signES256(t, "current", current, expired), true},
+		{"wrong audience", signES256(t, "current", current, wrongAud), true},
+		{"wrong issuer", signES256(t, "current", current, wrongIss), true},
+		{"missing exp", signES256(t, "current", current, noExp), true},
+		{"kid signed by unknown key", signES256(t, "current", unknown, validClaims()), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifyLocal(tt.token, secret, jwks, opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected verification to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims["sub"] != validClaims()["sub"] {
+				t.Fatalf("unexpected sub %v", claims["sub"])
+			}
+		})
+	}
+
+	// A token signed with a newly published key triggers a refresh. Allow it
+	// past the refresh rate limit, as if jwksMinRefreshInterval had elapsed.
+	jwks.fetchMu.Lock()
+	jwks.lastAttempt = time.Time{}
+	jwks.fetchMu.Unlock()
+	if _, err := verifyLocal(signES256(t, "rotated", rotated, validClaims()), secret, jwks, opts); err != nil {
+		t.Fatalf("expected rotated key to be picked up, got %v", err)
+	}
+
+	// Unknown kids within the rate limit do not refetch.
+	before := fetches.Load()
+	if _, err := verifyLocal(signES256(t, "bogus", unknown, validClaims()), secret, jwks, opts); err == nil {
+		t.Fatalf("expected unknown kid to fail")
+	}
+	if fetches.Load() != before {
+		t.Fatalf("expected unknown kid to be rate limited, got %d extra fetches", fetches.Load()-before)
+	}
+}
+
+func TestVerifyLocalJWKSUnavailable(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwks := NewJWKS(server.URL, time.Hour)
+
+	_, err := verifyLocal(signES256(t, "current", key, validClaims()), "secret", jwks, claimsOptions("authenticated", testIssuer))
+	if !errors.Is(err, ErrJWKSUnavailable) {
+		t.Fatalf("expected ErrJWKSUnavailable, got %v", err)
+	}
+}
+
+func TestParseJWKSSkipsUnusableKeys(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	good := ecJWK(t, "good", key)
+
+	entries := []jwk{
+		{Kty: good["kty"], Kid: good["kid"], Use: "sig", Crv: good["crv"], X: good["x"], Y: good["y"]},
+		{Kty: "EC", Kid: "off-curve", Crv: "P-256", X: good["x"], Y: good["x"]},
+		{Kty: "EC", Kid: "encryption", Use: "enc", Crv: "P-256", X: good["x"], Y: good["y"]},
+		{Kty: "oct", Kid: "symmetric"},
+		{Kty: "RSA", Kid: "weak", N: "AQAB", E: "AQAB"},
+	}
+
+	keys, err := parseJWKS(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys["good"] == nil {
+		t.Fatalf("expected only the good key, got %v", keys)
+	}
+
+	if _, err := parseJWKS(entries[1:]); err == nil {
+		t.Fatalf("expected an error when no key is usable")
+	}
+}
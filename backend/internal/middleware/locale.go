@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+)
+
+// LocaleLookup returns a user's saved locale, or "" when none is stored.
+type LocaleLookup func(ctx context.Context, userID string) string
+
+// Locale negotiates the response language from the "lang" query parameter or
+// Accept-Language. The result is stored in the request context and copied into
+// the Content-Language header so the response package can localize messages.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, ok := i18n.Normalize(r.URL.Query().Get("lang"))
+		if !ok {
+			locale = i18n.Negotiate(r.Header.Get("Accept-Language"))
+		}
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, setLocale(w, r, locale))
+	})
+}
+
+// UserLocale overrides the negotiated locale with the authenticated user's
+// saved preference. An explicit "lang" query parameter still wins. Must run
+// after AuthMiddleware.
+func UserLocale(lookup LocaleLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, explicit := i18n.Normalize(r.URL.Query().Get("lang")); !explicit {
+				if userID, ok := r.Context().Value(UserIDKey).(string); ok && userID != "" {
+					if locale, ok := i18n.Normalize(lookup(r.Context(), userID)); ok {
+						r = setLocale(w, r, locale)
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setLocale(w http.ResponseWriter, r *http.Request, locale string) *http.Request {
+	w.Header().Set("Content-Language", locale)
+	return r.WithContext(i18n.WithLocale(r.Context(), locale))
+}
@@ -54,6 +54,25 @@ var (
 		},
 		[]string{"status"},
 	)
+
+	// rateLimitedTotal counts requests rejected by a rate limit policy
+	rateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limited_requests_total",
+			Help: "Total number of requests rejected by rate limiting",
+		},
+		[]string{"policy"},
+	)
+
+	// bookingFunnelStepSeconds tracks time from slot hold to each funnel step
+	bookingFunnelStepSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "booking_funnel_step_seconds",
+			Help:    "Seconds from a booking's slot hold to the first time it reached a funnel step",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		},
+		[]string{"step"},
+	)
 )
 
 // responseWriter wraps http.ResponseWriter to capture status code
@@ -117,3 +136,8 @@ func RecordBookingOperation(operation, result string) {
 func RecordPaymentOperation(status string) {
 	paymentOperationsTotal.WithLabelValues(status).Inc()
 }
+
+// ObserveFunnelStep records how long after its hold a booking reached a funnel step
+func ObserveFunnelStep(step string, sinceHold time.Duration) {
+	bookingFunnelStepSeconds.WithLabelValues(step).Observe(sinceHold.Seconds())
+}
@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,36 +16,60 @@ import (
 	"go.uber.org/zap"
 )
 
-// rateBucket tracks request counts per IP (in-memory fallback).
-type rateBucket struct {
-	count   int
-	resetAt time.Time
+// RateLimitPolicy allows Limit requests per Period per client. It is a token
+// bucket (implemented as GCRA): tokens refill continuously at Limit/Period, and
+// a full bucket allows a burst of Limit requests.
+type RateLimitPolicy struct {
+	Name   string // namespaces the buckets, e.g. "global", "booking"
+	Limit  int
+	Period time.Duration
 }
 
-// RateLimiter provides per-IP rate limiting.
+// gcraScript is the Redis side of RateLimiter.take. It stores the bucket's
+// theoretical arrival time (TAT) in microseconds and uses the Redis clock so
+// every API instance agrees on "now". Returns {allowed, tat, now}.
+var gcraScript = cache.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then tat = now end
+local new_tat = tat + interval
+if new_tat - period > now then
+  return {0, tat, now}
+end
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+return {1, new_tat, now}
+`)
+
+// rateDecision is the outcome of one request against a bucket.
+type rateDecision struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration // until the bucket is full again
+	retryAfter time.Duration // until the next request is allowed (denied only)
+}
+
+// RateLimiter enforces a RateLimitPolicy per client: the authenticated user or
+// API key when known, otherwise the client IP (see RealIP).
 // Uses Redis when available, falls back to in-memory when Redis is unavailable.
 type RateLimiter struct {
-	mu      sync.Mutex
-	buckets map[string]*rateBucket
-	limit   int
-	window  time.Duration
-	name    string // identifier for this limiter (e.g., "global", "booking")
-}
+	policy   RateLimitPolicy
+	interval time.Duration // time to refill one token
 
-// NewRateLimiter creates a rate limiter: limit requests per window per IP.
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return NewNamedRateLimiter("default", limit, window)
+	mu   sync.Mutex
+	tats map[string]time.Time // in-memory fallback buckets
 }
 
-// NewNamedRateLimiter creates a rate limiter with a name for Redis key namespacing.
-func NewNamedRateLimiter(name string, limit int, window time.Duration) *RateLimiter {
+// NewRateLimiter creates a limiter for policy.
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
 	rl := &RateLimiter{
-		buckets: make(map[string]*rateBucket),
-		limit:   limit,
-		window:  window,
-		name:    name,
+		policy:   policy,
+		interval: policy.Period / time.Duration(policy.Limit),
+		tats:     make(map[string]time.Time),
 	}
-	// Cleanup stale entries every 5 minutes (for in-memory fallback)
+	// Cleanup full buckets every 5 minutes (for in-memory fallback)
 	go rl.cleanup()
 	return rl
 }
@@ -52,84 +79,131 @@ func (rl *RateLimiter) cleanup() {
 	for range ticker.C {
 		rl.mu.Lock()
 		now := time.Now()
-		for ip, bucket := range rl.buckets {
-			if now.After(bucket.resetAt) {
-				delete(rl.buckets, ip)
+		for key, tat := range rl.tats {
+			if !tat.After(now) {
+				delete(rl.tats, key)
 			}
 		}
 		rl.mu.Unlock()
 	}
 }
 
-// allowRedis checks rate limit using Redis sliding window counter.
-func (rl *RateLimiter) allowRedis(ctx context.Context, ip string) (bool, error) {
-	key := cache.RateLimitKey(ip, rl.name)
+// decide builds the decision for a request at now, given whether it was
+// allowed and the bucket's TAT afterwards.
+func (rl *RateLimiter) decide(allowed bool, tat, now time.Time) rateDecision {
+	d := rateDecision{allowed: allowed}
+	if wait := tat.Sub(now); wait > 0 {
+		d.reset = wait
+	}
+	if allowed {
+		d.remaining = int((rl.policy.Period - d.reset) / rl.interval)
+	} else {
+		d.retryAfter = tat.Add(rl.interval).Add(-rl.policy.Period).Sub(now)
+	}
+	return d
+}
 
-	// Increment counter
-	count, err := cache.Incr(ctx, key)
-	if err != nil {
-		return false, err
+// gcra applies one request at now to a bucket whose TAT is tat, returning
+// whether it is allowed and the TAT to store.
+func (rl *RateLimiter) gcra(tat, now time.Time) (bool, time.Time) {
+	if tat.Before(now) {
+		tat = now
 	}
+	next := tat.Add(rl.interval)
+	if next.Add(-rl.policy.Period).After(now) {
+		return false, tat
+	}
+	return true, next
+}
 
-	// Set expiry only on first request (count == 1)
-	if count == 1 {
-		if err := cache.Expire(ctx, key, rl.window); err != nil {
-			logger.Log.Warn("Failed to set rate limit TTL", zap.String("key", key), zap.Error(err))
-		}
+// takeRedis applies a request to the shared bucket in Redis.
+func (rl *RateLimiter) takeRedis(ctx context.Context, client string) (rateDecision, error) {
+	result, err := cache.RunScript(ctx, gcraScript, []string{cache.RateLimitKey(client, rl.policy.Name)},
+		rl.interval.Microseconds(), rl.policy.Period.Microseconds())
+	if err != nil {
+		return rateDecision{}, err
 	}
 
-	return count <= int64(rl.limit), nil
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 3 {
+		return rateDecision{}, fmt.Errorf("unexpected rate limit script result %v", result)
+	}
+	allowed, _ := values[0].(int64)
+	tat, _ := values[1].(int64)
+	now, _ := values[2].(int64)
+	return rl.decide(allowed == 1, time.UnixMicro(tat), time.UnixMicro(now)), nil
 }
 
-// allowInMemory checks rate limit using in-memory bucket (fallback).
-func (rl *RateLimiter) allowInMemory(ip string) bool {
+// takeInMemory applies a request to this instance's bucket (fallback).
+func (rl *RateLimiter) takeInMemory(client string) rateDecision {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	now := time.Now()
-	bucket, exists := rl.buckets[ip]
-
-	if !exists || now.After(bucket.resetAt) {
-		rl.buckets[ip] = &rateBucket{count: 1, resetAt: now.Add(rl.window)}
-		return true
-	}
-
-	bucket.count++
-	return bucket.count <= rl.limit
+	allowed, tat := rl.gcra(rl.tats[client], now)
+	rl.tats[client] = tat
+	return rl.decide(allowed, tat, now)
 }
 
-// Allow checks if the IP is within rate limit.
+// take checks and consumes one request for client.
 // Tries Redis first, falls back to in-memory if Redis is unavailable.
-func (rl *RateLimiter) Allow(ctx context.Context, ip string) bool {
-	// Try Redis first
+func (rl *RateLimiter) take(ctx context.Context, client string) rateDecision {
 	if cache.IsEnabled() {
-		allowed, err := rl.allowRedis(ctx, ip)
+		d, err := rl.takeRedis(ctx, client)
 		if err == nil {
-			return allowed
+			return d
 		}
 		// Redis error — fall through to in-memory
-		logger.Log.Debug("Rate limit Redis fallback", zap.String("ip", ip), zap.Error(err))
+		logger.Log.Debug("Rate limit Redis fallback", zap.String("client", client), zap.Error(err))
 	}
+	return rl.takeInMemory(client)
+}
 
-	// Fallback to in-memory
-	return rl.allowInMemory(ip)
+// Allow reports whether client is within the limit, consuming one request.
+func (rl *RateLimiter) Allow(ctx context.Context, client string) bool {
+	return rl.take(ctx, client).allowed
 }
 
-// Handler returns a middleware that enforces the rate limit.
+// Handler returns a middleware that enforces the limit and reports it in
+// RateLimit-* headers (IETF draft), plus Retry-After when rejecting.
 func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		if fwd := r.Header.Get("X-Real-Ip"); fwd != "" {
-			ip = fwd
-		} else if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
-			ip = fwd
-		}
+	policyHeader := fmt.Sprintf("%d;w=%d", rl.policy.Limit, int(rl.policy.Period.Seconds()))
 
-		if !rl.Allow(r.Context(), ip) {
-			appErr := apperror.RateLimitExceeded()
-			response.AppErr(w, appErr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := rl.take(r.Context(), rateLimitClient(r))
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", policyHeader)
+		h.Set("RateLimit-Limit", strconv.Itoa(rl.policy.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.reset)))
+
+		if !d.allowed {
+			rateLimitedTotal.WithLabelValues(rl.policy.Name).Inc()
+			h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.retryAfter)))
+			response.AppErr(w, apperror.RateLimitExceeded())
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// rateLimitClient identifies who a request counts against. Limiters mounted
+// after AuthMiddleware or APIKeyMiddleware count per principal; before that,
+// per client IP.
+func rateLimitClient(r *http.Request) string {
+	if userID, ok := r.Context().Value(UserIDKey).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	if keyID, _, ok := APIKeyFromContext(r.Context()); ok {
+		return "key:" + keyID
+	}
+	if ip, ok := parseIP(r.RemoteAddr); ok {
+		return "ip:" + ip.String()
+	}
+	return "ip:" + r.RemoteAddr
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
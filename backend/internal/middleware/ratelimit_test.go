@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestRateLimiterHandler(t *testing.T) {
+	logger.Log = zap.NewNop()
+	rl := NewRateLimiter(RateLimitPolicy{Name: "test", Limit: 3, Period: time.Minute})
+	handler := rl.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remote, userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remote
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The full bucket allows a burst of Limit requests.
+	for i, want := range []string{"2", "1", "0"} {
+		rec := request("203.0.113.5:1000", "")
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != want {
+			t.Fatalf("request %d: status %d remaining %q, want 200 and %s", i+1, rec.Code, rec.Header().Get("RateLimit-Remaining"), want)
+		}
+	}
+
+	rec := request("203.0.113.5:2000", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	// One token refills every 20s.
+	if got := rec.Header().Get("Retry-After"); got != "20" {
+		t.Fatalf("Retry-After = %q, want 20", got)
+	}
+	if got := rec.Header().Get("RateLimit-Policy"); got != "3;w=60" {
+		t.Fatalf("RateLimit-Policy = %q, want 3;w=60", got)
+	}
+
+	// Authenticated requests count against the user, not the shared IP.
+	if rec := request("203.0.113.5:3000", "user-1"); rec.Code != http.StatusOK {
+		t.Fatalf("user request status = %d, want 200", rec.Code)
+	}
+}
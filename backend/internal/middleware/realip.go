@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces r.RemoteAddr with the client address. Forwarding headers are
+// only believed when the connecting peer is a trusted proxy; X-Forwarded-For is
+// then read right to left, skipping trusted hops, so a client cannot choose its
+// own address by sending the header itself. Replaces chi's RealIP, which trusts
+// the headers from anyone.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := clientIP(r.RemoteAddr, r.Header, trusted); ip.IsValid() {
+				r.RemoteAddr = ip.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP resolves the client address for a request received from remoteAddr.
+func clientIP(remoteAddr string, header http.Header, trusted []netip.Prefix) netip.Addr {
+	peer, ok := parseIP(remoteAddr)
+	if !ok || !isTrustedProxy(peer, trusted) {
+		return peer
+	}
+
+	// Each proxy appends the address it received from, so the right-most
+	// untrusted entry is the furthest hop we can vouch for.
+	hops := strings.Split(strings.Join(header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseIP(strings.TrimSpace(hops[i]))
+		if !ok {
+			// Garbage in the chain: stop at the last address we could trust.
+			break
+		}
+		if !isTrustedProxy(hop, trusted) {
+			return hop
+		}
+		peer = hop
+	}
+
+	if realIP, ok := parseIP(strings.TrimSpace(header.Get("X-Real-Ip"))); ok && header.Get("X-Forwarded-For") == "" {
+		return realIP
+	}
+	return peer
+}
+
+// parseIP accepts "ip", "ip:port" and "[ipv6]:port".
+func parseIP(s string) (netip.Addr, bool) {
+	if s == "" {
+		return netip.Addr{}, false
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		realIP string
+		want   string
+	}{
+		{"direct client", "203.0.113.5:4321", "", "", "203.0.113.5"},
+		{"spoofed header from untrusted peer", "203.0.113.5:4321", "1.2.3.4", "5.6.7.8", "203.0.113.5"},
+		{"trusted proxy", "10.0.0.2:80", "198.51.100.9", "", "198.51.100.9"},
+		{"client-supplied prefix ignored", "10.0.0.2:80", "1.2.3.4, 198.51.100.9, 10.0.0.3", "", "198.51.100.9"},
+		{"garbage stops the walk", "10.0.0.2:80", "198.51.100.9, junk, 10.0.0.3", "", "10.0.0.3"},
+		{"x-real-ip from trusted proxy", "10.0.0.2:80", "", "198.51.100.9", "198.51.100.9"},
+		{"ipv6 peer", "[2001:db8::1]:443", "1.2.3.4", "", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.xff != "" {
+				header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				header.Set("X-Real-Ip", tt.realIP)
+			}
+			if got := clientIP(tt.remote, header, trusted); got.String() != tt.want {
+				t.Fatalf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
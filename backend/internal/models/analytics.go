@@ -0,0 +1,66 @@
+package models
+
+// AnalyticsPoint holds one period of admin analytics. Holds and their
+// conversion, abandonment are counted by creation time; bookings, revenue,
+// cancellations and users by confirmation time; refunds by refund time.
+type AnalyticsPoint struct {
+	Period           string  `json:"period"` // first day of the period, YYYY-MM-DD
+	Holds            int     `json:"holds"`
+	Converted        int     `json:"converted"`
+	Abandoned        int     `json:"abandoned"`
+	Bookings         int     `json:"bookings"`
+	Cancelled        int     `json:"cancelled"`
+	Revenue          float64 `json:"revenue"`
+	Refunds          float64 `json:"refunds"`
+	NetRevenue       float64 `json:"net_revenue"`
+	NewUsers         int     `json:"new_users"`
+	ReturningUsers   int     `json:"returning_users"`
+	ConversionRate   float64 `json:"conversion_rate"`   // converted / holds
+	AbandonmentRate  float64 `json:"abandonment_rate"`  // abandoned / holds
+	CancellationRate float64 `json:"cancellation_rate"` // cancelled / bookings
+}
+
+// SlotFill is how often one time slot was booked over a date range.
+type SlotFill struct {
+	Time     string  `json:"time"`
+	Offered  int     `json:"offered"` // bookable dates in the range
+	Booked   int     `json:"booked"`  // paid bookings in the slot
+	FillRate float64 `json:"fill_rate"`
+}
+
+// AdminAnalytics is the response of GET /admin/analytics.
+type AdminAnalytics struct {
+	Granularity string           `json:"granularity"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Timezone    string           `json:"timezone"`
+	Series      []AnalyticsPoint `json:"series"`
+	Totals      AnalyticsPoint   `json:"totals"` // user counts are summed per period
+	Slots       []SlotFill       `json:"slots"`
+}
+
+// FunnelLatency summarises the seconds from slot hold to a funnel step.
+type FunnelLatency struct {
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Mean float64 `json:"mean"`
+}
+
+// FunnelStepStats is how many holds reached one funnel step, and when.
+type FunnelStepStats struct {
+	Step           string        `json:"step"`
+	Bookings       int           `json:"bookings"`
+	ConversionRate float64       `json:"conversion_rate"` // bookings / holds
+	Latency        FunnelLatency `json:"latency_seconds"`
+}
+
+// BookingFunnel is the response of GET /admin/funnel. Holds are counted by
+// creation date; later steps follow the same holds.
+type BookingFunnel struct {
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Timezone string            `json:"timezone"`
+	Holds    int               `json:"holds"`
+	Steps    []FunnelStepStats `json:"steps"`
+}
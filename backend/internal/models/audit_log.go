@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog is one audit log entry. Seq and Hash are nil for entries written
+// before the log was hash-chained; RedactedAt is set once retention or account
+// erasure has cleared the entry's IP, user agent or personal details.
+type AuditLog struct {
+	ID         string          `json:"id"`
+	Seq        *int64          `json:"seq,omitempty"`
+	UserID     *string         `json:"user_id,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type,omitempty"`
+	EntityID   *string         `json:"entity_id,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	Hash       *string         `json:"hash,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	RedactedAt *time.Time      `json:"redacted_at,omitempty"`
+}
@@ -14,12 +14,22 @@ type Booking struct {
 	
 	// Meeting
 	MeetingLink string    `json:"meeting_link,omitempty"`
+	// Set while the intake form must be answered; MeetingLink is withheld until then
+	IntakeRequired bool `json:"intake_required,omitempty"`
+
+	// Locale used for emails about this booking (captured at creation)
+	Locale string `json:"locale,omitempty"`
 
 	// Payment
 	PaymentStatus     string  `json:"payment_status"` // pending, paid, failed
 	RazorpayOrderID   string  `json:"razorpay_order_id,omitempty"`
 	RazorpayPaymentID string  `json:"razorpay_payment_id,omitempty"`
 	Amount            float64 `json:"amount,omitempty"`
+	RefundID          string  `json:"refund_id,omitempty"`
+
+	// Session lifecycle, separate from payment: scheduled, in_progress,
+	// completed, no_show_client, no_show_mentor or late_cancelled
+	SessionStatus string `json:"session_status,omitempty"`
 
 	UserID    *string   `json:"user_id,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
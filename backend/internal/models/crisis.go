@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Helpline is a crisis support service shown to a user.
+type Helpline struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Hours string `json:"hours,omitempty"`
+}
+
+// CrisisSupport is returned alongside a response whose free text matched a
+// crisis screening rule.
+type CrisisSupport struct {
+	Message   string     `json:"message"`
+	Region    string     `json:"region"`
+	Helplines []Helpline `json:"helplines"`
+}
+
+// CrisisEvent is one entry of the restricted crisis log.
+type CrisisEvent struct {
+	ID                  string     `json:"id"`
+	UserID              *string    `json:"user_id,omitempty"`
+	BookingID           *string    `json:"booking_id,omitempty"`
+	Source              string     `json:"source"`   // booking_notes, intake or feedback
+	Severity            string     `json:"severity"` // elevated or high
+	Rules               []string   `json:"rules"`
+	Excerpt             string     `json:"excerpt"`
+	Region              string     `json:"region"`
+	AlertedAt           *time.Time `json:"alerted_at,omitempty"`
+	AcknowledgedBy      *string    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt      *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgementNote *string    `json:"acknowledgement_note,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// EmailSuppression blocks delivery to an address. Scope "all" blocks every
+// email; "non_transactional" only blocks mail the recipient opted out of.
+type EmailSuppression struct {
+	ID              string    `json:"id"`
+	Email           string    `json:"email"`
+	Reason          string    `json:"reason"`
+	Scope           string    `json:"scope"`
+	Source          string    `json:"source"`
+	ProviderEventID *string   `json:"provider_event_id,omitempty"`
+	Details         string    `json:"details,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// EmailTemplate is one stored version of an email template.
+// Version 0 denotes the embedded default shipped with the binary.
+type EmailTemplate struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	Subject   string    `json:"subject"`
+	HTMLBody  string    `json:"html_body"`
+	TextBody  string    `json:"text_body"`
+	IsActive  bool      `json:"is_active"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
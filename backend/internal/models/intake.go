@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Intake question types.
+const (
+	IntakeText         = "text"          // one line, max_length runes (default 500)
+	IntakeLongText     = "long_text"     // free text, max_length runes (default 4000)
+	IntakeSingleChoice = "single_choice" // one of options
+	IntakeMultiChoice  = "multi_choice"  // any of options
+	IntakeScale        = "scale"         // whole number from min to max (default 1 to 10)
+	IntakeBoolean      = "boolean"       // yes or no
+)
+
+// IntakeQuestion is one question of an intake form.
+type IntakeQuestion struct {
+	ID        string   `json:"id"` // stable key for the answer, e.g. "goals"
+	Type      string   `json:"type"`
+	Label     string   `json:"label"`
+	Help      string   `json:"help,omitempty"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options,omitempty"`
+	Min       *int     `json:"min,omitempty"`
+	Max       *int     `json:"max,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+}
+
+// IntakeForm is one stored version of the intake questionnaire.
+type IntakeForm struct {
+	ID          string           `json:"id"`
+	Version     int              `json:"version"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Questions   []IntakeQuestion `json:"questions"`
+	IsActive    bool             `json:"is_active"`
+	CreatedBy   *string          `json:"created_by,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// IntakeResponse is a client's answers for one booking, keyed by question ID.
+type IntakeResponse struct {
+	ID          string                 `json:"id"`
+	BookingID   string                 `json:"booking_id"`
+	UserID      string                 `json:"user_id"`
+	FormID      string                 `json:"form_id"`
+	FormVersion int                    `json:"form_version"`
+	Answers     map[string]interface{} `json:"answers"`
+	SubmittedAt time.Time              `json:"submitted_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// LedgerAccountBalance is the debit and credit total of one ledger account
+// over a period.
+type LedgerAccountBalance struct {
+	Account string  `json:"account"`
+	Name    string  `json:"name"` // as exported to the accounting system
+	Debit   float64 `json:"debit"`
+	Credit  float64 `json:"credit"`
+}
+
+// LedgerPeriod is one month of the ledger with its trial balance.
+type LedgerPeriod struct {
+	Period       string                 `json:"period"` // YYYY-MM
+	Closed       bool                   `json:"closed"`
+	ClosedAt     *time.Time             `json:"closed_at,omitempty"`
+	ClosedBy     *string                `json:"closed_by,omitempty"`
+	Transactions int                    `json:"transactions"`
+	Accounts     []LedgerAccountBalance `json:"accounts"`
+}
+
+// SettlementUpload is one imported Razorpay settlement report and how its
+// rows matched bookings.
+type SettlementUpload struct {
+	ID         string    `json:"id"`
+	Filename   string    `json:"filename"`
+	UploadedBy *string   `json:"uploaded_by,omitempty"`
+	Rows       int       `json:"rows"`
+	Matched    int       `json:"matched"`
+	Mismatched int       `json:"mismatched"` // booking found, amount differs
+	Unmatched  int       `json:"unmatched"`  // no booking with the payment or refund ID
+	Ignored    int       `json:"ignored"`    // adjustments, transfers and other entity types
+	Duplicates int       `json:"duplicates"` // already imported from an earlier report
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SettlementLine is one payment or refund settled by Razorpay.
+type SettlementLine struct {
+	ID           string     `json:"id"`
+	UploadID     string     `json:"upload_id"`
+	SettlementID string     `json:"settlement_id"`
+	EntityID     string     `json:"entity_id"`
+	EntityType   string     `json:"entity_type"`
+	Amount       float64    `json:"amount"`
+	Fee          float64    `json:"fee"` // including tax
+	Tax          float64    `json:"tax"`
+	SettledAt    *time.Time `json:"settled_at,omitempty"`
+	UTR          string     `json:"utr"`
+	BookingID    *string    `json:"booking_id,omitempty"`
+	MatchStatus  string     `json:"match_status"` // matched, amount_mismatch, unmatched or ignored
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Testimonial is post-session feedback as moderators see it.
+type Testimonial struct {
+	ID             string     `json:"id"`
+	UserID         *string    `json:"user_id,omitempty"`
+	BookingID      *string    `json:"booking_id,omitempty"`
+	Name           string     `json:"name"`
+	Content        string     `json:"content"`
+	Rating         int        `json:"rating"`
+	IsAnonymous    bool       `json:"is_anonymous"`
+	AllowPublish   bool       `json:"allow_publish"`
+	SessionDate    *string    `json:"session_date,omitempty"`
+	Status         string     `json:"status"` // pending, approved, rejected or featured
+	ModeratedBy    *string    `json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	ModerationNote *string    `json:"moderation_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PublicTestimonial is an approved testimonial as shown on the site.
+type PublicTestimonial struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Content     string    `json:"content"`
+	Rating      int       `json:"rating"`
+	SessionDate *string   `json:"session_date,omitempty"`
+	Featured    bool      `json:"featured"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// NotificationPreferences are the messages a user has opted in to.
+// Transactional emails (confirmations, cancellations) are always sent.
+type NotificationPreferences struct {
+	SessionReminders bool `json:"session_reminders"`
+	ProductUpdates   bool `json:"product_updates"`
+}
+
+// DefaultNotificationPreferences applies to users who never changed them.
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{SessionReminders: true}
+}
+
+// UserProfile is a user's account details and preferences.
+type UserProfile struct {
+	ID                      string                  `json:"id"`
+	Email                   string                  `json:"email"`
+	FullName                string                  `json:"full_name"`
+	DisplayName             string                  `json:"display_name"`
+	Phone                   string                  `json:"phone"`
+	Timezone                string                  `json:"timezone"`
+	Locale                  string                  `json:"locale"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	Blocked                 bool                    `json:"blocked"`
+	CreatedAt               time.Time               `json:"created_at"`
+	UpdatedAt               time.Time               `json:"updated_at"`
+}
+
+// AdminUser is a user as listed on the admin users page.
+type AdminUser struct {
+	UserProfile
+	BlockedAt     *time.Time `json:"blocked_at,omitempty"`
+	BlockedBy     *string    `json:"blocked_by,omitempty"`
+	BlockedReason *string    `json:"blocked_reason,omitempty"`
+	BookingCount  int        `json:"booking_count"` // paid bookings
+}
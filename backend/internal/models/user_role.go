@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserRole is one role assignment granting admin permissions to a user.
+type UserRole struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	Email     string    `json:"email"`
+	GrantedBy *string   `json:"granted_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ErrUpcomingBookings is returned by EraseUserData while the user still has
+// confirmed sessions ahead; they must be cancelled (and refunded) first.
+var ErrUpcomingBookings = errors.New("user has upcoming confirmed bookings")
+
+// exportSections are the files of a data export. Each query selects one
+// table's rows for the user ($1) as a JSON array, so columns added later are
+// exported without changes here.
+var exportSections = []struct {
+	file  string
+	query string
+}{
+	{"profile.json", `SELECT COALESCE(json_agg(t), '[]')::text FROM user_profiles t WHERE id = $1`},
+	// Blind indexes are keyed hashes, meaningless outside this database.
+	{"bookings.json", `SELECT COALESCE(json_agg(to_jsonb(t) - 'name_bidx' - 'email_bidx' ORDER BY t.created_at), '[]')::text FROM bookings t WHERE user_id = $1`},
+	{"subscriptions.json", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]')::text FROM subscriptions t WHERE user_id = $1`},
+	{"coupon_uses.json", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]')::text FROM coupon_uses t WHERE user_id = $1`},
+	{"audit_log.json", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]')::text FROM audit_logs t WHERE user_id = $1`},
+	{"feedback.json", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]')::text FROM testimonials t WHERE user_id = $1`},
+	{"intake.json", `SELECT COALESCE(json_agg(json_build_object(
+		'booking_id', t.booking_id, 'form_version', f.version, 'questions', f.questions,
+		'answers', t.answers, 'submitted_at', t.submitted_at, 'updated_at', t.updated_at
+	) ORDER BY t.submitted_at), '[]')::text FROM intake_responses t JOIN intake_forms f ON f.id = t.form_id WHERE t.user_id = $1`},
+	// Flagged text is yours already; only when and what kind of help was offered is listed.
+	{"safety.json", `SELECT COALESCE(json_agg(json_build_object(
+		'source', t.source, 'severity', t.severity, 'helpline_region', t.region,
+		'created_at', t.created_at, 'followed_up_at', t.acknowledged_at
+	) ORDER BY t.created_at), '[]')::text FROM crisis_events t WHERE t.user_id = $1`},
+	{"roles.json", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]')::text FROM user_roles t WHERE user_id = $1`},
+}
+
+const exportReadme = `Hidden Depths - personal data export
+
+Generated: %s
+User ID:   %s
+
+Each JSON file holds every record we store about you in one table:
+
+  profile.json        account preferences
+  bookings.json       sessions booked, with payment status and any notes
+  subscriptions.json  mentorship plans
+  coupon_uses.json    discount codes applied to your bookings
+  feedback.json       session feedback and testimonials you submitted
+  intake.json         your answers to pre-session intake questions
+  safety.json         times we showed you crisis helplines after screening
+                      something you wrote
+  audit_log.json      security log of actions taken on your account
+  roles.json          staff roles, if any
+
+Your sign-in identity (email and password or social login) is held by our
+authentication provider, Supabase.
+`
+
+// ExportUserData builds a ZIP archive of everything stored about userID.
+func ExportUserData(ctx context.Context, userID string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now().UTC()
+
+	for _, section := range exportSections {
+		var raw string
+		if err := database.Pool.QueryRow(ctx, section.query, userID).Scan(&raw); err != nil {
+			return nil, fmt.Errorf("export %s: %w", section.file, err)
+		}
+		var err error
+		switch section.file {
+		case "bookings.json":
+			raw, err = openExportedBookings(raw)
+		case "intake.json":
+			raw, err = openExportedIntake(raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", section.file, err)
+		}
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(raw), "", "  "); err != nil {
+			return nil, fmt.Errorf("export %s: %w", section.file, err)
+		}
+		if err := writeZipFile(zw, section.file, now, pretty.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	readme := fmt.Sprintf(exportReadme, now.Format(time.RFC3339), userID)
+	if err := writeZipFile(zw, "README.txt", now, []byte(readme)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// openExportedBookings decrypts the name and email of each exported booking.
+func openExportedBookings(raw string) (string, error) {
+	var bookings []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &bookings); err != nil {
+		return "", err
+	}
+	for _, b := range bookings {
+		name, _ := b["name"].(string)
+		email, _ := b["email"].(string)
+		if err := OpenBookingPII(&name, &email); err != nil {
+			return "", err
+		}
+		b["name"], b["email"] = name, email
+	}
+	opened, err := json.Marshal(bookings)
+	return string(opened), err
+}
+
+// openExportedIntake decrypts the answers of each exported intake response.
+func openExportedIntake(raw string) (string, error) {
+	var responses []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &responses); err != nil {
+		return "", err
+	}
+	for _, resp := range responses {
+		sealed, _ := resp["answers"].(string)
+		answers, err := openIntakeAnswers(sealed)
+		if err != nil {
+			return "", err
+		}
+		resp["answers"] = answers
+	}
+	opened, err := json.Marshal(responses)
+	return string(opened), err
+}
+
+func writeZipFile(zw *zip.Writer, name string, modified time.Time, content []byte) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
+// ErasureSummary describes what EraseUserData changed.
+type ErasureSummary struct {
+	BookingsAnonymised     int       `json:"bookings_anonymised"`
+	AuditEntriesAnonymised int       `json:"audit_entries_anonymised"`
+	TestimonialsDeleted    int       `json:"testimonials_deleted"`
+	IntakeResponsesDeleted int       `json:"intake_responses_deleted"`
+	HoldsReleased          []string  `json:"-"` // dates whose slot availability changed
+	AuthUserDeleted        bool      `json:"auth_user_deleted"`
+	ErasedAt               time.Time `json:"erased_at"`
+}
+
+// EraseUserData erases a user's personal data. Bookings keep their dates,
+// amounts and payment references for accounting but lose name, email,
+// meeting link and client IP; audit entries keep the action but lose IP and
+// user agent. Preferences, roles, session feedback and intake answers are deleted. Afterwards every token is
+// revoked and, when SUPABASE_SERVICE_ROLE_KEY is set, the Supabase auth user
+// is deleted too. The erasure itself is recorded in account_erasures.
+func EraseUserData(ctx context.Context, userID string) (ErasureSummary, error) {
+	summary := ErasureSummary{ErasedAt: time.Now().UTC()}
+
+	err := pgx.BeginFunc(ctx, database.Pool, func(tx pgx.Tx) error {
+		var upcoming int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM bookings
+			 WHERE user_id = $1 AND payment_status = 'paid' AND date >= $2`,
+			userID, summary.ErasedAt.Format("2006-01-02"),
+		).Scan(&upcoming); err != nil {
+			return err
+		}
+		if upcoming > 0 {
+			return ErrUpcomingBookings
+		}
+
+		// Unpaid holds would otherwise block their slots until they expire.
+		rows, err := tx.Query(ctx,
+			`UPDATE bookings
+			 SET payment_status = 'failed',
+			     status_reason = 'account_erased',
+			     failed_at = COALESCE(failed_at, NOW()),
+			     released_at = COALESCE(released_at, NOW())
+			 WHERE user_id = $1 AND payment_status = 'pending'
+			 RETURNING date`,
+			userID,
+		)
+		if err != nil {
+			return err
+		}
+		dates, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		summary.HoldsReleased = dates
+
+		tag, err := tx.Exec(ctx,
+			`UPDATE bookings
+			 SET name = 'Erased user',
+			     email = 'erased+' || id || '@invalid',
+			     name_bidx = NULL,
+			     email_bidx = NULL,
+			     meeting_link = NULL,
+			     client_ip = NULL,
+			     erased_at = $2
+			 WHERE user_id = $1 AND erased_at IS NULL`,
+			userID, summary.ErasedAt,
+		)
+		if err != nil {
+			return err
+		}
+		summary.BookingsAnonymised = int(tag.RowsAffected())
+
+		tag, err = tx.Exec(ctx,
+			`UPDATE audit_logs
+			 SET ip_address = NULL,
+			     user_agent = NULL,
+			     details = CASE WHEN jsonb_typeof(details) = 'object' THEN details - 'email' - 'name' ELSE details END,
+			     redacted_at = NOW()
+			 WHERE user_id = $1`,
+			userID,
+		)
+		if err != nil {
+			return err
+		}
+		summary.AuditEntriesAnonymised = int(tag.RowsAffected())
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE subscriptions SET status = 'cancelled' WHERE user_id = $1 AND status = 'active'`,
+			userID,
+		); err != nil {
+			return err
+		}
+		tag, err = tx.Exec(ctx, `DELETE FROM testimonials WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		summary.TestimonialsDeleted = int(tag.RowsAffected())
+
+		tag, err = tx.Exec(ctx, `DELETE FROM intake_responses WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		summary.IntakeResponsesDeleted = int(tag.RowsAffected())
+
+		// The crisis log keeps that an event happened, not who or what
+		if _, err := tx.Exec(ctx,
+			`UPDATE crisis_events SET user_id = NULL, excerpt = '' WHERE user_id = $1`,
+			userID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM user_profiles WHERE id = $1`, userID)
+		return err
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	// Log the user out everywhere and drop everything cached about them.
+	if _, err := RevokeUserSessions(ctx, userID, userID, "account_erased"); err != nil {
+		logger.Error("Failed to revoke sessions after erasure", zap.String("user_id", userID), zap.Error(err))
+	}
+	if err := cache.Delete(ctx, cache.UserLocaleKey(userID), cache.UserRolesKey(userID), cache.UserBlockedKey(userID)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to purge cached user data after erasure", zap.String("user_id", userID), zap.Error(err))
+	}
+	if summary.TestimonialsDeleted > 0 {
+		if err := cache.Delete(ctx, cache.TestimonialsKey()); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+			logger.Warn("Failed to invalidate testimonials cache after erasure", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+
+	deleted, err := deleteAuthUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to delete Supabase auth user after erasure", zap.String("user_id", userID), zap.Error(err))
+	}
+	summary.AuthUserDeleted = deleted
+
+	if _, err := database.Pool.Exec(ctx,
+		`INSERT INTO account_erasures (user_id, bookings_anonymised, audit_entries_anonymised, auth_user_deleted, erased_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		userID, summary.BookingsAnonymised, summary.AuditEntriesAnonymised, summary.AuthUserDeleted, summary.ErasedAt,
+	); err != nil {
+		logger.Error("Failed to record account erasure", zap.String("user_id", userID), zap.Error(err))
+	}
+	return summary, nil
+}
+
+// deleteAuthUser removes the user from Supabase Auth with the service role
+// key. It reports false without error when no service role key is configured.
+func deleteAuthUser(ctx context.Context, userID string) (bool, error) {
+	serviceKey := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
+	baseURL := strings.TrimRight(os.Getenv("SUPABASE_URL"), "/")
+	if serviceKey == "" || baseURL == "" {
+		return false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, baseURL+"/auth/v1/admin/users/"+userID, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("apikey", serviceKey)
+	req.Header.Set("Authorization", "Bearer "+serviceKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	// 404: already gone, which is the outcome we wanted.
+	if resp.StatusCode == http.StatusNotFound {
+		return true, nil
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("supabase admin API returned %d", resp.StatusCode)
+	}
+	return true, nil
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+)
+
+// AnalyticsQuery selects the admin analytics to compute.
+type AnalyticsQuery struct {
+	Granularity     string    // day, week (starting Monday) or month
+	From            time.Time // first date, inclusive
+	To              time.Time // last date, inclusive
+	Timezone        string    // IANA zone periods are cut in
+	TimeSlots       []string  // bookable slots, listed first in slot fill
+	AllowedWeekdays []time.Weekday
+}
+
+// analyticsSeriesQuery fills every period between $2 and $3 (exclusive),
+// truncated to $1 in zone $4, with the metrics documented on
+// models.AnalyticsPoint. Abandoned holds are unpaid holds that expired or
+// were released; erased accounts are not abandonment.
+const analyticsSeriesQuery = `
+WITH buckets AS (
+	SELECT generate_series(
+		date_trunc($1, $2::timestamp),
+		date_trunc($1, $3::timestamp - interval '1 day'),
+		('1 ' || $1)::interval
+	) AS bucket
+),
+holds AS (
+	SELECT date_trunc($1, created_at AT TIME ZONE $4) AS bucket,
+	       COUNT(*) AS holds,
+	       COUNT(*) FILTER (WHERE confirmed_at IS NOT NULL) AS converted,
+	       COUNT(*) FILTER (WHERE confirmed_at IS NULL AND payment_status = 'failed'
+	                          AND status_reason IS DISTINCT FROM 'account_erased') AS abandoned
+	FROM bookings
+	WHERE created_at >= $2::timestamp AT TIME ZONE $4 AND created_at < $3::timestamp AT TIME ZONE $4
+	GROUP BY 1
+),
+firsts AS (
+	SELECT user_id, MIN(confirmed_at) AS first_at
+	FROM bookings
+	WHERE confirmed_at IS NOT NULL AND user_id IS NOT NULL
+	GROUP BY user_id
+),
+paid AS (
+	SELECT date_trunc($1, b.confirmed_at AT TIME ZONE $4) AS bucket,
+	       COUNT(*) AS bookings,
+	       COUNT(*) FILTER (WHERE b.payment_status = 'cancelled' OR b.session_status = 'late_cancelled') AS cancelled,
+	       SUM(b.amount) AS revenue,
+	       COUNT(DISTINCT b.user_id) FILTER (WHERE date_trunc($1, f.first_at AT TIME ZONE $4) = date_trunc($1, b.confirmed_at AT TIME ZONE $4)) AS new_users,
+	       COUNT(DISTINCT b.user_id) FILTER (WHERE date_trunc($1, f.first_at AT TIME ZONE $4) < date_trunc($1, b.confirmed_at AT TIME ZONE $4)) AS returning_users
+	FROM bookings b
+	LEFT JOIN firsts f ON f.user_id = b.user_id
+	WHERE b.confirmed_at >= $2::timestamp AT TIME ZONE $4 AND b.confirmed_at < $3::timestamp AT TIME ZONE $4
+	GROUP BY 1
+),
+refunds AS (
+	SELECT date_trunc($1, refunded_at AT TIME ZONE $4) AS bucket, SUM(refund_amount) AS refunds
+	FROM bookings
+	WHERE refunded_at >= $2::timestamp AT TIME ZONE $4 AND refunded_at < $3::timestamp AT TIME ZONE $4
+	GROUP BY 1
+)
+SELECT to_char(k.bucket, 'YYYY-MM-DD'),
+       COALESCE(h.holds, 0), COALESCE(h.converted, 0), COALESCE(h.abandoned, 0),
+       COALESCE(p.bookings, 0), COALESCE(p.cancelled, 0),
+       COALESCE(p.revenue, 0)::float8, COALESCE(r.refunds, 0)::float8,
+       COALESCE(p.new_users, 0), COALESCE(p.returning_users, 0)
+FROM buckets k
+LEFT JOIN holds h ON h.bucket = k.bucket
+LEFT JOIN paid p ON p.bucket = k.bucket
+LEFT JOIN refunds r ON r.bucket = k.bucket
+ORDER BY k.bucket`
+
+// GetAdminAnalytics computes time series and slot fill for q. Results are
+// cached for cache.AnalyticsTTL.
+func GetAdminAnalytics(ctx context.Context, q AnalyticsQuery) (models.AdminAnalytics, error) {
+	from, to := q.From.Format("2006-01-02"), q.To.Format("2006-01-02")
+	cacheKey := cache.AnalyticsKey(q.Granularity, from, to, q.Timezone)
+	if analytics, err := cache.Get[models.AdminAnalytics](ctx, cacheKey); err == nil {
+		return analytics, nil
+	}
+
+	analytics := models.AdminAnalytics{
+		Granularity: q.Granularity,
+		From:        from,
+		To:          to,
+		Timezone:    q.Timezone,
+		Series:      []models.AnalyticsPoint{},
+	}
+
+	rows, err := database.Pool.Query(ctx, analyticsSeriesQuery,
+		q.Granularity, from, q.To.AddDate(0, 0, 1).Format("2006-01-02"), q.Timezone,
+	)
+	if err != nil {
+		return analytics, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.AnalyticsPoint
+		if err := rows.Scan(&p.Period, &p.Holds, &p.Converted, &p.Abandoned, &p.Bookings, &p.Cancelled,
+			&p.Revenue, &p.Refunds, &p.NewUsers, &p.ReturningUsers); err != nil {
+			return analytics, err
+		}
+		analytics.Series = append(analytics.Series, withAnalyticsRates(p))
+		analytics.Totals = addAnalyticsPoints(analytics.Totals, p)
+	}
+	if err := rows.Err(); err != nil {
+		return analytics, err
+	}
+	analytics.Totals = withAnalyticsRates(analytics.Totals)
+
+	booked, err := paidBookingsBySlot(ctx, from, to)
+	if err != nil {
+		return analytics, err
+	}
+	analytics.Slots = slotFill(q.TimeSlots, booked, bookableDates(q.From, q.To, q.AllowedWeekdays))
+
+	_ = cache.Set(ctx, cacheKey, analytics, cache.AnalyticsTTL)
+	return analytics, nil
+}
+
+func paidBookingsBySlot(ctx context.Context, from, to string) (map[string]int, error) {
+	rows, err := database.Pool.Query(ctx,
+		`SELECT time, COUNT(*) FROM bookings
+		 WHERE payment_status = 'paid' AND date >= $1 AND date <= $2
+		 GROUP BY time`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	booked := map[string]int{}
+	for rows.Next() {
+		var slot string
+		var count int
+		if err := rows.Scan(&slot, &count); err != nil {
+			return nil, err
+		}
+		booked[slot] = count
+	}
+	return booked, rows.Err()
+}
+
+// slotFill lists the configured slots in order, then any other slot that
+// was booked (e.g. before a schedule change), alphabetically.
+func slotFill(slots []string, booked map[string]int, offered int) []models.SlotFill {
+	fill := make([]models.SlotFill, 0, len(slots))
+	seen := make(map[string]bool, len(slots))
+	for _, slot := range slots {
+		seen[slot] = true
+		fill = append(fill, models.SlotFill{Time: slot, Offered: offered, Booked: booked[slot], FillRate: analyticsRatio(booked[slot], offered)})
+	}
+
+	extra := []string{}
+	for slot := range booked {
+		if !seen[slot] {
+			extra = append(extra, slot)
+		}
+	}
+	sort.Strings(extra)
+	for _, slot := range extra {
+		fill = append(fill, models.SlotFill{Time: slot, Booked: booked[slot]})
+	}
+	return fill
+}
+
+// bookableDates counts the dates from..to (inclusive) on allowed weekdays.
+func bookableDates(from, to time.Time, weekdays []time.Weekday) int {
+	allowed := make(map[time.Weekday]bool, len(weekdays))
+	for _, day := range weekdays {
+		allowed[day] = true
+	}
+	count := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if allowed[d.Weekday()] {
+			count++
+		}
+	}
+	return count
+}
+
+func addAnalyticsPoints(total, p models.AnalyticsPoint) models.AnalyticsPoint {
+	total.Holds += p.Holds
+	total.Converted += p.Converted
+	total.Abandoned += p.Abandoned
+	total.Bookings += p.Bookings
+	total.Cancelled += p.Cancelled
+	total.Revenue += p.Revenue
+	total.Refunds += p.Refunds
+	total.NewUsers += p.NewUsers
+	total.ReturningUsers += p.ReturningUsers
+	return total
+}
+
+func withAnalyticsRates(p models.AnalyticsPoint) models.AnalyticsPoint {
+	p.NetRevenue = math.Round((p.Revenue-p.Refunds)*100) / 100
+	p.ConversionRate = analyticsRatio(p.Converted, p.Holds)
+	p.AbandonmentRate = analyticsRatio(p.Abandoned, p.Holds)
+	p.CancellationRate = analyticsRatio(p.Cancelled, p.Bookings)
+	return p
+}
+
+// analyticsRatio is part/whole to four decimal places, 0 when whole is 0.
+func analyticsRatio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+)
+
+func TestSlotFill(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) // Thursday
+	to := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	offered := bookableDates(from, to, []time.Weekday{time.Saturday, time.Sunday})
+	if offered != 4 {
+		t.Fatalf("bookableDates() = %d, want 4", offered)
+	}
+
+	fill := slotFill([]string{"10:00", "18:00"}, map[string]int{"18:00": 3, "09:00": 1}, offered)
+	want := []struct {
+		time     string
+		booked   int
+		fillRate float64
+	}{
+		{"10:00", 0, 0},
+		{"18:00", 3, 0.75},
+		{"09:00", 1, 0},
+	}
+	if len(fill) != len(want) {
+		t.Fatalf("slotFill() returned %d slots, want %d", len(fill), len(want))
+	}
+	for i, w := range want {
+		if fill[i].Time != w.time || fill[i].Booked != w.booked || fill[i].FillRate != w.fillRate {
+			t.Fatalf("slotFill()[%d] = %+v, want %+v", i, fill[i], w)
+		}
+	}
+}
+
+func TestFunnelStepStats(t *testing.T) {
+	byStep := map[string]models.FunnelStepStats{
+		FunnelHoldCreated: {Step: FunnelHoldCreated, Bookings: 8},
+		FunnelConfirmed:   {Step: FunnelConfirmed, Bookings: 6, Latency: models.FunnelLatency{P50: 42.12345, Mean: 50.0004}},
+	}
+	steps := funnelStepStats(byStep, 8)
+	if len(steps) != len(FunnelSteps) {
+		t.Fatalf("funnelStepStats() returned %d steps, want %d", len(steps), len(FunnelSteps))
+	}
+	for i, s := range steps {
+		if s.Step != FunnelSteps[i] {
+			t.Fatalf("steps[%d] = %q, want %q", i, s.Step, FunnelSteps[i])
+		}
+	}
+	confirmed := steps[4]
+	if confirmed.ConversionRate != 0.75 || confirmed.Latency.P50 != 42.123 || confirmed.Latency.Mean != 50 {
+		t.Fatalf("confirmed = %+v", confirmed)
+	}
+	if steps[1].Bookings != 0 || steps[1].ConversionRate != 0 {
+		t.Fatalf("checkout_opened = %+v, want zero", steps[1])
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// API keys look like "hdk_<prefix>_<secret>". The prefix is public and stored
+// in clear for identification; the whole key is only stored as a SHA-256 hash,
+// which is sufficient for 256-bit random secrets.
+const (
+	apiKeyPrefix      = "hdk_"
+	apiKeyIDBytes     = 6
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval limits last_used_at writes to one per key per minute.
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey is returned for unknown, malformed, expired or revoked keys.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// cachedAPIKey is what a validated key resolves to.
+type cachedAPIKey struct {
+	ID        string            `json:"id"`
+	Scopes    []rbac.Permission `json:"scopes"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+}
+
+// GenerateAPIKey returns a new plaintext key, its public prefix and its hash.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, apiKeyIDBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of a key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// looksLikeAPIKey rejects obviously malformed keys before any lookup.
+func looksLikeAPIKey(key string) bool {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	return ok && len(id) == apiKeyIDBytes*2 && len(secret) >= 40
+}
+
+// AuthenticateAPIKey resolves a presented key to its ID and scopes, and
+// records its use. Validated keys are cached briefly; revocation clears the cache.
+func AuthenticateAPIKey(ctx context.Context, key, ip string) (string, []rbac.Permission, error) {
+	if !looksLikeAPIKey(key) {
+		return "", nil, ErrInvalidAPIKey
+	}
+	hash := HashAPIKey(key)
+	cacheKey := cache.APIKeyKey(hash)
+
+	resolved, err := cache.Get[cachedAPIKey](ctx, cacheKey)
+	if err != nil {
+		resolved, err = loadAPIKey(ctx, hash)
+		if err != nil {
+			return "", nil, err
+		}
+		_ = cache.Set(ctx, cacheKey, resolved, cache.APIKeyTTL)
+	}
+
+	if resolved.ExpiresAt != nil && time.Now().After(*resolved.ExpiresAt) {
+		return "", nil, ErrInvalidAPIKey
+	}
+
+	go touchAPIKey(resolved.ID, ip)
+	return resolved.ID, resolved.Scopes, nil
+}
+
+func loadAPIKey(ctx context.Context, hash string) (cachedAPIKey, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var resolved cachedAPIKey
+	var scopes []string
+	err := database.Pool.QueryRow(queryCtx,
+		`SELECT id, scopes, expires_at
+		 FROM api_keys
+		 WHERE key_hash = $1 AND revoked_at IS NULL`,
+		hash,
+	).Scan(&resolved.ID, &scopes, &resolved.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return resolved, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return resolved, err
+	}
+
+	for _, scope := range scopes {
+		resolved.Scopes = append(resolved.Scopes, rbac.Permission(scope))
+	}
+	return resolved, nil
+}
+
+// touchAPIKey updates last-used tracking, skipping the write when the key was
+// already marked within apiKeyTouchInterval.
+func touchAPIKey(id, ip string) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeout)
+	defer cancel()
+
+	_, err := database.Pool.Exec(ctx,
+		`UPDATE api_keys
+		 SET last_used_at = NOW(), last_used_ip = $2
+		 WHERE id = $1
+		   AND (last_used_at IS NULL OR last_used_at < NOW() - make_interval(secs => $3))`,
+		id, ip, apiKeyTouchInterval.Seconds(),
+	)
+	if err != nil {
+		logger.Warn("Failed to record API key use", zap.String("api_key_id", id), zap.Error(err))
+	}
+}
+
+// CreateAPIKey stores a new key and returns it with the plaintext secret,
+// which is not retrievable later.
+func CreateAPIKey(ctx context.Context, name string, scopes []rbac.Permission, expiresAt *time.Time, createdBy string) (models.APIKey, string, error) {
+	plaintext, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	scopeNames := make([]string, len(scopes))
+	for i, scope := range scopes {
+		scopeNames[i] = string(scope)
+	}
+
+	var k models.APIKey
+	err = database.Pool.QueryRow(ctx,
+		`INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, name, prefix, scopes, created_by, expires_at, created_at`,
+		name, prefix, hash, scopeNames, parseUUID(createdBy), expiresAt,
+	).Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedBy, &k.ExpiresAt, &k.CreatedAt)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	return k, plaintext, nil
+}
+
+// APIKeyListPage is how API keys page: newest first.
+var APIKeyListPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+		"name":       {Expr: "name", Type: pagination.TypeText},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.APIKey{}),
+}
+
+// ListAPIKeys returns the page p of keys, including revoked and expired ones.
+// Secrets are never returned.
+func ListAPIKeys(ctx context.Context, p pagination.Params) (pagination.Page[models.APIKey], error) {
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, name, prefix, scopes, created_by, expires_at, last_used_at, last_used_ip, revoked_at, created_at, %s
+		 FROM api_keys
+		 WHERE %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.APIKey]{}, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var k models.APIKey
+		var pos pagination.Position
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedBy, &k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP, &k.RevokedAt, &k.CreatedAt, &pos.Value, &pos.Key); err != nil {
+			return pagination.Page[models.APIKey]{}, err
+		}
+		keys = append(keys, k)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.APIKey]{}, err
+	}
+	return pagination.NewPage(p, keys, positions), nil
+}
+
+// RevokeAPIKey disables a key immediately. It reports whether an active key was revoked.
+func RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	var hash string
+	err := database.Pool.QueryRow(ctx,
+		`UPDATE api_keys SET revoked_at = NOW()
+		 WHERE id = $1 AND revoked_at IS NULL
+		 RETURNING key_hash`,
+		id,
+	).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := cache.Delete(ctx, cache.APIKeyKey(hash)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to invalidate API key cache", zap.String("api_key_id", id), zap.Error(err))
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+	if !strings.HasPrefix(key, prefix+"_") {
+		t.Fatalf("key %q does not start with prefix %q", key, prefix)
+	}
+	if !looksLikeAPIKey(key) {
+		t.Fatalf("generated key %q rejected by looksLikeAPIKey", key)
+	}
+	if hash != HashAPIKey(key) {
+		t.Fatalf("hash mismatch")
+	}
+
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+	if other == key {
+		t.Fatalf("two generated keys are identical")
+	}
+}
+
+func TestLooksLikeAPIKey(t *testing.T) {
+	valid, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"generated", valid, true},
+		{"empty", "", false},
+		{"wrong prefix", "sk_" + strings.TrimPrefix(valid, apiKeyPrefix), false},
+		{"missing secret", valid[:len(apiKeyPrefix)+apiKeyIDBytes*2], false},
+		{"short secret", valid[:len(apiKeyPrefix)+apiKeyIDBytes*2+10], false},
+		{"bearer token", "eyJhbGciOiJIUzI1NiJ9.e30.sig", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := looksLikeAPIKey(tc.key); got != tc.want {
+				t.Fatalf("looksLikeAPIKey(%q) = %v, want %v", tc.key, got, tc.want)
+			}
+		})
+	}
+}
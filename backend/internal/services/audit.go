@@ -2,42 +2,245 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/retry"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
-type AuditService struct{}
+const (
+	auditBufferSize    = 1024                   // entries queued before Log writes inline
+	auditBatchSize     = 100                    // entries per INSERT transaction
+	auditFlushInterval = 500 * time.Millisecond // max delay before a partial batch is written
+	auditWriteTimeout  = 10 * time.Second
+)
+
+// AuditService writes audit log entries. Log queues entries for a background
+// writer that inserts them in batches; Close flushes whatever is queued.
+// Every entry is appended to a single hash chain (see VerifyAuditChain).
+type AuditService struct {
+	entries chan auditEntry
+	done    chan struct{}
 
+	mu     sync.RWMutex
+	closed bool
+}
+
+// auditEntry is one audit log row before it is chained.
+type auditEntry struct {
+	Action     string
+	UserID     string // canonical UUID or ""
+	EntityID   string // canonical UUID or ""
+	EntityType string
+	IP         string
+	UserAgent  string
+	Details    []byte // canonical JSON, see canonicalJSON
+	CreatedAt  time.Time
+}
+
+// NewAuditService starts the background writer.
 func NewAuditService() *AuditService {
-	return &AuditService{}
+	s := &AuditService{
+		entries: make(chan auditEntry, auditBufferSize),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
 }
 
+// Log records an audit entry without waiting for the database. The entry is
+// written inline instead when the queue is full or the service is closed, so
+// entries are not dropped under load or during shutdown.
 func (s *AuditService) Log(ctx context.Context, action string, userID, entityID, entityType, ip, userAgent string, details interface{}) {
-	// 1. Prepare JSON details
-	detailsJSON, err := json.Marshal(details)
-	if err != nil {
-		logger.Error("Failed to marshal audit details", zap.Error(err))
-		detailsJSON = []byte("{}")
-	}
-
-	// 2. Insert into DB (Async to not block main request)
-	go func() {
-		// Use a new context for the background goroutine
-		bgCtx := context.Background()
-		
-		_, err := database.Pool.Exec(bgCtx,
-			`INSERT INTO audit_logs (user_id, action, entity_type, entity_id, ip_address, user_agent, details)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-			parseUUID(userID), action, entityType, parseUUID(entityID), ip, userAgent, detailsJSON,
+	entry := newAuditEntry(action, userID, entityID, entityType, ip, userAgent, details)
+
+	s.mu.RLock()
+	if !s.closed {
+		select {
+		case s.entries <- entry:
+			s.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	s.mu.RUnlock()
+
+	// The request context may be cancelled as soon as the handler returns.
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	s.write(writeCtx, []auditEntry{entry})
+}
+
+// LogSync writes an audit entry before returning. Used by short-lived
+// processes (hdctl) that would exit before Log's background write finishes.
+func (s *AuditService) LogSync(ctx context.Context, action string, userID, entityID, entityType, ip, userAgent string, details interface{}) error {
+	return insertAuditBatch(ctx, []auditEntry{newAuditEntry(action, userID, entityID, entityType, ip, userAgent, details)})
+}
+
+// Close stops the background writer after flushing queued entries. It
+// returns ctx's error if the flush does not finish in time.
+func (s *AuditService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.entries)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AuditService) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]auditEntry, 0, auditBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		s.write(ctx, batch)
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-s.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= auditBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// write inserts entries with retries. If that still fails, each entry is
+// logged in full so it can be recovered from the application logs.
+func (s *AuditService) write(ctx context.Context, entries []auditEntry) {
+	err := retry.Do(ctx, retry.DefaultConfig(), "write audit log", func() error {
+		if err := insertAuditBatch(ctx, entries); err != nil {
+			return apperror.DatabaseError("write audit log", err)
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+	for _, e := range entries {
+		logger.Error("Failed to write audit log",
+			zap.Error(err),
+			zap.String("action", e.Action),
+			zap.String("user_id", e.UserID),
+			zap.String("entity_type", e.EntityType),
+			zap.String("entity_id", e.EntityID),
+			zap.String("ip", e.IP),
+			zap.String("user_agent", e.UserAgent),
+			zap.ByteString("details", e.Details),
+			zap.Time("created_at", e.CreatedAt),
 		)
+	}
+}
 
-		if err != nil {
-			logger.Error("Failed to write audit log", zap.Error(err))
+func newAuditEntry(action, userID, entityID, entityType, ip, userAgent string, details interface{}) auditEntry {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		logger.Error("Failed to marshal audit details", zap.String("action", action), zap.Error(err))
+		raw = []byte("{}")
+	}
+	canonical, err := canonicalJSON(raw)
+	if err != nil {
+		canonical = []byte("{}")
+	}
+	return auditEntry{
+		Action:     action,
+		UserID:     auditUUID(action, "user_id", userID),
+		EntityID:   auditUUID(action, "entity_id", entityID),
+		EntityType: entityType,
+		IP:         ip,
+		UserAgent:  userAgent,
+		Details:    canonical,
+		// Postgres stores microseconds; truncate so the hash matches on read.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// auditUUID returns id in Postgres' canonical UUID form. An invalid id is
+// dropped with a warning rather than failing the whole batch it is written in.
+func auditUUID(action, field, id string) string {
+	if id == "" {
+		return ""
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		logger.Warn("Dropping invalid UUID from audit entry",
+			zap.String("action", action), zap.String("field", field), zap.String("value", id))
+		return ""
+	}
+	return parsed.String()
+}
+
+// insertAuditBatch appends entries to the chain in one transaction. The
+// advisory lock serialises writers across instances so seq has no gaps.
+func insertAuditBatch(ctx context.Context, entries []auditEntry) error {
+	return pgx.BeginFunc(ctx, database.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('audit_chain'))`); err != nil {
+			return err
+		}
+
+		var seq int64
+		var prevHash string
+		err := tx.QueryRow(ctx,
+			`SELECT seq, hash FROM audit_logs WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1`,
+		).Scan(&seq, &prevHash)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for _, e := range entries {
+			seq++
+			digest := auditContentDigest(e.IP, e.UserAgent, e.Details)
+			entryHash := auditChainHash(seq, prevHash, e.CreatedAt, e.Action, e.UserID, e.EntityType, e.EntityID, digest)
+			batch.Queue(
+				`INSERT INTO audit_logs (seq, prev_hash, hash, content_digest, user_id, action, entity_type, entity_id, ip_address, user_agent, details, created_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+				seq, prevHash, entryHash, digest, parseUUID(e.UserID), e.Action, e.EntityType, parseUUID(e.EntityID),
+				e.IP, e.UserAgent, e.Details, e.CreatedAt,
+			)
+			prevHash = entryHash
 		}
-	}()
+		return tx.SendBatch(ctx, batch).Close()
+	})
 }
 
 // Helper to handle empty/invalid UUID strings gracefully
@@ -47,3 +250,287 @@ func parseUUID(id string) *string {
 	}
 	return &id
 }
+
+// canonicalJSON re-encodes raw so that equal documents have equal bytes
+// whether they come from json.Marshal or back out of a JSONB column: object
+// keys are sorted and numbers are normalised through float64.
+func canonicalJSON(raw []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// auditContentDigest covers the parts of an entry that retention and erasure
+// may clear later. It is chained through auditChainHash instead of the
+// content itself, so clearing the content does not break the chain.
+func auditContentDigest(ip, userAgent string, details []byte) string {
+	h := sha256.New()
+	writeHashFields(h, ip, userAgent, string(details))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// auditChainHash is an entry's hash: it covers the previous entry's hash, so
+// changing, removing or reordering any entry breaks every later link.
+func auditChainHash(seq int64, prevHash string, createdAt time.Time, action, userID, entityType, entityID, contentDigest string) string {
+	h := sha256.New()
+	writeHashFields(h,
+		fmt.Sprint(seq), prevHash, createdAt.UTC().Format(time.RFC3339Nano),
+		action, userID, entityType, entityID, contentDigest,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashFields length-prefixes each field so that no two field lists
+// produce the same input.
+func writeHashFields(h hash.Hash, fields ...string) {
+	for _, f := range fields {
+		fmt.Fprintf(h, "%d:%s;", len(f), f)
+	}
+}
+
+// AuditChainProblem is one inconsistency found by VerifyAuditChain.
+type AuditChainProblem struct {
+	Seq     int64  `json:"seq"`
+	Problem string `json:"problem"`
+}
+
+// AuditChainReport is the result of VerifyAuditChain. LastSeq and LastHash
+// identify the chain head; recording them elsewhere lets a later run detect
+// entries removed from the end, which the chain alone cannot.
+type AuditChainReport struct {
+	Checked  int64               `json:"checked"`
+	Redacted int64               `json:"redacted"`
+	LastSeq  int64               `json:"last_seq"`
+	LastHash string              `json:"last_hash"`
+	Problems []AuditChainProblem `json:"problems"`
+}
+
+// maxAuditChainProblems stops a badly damaged chain from flooding the report.
+const maxAuditChainProblems = 100
+
+// VerifyAuditChain recomputes the hash chain from seq fromSeq (1 for the
+// whole log) and reports gaps, broken links, altered entries and, for entries
+// not marked redacted, altered content.
+func VerifyAuditChain(ctx context.Context, fromSeq int64) (AuditChainReport, error) {
+	report := AuditChainReport{Problems: []AuditChainProblem{}}
+	if fromSeq < 1 {
+		fromSeq = 1
+	}
+
+	// Start one entry early so the first checked entry's link is verified.
+	rows, err := database.Pool.Query(ctx,
+		`SELECT seq, COALESCE(prev_hash, ''), COALESCE(hash, ''), COALESCE(content_digest, ''), created_at,
+		        action, COALESCE(user_id::text, ''), COALESCE(entity_type, ''), COALESCE(entity_id::text, ''),
+		        COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(details::text, 'null'),
+		        redacted_at IS NOT NULL
+		 FROM audit_logs
+		 WHERE seq >= $1
+		 ORDER BY seq`,
+		fromSeq-1,
+	)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	problem := func(seq int64, format string, args ...interface{}) {
+		if len(report.Problems) < maxAuditChainProblems {
+			report.Problems = append(report.Problems, AuditChainProblem{Seq: seq, Problem: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	var prevSeq int64
+	var prevHash string
+	first := true
+	for rows.Next() {
+		var (
+			seq                                              int64
+			storedPrev, storedHash, storedDigest             string
+			createdAt                                        time.Time
+			action, userID, entityType, entityID, ip, ua, dt string
+			redacted                                         bool
+		)
+		if err := rows.Scan(&seq, &storedPrev, &storedHash, &storedDigest, &createdAt,
+			&action, &userID, &entityType, &entityID, &ip, &ua, &dt, &redacted); err != nil {
+			return report, err
+		}
+
+		anchor := first && seq < fromSeq
+		switch {
+		case first && seq == 1 && storedPrev != "":
+			problem(seq, "first entry has a previous hash")
+		case first && !anchor && seq > 1:
+			problem(seq, "entries before seq %d are missing", seq)
+		case !first && seq != prevSeq+1:
+			problem(seq, "entries %d to %d are missing", prevSeq+1, seq-1)
+		case !first && storedPrev != prevHash:
+			problem(seq, "previous hash does not match entry %d", prevSeq)
+		}
+
+		if !redacted {
+			details, err := canonicalJSON([]byte(dt))
+			if err != nil || auditContentDigest(ip, ua, details) != storedDigest {
+				problem(seq, "content does not match its digest")
+			}
+		}
+		if auditChainHash(seq, storedPrev, createdAt, action, userID, entityType, entityID, storedDigest) != storedHash {
+			problem(seq, "hash does not match entry")
+		}
+
+		if !anchor {
+			report.Checked++
+			if redacted {
+				report.Redacted++
+			}
+		}
+		first = false
+		prevSeq, prevHash = seq, storedHash
+		report.LastSeq, report.LastHash = seq, storedHash
+	}
+	return report, rows.Err()
+}
+
+// AuditFilter selects audit log entries for ListAuditLogs. Zero fields are
+// not filtered on.
+type AuditFilter struct {
+	UserID     string
+	EntityType string
+	EntityID   string
+	Action     string // exact match, or a prefix ending in "." such as "booking."
+	From       *time.Time
+	To         *time.Time
+}
+
+// auditConditions returns the WHERE clause selecting f's entries and its
+// arguments.
+func auditConditions(f AuditFilter) (string, []interface{}) {
+	conditions := make([]string, 0, 6)
+	args := make([]interface{}, 0, 8)
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.UserID != "" {
+		add("user_id = $%d", f.UserID)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if strings.HasSuffix(f.Action, ".") {
+		add("starts_with(action, $%d)", f.Action)
+	} else if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+const auditLogColumns = `id, seq, user_id, action, COALESCE(entity_type, ''), entity_id,
+		       COALESCE(ip_address, ''), COALESCE(user_agent, ''), details, hash, created_at, redacted_at`
+
+func scanAuditLog(row pgx.Row, extra ...any) (models.AuditLog, error) {
+	var e models.AuditLog
+	var details []byte
+	dest := append([]any{&e.ID, &e.Seq, &e.UserID, &e.Action, &e.EntityType, &e.EntityID,
+		&e.IPAddress, &e.UserAgent, &details, &e.Hash, &e.CreatedAt, &e.RedactedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return e, err
+	}
+	if len(details) > 0 && string(details) != "null" {
+		e.Details = details
+	}
+	return e, nil
+}
+
+// AuditLogPage is how audit log lists page: newest first by default.
+var AuditLogPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 50,
+	MaxLimit:     200,
+	Fields:       pagination.JSONFields(models.AuditLog{}),
+}
+
+// ListAuditLogs returns the page p of entries matching f.
+func ListAuditLogs(ctx context.Context, f AuditFilter, p pagination.Params) (pagination.Page[models.AuditLog], error) {
+	whereClause, args := auditConditions(f)
+	after, afterArgs := p.Where(len(args) + 1)
+	args = append(args, afterArgs...)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT `+auditLogColumns+`, %s
+		FROM audit_logs
+		WHERE %s AND %s
+		ORDER BY %s
+		LIMIT $%d`, p.Select(), whereClause, after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.AuditLog]{}, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditLog, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var pos pagination.Position
+		e, err := scanAuditLog(rows, &pos.Value, &pos.Key)
+		if err != nil {
+			return pagination.Page[models.AuditLog]{}, err
+		}
+		entries = append(entries, e)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.AuditLog]{}, err
+	}
+	return pagination.NewPage(p, entries, positions), nil
+}
+
+// StreamAuditLogs calls fn for every entry matching f, newest first. Rows
+// are read from the database as fn consumes them, so the whole log is never
+// held in memory. An error from fn stops the stream and is returned.
+func StreamAuditLogs(ctx context.Context, f AuditFilter, fn func(models.AuditLog) error) error {
+	whereClause, args := auditConditions(f)
+	rows, err := database.Pool.Query(ctx, `
+		SELECT `+auditLogColumns+`
+		FROM audit_logs
+		WHERE `+whereClause+`
+		ORDER BY created_at DESC, seq DESC NULLS LAST`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditLog(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
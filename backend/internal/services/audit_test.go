@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"key order", `{"b":1,"a":"x"}`, `{"a": "x", "b": 1}`},
+		{"number form", `{"n":100}`, `{"n":1e2}`},
+		{"trailing zeros", `{"n":1.5}`, `{"n":1.50}`},
+		{"null", `null`, ` null `},
+	}
+	for _, tt := range tests {
+		a, err := canonicalJSON([]byte(tt.a))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		b, err := canonicalJSON([]byte(tt.b))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(a) != string(b) {
+			t.Fatalf("%s: %s != %s", tt.name, a, b)
+		}
+	}
+}
+
+func TestAuditChainHash(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC)
+	digest := auditContentDigest("203.0.113.7", "curl/8", []byte(`{"a":1}`))
+	base := auditChainHash(2, "prev", at, "booking.cancel", "u1", "booking", "e1", digest)
+
+	if got := auditChainHash(2, "prev", at.In(time.FixedZone("IST", 19800)), "booking.cancel", "u1", "booking", "e1", digest); got != base {
+		t.Fatalf("hash depends on the time zone it is read in")
+	}
+
+	changed := []string{
+		auditChainHash(3, "prev", at, "booking.cancel", "u1", "booking", "e1", digest),
+		auditChainHash(2, "other", at, "booking.cancel", "u1", "booking", "e1", digest),
+		auditChainHash(2, "prev", at.Add(time.Microsecond), "booking.cancel", "u1", "booking", "e1", digest),
+		auditChainHash(2, "prev", at, "booking.create", "u1", "booking", "e1", digest),
+		auditChainHash(2, "prev", at, "booking.cancel", "u1", "booking", "e2", digest),
+		// Field boundaries are unambiguous.
+		auditChainHash(2, "prev", at, "booking.cancel", "u1b", "ooking", "e1", digest),
+		auditChainHash(2, "prev", at, "booking.cancel", "u1", "booking", "e1",
+			auditContentDigest("203.0.113.8", "curl/8", []byte(`{"a":1}`))),
+	}
+	for i, h := range changed {
+		if h == base {
+			t.Fatalf("change %d did not alter the hash", i)
+		}
+	}
+}
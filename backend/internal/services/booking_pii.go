@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/jackc/pgx/v5"
+)
+
+// Field names bound into booking ciphertexts and blind indexes.
+const (
+	bookingNameField  = "bookings.name"
+	bookingEmailField = "bookings.email"
+)
+
+// ErrPIIKeysMissing is returned when stored data is encrypted but no keys are
+// configured to read it.
+var ErrPIIKeysMissing = errors.New("booking data is encrypted but PII_ENCRYPTION_KEYS is not set")
+
+// BookingPII is a booking's name and email in stored form.
+type BookingPII struct {
+	Name       string
+	Email      string
+	NameIndex  *string // nil while encryption is disabled
+	EmailIndex *string
+}
+
+// SealBookingPII prepares name and email for storage: encrypted with blind
+// indexes when PII encryption is enabled, unchanged otherwise.
+func SealBookingPII(name, email string) (BookingPII, error) {
+	k := pii.Default()
+	if k == nil {
+		return BookingPII{Name: name, Email: email}, nil
+	}
+	return sealBookingPII(k, name, email)
+}
+
+func sealBookingPII(k *pii.Keyring, name, email string) (BookingPII, error) {
+	sealedName, err := k.Encrypt(bookingNameField, name)
+	if err != nil {
+		return BookingPII{}, err
+	}
+	sealedEmail, err := k.Encrypt(bookingEmailField, email)
+	if err != nil {
+		return BookingPII{}, err
+	}
+	nameIndex := k.BlindIndex(bookingNameField, normalizeBookingName(name))
+	emailIndex := k.BlindIndex(bookingEmailField, NormalizeEmailAddress(email))
+	return BookingPII{Name: sealedName, Email: sealedEmail, NameIndex: &nameIndex, EmailIndex: &emailIndex}, nil
+}
+
+// OpenBookingPII decrypts a stored booking name and email in place; either
+// may be nil when only one was read. Plaintext values (rows not yet migrated,
+// or redacted placeholders) are left as they are.
+func OpenBookingPII(name, email *string) error {
+	if !isSealed(name) && !isSealed(email) {
+		return nil
+	}
+	k := pii.Default()
+	if k == nil {
+		return ErrPIIKeysMissing
+	}
+	return openBookingPII(k, name, email)
+}
+
+func isSealed(value *string) bool {
+	return value != nil && strings.HasPrefix(*value, pii.Prefix)
+}
+
+func openBookingPII(k *pii.Keyring, name, email *string) error {
+	for _, f := range []struct {
+		field string
+		value *string
+	}{{bookingNameField, name}, {bookingEmailField, email}} {
+		if f.value == nil {
+			continue
+		}
+		plain, err := k.Decrypt(f.field, *f.value)
+		if err != nil {
+			return err
+		}
+		*f.value = plain
+	}
+	return nil
+}
+
+// normalizeBookingName folds case and whitespace so index lookups match the
+// way people type names.
+func normalizeBookingName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// BookingSearchCondition returns a WHERE condition matching bookings by
+// search, with placeholders numbered from argIndex, and its arguments. With
+// PII encryption enabled, names and emails match exactly (case-insensitive)
+// through their blind indexes; booking and user IDs, and rows not yet
+// encrypted, still match on substrings.
+func BookingSearchCondition(search string, argIndex int) (string, []interface{}) {
+	search = strings.TrimSpace(search)
+	like := "%" + strings.ToLower(search) + "%"
+
+	k := pii.Default()
+	if k == nil {
+		return fmt.Sprintf(`(LOWER(email) LIKE $%[1]d OR LOWER(name) LIKE $%[1]d OR id::text ILIKE $%[1]d OR COALESCE(user_id::text, '') ILIKE $%[1]d)`, argIndex),
+			[]interface{}{like}
+	}
+	return fmt.Sprintf(`(email_bidx = $%[1]d OR name_bidx = $%[2]d
+			OR id::text ILIKE $%[3]d OR COALESCE(user_id::text, '') ILIKE $%[3]d
+			OR (NOT starts_with(email, '%[4]s') AND (LOWER(email) LIKE $%[3]d OR LOWER(name) LIKE $%[3]d)))`,
+			argIndex, argIndex+1, argIndex+2, pii.Prefix),
+		[]interface{}{
+			k.BlindIndex(bookingEmailField, NormalizeEmailAddress(search)),
+			k.BlindIndex(bookingNameField, normalizeBookingName(search)),
+			like,
+		}
+}
+
+// BookingPIIStatus counts bookings by how their name and email are stored.
+type BookingPIIStatus struct {
+	Plaintext  int64 `json:"plaintext"`
+	CurrentKey int64 `json:"current_key"`
+	OldKey     int64 `json:"old_key"`
+}
+
+// CountBookingPII reports how far encryption (or key rotation) has progressed.
+func CountBookingPII(ctx context.Context) (BookingPIIStatus, error) {
+	var s BookingPIIStatus
+	current := pii.Prefix + ":" // matches nothing while encryption is disabled
+	if k := pii.Default(); k != nil {
+		current = k.CurrentPrefix()
+	}
+	err := database.Pool.QueryRow(ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE NOT starts_with(name, $1) OR NOT starts_with(email, $1)),
+			COUNT(*) FILTER (WHERE starts_with(name, $2) AND starts_with(email, $2)),
+			COUNT(*) FILTER (WHERE starts_with(name, $1) AND starts_with(email, $1)
+				AND NOT (starts_with(name, $2) AND starts_with(email, $2)))
+		 FROM bookings`,
+		pii.Prefix, current,
+	).Scan(&s.Plaintext, &s.CurrentKey, &s.OldKey)
+	return s, err
+}
+
+// EncryptBookings encrypts every booking not yet sealed with the current key
+// (plaintext rows and rows under a rotated-out key), batchSize rows per
+// transaction, and returns how many it changed. It is safe to run while the
+// API is serving traffic and to resume after an interruption.
+func EncryptBookings(ctx context.Context, batchSize int) (int64, error) {
+	k := pii.Default()
+	if k == nil {
+		return 0, errors.New("PII encryption is not configured: set PII_ENCRYPTION_KEYS and PII_BLIND_INDEX_KEY")
+	}
+	return migrateBookingPII(ctx, batchSize,
+		`NOT (starts_with(name, $1) AND starts_with(email, $1))`, k.CurrentPrefix(),
+		func(name, email string) (BookingPII, error) {
+			if err := openBookingPII(k, &name, &email); err != nil {
+				return BookingPII{}, err
+			}
+			return sealBookingPII(k, name, email)
+		},
+	)
+}
+
+// DecryptBookings reverses EncryptBookings, for turning encryption off. The
+// keys must still be configured.
+func DecryptBookings(ctx context.Context, batchSize int) (int64, error) {
+	k := pii.Default()
+	if k == nil {
+		return 0, ErrPIIKeysMissing
+	}
+	return migrateBookingPII(ctx, batchSize,
+		`starts_with(name, $1) OR starts_with(email, $1)`, pii.Prefix,
+		func(name, email string) (BookingPII, error) {
+			err := openBookingPII(k, &name, &email)
+			return BookingPII{Name: name, Email: email}, err
+		},
+	)
+}
+
+// migrateBookingPII rewrites the name and email of bookings matching match
+// ($1 is prefix) through convert, one batch per transaction. Rows locked by
+// live requests are skipped; running the command again picks them up.
+func migrateBookingPII(ctx context.Context, batchSize int, match, prefix string, convert func(name, email string) (BookingPII, error)) (int64, error) {
+	var total int64
+	for {
+		var n int
+		err := pgx.BeginFunc(ctx, database.Pool, func(tx pgx.Tx) error {
+			rows, err := tx.Query(ctx,
+				`SELECT id, name, email FROM bookings WHERE `+match+` LIMIT $2 FOR UPDATE SKIP LOCKED`,
+				prefix, batchSize,
+			)
+			if err != nil {
+				return err
+			}
+			type row struct{ id, name, email string }
+			batch, err := pgx.CollectRows(rows, func(r pgx.CollectableRow) (row, error) {
+				var b row
+				err := r.Scan(&b.id, &b.name, &b.email)
+				return b, err
+			})
+			if err != nil {
+				return err
+			}
+
+			updates := &pgx.Batch{}
+			for _, b := range batch {
+				stored, err := convert(b.name, b.email)
+				if err != nil {
+					return fmt.Errorf("booking %s: %w", b.id, err)
+				}
+				updates.Queue(
+					`UPDATE bookings SET name = $2, email = $3, name_bidx = $4, email_bidx = $5 WHERE id = $1`,
+					b.id, stored.Name, stored.Email, stored.NameIndex, stored.EmailIndex,
+				)
+			}
+			n = len(batch)
+			if n == 0 {
+				return nil
+			}
+			return tx.SendBatch(ctx, updates).Close()
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(n)
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// turnstileVerifyURL is Cloudflare Turnstile's server-side verification endpoint.
+const turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+var captchaHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// CaptchaEnabled reports whether booking creation requires a CAPTCHA token.
+func CaptchaEnabled() bool {
+	return os.Getenv("TURNSTILE_SECRET_KEY") != ""
+}
+
+// VerifyCaptcha checks a Turnstile token with Cloudflare. It returns nil when
+// CAPTCHA is not configured, apperror.CaptchaFailed for a missing or rejected
+// token, and an external-service error when Cloudflare cannot be reached.
+func VerifyCaptcha(ctx context.Context, token, remoteIP string) error {
+	secret := os.Getenv("TURNSTILE_SECRET_KEY")
+	if secret == "" {
+		return nil
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return apperror.CaptchaFailed()
+	}
+
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, turnstileVerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return apperror.InternalError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := captchaHTTPClient.Do(req)
+	if err != nil {
+		return apperror.ExternalServiceError("captcha", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return apperror.ExternalServiceError("captcha", fmt.Errorf("siteverify returned %d", resp.StatusCode))
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return apperror.ExternalServiceError("captcha", err)
+	}
+	if !result.Success {
+		logger.Warn("CAPTCHA verification rejected", zap.Strings("error_codes", result.ErrorCodes))
+		return apperror.CaptchaFailed()
+	}
+	return nil
+}
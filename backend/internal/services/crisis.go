@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Sources of screened text (crisis_events.source).
+const (
+	CrisisSourceBookingNotes = "booking_notes"
+	CrisisSourceIntake       = "intake"
+	CrisisSourceFeedback     = "feedback"
+)
+
+// crisisExcerptField is bound into crisis excerpt ciphertexts.
+const crisisExcerptField = "crisis_events.excerpt"
+
+const maxCrisisExcerptLength = 4000
+
+// crisisSupportMessage is shown with the helplines. It is a catalog key.
+const crisisSupportMessage = "If you are thinking about harming yourself or are in danger, please reach out to one of these services now. You don't have to go through this alone."
+
+var (
+	// ErrCrisisEventNotFound is returned for unknown crisis event IDs.
+	ErrCrisisEventNotFound = errors.New("crisis event not found")
+	// ErrCrisisEventAcknowledged is returned when acknowledging twice.
+	ErrCrisisEventAcknowledged = errors.New("crisis event already acknowledged")
+)
+
+// helplines lists crisis services by ISO country code. "" is the fallback
+// for every other region.
+var helplines = map[string][]models.Helpline{
+	"IN": {
+		{Name: "Tele-MANAS", Phone: "14416", URL: "https://telemanas.mohfw.gov.in", Hours: "24x7"},
+		{Name: "KIRAN Mental Health Helpline", Phone: "1800-599-0019", Hours: "24x7"},
+		{Name: "Emergency services", Phone: "112"},
+	},
+	"BD": {
+		{Name: "Kaan Pete Roi", Phone: "+880 9612-119911", URL: "https://shuni.org"},
+		{Name: "Emergency services", Phone: "999"},
+	},
+	"US": {
+		{Name: "988 Suicide & Crisis Lifeline", Phone: "988", URL: "https://988lifeline.org", Hours: "24/7"},
+		{Name: "Emergency services", Phone: "911"},
+	},
+	"GB": {
+		{Name: "Samaritans", Phone: "116 123", URL: "https://www.samaritans.org", Hours: "24/7"},
+		{Name: "Emergency services", Phone: "999"},
+	},
+	"": {
+		{Name: "Find A Helpline", URL: "https://findahelpline.com"},
+		{Name: "Local emergency services"},
+	},
+}
+
+// CrisisConfig controls crisis screening.
+type CrisisConfig struct {
+	Screener      Screener // nil uses the keyword rules
+	AlertEmails   []string // on-call addresses; empty alerts every role holding crisis:read
+	DefaultRegion string   // ISO country code used when the request has none
+}
+
+var (
+	crisisConfigMu sync.RWMutex
+	crisisConfig   = CrisisConfig{
+		Screener:      NewKeywordScreener(DefaultCrisisRules()),
+		DefaultRegion: "IN",
+	}
+)
+
+// SetCrisisConfig sets process-wide crisis screening. Call once at startup.
+func SetCrisisConfig(cfg CrisisConfig) {
+	if cfg.Screener == nil {
+		cfg.Screener = NewKeywordScreener(DefaultCrisisRules())
+	}
+	cfg.DefaultRegion = strings.ToUpper(strings.TrimSpace(cfg.DefaultRegion))
+	if cfg.DefaultRegion == "" {
+		cfg.DefaultRegion = "IN"
+	}
+
+	crisisConfigMu.Lock()
+	defer crisisConfigMu.Unlock()
+	crisisConfig = CrisisConfig{
+		Screener:      cfg.Screener,
+		AlertEmails:   append([]string{}, cfg.AlertEmails...),
+		DefaultRegion: cfg.DefaultRegion,
+	}
+}
+
+func getCrisisConfig() CrisisConfig {
+	crisisConfigMu.RLock()
+	defer crisisConfigMu.RUnlock()
+	return crisisConfig
+}
+
+// CrisisScreeningInput is one submission to screen.
+type CrisisScreeningInput struct {
+	Source    string
+	UserID    string
+	BookingID string
+	Region    string            // ISO country code of the client, if known
+	Texts     map[string]string // free text by field name
+}
+
+// ScreenForCrisis screens the submitted texts. When a rule matches it
+// records a crisis event, alerts on-call staff in the background and returns
+// helplines for the client's region; otherwise it returns nil. Screening
+// never fails a submission: errors are logged and the text is let through.
+func ScreenForCrisis(ctx context.Context, in CrisisScreeningInput) *models.CrisisSupport {
+	cfg := getCrisisConfig()
+
+	fields := make([]string, 0, len(in.Texts))
+	for field, text := range in.Texts {
+		if strings.TrimSpace(text) != "" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var (
+		result  ScreeningResult
+		excerpt strings.Builder
+	)
+	for _, field := range fields {
+		found, err := cfg.Screener.Screen(ctx, in.Texts[field])
+		if err != nil {
+			logger.Error("Crisis screening failed", zap.String("source", in.Source), zap.String("field", field), zap.Error(err))
+			continue
+		}
+		if !found.Flagged {
+			continue
+		}
+		result.Flagged = true
+		result.Severity = higherSeverity(result.Severity, found.Severity)
+		result.Rules = mergeRules(result.Rules, found.Rules)
+		fmt.Fprintf(&excerpt, "%s: %s\n", field, strings.TrimSpace(in.Texts[field]))
+	}
+	if !result.Flagged {
+		return nil
+	}
+
+	region, lines := HelplinesFor(in.Region, cfg.DefaultRegion)
+	support := &models.CrisisSupport{
+		Message:   i18n.T(i18n.FromContext(ctx), crisisSupportMessage),
+		Region:    region,
+		Helplines: lines,
+	}
+
+	event := models.CrisisEvent{
+		UserID:    parseUUID(in.UserID),
+		BookingID: parseUUID(in.BookingID),
+		Source:    in.Source,
+		Severity:  result.Severity,
+		Rules:     result.Rules,
+		Excerpt:   truncateRunes(excerpt.String(), maxCrisisExcerptLength),
+		Region:    region,
+	}
+	if err := recordCrisisEvent(ctx, &event); err != nil {
+		logger.Error("Failed to record crisis event", zap.String("source", in.Source), zap.String("severity", result.Severity), zap.Error(err))
+	}
+	logger.Warn("Crisis signal detected",
+		zap.String("event_id", event.ID),
+		zap.String("source", in.Source),
+		zap.String("severity", result.Severity),
+		zap.Strings("rules", result.Rules),
+	)
+
+	go alertCrisisEvent(event, cfg.AlertEmails)
+	return support
+}
+
+// HelplinesFor returns the helplines for an ISO country code, falling back to
+// defaultRegion and then to international resources. The returned region is
+// the code whose list was used ("" for international).
+func HelplinesFor(region, defaultRegion string) (string, []models.Helpline) {
+	for _, code := range []string{region, defaultRegion} {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if lines, ok := helplines[code]; ok && code != "" {
+			return code, append([]models.Helpline{}, lines...)
+		}
+	}
+	return "", append([]models.Helpline{}, helplines[""]...)
+}
+
+func mergeRules(rules, more []string) []string {
+	for _, rule := range more {
+		if !containsString(rules, rule) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+func recordCrisisEvent(ctx context.Context, event *models.CrisisEvent) error {
+	excerpt := event.Excerpt
+	if k := pii.Default(); k != nil {
+		sealed, err := k.Encrypt(crisisExcerptField, excerpt)
+		if err != nil {
+			return err
+		}
+		excerpt = sealed
+	}
+
+	// Detached so a cancelled request cannot lose the record
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dbQueryTimeout)
+	defer cancel()
+
+	return database.Pool.QueryRow(writeCtx,
+		`INSERT INTO crisis_events (user_id, booking_id, source, severity, rules, excerpt, region)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, created_at`,
+		event.UserID, event.BookingID, event.Source, event.Severity, event.Rules, excerpt, event.Region,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// alertCrisisEvent emails on-call staff. The alert says where to look, not
+// what was written: the text stays in the restricted log.
+func alertCrisisEvent(event models.CrisisEvent, recipients []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if len(recipients) == 0 {
+		var err error
+		if recipients, err = crisisResponderEmails(ctx); err != nil {
+			logger.Error("Failed to look up crisis alert recipients", zap.String("event_id", event.ID), zap.Error(err))
+			return
+		}
+	}
+	if len(recipients) == 0 {
+		logger.Error("No crisis alert recipients configured", zap.String("event_id", event.ID))
+		return
+	}
+
+	booking := "-"
+	if event.BookingID != nil {
+		booking = *event.BookingID
+	}
+	body := fmt.Sprintf(`<p>A <strong>%s</strong> crisis signal was detected in %s.</p>
+<p>Event: %s<br>Booking: %s<br>Rules: %s<br>Detected: %s</p>
+<p>Review and acknowledge it in the admin crisis log. The client was shown helplines for region %s.</p>`,
+		html.EscapeString(event.Severity), html.EscapeString(event.Source),
+		html.EscapeString(event.ID), html.EscapeString(booking),
+		html.EscapeString(strings.Join(event.Rules, ", ")), event.CreatedAt.UTC().Format(time.RFC3339),
+		html.EscapeString(event.Region),
+	)
+
+	sent := 0
+	for _, to := range recipients {
+		if err := DeliverEmail(OutgoingEmail{
+			To:       to,
+			Subject:  fmt.Sprintf("[Urgent] Crisis signal (%s) - Hidden Depths", event.Severity),
+			HTML:     body,
+			Category: EmailTransactional,
+		}); err != nil {
+			logger.Error("Failed to send crisis alert", zap.String("event_id", event.ID), zap.String("to", to), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+	if sent == 0 || event.ID == "" {
+		return
+	}
+	if _, err := database.Pool.Exec(ctx, `UPDATE crisis_events SET alerted_at = NOW() WHERE id = $1`, event.ID); err != nil {
+		logger.Warn("Failed to mark crisis event alerted", zap.String("event_id", event.ID), zap.Error(err))
+	}
+}
+
+// crisisResponderEmails returns the addresses of everyone whose role grants
+// crisis:read.
+func crisisResponderEmails(ctx context.Context) ([]string, error) {
+	roles := []string{}
+	for _, role := range rbac.Roles() {
+		if rbac.Allows([]rbac.Role{role}, rbac.PermCrisisRead) {
+			roles = append(roles, string(role))
+		}
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT DISTINCT email FROM user_roles WHERE role = ANY($1) AND COALESCE(email, '') <> ''`,
+		roles,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// CrisisEventFilter selects crisis events. Status is "open", "acknowledged"
+// or "" for both.
+type CrisisEventFilter struct {
+	Status string
+}
+
+// CrisisEventPage is how crisis event lists page: newest first by default.
+var CrisisEventPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.CrisisEvent{}),
+}
+
+// ListCrisisEvents returns the page p of crisis events matching the filter,
+// with decrypted excerpts.
+func ListCrisisEvents(ctx context.Context, filter CrisisEventFilter, p pagination.Params) (pagination.Page[models.CrisisEvent], error) {
+	after, args := p.Where(2)
+	args = append([]any{filter.Status}, args...)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, user_id::text, booking_id::text, source, severity, rules, excerpt, region, alerted_at,
+		        acknowledged_by::text, acknowledged_at, acknowledgement_note, created_at, %s
+		 FROM crisis_events
+		 WHERE ($1 = '' OR ($1 = 'open') = (acknowledged_at IS NULL)) AND %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.CrisisEvent]{}, err
+	}
+	defer rows.Close()
+
+	events := make([]models.CrisisEvent, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var pos pagination.Position
+		event, err := scanCrisisEvent(rows, &pos.Value, &pos.Key)
+		if err != nil {
+			return pagination.Page[models.CrisisEvent]{}, err
+		}
+		events = append(events, event)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.CrisisEvent]{}, err
+	}
+	return pagination.NewPage(p, events, positions), nil
+}
+
+// AcknowledgeCrisisEvent records that a staff member followed up an event.
+func AcknowledgeCrisisEvent(ctx context.Context, id, adminID, note string) (models.CrisisEvent, error) {
+	event, err := scanCrisisEvent(database.Pool.QueryRow(ctx,
+		`UPDATE crisis_events
+		 SET acknowledged_by = $2, acknowledged_at = NOW(), acknowledgement_note = $3
+		 WHERE id = $1 AND acknowledged_at IS NULL
+		 RETURNING id, user_id::text, booking_id::text, source, severity, rules, excerpt, region, alerted_at,
+		           acknowledged_by::text, acknowledged_at, acknowledgement_note, created_at`,
+		id, parseUUID(adminID), nilIfBlank(note),
+	))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return event, err
+	}
+
+	var exists bool
+	if err := database.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM crisis_events WHERE id = $1)`, id,
+	).Scan(&exists); err != nil {
+		return event, err
+	}
+	if exists {
+		return event, ErrCrisisEventAcknowledged
+	}
+	return event, ErrCrisisEventNotFound
+}
+
+// scanCrisisEvent reads the crisis event columns, then extra, from row and
+// decrypts the excerpt.
+func scanCrisisEvent(row pgx.Row, extra ...interface{}) (models.CrisisEvent, error) {
+	var event models.CrisisEvent
+	targets := append([]interface{}{
+		&event.ID, &event.UserID, &event.BookingID, &event.Source, &event.Severity, &event.Rules, &event.Excerpt,
+		&event.Region, &event.AlertedAt, &event.AcknowledgedBy, &event.AcknowledgedAt, &event.AcknowledgementNote,
+		&event.CreatedAt,
+	}, extra...)
+	if err := row.Scan(targets...); err != nil {
+		return event, err
+	}
+	if isSealed(&event.Excerpt) {
+		k := pii.Default()
+		if k == nil {
+			return event, ErrPIIKeysMissing
+		}
+		opened, err := k.Decrypt(crisisExcerptField, event.Excerpt)
+		if err != nil {
+			return event, err
+		}
+		event.Excerpt = opened
+	}
+	return event, nil
+}
@@ -23,7 +23,18 @@ var EmailBreaker = circuitbreaker.New("email-smtp", circuitbreaker.Config{
 	OpenTimeout:      60 * time.Second,
 })
 
-func SendEmail(to, subject, body string) error {
+// OutgoingEmail is a rendered message handed to a delivery provider.
+type OutgoingEmail struct {
+	To       string
+	Subject  string
+	HTML     string
+	Text     string // generated from HTML when empty
+	Headers  map[string]string
+	Category EmailCategory
+}
+
+// SendEmail delivers a message over SMTP with retry and circuit breaker.
+func SendEmail(msg OutgoingEmail) error {
 	smtpHost := os.Getenv("SMTP_HOST")
 	smtpPortStr := os.Getenv("SMTP_PORT")
 	smtpUser := os.Getenv("SMTP_USER")
@@ -42,6 +53,10 @@ func SendEmail(to, subject, body string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if err := prepareOutgoingEmail(ctx, &msg); err != nil {
+		return err
+	}
+
 	return retry.Do(ctx, retry.Config{
 		MaxAttempts: 3,
 		BaseDelay:   500 * time.Millisecond,
@@ -51,16 +66,20 @@ func SendEmail(to, subject, body string) error {
 		return EmailBreaker.Execute(func() error {
 			m := gomail.NewMessage()
 			m.SetHeader("From", smtpUser)
-			m.SetHeader("To", to)
-			m.SetHeader("Subject", subject)
-			m.SetBody("text/html", body)
+			m.SetHeader("To", msg.To)
+			m.SetHeader("Subject", msg.Subject)
+			for name, value := range msg.Headers {
+				m.SetHeader(name, value)
+			}
+			m.SetBody("text/plain", msg.Text)
+			m.AddAlternative("text/html", msg.HTML)
 
 			d := gomail.NewDialer(smtpHost, smtpPort, smtpUser, smtpPass)
 			d.TLSConfig = &tls.Config{InsecureSkipVerify: false}
 
 			if err := d.DialAndSend(m); err != nil {
 				logger.Log.Warn("Email send attempt failed",
-					zap.String("to", to),
+					zap.String("to", msg.To),
 					zap.Error(err),
 				)
 				// Wrap as retryable external service error
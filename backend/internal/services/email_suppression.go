@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// EmailCategory tells delivery whether a recipient may opt out of a message.
+type EmailCategory string
+
+const (
+	// EmailTransactional is mail the recipient needs regardless of preferences
+	// (confirmations, cancellations). Only bounces and complaints block it.
+	EmailTransactional EmailCategory = "transactional"
+	// EmailNotification is optional mail (reminders). It carries List-Unsubscribe
+	// headers and is also blocked by unsubscribes.
+	EmailNotification EmailCategory = "notification"
+)
+
+// Suppression reasons stored in email_suppressions.reason.
+const (
+	SuppressionBounce      = "bounce"
+	SuppressionComplaint   = "complaint"
+	SuppressionUnsubscribe = "unsubscribe"
+)
+
+const defaultPublicAPIURL = "https://hidden-depths-web.onrender.com"
+
+// ErrEmailSuppressed is the cause of errors returned when a recipient is suppressed.
+var ErrEmailSuppressed = errors.New("email recipient is suppressed")
+
+// ErrInvalidUnsubscribeToken is returned for tokens that fail verification.
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// NormalizeEmailAddress returns the form used as the suppression list key.
+func NormalizeEmailAddress(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// SuppressEmail records a suppression for an address. Repeated events for the
+// same address and reason refresh the stored details.
+func SuppressEmail(ctx context.Context, email, reason, source, providerEventID, details string) error {
+	scope := "all"
+	if reason == SuppressionUnsubscribe {
+		scope = "non_transactional"
+	}
+
+	_, err := database.Pool.Exec(ctx,
+		`INSERT INTO email_suppressions (email, reason, scope, source, provider_event_id, details)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (email, reason) DO UPDATE
+		 SET source = EXCLUDED.source,
+		     provider_event_id = EXCLUDED.provider_event_id,
+		     details = EXCLUDED.details`,
+		NormalizeEmailAddress(email), reason, scope, source, parseUUID(providerEventID), details,
+	)
+	return err
+}
+
+// EmailSuppressionPage is how the suppression list pages: newest first by
+// default.
+var EmailSuppressionPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+		"email":      {Expr: "email", Type: pagination.TypeText},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 100,
+	MaxLimit:     500,
+	Fields:       pagination.JSONFields(models.EmailSuppression{}),
+}
+
+// ListEmailSuppressions returns the page p of suppressed addresses.
+func ListEmailSuppressions(ctx context.Context, p pagination.Params) (pagination.Page[models.EmailSuppression], error) {
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, email, reason, scope, source, provider_event_id, details, created_at, %s
+		 FROM email_suppressions
+		 WHERE %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.EmailSuppression]{}, err
+	}
+	defer rows.Close()
+
+	suppressions := make([]models.EmailSuppression, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var s models.EmailSuppression
+		var pos pagination.Position
+		if err := rows.Scan(&s.ID, &s.Email, &s.Reason, &s.Scope, &s.Source, &s.ProviderEventID, &s.Details, &s.CreatedAt, &pos.Value, &pos.Key); err != nil {
+			return pagination.Page[models.EmailSuppression]{}, err
+		}
+		suppressions = append(suppressions, s)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.EmailSuppression]{}, err
+	}
+	return pagination.NewPage(p, suppressions, positions), nil
+}
+
+// RemoveEmailSuppression deletes every suppression for an address and reports
+// how many rows were removed.
+func RemoveEmailSuppression(ctx context.Context, email string) (int64, error) {
+	result, err := database.Pool.Exec(ctx,
+		`DELETE FROM email_suppressions WHERE email = $1`,
+		NormalizeEmailAddress(email),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// emailSuppressionReason returns the reason an address must not receive a
+// message of the given category, if any.
+func emailSuppressionReason(ctx context.Context, email string, category EmailCategory) (string, bool, error) {
+	var reason string
+	err := database.Pool.QueryRow(ctx,
+		`SELECT reason
+		 FROM email_suppressions
+		 WHERE email = $1 AND (scope = 'all' OR $2)
+		 ORDER BY (scope = 'all') DESC
+		 LIMIT 1`,
+		NormalizeEmailAddress(email), category == EmailNotification,
+	).Scan(&reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return reason, true, nil
+}
+
+// prepareOutgoingEmail runs the checks shared by every delivery provider: it
+// rejects suppressed recipients, fills in a text/plain part and adds
+// List-Unsubscribe headers to notification mail.
+func prepareOutgoingEmail(ctx context.Context, msg *OutgoingEmail) error {
+	checkCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	reason, suppressed, err := emailSuppressionReason(checkCtx, msg.To, msg.Category)
+	cancel()
+	switch {
+	case err != nil:
+		// Fail open: a suppression lookup outage must not stop booking emails.
+		logger.Warn("Failed to check email suppression list, sending anyway",
+			zap.String("to", msg.To),
+			zap.Error(err),
+		)
+	case suppressed:
+		logger.Info("Skipping email to suppressed recipient",
+			zap.String("to", msg.To),
+			zap.String("reason", reason),
+			zap.String("subject", msg.Subject),
+		)
+		return apperror.EmailSuppressed(reason).WithCause(ErrEmailSuppressed)
+	}
+
+	if strings.TrimSpace(msg.Text) == "" {
+		msg.Text = HTMLToText(msg.HTML)
+	}
+
+	if msg.Category == EmailNotification {
+		if link := EmailUnsubscribeURL(msg.To); link != "" {
+			if msg.Headers == nil {
+				msg.Headers = make(map[string]string, 2)
+			}
+			msg.Headers["List-Unsubscribe"] = "<" + link + ">"
+			msg.Headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+		}
+	}
+	return nil
+}
+
+// EmailUnsubscribeURL returns the one-click unsubscribe link for an address, or
+// an empty string when EMAIL_UNSUBSCRIBE_SECRET is not configured.
+func EmailUnsubscribeURL(email string) string {
+	secret := os.Getenv("EMAIL_UNSUBSCRIBE_SECRET")
+	if secret == "" {
+		return ""
+	}
+
+	base := strings.TrimRight(os.Getenv("PUBLIC_API_URL"), "/")
+	if base == "" {
+		base = defaultPublicAPIURL
+	}
+	token := signUnsubscribeToken(secret, NormalizeEmailAddress(email))
+	return base + "/api/v1/email/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// ParseUnsubscribeToken verifies an unsubscribe token and returns its address.
+func ParseUnsubscribeToken(token string) (string, error) {
+	secret := os.Getenv("EMAIL_UNSUBSCRIBE_SECRET")
+	if secret == "" {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	return verifyUnsubscribeToken(secret, token)
+}
+
+// signUnsubscribeToken encodes the address with an HMAC so links cannot be forged
+// for other recipients. Tokens do not expire: unsubscribe links must keep working.
+func signUnsubscribeToken(secret, email string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(email))
+	return encoded + "." + unsubscribeSignature(secret, email)
+}
+
+func verifyUnsubscribeToken(secret, token string) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw) == 0 {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	email := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(unsubscribeSignature(secret, email))) {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	return email, nil
+}
+
+func unsubscribeSignature(secret, email string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("unsubscribe:" + email))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/internal/templates"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Email template names. Each one has an embedded default in internal/templates/emails.
+const (
+	TemplateBookingConfirmation = "booking_confirmation"
+	TemplateBookingReminder     = "booking_reminder"
+	TemplateBookingCancellation = "booking_cancellation"
+)
+
+// defaultTemplateSubjects holds the subject line used with each embedded default.
+var defaultTemplateSubjects = map[string]string{
+	TemplateBookingConfirmation: `{{.T "✨ Booking Confirmed - Your Journey Begins"}}`,
+	TemplateBookingReminder:     `{{.T "⏰ Reminder: Your Session is Tomorrow"}}`,
+	TemplateBookingCancellation: `{{.T "Booking Cancelled - Hidden Depths"}}`,
+}
+
+// templateCategories marks which templates recipients may unsubscribe from.
+// Templates not listed are transactional.
+var templateCategories = map[string]EmailCategory{
+	TemplateBookingReminder: EmailNotification,
+}
+
+const (
+	siteBaseURL    = "https://hidden-depths-web.pages.dev"
+	emailLogoURL   = siteBaseURL + "/logo.png"
+	emailProfile   = siteBaseURL + "/profile"
+	emailSupport   = siteBaseURL + "/contact"
+	dbQueryTimeout = 5 * time.Second
+)
+
+// ErrUnknownEmailTemplate is returned for template names without an embedded default.
+var ErrUnknownEmailTemplate = errors.New("unknown email template")
+
+// RenderedEmail is a template rendered with concrete data, ready to send.
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// EmailTemplateNames returns the editable template names in stable order.
+func EmailTemplateNames() []string {
+	names := make([]string, 0, len(defaultTemplateSubjects))
+	for name := range defaultTemplateSubjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsKnownEmailTemplate reports whether name refers to an editable template.
+func IsKnownEmailTemplate(name string) bool {
+	_, ok := defaultTemplateSubjects[name]
+	return ok
+}
+
+// EmailTemplateCategory returns the delivery category for a template name.
+func EmailTemplateCategory(name string) EmailCategory {
+	if category, ok := templateCategories[name]; ok {
+		return category
+	}
+	return EmailTransactional
+}
+
+// NewEmailTemplateData builds template data for a booking email with the standard
+// links. date is YYYY-MM-DD and timeSlot a booking slot such as "08:00 PM"; both
+// are formatted for locale.
+func NewEmailTemplateData(locale, name, date, timeSlot, meetingLink string) EmailTemplateData {
+	if !i18n.IsSupported(locale) {
+		locale = i18n.Default
+	}
+	return EmailTemplateData{
+		Locale:      locale,
+		Name:        name,
+		Date:        i18n.FormatDateString(locale, date),
+		Time:        i18n.FormatTimeSlot(locale, timeSlot),
+		MeetingLink: meetingLink,
+		LogoURL:     emailLogoURL,
+		ProfileURL:  emailProfile,
+		SupportURL:  emailSupport,
+		Year:        time.Now().Year(),
+	}
+}
+
+// T translates an English catalog message into the email's locale.
+func (d EmailTemplateData) T(msgID string, args ...any) string {
+	return i18n.T(d.Locale, msgID, args...)
+}
+
+// SampleEmailTemplateData returns placeholder data for previews and test sends.
+func SampleEmailTemplateData(locale string) EmailTemplateData {
+	data := NewEmailTemplateData(locale, "Alex", "2026-04-19", "08:00 PM", siteBaseURL+"/session?room=preview")
+	data.UnsubscribeURL = defaultPublicAPIURL + "/api/v1/email/unsubscribe?token=preview"
+	return data
+}
+
+// DefaultEmailTemplate returns the embedded default for a template name.
+func DefaultEmailTemplate(name string) (models.EmailTemplate, error) {
+	subject, ok := defaultTemplateSubjects[name]
+	if !ok {
+		return models.EmailTemplate{}, ErrUnknownEmailTemplate
+	}
+
+	html, err := templates.EmailTemplates.ReadFile("emails/" + name + ".html")
+	if err != nil {
+		return models.EmailTemplate{}, fmt.Errorf("failed to read embedded template %s: %w", name, err)
+	}
+
+	return models.EmailTemplate{
+		Name:     name,
+		Version:  0,
+		Subject:  subject,
+		HTMLBody: string(html),
+		IsActive: true,
+	}, nil
+}
+
+// RenderEmailTemplate executes a template's subject, HTML and text parts with data.
+// Missing fields are treated as errors so broken edits are caught before saving.
+func RenderEmailTemplate(tmpl models.EmailTemplate, data EmailTemplateData) (RenderedEmail, error) {
+	var rendered RenderedEmail
+
+	subject, err := executeTextTemplate(tmpl.Name+":subject", tmpl.Subject, data)
+	if err != nil {
+		return rendered, err
+	}
+	rendered.Subject = subject
+
+	htmlTmpl, err := htmltemplate.New(tmpl.Name + ":html").Option("missingkey=error").Parse(tmpl.HTMLBody)
+	if err != nil {
+		return rendered, fmt.Errorf("failed to parse HTML body: %w", err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := htmlTmpl.Execute(&htmlBuf, data); err != nil {
+		return rendered, fmt.Errorf("failed to execute HTML body: %w", err)
+	}
+	rendered.HTML = htmlBuf.String()
+
+	if tmpl.TextBody != "" {
+		text, err := executeTextTemplate(tmpl.Name+":text", tmpl.TextBody, data)
+		if err != nil {
+			return rendered, err
+		}
+		rendered.Text = text
+	} else {
+		rendered.Text = HTMLToText(rendered.HTML)
+	}
+
+	return rendered, nil
+}
+
+func executeTextTemplate(name, body string, data EmailTemplateData) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// ActiveEmailTemplate returns the active version of a template, falling back to the
+// embedded default when no version is active or the database is unreachable.
+func ActiveEmailTemplate(ctx context.Context, name string) (models.EmailTemplate, error) {
+	fallback, err := DefaultEmailTemplate(name)
+	if err != nil {
+		return models.EmailTemplate{}, err
+	}
+
+	cacheKey := cache.EmailTemplateKey(name)
+	if tmpl, err := cache.Get[models.EmailTemplate](ctx, cacheKey); err == nil {
+		return tmpl, nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tmpl, err := scanEmailTemplate(database.Pool.QueryRow(queryCtx,
+		`SELECT id, name, version, subject, html_body, text_body, is_active, created_by, created_at
+		 FROM email_templates
+		 WHERE name = $1 AND is_active
+		 LIMIT 1`,
+		name,
+	))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		tmpl = fallback
+	case err != nil:
+		// Never block an email on template storage; the embedded default is always valid.
+		logger.Warn("Failed to load active email template, using default",
+			zap.String("template", name),
+			zap.Error(err),
+		)
+		return fallback, nil
+	}
+
+	_ = cache.Set(ctx, cacheKey, tmpl, cache.EmailTemplateTTL)
+	return tmpl, nil
+}
+
+// RenderActiveEmailTemplate renders the active version of a template with data.
+func RenderActiveEmailTemplate(ctx context.Context, name string, data EmailTemplateData) (RenderedEmail, error) {
+	tmpl, err := ActiveEmailTemplate(ctx, name)
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+
+	rendered, err := RenderEmailTemplate(tmpl, data)
+	if err != nil && tmpl.Version > 0 {
+		logger.Error("Active email template failed to render, using default",
+			zap.String("template", name),
+			zap.Int("version", tmpl.Version),
+			zap.Error(err),
+		)
+		fallback, defaultErr := DefaultEmailTemplate(name)
+		if defaultErr != nil {
+			return RenderedEmail{}, defaultErr
+		}
+		return RenderEmailTemplate(fallback, data)
+	}
+	return rendered, err
+}
+
+// InvalidateEmailTemplateCache drops the cached active version of a template.
+func InvalidateEmailTemplateCache(ctx context.Context, name string) {
+	if err := cache.Delete(ctx, cache.EmailTemplateKey(name)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to invalidate email template cache", zap.String("template", name), zap.Error(err))
+	}
+}
+
+// ListEmailTemplateVersions returns all stored versions of a template, newest first.
+func ListEmailTemplateVersions(ctx context.Context, name string) ([]models.EmailTemplate, error) {
+	if !IsKnownEmailTemplate(name) {
+		return nil, ErrUnknownEmailTemplate
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, name, version, subject, html_body, text_body, is_active, created_by, created_at
+		 FROM email_templates
+		 WHERE name = $1
+		 ORDER BY version DESC`,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]models.EmailTemplate, 0, 8)
+	for rows.Next() {
+		tmpl, err := scanEmailTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, tmpl)
+	}
+	return versions, rows.Err()
+}
+
+// GetEmailTemplateVersion returns one stored version; version 0 is the embedded default.
+func GetEmailTemplateVersion(ctx context.Context, name string, version int) (models.EmailTemplate, error) {
+	if version == 0 {
+		return DefaultEmailTemplate(name)
+	}
+	if !IsKnownEmailTemplate(name) {
+		return models.EmailTemplate{}, ErrUnknownEmailTemplate
+	}
+
+	return scanEmailTemplate(database.Pool.QueryRow(ctx,
+		`SELECT id, name, version, subject, html_body, text_body, is_active, created_by, created_at
+		 FROM email_templates
+		 WHERE name = $1 AND version = $2`,
+		name, version,
+	))
+}
+
+// CreateEmailTemplateVersion validates and stores a new version, making it active.
+func CreateEmailTemplateVersion(ctx context.Context, tmpl models.EmailTemplate, createdBy string) (models.EmailTemplate, error) {
+	if !IsKnownEmailTemplate(tmpl.Name) {
+		return tmpl, ErrUnknownEmailTemplate
+	}
+	if _, err := RenderEmailTemplate(tmpl, SampleEmailTemplateData(i18n.Default)); err != nil {
+		return tmpl, apperror.ValidationError("template", err.Error())
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return tmpl, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize version numbering per template name.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('email_template:' || $1))`, tmpl.Name); err != nil {
+		return tmpl, err
+	}
+
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM email_templates WHERE name = $1`,
+		tmpl.Name,
+	).Scan(&tmpl.Version); err != nil {
+		return tmpl, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE email_templates SET is_active = FALSE WHERE name = $1 AND is_active`, tmpl.Name); err != nil {
+		return tmpl, err
+	}
+
+	stored, err := scanEmailTemplate(tx.QueryRow(ctx,
+		`INSERT INTO email_templates (name, version, subject, html_body, text_body, is_active, created_by)
+		 VALUES ($1, $2, $3, $4, $5, TRUE, $6)
+		 RETURNING id, name, version, subject, html_body, text_body, is_active, created_by, created_at`,
+		tmpl.Name, tmpl.Version, tmpl.Subject, tmpl.HTMLBody, tmpl.TextBody, parseUUID(createdBy),
+	))
+	if err != nil {
+		return tmpl, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return tmpl, err
+	}
+
+	InvalidateEmailTemplateCache(ctx, tmpl.Name)
+	return stored, nil
+}
+
+// ActivateEmailTemplateVersion makes a stored version active. Version 0 deactivates
+// every stored version so the embedded default is used again.
+func ActivateEmailTemplateVersion(ctx context.Context, name string, version int) (models.EmailTemplate, error) {
+	if !IsKnownEmailTemplate(name) {
+		return models.EmailTemplate{}, ErrUnknownEmailTemplate
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return models.EmailTemplate{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('email_template:' || $1))`, name); err != nil {
+		return models.EmailTemplate{}, err
+	}
+
+	var activated models.EmailTemplate
+	if version > 0 {
+		// Look the version up first so a missing one leaves the current active version untouched.
+		activated, err = scanEmailTemplate(tx.QueryRow(ctx,
+			`SELECT id, name, version, subject, html_body, text_body, is_active, created_by, created_at
+			 FROM email_templates
+			 WHERE name = $1 AND version = $2`,
+			name, version,
+		))
+		if err != nil {
+			return models.EmailTemplate{}, err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE email_templates SET is_active = FALSE WHERE name = $1 AND is_active`, name); err != nil {
+		return models.EmailTemplate{}, err
+	}
+
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE email_templates SET is_active = TRUE WHERE id = $1`, activated.ID); err != nil {
+			return models.EmailTemplate{}, err
+		}
+		activated.IsActive = true
+	} else {
+		activated, err = DefaultEmailTemplate(name)
+		if err != nil {
+			return models.EmailTemplate{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.EmailTemplate{}, err
+	}
+
+	InvalidateEmailTemplateCache(ctx, name)
+	return activated, nil
+}
+
+// SendTemplatedEmail renders the active version of a template and delivers it,
+// preferring Resend and falling back to SMTP.
+func SendTemplatedEmail(ctx context.Context, to, name string, data EmailTemplateData) error {
+	category := EmailTemplateCategory(name)
+	if category == EmailNotification {
+		data.UnsubscribeURL = EmailUnsubscribeURL(to)
+	}
+
+	rendered, err := RenderActiveEmailTemplate(ctx, name, data)
+	if err != nil {
+		return apperror.InternalError(fmt.Errorf("failed to render %s template: %w", name, err))
+	}
+	return DeliverEmail(NewOutgoingEmail(to, rendered, category))
+}
+
+// NewOutgoingEmail wraps a rendered template for delivery.
+func NewOutgoingEmail(to string, email RenderedEmail, category EmailCategory) OutgoingEmail {
+	return OutgoingEmail{
+		To:       to,
+		Subject:  email.Subject,
+		HTML:     email.HTML,
+		Text:     email.Text,
+		Category: category,
+	}
+}
+
+// DeliverEmail sends a message through the configured provider. Both providers
+// skip suppressed recipients and return an error wrapping ErrEmailSuppressed.
+func DeliverEmail(msg OutgoingEmail) error {
+	if emailSvc := GetEmailService(); emailSvc.IsEnabled() {
+		return emailSvc.sendEmail(msg)
+	}
+	return SendEmail(msg)
+}
+
+type emailTemplateRow interface {
+	Scan(dest ...any) error
+}
+
+func scanEmailTemplate(row emailTemplateRow) (models.EmailTemplate, error) {
+	var tmpl models.EmailTemplate
+	err := row.Scan(
+		&tmpl.ID,
+		&tmpl.Name,
+		&tmpl.Version,
+		&tmpl.Subject,
+		&tmpl.HTMLBody,
+		&tmpl.TextBody,
+		&tmpl.IsActive,
+		&tmpl.CreatedBy,
+		&tmpl.CreatedAt,
+	)
+	return tmpl, err
+}
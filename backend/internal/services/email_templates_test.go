@@ -0,0 +1,113 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
+)
+
+func TestDefaultEmailTemplatesRender(t *testing.T) {
+	for _, locale := range i18n.Supported() {
+		data := SampleEmailTemplateData(locale)
+
+		for _, name := range EmailTemplateNames() {
+			t.Run(locale+"/"+name, func(t *testing.T) {
+				tmpl, err := DefaultEmailTemplate(name)
+				if err != nil {
+					t.Fatalf("unexpected error loading default: %v", err)
+				}
+				if tmpl.Version != 0 {
+					t.Fatalf("expected default to be version 0, got %d", tmpl.Version)
+				}
+
+				rendered, err := RenderEmailTemplate(tmpl, data)
+				if err != nil {
+					t.Fatalf("unexpected render error: %v", err)
+				}
+				if rendered.Subject == "" {
+					t.Fatalf("expected subject to be rendered")
+				}
+				if !strings.Contains(rendered.HTML, data.Name) {
+					t.Fatalf("expected HTML to contain recipient name %q", data.Name)
+				}
+				if !strings.Contains(rendered.HTML, `lang="`+locale+`"`) {
+					t.Fatalf("expected HTML to declare lang %q", locale)
+				}
+				if locale != i18n.English && !strings.Contains(rendered.HTML, i18n.T(locale, "Hello")+" ") && !strings.Contains(rendered.HTML, i18n.T(locale, "Welcome,")+" ") {
+					t.Fatalf("expected HTML greeting to be translated to %q", locale)
+				}
+			})
+		}
+	}
+}
+
+func TestDefaultEmailTemplateUnknownName(t *testing.T) {
+	if _, err := DefaultEmailTemplate("newsletter"); err != ErrUnknownEmailTemplate {
+		t.Fatalf("expected ErrUnknownEmailTemplate, got %v", err)
+	}
+}
+
+func TestRenderEmailTemplate(t *testing.T) {
+	data := NewEmailTemplateData(i18n.Default, "Priya", "2026-04-19", "11:00 AM", "https://example.com/room")
+
+	tests := []struct {
+		name        string
+		tmpl        models.EmailTemplate
+		wantSubject string
+		wantText    string
+		wantErr     bool
+	}{
+		{
+			name: "renders subject and text parts",
+			tmpl: models.EmailTemplate{
+				Name:     TemplateBookingReminder,
+				Subject:  "See you at {{.Time}}",
+				HTMLBody: "<p>Hi {{.Name}}</p>",
+				TextBody: "Hi {{.Name}}, join at {{.MeetingLink}}",
+			},
+			wantSubject: "See you at 11:00 AM",
+			wantText:    "Hi Priya, join at https://example.com/room",
+		},
+		{
+			name: "rejects unknown fields",
+			tmpl: models.EmailTemplate{
+				Name:     TemplateBookingReminder,
+				Subject:  "Hello",
+				HTMLBody: "<p>{{.Nickname}}</p>",
+			},
+			wantErr: true,
+		},
+		{
+			name: "rejects malformed syntax",
+			tmpl: models.EmailTemplate{
+				Name:     TemplateBookingReminder,
+				Subject:  "Hello {{.Name",
+				HTMLBody: "<p>ok</p>",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderEmailTemplate(tc.tmpl, data)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Subject != tc.wantSubject {
+				t.Fatalf("expected subject %q, got %q", tc.wantSubject, got.Subject)
+			}
+			if got.Text != tc.wantText {
+				t.Fatalf("expected text %q, got %q", tc.wantText, got.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlDropBlocks   = regexp.MustCompile(`(?is)<(head|style|script|title)\b[^>]*>.*?</(head|style|script|title)>|<!--.*?-->`)
+	htmlAnchor       = regexp.MustCompile(`(?is)<a\b[^>]*\bhref\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	htmlLineBreaks   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|tr|li|table|blockquote)>`)
+	htmlListItem     = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlTags         = regexp.MustCompile(`(?s)<[^>]*>`)
+	horizontalSpaces = regexp.MustCompile(`[ \t\r\f\v\x{00a0}]+`)
+	blankLineRuns    = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText derives a readable text/plain alternative from an HTML email body.
+// Links keep their target in parentheses so they stay usable in text-only clients.
+func HTMLToText(body string) string {
+	text := htmlDropBlocks.ReplaceAllString(body, "")
+	text = htmlAnchor.ReplaceAllStringFunc(text, func(match string) string {
+		parts := htmlAnchor.FindStringSubmatch(match)
+		href := strings.TrimSpace(html.UnescapeString(parts[1]))
+		label := strings.TrimSpace(htmlTags.ReplaceAllString(parts[2], ""))
+		if label == "" || html.UnescapeString(label) == href {
+			return href
+		}
+		return label + " (" + href + ")"
+	})
+	text = htmlLineBreaks.ReplaceAllString(text, "\n")
+	text = htmlListItem.ReplaceAllString(text, "\n- ")
+	text = htmlTags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpaces.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLineRuns.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "drops head and styles",
+			html: `<html><head><title>Hi</title><style>p{color:red}</style></head><body><p>Hello</p></body></html>`,
+			want: "Hello",
+		},
+		{
+			name: "keeps link targets",
+			html: `<p>Join <a href="https://example.com/room?a=1&amp;b=2">your session</a></p>`,
+			want: "Join your session (https://example.com/room?a=1&b=2)",
+		},
+		{
+			name: "bare links are not duplicated",
+			html: `<a href="https://example.com">https://example.com</a>`,
+			want: "https://example.com",
+		},
+		{
+			name: "block elements become lines",
+			html: "<div>One</div>\n\n\n\n<div>Two<br>Three</div><ul><li>Four</li></ul>",
+			want: "One\n\nTwo\nThree\n\n- Four",
+		},
+		{
+			name: "unescapes entities and collapses spaces",
+			html: `<p>Tom   &amp;&nbsp;Jerry</p>`,
+			want: "Tom & Jerry",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := HTMLToText(tc.html); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	token := signUnsubscribeToken("secret", "user@example.com")
+
+	email, err := verifyUnsubscribeToken("secret", token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if email != "user@example.com" {
+		t.Fatalf("expected user@example.com, got %q", email)
+	}
+
+	tampered := signUnsubscribeToken("secret", "other@example.com")
+	_, signature, _ := strings.Cut(token, ".")
+	encoded, _, _ := strings.Cut(tampered, ".")
+	if _, err := verifyUnsubscribeToken("secret", encoded+"."+signature); err != ErrInvalidUnsubscribeToken {
+		t.Fatalf("expected forged token to be rejected, got %v", err)
+	}
+	if _, err := verifyUnsubscribeToken("other-secret", token); err != ErrInvalidUnsubscribeToken {
+		t.Fatalf("expected wrong secret to be rejected, got %v", err)
+	}
+}
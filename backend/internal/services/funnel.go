@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	appmetrics "github.com/Himadryy/hidden-depths-backend/internal/middleware"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Steps of the paid booking funnel (booking_funnel_events.step).
+const (
+	FunnelHoldCreated     = "hold_created"
+	FunnelCheckoutOpened  = "checkout_opened" // reported by the client
+	FunnelVerifyCalled    = "verify_called"
+	FunnelWebhookReceived = "webhook_received"
+	FunnelConfirmed       = "confirmed"
+	FunnelReleased        = "released"
+	FunnelExpired         = "expired"
+	FunnelFailed          = "failed"
+)
+
+// FunnelSteps lists the funnel steps in the order they are reported.
+var FunnelSteps = []string{
+	FunnelHoldCreated,
+	FunnelCheckoutOpened,
+	FunnelVerifyCalled,
+	FunnelWebhookReceived,
+	FunnelConfirmed,
+	FunnelReleased,
+	FunnelExpired,
+	FunnelFailed,
+}
+
+// recordFunnelStepQuery keeps the first time a booking reached a step. The
+// bookings filter on $1 is appended by the caller.
+const recordFunnelStepQuery = `
+	INSERT INTO booking_funnel_events (booking_id, step, detail, elapsed_ms)
+	SELECT id, $2, $3, GREATEST(0, (EXTRACT(EPOCH FROM NOW() - created_at) * 1000)::bigint)
+	FROM bookings
+	WHERE `
+
+// RecordFunnelStep records that a booking reached a funnel step, with detail
+// such as the status reason. Only the first time per step is kept. Recording
+// is best effort: failures are logged, never returned, so the booking flow
+// does not depend on it.
+func RecordFunnelStep(ctx context.Context, bookingID, step, detail string) {
+	if _, err := uuid.Parse(bookingID); err != nil {
+		return
+	}
+	recordFunnelStep(ctx, `id = $1`, bookingID, step, detail)
+}
+
+// RecordFunnelStepForOrder is RecordFunnelStep for the latest booking of a
+// Razorpay order, for webhooks that carry no booking ID.
+func RecordFunnelStepForOrder(ctx context.Context, orderID, step, detail string) {
+	if orderID == "" {
+		return
+	}
+	recordFunnelStep(ctx, `razorpay_order_id = $1 ORDER BY created_at DESC LIMIT 1`, orderID, step, detail)
+}
+
+func recordFunnelStep(ctx context.Context, where, key, step, detail string) {
+	var elapsedMS int64
+	err := database.Pool.QueryRow(ctx,
+		recordFunnelStepQuery+where+`
+		ON CONFLICT (booking_id, step) DO NOTHING
+		RETURNING elapsed_ms`,
+		key, step, detail,
+	).Scan(&elapsedMS)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// Unknown booking, or the step was already recorded
+	case err != nil:
+		logger.Warn("Failed to record booking funnel step",
+			zap.String("step", step), zap.String("key", key), zap.Error(err),
+		)
+	default:
+		appmetrics.ObserveFunnelStep(step, time.Duration(elapsedMS)*time.Millisecond)
+	}
+}
+
+// GetBookingFunnel reports, for holds created from..to (inclusive dates in
+// timezone), how many reached each step and how long after the hold.
+// Results are cached for cache.AnalyticsTTL.
+func GetBookingFunnel(ctx context.Context, from, to time.Time, timezone string) (models.BookingFunnel, error) {
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+	cacheKey := cache.FunnelKey(fromDate, toDate, timezone)
+	if funnel, err := cache.Get[models.BookingFunnel](ctx, cacheKey); err == nil {
+		return funnel, nil
+	}
+
+	funnel := models.BookingFunnel{From: fromDate, To: toDate, Timezone: timezone}
+	rows, err := database.Pool.Query(ctx,
+		`SELECT e.step, COUNT(*),
+		        percentile_cont(0.5) WITHIN GROUP (ORDER BY e.elapsed_ms) / 1000,
+		        percentile_cont(0.9) WITHIN GROUP (ORDER BY e.elapsed_ms) / 1000,
+		        percentile_cont(0.99) WITHIN GROUP (ORDER BY e.elapsed_ms) / 1000,
+		        AVG(e.elapsed_ms)::float8 / 1000
+		 FROM booking_funnel_events h
+		 JOIN booking_funnel_events e ON e.booking_id = h.booking_id
+		 WHERE h.step = 'hold_created'
+		   AND h.created_at >= $1::timestamp AT TIME ZONE $3
+		   AND h.created_at < $2::timestamp AT TIME ZONE $3
+		 GROUP BY e.step`,
+		fromDate, to.AddDate(0, 0, 1).Format("2006-01-02"), timezone,
+	)
+	if err != nil {
+		return funnel, err
+	}
+	defer rows.Close()
+
+	byStep := make(map[string]models.FunnelStepStats, len(FunnelSteps))
+	for rows.Next() {
+		var s models.FunnelStepStats
+		if err := rows.Scan(&s.Step, &s.Bookings, &s.Latency.P50, &s.Latency.P90, &s.Latency.P99, &s.Latency.Mean); err != nil {
+			return funnel, err
+		}
+		byStep[s.Step] = s
+	}
+	if err := rows.Err(); err != nil {
+		return funnel, err
+	}
+
+	funnel.Holds = byStep[FunnelHoldCreated].Bookings
+	funnel.Steps = funnelStepStats(byStep, funnel.Holds)
+
+	_ = cache.Set(ctx, cacheKey, funnel, cache.AnalyticsTTL)
+	return funnel, nil
+}
+
+// funnelStepStats lists every step in funnel order, with conversion from the
+// hold and latencies rounded to milliseconds.
+func funnelStepStats(byStep map[string]models.FunnelStepStats, holds int) []models.FunnelStepStats {
+	steps := make([]models.FunnelStepStats, 0, len(FunnelSteps))
+	for _, step := range FunnelSteps {
+		s := byStep[step]
+		s.Step = step
+		s.ConversionRate = analyticsRatio(s.Bookings, holds)
+		s.Latency = models.FunnelLatency{
+			P50:  roundMillis(s.Latency.P50),
+			P90:  roundMillis(s.Latency.P90),
+			P99:  roundMillis(s.Latency.P99),
+			Mean: roundMillis(s.Latency.Mean),
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+func roundMillis(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
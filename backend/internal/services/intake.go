@@ -0,0 +1,526 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// intakeAnswersField is bound into intake answer ciphertexts.
+const intakeAnswersField = "intake_responses.answers"
+
+const (
+	maxIntakeQuestions       = 50
+	defaultIntakeTextLength  = 500
+	defaultIntakeLongLength  = 4000
+	defaultIntakeScaleMin    = 1
+	defaultIntakeScaleMax    = 10
+	maxIntakeOptionsPerField = 20
+)
+
+var (
+	// ErrNoActiveIntakeForm is returned when no intake form is active, so
+	// no booking needs one.
+	ErrNoActiveIntakeForm = errors.New("no active intake form")
+	// ErrIntakeResponseNotFound is returned for bookings without a response.
+	ErrIntakeResponseNotFound = errors.New("intake response not found")
+)
+
+var intakeQuestionID = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IntakePendingCondition is an SQL condition, over bookings aliased b, that
+// holds while a paid booking still needs the active intake form: its client
+// has answered no intake form yet and has not completed a session before.
+const IntakePendingCondition = `(b.payment_status = 'paid' AND b.user_id IS NOT NULL
+	AND EXISTS (SELECT 1 FROM intake_forms f WHERE f.is_active)
+	AND NOT EXISTS (SELECT 1 FROM intake_responses ir WHERE ir.user_id = b.user_id)
+	AND NOT EXISTS (SELECT 1 FROM bookings c WHERE c.user_id = b.user_id AND c.id <> b.id AND c.session_status = 'completed'))`
+
+// IntakePending reports whether a booking still needs the intake form.
+func IntakePending(ctx context.Context, bookingID string) (bool, error) {
+	var pending bool
+	err := database.Pool.QueryRow(ctx,
+		`SELECT `+IntakePendingCondition+` FROM bookings b WHERE b.id = $1`,
+		bookingID,
+	).Scan(&pending)
+	return pending, err
+}
+
+// EmailJoinLink is the link booking emails give for joining a session: the
+// meeting link, or the profile page while the intake form is pending, so the
+// room is only reached through JoinSession once the form is answered.
+func EmailJoinLink(intakePending bool, meetingLink string) string {
+	if intakePending && meetingLink != "" {
+		return emailProfile
+	}
+	return meetingLink
+}
+
+// BookingEmailLink looks up whether a booking's intake is pending and returns
+// its EmailJoinLink. Lookup failures withhold the meeting link.
+func BookingEmailLink(ctx context.Context, bookingID, meetingLink string) string {
+	if meetingLink == "" {
+		return ""
+	}
+	pending, err := IntakePending(ctx, bookingID)
+	if err != nil {
+		logger.Error("Intake check for booking email failed", zap.String("booking_id", bookingID), zap.Error(err))
+		pending = true
+	}
+	return EmailJoinLink(pending, meetingLink)
+}
+
+// ValidateIntakeQuestions checks an intake form definition.
+func ValidateIntakeQuestions(questions []models.IntakeQuestion) *apperror.AppError {
+	if len(questions) == 0 || len(questions) > maxIntakeQuestions {
+		return apperror.ValidationErrorf("questions", "A form needs between 1 and %d questions", maxIntakeQuestions)
+	}
+	seen := make(map[string]bool, len(questions))
+	for i, q := range questions {
+		if !intakeQuestionID.MatchString(q.ID) || seen[q.ID] {
+			return apperror.ValidationErrorf("questions", "Question %d needs a unique id of lowercase letters, digits and underscores", i+1)
+		}
+		seen[q.ID] = true
+		if strings.TrimSpace(q.Label) == "" {
+			return apperror.ValidationErrorf("questions", "Question %s needs a label", q.ID)
+		}
+		switch q.Type {
+		case models.IntakeText, models.IntakeLongText:
+			if q.MaxLength < 0 || q.MaxLength > defaultIntakeLongLength {
+				return apperror.ValidationErrorf("questions", "Question %s: max_length must be at most %d", q.ID, defaultIntakeLongLength)
+			}
+		case models.IntakeSingleChoice, models.IntakeMultiChoice:
+			if len(q.Options) < 2 || len(q.Options) > maxIntakeOptionsPerField {
+				return apperror.ValidationErrorf("questions", "Question %s needs between 2 and %d options", q.ID, maxIntakeOptionsPerField)
+			}
+			options := make(map[string]bool, len(q.Options))
+			for _, option := range q.Options {
+				if strings.TrimSpace(option) == "" || options[option] {
+					return apperror.ValidationErrorf("questions", "Question %s has a blank or repeated option", q.ID)
+				}
+				options[option] = true
+			}
+		case models.IntakeScale:
+			min, max := intakeScaleRange(q)
+			if min >= max {
+				return apperror.ValidationErrorf("questions", "Question %s: min must be below max", q.ID)
+			}
+		case models.IntakeBoolean:
+		default:
+			return apperror.ValidationErrorf("questions", "Question %s has unknown type %q", q.ID, q.Type)
+		}
+	}
+	return nil
+}
+
+// ValidateIntakeAnswers checks raw answers against questions and returns
+// them decoded: strings, []string, int or bool by question type. Blank
+// answers to optional questions are dropped.
+func ValidateIntakeAnswers(questions []models.IntakeQuestion, raw map[string]json.RawMessage) (map[string]interface{}, *apperror.AppError) {
+	known := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		known[q.ID] = true
+	}
+	for id := range raw {
+		if !known[id] {
+			return nil, apperror.ValidationErrorf("answers", "Unknown question: %s", id)
+		}
+	}
+
+	answers := make(map[string]interface{}, len(questions))
+	for _, q := range questions {
+		field := "answers." + q.ID
+		value, appErr := decodeIntakeAnswer(q, raw[q.ID], field)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if value == nil {
+			if q.Required {
+				return nil, apperror.ValidationError(field, "This question is required")
+			}
+			continue
+		}
+		answers[q.ID] = value
+	}
+	return answers, nil
+}
+
+// decodeIntakeAnswer returns nil for a missing or blank answer.
+func decodeIntakeAnswer(q models.IntakeQuestion, raw json.RawMessage, field string) (interface{}, *apperror.AppError) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	switch q.Type {
+	case models.IntakeText, models.IntakeLongText:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, apperror.ValidationError(field, "Answer must be text")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		limit := q.MaxLength
+		if limit == 0 {
+			limit = defaultIntakeTextLength
+			if q.Type == models.IntakeLongText {
+				limit = defaultIntakeLongLength
+			}
+		}
+		if utf8.RuneCountInString(text) > limit {
+			return nil, apperror.ValidationErrorf(field, "Answer must be at most %d characters", limit)
+		}
+		return text, nil
+
+	case models.IntakeSingleChoice:
+		var choice string
+		if err := json.Unmarshal(raw, &choice); err != nil || !containsString(q.Options, choice) {
+			return nil, apperror.ValidationError(field, "Choose one of the listed options")
+		}
+		return choice, nil
+
+	case models.IntakeMultiChoice:
+		var choices []string
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, apperror.ValidationError(field, "Choose one of the listed options")
+		}
+		if len(choices) == 0 {
+			return nil, nil
+		}
+		seen := make(map[string]bool, len(choices))
+		for _, choice := range choices {
+			if !containsString(q.Options, choice) || seen[choice] {
+				return nil, apperror.ValidationError(field, "Choose one of the listed options")
+			}
+			seen[choice] = true
+		}
+		return choices, nil
+
+	case models.IntakeScale:
+		min, max := intakeScaleRange(q)
+		var number float64
+		if err := json.Unmarshal(raw, &number); err != nil || number != math.Trunc(number) || number < float64(min) || number > float64(max) {
+			return nil, apperror.ValidationErrorf(field, "Answer must be a whole number from %d to %d", min, max)
+		}
+		return int(number), nil
+
+	case models.IntakeBoolean:
+		var yes bool
+		if err := json.Unmarshal(raw, &yes); err != nil {
+			return nil, apperror.ValidationError(field, "Answer must be yes or no")
+		}
+		return yes, nil
+	}
+	return nil, apperror.ValidationErrorf("questions", "Question %s has unknown type %q", q.ID, q.Type)
+}
+
+// IntakeFreeText returns the answers to text questions, for crisis screening.
+func IntakeFreeText(questions []models.IntakeQuestion, answers map[string]interface{}) map[string]string {
+	texts := map[string]string{}
+	for _, q := range questions {
+		if q.Type != models.IntakeText && q.Type != models.IntakeLongText {
+			continue
+		}
+		if text, ok := answers[q.ID].(string); ok {
+			texts[q.ID] = text
+		}
+	}
+	return texts
+}
+
+func intakeScaleRange(q models.IntakeQuestion) (int, int) {
+	min, max := defaultIntakeScaleMin, defaultIntakeScaleMax
+	if q.Min != nil {
+		min = *q.Min
+	}
+	if q.Max != nil {
+		max = *q.Max
+	}
+	return min, max
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveIntakeForm returns the active intake form, or ErrNoActiveIntakeForm.
+// "No form" is cached as well, as version 0.
+func ActiveIntakeForm(ctx context.Context) (models.IntakeForm, error) {
+	cacheKey := cache.IntakeFormKey()
+	if form, err := cache.Get[models.IntakeForm](ctx, cacheKey); err == nil {
+		if form.Version == 0 {
+			return form, ErrNoActiveIntakeForm
+		}
+		return form, nil
+	}
+
+	form, err := scanIntakeForm(database.Pool.QueryRow(ctx,
+		`SELECT id, version, title, description, questions, is_active, created_by::text, created_at
+		 FROM intake_forms WHERE is_active`,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		_ = cache.Set(ctx, cacheKey, models.IntakeForm{}, cache.IntakeFormTTL)
+		return form, ErrNoActiveIntakeForm
+	}
+	if err != nil {
+		return form, err
+	}
+	_ = cache.Set(ctx, cacheKey, form, cache.IntakeFormTTL)
+	return form, nil
+}
+
+// IntakeFormListPage is how intake form versions page: newest first.
+var IntakeFormListPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"version": {Expr: "version", Type: pagination.TypeInt},
+	},
+	DefaultSort:  "-version",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.IntakeForm{}),
+}
+
+// ListIntakeForms returns the page p of stored versions.
+func ListIntakeForms(ctx context.Context, p pagination.Params) (pagination.Page[models.IntakeForm], error) {
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT id, version, title, description, questions, is_active, created_by::text, created_at, %s
+		 FROM intake_forms
+		 WHERE %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.IntakeForm]{}, err
+	}
+	defer rows.Close()
+
+	forms := make([]models.IntakeForm, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var pos pagination.Position
+		form, err := scanIntakeForm(rows, &pos.Value, &pos.Key)
+		if err != nil {
+			return pagination.Page[models.IntakeForm]{}, err
+		}
+		forms = append(forms, form)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.IntakeForm]{}, err
+	}
+	return pagination.NewPage(p, forms, positions), nil
+}
+
+// CreateIntakeForm validates and stores a new version, making it active.
+func CreateIntakeForm(ctx context.Context, form models.IntakeForm, createdBy string) (models.IntakeForm, error) {
+	form.Title = strings.TrimSpace(form.Title)
+	if form.Title == "" {
+		return form, apperror.ValidationError("title", "Title is required")
+	}
+	if appErr := ValidateIntakeQuestions(form.Questions); appErr != nil {
+		return form, appErr
+	}
+	questions, err := json.Marshal(form.Questions)
+	if err != nil {
+		return form, err
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return form, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize version numbering.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('intake_form'))`); err != nil {
+		return form, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE intake_forms SET is_active = FALSE WHERE is_active`); err != nil {
+		return form, err
+	}
+	stored, err := scanIntakeForm(tx.QueryRow(ctx,
+		`INSERT INTO intake_forms (version, title, description, questions, is_active, created_by)
+		 SELECT COALESCE(MAX(version), 0) + 1, $1, $2, $3, TRUE, $4 FROM intake_forms
+		 RETURNING id, version, title, description, questions, is_active, created_by::text, created_at`,
+		form.Title, strings.TrimSpace(form.Description), string(questions), parseUUID(createdBy),
+	))
+	if err != nil {
+		return form, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return form, err
+	}
+
+	invalidateIntakeFormCache(ctx)
+	return stored, nil
+}
+
+// ActivateIntakeForm makes a stored version active. Version 0 deactivates
+// every version, so no booking needs an intake form.
+func ActivateIntakeForm(ctx context.Context, version int) (models.IntakeForm, error) {
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return models.IntakeForm{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('intake_form'))`); err != nil {
+		return models.IntakeForm{}, err
+	}
+
+	var activated models.IntakeForm
+	if version > 0 {
+		// Look the version up first so a missing one leaves the active form untouched.
+		activated, err = scanIntakeForm(tx.QueryRow(ctx,
+			`SELECT id, version, title, description, questions, is_active, created_by::text, created_at
+			 FROM intake_forms WHERE version = $1`,
+			version,
+		))
+		if err != nil {
+			return models.IntakeForm{}, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE intake_forms SET is_active = FALSE WHERE is_active`); err != nil {
+		return models.IntakeForm{}, err
+	}
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE intake_forms SET is_active = TRUE WHERE id = $1`, activated.ID); err != nil {
+			return models.IntakeForm{}, err
+		}
+		activated.IsActive = true
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.IntakeForm{}, err
+	}
+
+	invalidateIntakeFormCache(ctx)
+	return activated, nil
+}
+
+// SaveIntakeResponse stores validated answers to form for a booking,
+// replacing earlier answers.
+func SaveIntakeResponse(ctx context.Context, bookingID, userID string, form models.IntakeForm, answers map[string]interface{}) (models.IntakeResponse, error) {
+	sealed, err := sealIntakeAnswers(answers)
+	if err != nil {
+		return models.IntakeResponse{}, err
+	}
+
+	resp := models.IntakeResponse{BookingID: bookingID, UserID: userID, FormID: form.ID, FormVersion: form.Version, Answers: answers}
+	err = database.Pool.QueryRow(ctx,
+		`INSERT INTO intake_responses (booking_id, user_id, form_id, answers)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (booking_id) DO UPDATE
+		 SET form_id = EXCLUDED.form_id, answers = EXCLUDED.answers, updated_at = NOW()
+		 RETURNING id, submitted_at, updated_at`,
+		bookingID, userID, form.ID, sealed,
+	).Scan(&resp.ID, &resp.SubmittedAt, &resp.UpdatedAt)
+	return resp, err
+}
+
+// GetIntakeResponse returns a booking's decrypted intake answers and the
+// form version they answer.
+func GetIntakeResponse(ctx context.Context, bookingID string) (models.IntakeResponse, models.IntakeForm, error) {
+	var (
+		resp   models.IntakeResponse
+		sealed string
+	)
+	form, err := scanIntakeForm(database.Pool.QueryRow(ctx,
+		`SELECT f.id, f.version, f.title, f.description, f.questions, f.is_active, f.created_by::text, f.created_at,
+		        r.id, r.booking_id::text, r.user_id::text, r.answers, r.submitted_at, r.updated_at
+		 FROM intake_responses r
+		 JOIN intake_forms f ON f.id = r.form_id
+		 WHERE r.booking_id = $1`,
+		bookingID,
+	), &resp.ID, &resp.BookingID, &resp.UserID, &sealed, &resp.SubmittedAt, &resp.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return resp, form, ErrIntakeResponseNotFound
+	}
+	if err != nil {
+		return resp, form, err
+	}
+
+	resp.FormID, resp.FormVersion = form.ID, form.Version
+	resp.Answers, err = openIntakeAnswers(sealed)
+	return resp, form, err
+}
+
+// scanIntakeForm reads the intake form columns, then extra, from row.
+func scanIntakeForm(row pgx.Row, extra ...interface{}) (models.IntakeForm, error) {
+	var (
+		form      models.IntakeForm
+		questions []byte
+	)
+	targets := append([]interface{}{
+		&form.ID, &form.Version, &form.Title, &form.Description, &questions, &form.IsActive, &form.CreatedBy, &form.CreatedAt,
+	}, extra...)
+	if err := row.Scan(targets...); err != nil {
+		return form, err
+	}
+	if err := json.Unmarshal(questions, &form.Questions); err != nil {
+		return form, err
+	}
+	return form, nil
+}
+
+func invalidateIntakeFormCache(ctx context.Context) {
+	if err := cache.Delete(ctx, cache.IntakeFormKey()); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to invalidate intake form cache", zap.Error(err))
+	}
+}
+
+// sealIntakeAnswers encodes answers for storage, encrypted when PII
+// encryption is enabled.
+func sealIntakeAnswers(answers map[string]interface{}) (string, error) {
+	encoded, err := json.Marshal(answers)
+	if err != nil {
+		return "", err
+	}
+	k := pii.Default()
+	if k == nil {
+		return string(encoded), nil
+	}
+	return k.Encrypt(intakeAnswersField, string(encoded))
+}
+
+func openIntakeAnswers(stored string) (map[string]interface{}, error) {
+	plain := stored
+	if isSealed(&stored) {
+		k := pii.Default()
+		if k == nil {
+			return nil, ErrPIIKeysMissing
+		}
+		var err error
+		if plain, err = k.Decrypt(intakeAnswersField, stored); err != nil {
+			return nil, err
+		}
+	}
+	answers := map[string]interface{}{}
+	err := json.Unmarshal([]byte(plain), &answers)
+	return answers, err
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+)
+
+func TestValidateIntakeQuestions(t *testing.T) {
+	one, five := 1, 5
+	tests := []struct {
+		name      string
+		questions []models.IntakeQuestion
+		ok        bool
+	}{
+		{"valid", []models.IntakeQuestion{
+			{ID: "goals", Type: models.IntakeLongText, Label: "What brings you here?", Required: true},
+			{ID: "mood", Type: models.IntakeScale, Label: "Mood", Min: &one, Max: &five},
+			{ID: "topics", Type: models.IntakeMultiChoice, Label: "Topics", Options: []string{"Career", "Stress"}},
+		}, true},
+		{"no questions", nil, false},
+		{"duplicate id", []models.IntakeQuestion{
+			{ID: "goals", Type: models.IntakeText, Label: "A"},
+			{ID: "goals", Type: models.IntakeText, Label: "B"},
+		}, false},
+		{"id not a slug", []models.IntakeQuestion{{ID: "Your Goals", Type: models.IntakeText, Label: "A"}}, false},
+		{"missing label", []models.IntakeQuestion{{ID: "goals", Type: models.IntakeText, Label: " "}}, false},
+		{"unknown type", []models.IntakeQuestion{{ID: "goals", Type: "date", Label: "A"}}, false},
+		{"one option", []models.IntakeQuestion{{ID: "pick", Type: models.IntakeSingleChoice, Label: "A", Options: []string{"Yes"}}}, false},
+		{"repeated option", []models.IntakeQuestion{{ID: "pick", Type: models.IntakeSingleChoice, Label: "A", Options: []string{"Yes", "Yes"}}}, false},
+		{"inverted scale", []models.IntakeQuestion{{ID: "mood", Type: models.IntakeScale, Label: "A", Min: &five, Max: &one}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateIntakeQuestions(tc.questions); (err == nil) != tc.ok {
+				t.Fatalf("ValidateIntakeQuestions() = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestValidateIntakeAnswers(t *testing.T) {
+	questions := []models.IntakeQuestion{
+		{ID: "goals", Type: models.IntakeText, Label: "Goals", Required: true, MaxLength: 10},
+		{ID: "format", Type: models.IntakeSingleChoice, Label: "Format", Options: []string{"Video", "Audio"}},
+		{ID: "topics", Type: models.IntakeMultiChoice, Label: "Topics", Options: []string{"Career", "Stress"}},
+		{ID: "mood", Type: models.IntakeScale, Label: "Mood"},
+		{ID: "first", Type: models.IntakeBoolean, Label: "First time?"},
+	}
+	tests := []struct {
+		name    string
+		answers string
+		want    map[string]interface{}
+		field   string
+	}{
+		{"all answered", `{"goals":" Focus ","format":"Audio","topics":["Stress"],"mood":7,"first":true}`,
+			map[string]interface{}{"goals": "Focus", "format": "Audio", "topics": []string{"Stress"}, "mood": 7, "first": true}, ""},
+		{"optional blanks dropped", `{"goals":"Focus","format":null,"topics":[]}`,
+			map[string]interface{}{"goals": "Focus"}, ""},
+		{"required missing", `{"mood":3}`, nil, "answers.goals"},
+		{"required blank", `{"goals":"   "}`, nil, "answers.goals"},
+		{"too long", `{"goals":"far too long"}`, nil, "answers.goals"},
+		{"not text", `{"goals":5}`, nil, "answers.goals"},
+		{"unlisted option", `{"goals":"Focus","format":"Chat"}`, nil, "answers.format"},
+		{"repeated choice", `{"goals":"Focus","topics":["Career","Career"]}`, nil, "answers.topics"},
+		{"scale out of range", `{"goals":"Focus","mood":11}`, nil, "answers.mood"},
+		{"scale fraction", `{"goals":"Focus","mood":2.5}`, nil, "answers.mood"},
+		{"not boolean", `{"goals":"Focus","first":"yes"}`, nil, "answers.first"},
+		{"unknown question", `{"goals":"Focus","age":30}`, nil, "answers"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tc.answers), &raw); err != nil {
+				t.Fatalf("bad fixture: %v", err)
+			}
+			got, appErr := ValidateIntakeAnswers(questions, raw)
+			if tc.field != "" {
+				if appErr == nil || appErr.Context["field"] != tc.field {
+					t.Fatalf("ValidateIntakeAnswers() error = %v, want field %q", appErr, tc.field)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("ValidateIntakeAnswers() error = %v", appErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ValidateIntakeAnswers() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEmailJoinLink(t *testing.T) {
+	const link = "https://hidden-depths-web.pages.dev/session?room=abc-2026-10-20"
+	tests := []struct {
+		pending bool
+		link    string
+		want    string
+	}{
+		{false, link, link},
+		{true, link, emailProfile},
+		{true, "", ""},
+	}
+	for _, tt := range tests {
+		if got := EmailJoinLink(tt.pending, tt.link); got != tt.want {
+			t.Fatalf("EmailJoinLink(%v, %q) = %q, want %q", tt.pending, tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,620 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Ledger accounts (ledger_lines.account). Display names come from
+// LedgerConfig.Accounts.
+const (
+	AccountRazorpayClearing = "razorpay_clearing"
+	AccountBank             = "bank"
+	AccountSessionRevenue   = "session_revenue"
+	AccountDiscounts        = "discounts_allowed"
+	AccountGatewayFees      = "gateway_fees"
+	AccountGSTInputCredit   = "gst_input_credit"
+)
+
+// Kinds of ledger transaction (ledger_transactions.kind).
+const (
+	LedgerPayment    = "payment"
+	LedgerDiscount   = "discount"
+	LedgerRefund     = "refund"
+	LedgerSettlement = "settlement" // includes the gateway fee
+)
+
+// ledgerSyncBatch bounds how many postings of one kind a sync query loads.
+const ledgerSyncBatch = 500
+
+var (
+	// ErrPeriodNotEnded is returned when closing a month that is not over.
+	ErrPeriodNotEnded = errors.New("ledger period has not ended")
+	// ErrPeriodClosed is returned when closing a month twice.
+	ErrPeriodClosed = errors.New("ledger period is already closed")
+	// ErrPeriodOpen is returned when exporting a month that is not closed.
+	ErrPeriodOpen = errors.New("ledger period is not closed")
+)
+
+var ledgerPeriodPattern = regexp.MustCompile(`^[0-9]{4}-(0[1-9]|1[0-2])$`)
+
+// ValidLedgerPeriod reports whether period is a month in YYYY-MM format.
+func ValidLedgerPeriod(period string) bool {
+	return ledgerPeriodPattern.MatchString(period)
+}
+
+// LedgerConfig controls the accounting ledger.
+type LedgerConfig struct {
+	Accounts map[string]string // display name per account; missing accounts use their code
+	Timezone string            // IANA zone dates and periods are cut in (default Asia/Kolkata)
+}
+
+type ledgerSettings struct {
+	accounts map[string]string
+	location *time.Location
+}
+
+var (
+	ledgerConfigMu sync.RWMutex
+	ledgerConfig   = ledgerSettings{location: mustLoadLocation("Asia/Kolkata")}
+)
+
+// SetLedgerConfig sets process-wide ledger settings. Call once at startup.
+func SetLedgerConfig(cfg LedgerConfig) {
+	loc := mustLoadLocation("Asia/Kolkata")
+	if cfg.Timezone != "" {
+		if l, err := time.LoadLocation(cfg.Timezone); err == nil {
+			loc = l
+		} else {
+			logger.Warn("Invalid ledger timezone, using Asia/Kolkata", zap.String("timezone", cfg.Timezone))
+		}
+	}
+	accounts := make(map[string]string, len(cfg.Accounts))
+	for account, name := range cfg.Accounts {
+		accounts[account] = name
+	}
+
+	ledgerConfigMu.Lock()
+	defer ledgerConfigMu.Unlock()
+	ledgerConfig = ledgerSettings{accounts: accounts, location: loc}
+}
+
+func getLedgerConfig() ledgerSettings {
+	ledgerConfigMu.RLock()
+	defer ledgerConfigMu.RUnlock()
+	return ledgerConfig
+}
+
+func (s ledgerSettings) accountName(account string) string {
+	if name := s.accounts[account]; name != "" {
+		return name
+	}
+	return account
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ledgerLine is one side of a posting. Amounts are in paise so entries
+// balance exactly.
+type ledgerLine struct {
+	Account string
+	Debit   int64
+	Credit  int64
+}
+
+// ledgerEntry is a transaction waiting to be posted.
+type ledgerEntry struct {
+	Kind      string
+	SourceRef string // unique per kind, e.g. the booking or refund ID
+	BookingID *string
+	Date      time.Time // posting date; only the calendar date is used
+	Narration string
+	Lines     []ledgerLine
+}
+
+// balanced reports whether lines are non-empty, one-sided, non-negative and
+// debits equal credits.
+func balanced(lines []ledgerLine) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	var debits, credits int64
+	for _, l := range lines {
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit == 0) == (l.Credit == 0) {
+			return false
+		}
+		debits += l.Debit
+		credits += l.Credit
+	}
+	return debits == credits
+}
+
+// withoutZeroLines drops lines with no amount, e.g. a fee without tax.
+func withoutZeroLines(lines []ledgerLine) []ledgerLine {
+	kept := lines[:0]
+	for _, l := range lines {
+		if l.Debit != 0 || l.Credit != 0 {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// formatPaise renders paise as rupees with two decimals, e.g. -150 as "-1.50".
+func formatPaise(paise int64) string {
+	sign := ""
+	if paise < 0 {
+		sign, paise = "-", -paise
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, paise/100, paise%100)
+}
+
+// ledgerPeriodOf is the YYYY-MM period of a posting date.
+func ledgerPeriodOf(date time.Time) string {
+	return date.Format("2006-01")
+}
+
+// lockLedgerPeriods serialises postings against period closing: postings
+// share the lock, ClosePeriod takes it exclusively.
+func lockLedgerPeriods(ctx context.Context, tx pgx.Tx, exclusive bool) error {
+	query := `SELECT pg_advisory_xact_lock_shared(hashtext('ledger_periods'))`
+	if exclusive {
+		query = `SELECT pg_advisory_xact_lock(hashtext('ledger_periods'))`
+	}
+	_, err := tx.Exec(ctx, query)
+	return err
+}
+
+// postLedgerEntry writes e unless a transaction with its kind and source
+// already exists, and reports whether it did. Entries dated in a closed
+// period are posted on today's date instead, so closed periods never change.
+func postLedgerEntry(ctx context.Context, e ledgerEntry) (bool, error) {
+	e.Lines = withoutZeroLines(e.Lines)
+	if !balanced(e.Lines) {
+		return false, fmt.Errorf("ledger: unbalanced %s entry %s", e.Kind, e.SourceRef)
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockLedgerPeriods(ctx, tx, false); err != nil {
+		return false, err
+	}
+	loc := getLedgerConfig().location
+	date := e.Date.In(loc)
+	var closed bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM ledger_periods WHERE period = $1)`, ledgerPeriodOf(date),
+	).Scan(&closed); err != nil {
+		return false, err
+	}
+	if closed {
+		date = time.Now().In(loc)
+	}
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO ledger_transactions (kind, source_ref, booking_id, posted_on, narration)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (kind, source_ref) DO NOTHING
+		 RETURNING id`,
+		e.Kind, e.SourceRef, e.BookingID, date.Format("2006-01-02"), e.Narration,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, l := range e.Lines {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO ledger_lines (transaction_id, account, debit, credit)
+			 VALUES ($1, $2, $3::numeric / 100, $4::numeric / 100)`,
+			id, l.Account, l.Debit, l.Credit,
+		); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ledgerSource finds entries of one kind that are not posted yet. The query
+// has a %s placeholder for the condition on bookings b that postLedgerSource
+// fills in.
+type ledgerSource struct {
+	kind  string
+	query string
+	scan  func(pgx.CollectableRow) (ledgerEntry, error)
+}
+
+// paymentEntry books a captured payment into clearing, or straight to the
+// bank when it was confirmed offline without a gateway payment.
+func paymentEntry(bookingID string, paise int64, paymentID, reference string, confirmedAt time.Time) ledgerEntry {
+	debit, narration := AccountRazorpayClearing, "Session booking "+bookingID+", Razorpay payment "+paymentID
+	if paymentID == "" {
+		debit, narration = AccountBank, "Session booking "+bookingID+", paid offline"
+		if reference != "" {
+			narration += " (" + reference + ")"
+		}
+	}
+	return ledgerEntry{
+		Kind:      LedgerPayment,
+		SourceRef: bookingID,
+		BookingID: &bookingID,
+		Date:      confirmedAt,
+		Narration: narration,
+		Lines: []ledgerLine{
+			{Account: debit, Debit: paise},
+			{Account: AccountSessionRevenue, Credit: paise},
+		},
+	}
+}
+
+// discountEntry grosses revenue up by a coupon discount, so revenue is the
+// list price and the discount shows as an expense.
+func discountEntry(couponUseID, bookingID string, paise int64, code string, confirmedAt time.Time) ledgerEntry {
+	return ledgerEntry{
+		Kind:      LedgerDiscount,
+		SourceRef: couponUseID,
+		BookingID: &bookingID,
+		Date:      confirmedAt,
+		Narration: "Coupon " + code + " on session booking " + bookingID,
+		Lines: []ledgerLine{
+			{Account: AccountDiscounts, Debit: paise},
+			{Account: AccountSessionRevenue, Credit: paise},
+		},
+	}
+}
+
+// refundEntry reverses revenue for a gateway refund.
+func refundEntry(refundID, bookingID string, paise int64, refundedAt time.Time) ledgerEntry {
+	return ledgerEntry{
+		Kind:      LedgerRefund,
+		SourceRef: refundID,
+		BookingID: &bookingID,
+		Date:      refundedAt,
+		Narration: "Refund " + refundID + " of session booking " + bookingID,
+		Lines: []ledgerLine{
+			{Account: AccountSessionRevenue, Debit: paise},
+			{Account: AccountRazorpayClearing, Credit: paise},
+		},
+	}
+}
+
+var ledgerSources = []ledgerSource{
+	{
+		kind: LedgerPayment,
+		query: `SELECT b.id, (b.amount * 100)::bigint, COALESCE(b.razorpay_payment_id, ''), COALESCE(b.payment_reference, ''), b.confirmed_at
+			FROM bookings b
+			WHERE b.confirmed_at IS NOT NULL AND COALESCE(b.amount, 0) > 0
+			  AND %s
+			  AND NOT EXISTS (SELECT 1 FROM ledger_transactions t WHERE t.kind = 'payment' AND t.source_ref = b.id::text)
+			ORDER BY b.confirmed_at`,
+		scan: func(rows pgx.CollectableRow) (ledgerEntry, error) {
+			var id, paymentID, reference string
+			var paise int64
+			var confirmedAt time.Time
+			err := rows.Scan(&id, &paise, &paymentID, &reference, &confirmedAt)
+			return paymentEntry(id, paise, paymentID, reference, confirmedAt), err
+		},
+	},
+	{
+		kind: LedgerDiscount,
+		query: `SELECT cu.id, b.id, (cu.discount_applied * 100)::bigint, COALESCE(c.code, ''), b.confirmed_at
+			FROM coupon_uses cu
+			JOIN bookings b ON b.id = cu.booking_id
+			LEFT JOIN coupons c ON c.id = cu.coupon_id
+			WHERE b.confirmed_at IS NOT NULL AND COALESCE(cu.discount_applied, 0) > 0
+			  AND %s
+			  AND NOT EXISTS (SELECT 1 FROM ledger_transactions t WHERE t.kind = 'discount' AND t.source_ref = cu.id::text)
+			ORDER BY b.confirmed_at`,
+		scan: func(rows pgx.CollectableRow) (ledgerEntry, error) {
+			var id, bookingID, code string
+			var paise int64
+			var confirmedAt time.Time
+			err := rows.Scan(&id, &bookingID, &paise, &code, &confirmedAt)
+			return discountEntry(id, bookingID, paise, code, confirmedAt), err
+		},
+	},
+	{
+		kind: LedgerRefund,
+		query: `SELECT b.refund_id, b.id, (b.refund_amount * 100)::bigint, b.refunded_at
+			FROM bookings b
+			WHERE b.refund_id IS NOT NULL AND b.refunded_at IS NOT NULL AND COALESCE(b.refund_amount, 0) > 0
+			  AND %s
+			  AND NOT EXISTS (SELECT 1 FROM ledger_transactions t WHERE t.kind = 'refund' AND t.source_ref = b.refund_id)
+			ORDER BY b.refunded_at`,
+		scan: func(rows pgx.CollectableRow) (ledgerEntry, error) {
+			var refundID, bookingID string
+			var paise int64
+			var refundedAt time.Time
+			err := rows.Scan(&refundID, &bookingID, &paise, &refundedAt)
+			return refundEntry(refundID, bookingID, paise, refundedAt), err
+		},
+	},
+}
+
+// postLedgerSource posts up to ledgerSyncBatch missing entries of one kind
+// and returns how many it posted and how many it found. An empty bookingID
+// means every booking; otherwise the filter is b.id = $1::uuid so the
+// primary key serves the per-booking posts made on every confirmation.
+func postLedgerSource(ctx context.Context, src ledgerSource, bookingID string) (posted, found int, err error) {
+	filter, args := "TRUE", []any{}
+	if bookingID != "" {
+		filter, args = "b.id = $1::uuid", []any{bookingID}
+	}
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(src.query, filter)+fmt.Sprintf(" LIMIT %d", ledgerSyncBatch), args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ledgerEntry, error) {
+		return src.scan(row)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, e := range entries {
+		ok, err := postLedgerEntry(ctx, e)
+		if err != nil {
+			return posted, len(entries), err
+		}
+		if ok {
+			posted++
+		}
+	}
+	return posted, len(entries), nil
+}
+
+// PostBookingLedger posts a booking's payment, discount and refund if they
+// are not posted yet. It is best effort: failures are logged, never
+// returned, and SyncLedger posts anything missed.
+func PostBookingLedger(ctx context.Context, bookingID string) {
+	if bookingID == "" {
+		return
+	}
+	for _, src := range ledgerSources {
+		if _, _, err := postLedgerSource(ctx, src, bookingID); err != nil {
+			logger.Warn("Failed to post booking to ledger",
+				zap.String("booking_id", bookingID), zap.String("kind", src.kind), zap.Error(err),
+			)
+		}
+	}
+}
+
+// syncLedger posts every payment, discount, refund and settled line that is
+// missing from the ledger and returns how many transactions it wrote.
+func syncLedger(ctx context.Context) (int, error) {
+	total := 0
+	for _, src := range ledgerSources {
+		for {
+			posted, found, err := postLedgerSource(ctx, src, "")
+			total += posted
+			if err != nil {
+				return total, fmt.Errorf("%s: %w", src.kind, err)
+			}
+			if found < ledgerSyncBatch {
+				break
+			}
+		}
+	}
+	posted, err := postSettlementLines(ctx)
+	total += posted
+	return total, err
+}
+
+// SyncLedger runs hourly to post anything the booking and settlement hooks
+// missed, on one instance at a time.
+func SyncLedger() {
+	ctx, cancel := context.WithTimeout(context.Background(), schedulerTimeout)
+	defer cancel()
+
+	err := withJobLock(ctx, "ledger-sync", func(ctx context.Context) error {
+		posted, err := syncLedger(ctx)
+		if posted > 0 {
+			logger.Info("Posted missing ledger transactions", zap.Int("posted", posted))
+		}
+		return err
+	})
+	switch {
+	case errors.Is(err, errJobLocked):
+		logger.Info("Ledger sync skipped: running on another instance")
+	case err != nil:
+		logger.Error("Ledger sync failed", zap.Error(err))
+	}
+}
+
+// ListLedgerPeriods returns, newest first, every month that has postings or
+// was closed, with a trial balance per account.
+func ListLedgerPeriods(ctx context.Context) ([]models.LedgerPeriod, error) {
+	settings := getLedgerConfig()
+	rows, err := database.Pool.Query(ctx,
+		`WITH months AS (
+			SELECT to_char(posted_on, 'YYYY-MM') AS period FROM ledger_transactions
+			UNION
+			SELECT period FROM ledger_periods
+		)
+		SELECT m.period, p.closed_at, p.closed_by::text,
+		       (SELECT COUNT(*) FROM ledger_transactions t WHERE to_char(t.posted_on, 'YYYY-MM') = m.period)
+		FROM months m
+		LEFT JOIN ledger_periods p ON p.period = m.period
+		ORDER BY m.period DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	periods, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.LedgerPeriod, error) {
+		var p models.LedgerPeriod
+		err := row.Scan(&p.Period, &p.ClosedAt, &p.ClosedBy, &p.Transactions)
+		p.Closed = p.ClosedAt != nil
+		p.Accounts = []models.LedgerAccountBalance{}
+		return p, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = database.Pool.Query(ctx,
+		`SELECT to_char(t.posted_on, 'YYYY-MM'), l.account, SUM(l.debit)::float8, SUM(l.credit)::float8
+		 FROM ledger_lines l
+		 JOIN ledger_transactions t ON t.id = l.transaction_id
+		 GROUP BY 1, 2
+		 ORDER BY 1, 2`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(periods))
+	for i, p := range periods {
+		index[p.Period] = i
+	}
+	for rows.Next() {
+		var period string
+		var b models.LedgerAccountBalance
+		if err := rows.Scan(&period, &b.Account, &b.Debit, &b.Credit); err != nil {
+			return nil, err
+		}
+		b.Name = settings.accountName(b.Account)
+		if i, ok := index[period]; ok {
+			periods[i].Accounts = append(periods[i].Accounts, b)
+		}
+	}
+	return periods, rows.Err()
+}
+
+// ClosePeriod posts anything missing and then closes a month that has ended
+// in the ledger timezone. Later postings dated in it are moved to the date
+// they are made, so exports of a closed period never change.
+func ClosePeriod(ctx context.Context, period, closedBy string) (models.LedgerPeriod, error) {
+	result := models.LedgerPeriod{Period: period, Accounts: []models.LedgerAccountBalance{}}
+	start, err := time.ParseInLocation("2006-01", period, getLedgerConfig().location)
+	if err != nil {
+		return result, err
+	}
+	if !time.Now().After(start.AddDate(0, 1, 0)) {
+		return result, ErrPeriodNotEnded
+	}
+
+	if _, err := syncLedger(ctx); err != nil {
+		return result, err
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockLedgerPeriods(ctx, tx, true); err != nil {
+		return result, err
+	}
+	var closedAt time.Time
+	err = tx.QueryRow(ctx,
+		`INSERT INTO ledger_periods (period, closed_by)
+		 VALUES ($1, $2)
+		 ON CONFLICT (period) DO NOTHING
+		 RETURNING closed_at`,
+		period, nilIfBlank(closedBy),
+	).Scan(&closedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return result, ErrPeriodClosed
+	}
+	if err != nil {
+		return result, err
+	}
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM ledger_transactions WHERE to_char(posted_on, 'YYYY-MM') = $1`, period,
+	).Scan(&result.Transactions); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+
+	result.Closed = true
+	result.ClosedAt = &closedAt
+	if closedBy != "" {
+		result.ClosedBy = &closedBy
+	}
+	return result, nil
+}
+
+// ledgerJournal is a posted transaction with its lines, as exported.
+type ledgerJournal struct {
+	Number    string // voucher number, sequential within the period
+	ID        string
+	Kind      string
+	SourceRef string
+	Date      time.Time
+	Narration string
+	Lines     []ledgerLine
+}
+
+// loadPeriodJournals returns the transactions of a closed period in posting
+// order, numbered HD/<period>/0001 onwards.
+func loadPeriodJournals(ctx context.Context, period string) ([]ledgerJournal, error) {
+	var closed bool
+	if err := database.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM ledger_periods WHERE period = $1)`, period,
+	).Scan(&closed); err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrPeriodOpen
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT t.id, t.kind, t.source_ref, t.posted_on, t.narration,
+		        l.account, (l.debit * 100)::bigint, (l.credit * 100)::bigint
+		 FROM ledger_transactions t
+		 JOIN ledger_lines l ON l.transaction_id = t.id
+		 WHERE to_char(t.posted_on, 'YYYY-MM') = $1
+		 ORDER BY t.posted_on, t.created_at, t.id, l.id`,
+		period,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	journals := []ledgerJournal{}
+	for rows.Next() {
+		var j ledgerJournal
+		var l ledgerLine
+		if err := rows.Scan(&j.ID, &j.Kind, &j.SourceRef, &j.Date, &j.Narration, &l.Account, &l.Debit, &l.Credit); err != nil {
+			return nil, err
+		}
+		if n := len(journals); n == 0 || journals[n-1].ID != j.ID {
+			j.Number = fmt.Sprintf("HD/%s/%04d", period, n+1)
+			journals = append(journals, j)
+		}
+		last := &journals[len(journals)-1]
+		last.Lines = append(last.Lines, l)
+	}
+	return journals, rows.Err()
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/export"
+)
+
+// Accounting export formats.
+const (
+	LedgerFormatTally = "tally" // Tally Prime / ERP 9 XML import of journal vouchers
+	LedgerFormatZoho  = "zoho"  // Zoho Books manual journal CSV import
+)
+
+// IsLedgerFormat reports whether format is an accounting export format.
+func IsLedgerFormat(format string) bool {
+	return format == LedgerFormatTally || format == LedgerFormatZoho
+}
+
+// LedgerExport holds the journals of a closed period, ready to write in an
+// accounting system's import format.
+type LedgerExport struct {
+	Period   string
+	journals []ledgerJournal
+	settings ledgerSettings
+}
+
+// LoadLedgerExport loads a closed period. It returns ErrPeriodOpen if the
+// period has not been closed, so exports never change once taken.
+func LoadLedgerExport(ctx context.Context, period string) (*LedgerExport, error) {
+	journals, err := loadPeriodJournals(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	return &LedgerExport{Period: period, journals: journals, settings: getLedgerConfig()}, nil
+}
+
+// Transactions is the number of journals in the export.
+func (e *LedgerExport) Transactions() int {
+	return len(e.journals)
+}
+
+// Filename is the suggested download name for format.
+func (e *LedgerExport) Filename(format string) string {
+	if format == LedgerFormatTally {
+		return fmt.Sprintf("ledger-%s-tally.xml", e.Period)
+	}
+	return fmt.Sprintf("ledger-%s-zoho.csv", e.Period)
+}
+
+// ContentType is the MIME type of format.
+func (e *LedgerExport) ContentType(format string) string {
+	if format == LedgerFormatTally {
+		return "application/xml; charset=utf-8"
+	}
+	return export.ContentType(export.FormatCSV)
+}
+
+// Write writes the export to w in format.
+func (e *LedgerExport) Write(w io.Writer, format string) error {
+	switch format {
+	case LedgerFormatTally:
+		return writeTallyXML(w, e.journals, e.settings)
+	case LedgerFormatZoho:
+		return writeZohoCSV(w, e.journals, e.settings)
+	default:
+		return fmt.Errorf("ledger: unsupported export format %q", format)
+	}
+}
+
+type tallyEnvelope struct {
+	XMLName      xml.Name       `xml:"ENVELOPE"`
+	TallyRequest string         `xml:"HEADER>TALLYREQUEST"`
+	ReportName   string         `xml:"BODY>IMPORTDATA>REQUESTDESC>REPORTNAME"`
+	Messages     []tallyMessage `xml:"BODY>IMPORTDATA>REQUESTDATA>TALLYMESSAGE"`
+}
+
+type tallyMessage struct {
+	Voucher tallyVoucher `xml:"VOUCHER"`
+}
+
+type tallyVoucher struct {
+	VoucherType     string             `xml:"VCHTYPE,attr"`
+	Action          string             `xml:"ACTION,attr"`
+	Date            string             `xml:"DATE"`
+	VoucherTypeName string             `xml:"VOUCHERTYPENAME"`
+	VoucherNumber   string             `xml:"VOUCHERNUMBER"`
+	Reference       string             `xml:"REFERENCE"`
+	Narration       string             `xml:"NARRATION"`
+	Entries         []tallyLedgerEntry `xml:"ALLLEDGERENTRIES.LIST"`
+}
+
+// tallyLedgerEntry is one side of a voucher. Tally writes debits as negative
+// amounts that are "deemed positive", and credits as positive amounts.
+type tallyLedgerEntry struct {
+	LedgerName       string `xml:"LEDGERNAME"`
+	IsDeemedPositive string `xml:"ISDEEMEDPOSITIVE"`
+	Amount           string `xml:"AMOUNT"`
+}
+
+// writeTallyXML writes journals as Tally journal vouchers. Ledger names must
+// already exist in the Tally company.
+func writeTallyXML(w io.Writer, journals []ledgerJournal, settings ledgerSettings) error {
+	envelope := tallyEnvelope{TallyRequest: "Import Data", ReportName: "Vouchers"}
+	for _, j := range journals {
+		voucher := tallyVoucher{
+			VoucherType:     "Journal",
+			Action:          "Create",
+			Date:            j.Date.Format("20060102"),
+			VoucherTypeName: "Journal",
+			VoucherNumber:   j.Number,
+			Reference:       j.Kind + ":" + j.SourceRef,
+			Narration:       j.Narration,
+		}
+		for _, l := range j.Lines {
+			entry := tallyLedgerEntry{LedgerName: settings.accountName(l.Account), IsDeemedPositive: "No", Amount: formatPaise(l.Credit)}
+			if l.Debit > 0 {
+				entry.IsDeemedPositive, entry.Amount = "Yes", formatPaise(-l.Debit)
+			}
+			voucher.Entries = append(voucher.Entries, entry)
+		}
+		envelope.Messages = append(envelope.Messages, tallyMessage{Voucher: voucher})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(envelope); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeZohoCSV writes journals in the Zoho Books manual journal import
+// layout: one row per line, with the journal's fields repeated.
+func writeZohoCSV(w io.Writer, journals []ledgerJournal, settings ledgerSettings) error {
+	out, err := export.New(export.FormatCSV, w, "")
+	if err != nil {
+		return err
+	}
+	if err := out.WriteRow("Journal Date", "Journal Number", "Reference Number", "Notes", "Currency", "Account", "Description", "Debit", "Credit"); err != nil {
+		return err
+	}
+	for _, j := range journals {
+		for _, l := range j.Lines {
+			debit, credit := "", ""
+			if l.Debit > 0 {
+				debit = formatPaise(l.Debit)
+			} else {
+				credit = formatPaise(l.Credit)
+			}
+			if err := out.WriteRow(j.Date.Format("2006-01-02"), j.Number, j.Kind+":"+j.SourceRef, j.Narration, "INR",
+				settings.accountName(l.Account), j.Narration, debit, credit); err != nil {
+				return err
+			}
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLedgerEntriesBalance(t *testing.T) {
+	at := time.Date(2026, 9, 30, 20, 0, 0, 0, time.UTC)
+	booking := "b1"
+	tests := []struct {
+		name  string
+		entry ledgerEntry
+		lines int
+	}{
+		{"gateway payment", paymentEntry(booking, 149900, "pay_1", "", at), 2},
+		{"offline payment", paymentEntry(booking, 149900, "", "UPI 123", at), 2},
+		{"discount", discountEntry("cu1", booking, 50000, "WELCOME", at), 2},
+		{"refund", refundEntry("rfnd_1", booking, 149900, at), 2},
+		{"settled payment", settlementEntry("setl_1", "pay_1", "payment", 149900, 3538, 540, "UTR1", &booking, at), 4},
+		{"settled payment without tax", settlementEntry("setl_1", "pay_2", "payment", 149900, 2998, 0, "", &booking, at), 3},
+		{"settled refund", settlementEntry("setl_2", "rfnd_1", "refund", 149900, 0, 0, "", &booking, at), 2},
+	}
+	for _, tt := range tests {
+		lines := withoutZeroLines(tt.entry.Lines)
+		if !balanced(lines) {
+			t.Fatalf("%s: entry does not balance: %+v", tt.name, lines)
+		}
+		if len(lines) != tt.lines {
+			t.Fatalf("%s: got %d lines, want %d", tt.name, len(lines), tt.lines)
+		}
+	}
+
+	if e := paymentEntry(booking, 100, "", "", at); e.Lines[0].Account != AccountBank {
+		t.Fatalf("offline payment debits %q, want %q", e.Lines[0].Account, AccountBank)
+	}
+	for _, lines := range [][]ledgerLine{
+		{{Account: AccountBank, Debit: 100}},
+		{{Account: AccountBank, Debit: 100}, {Account: AccountSessionRevenue, Credit: 99}},
+		{{Account: AccountBank, Debit: 100, Credit: 100}, {Account: AccountSessionRevenue, Credit: 0, Debit: 0}},
+		{{Account: AccountBank, Debit: -100}, {Account: AccountSessionRevenue, Credit: -100}},
+	} {
+		if balanced(lines) {
+			t.Fatalf("balanced(%+v) = true, want false", lines)
+		}
+	}
+}
+
+func TestParseSettlementReport(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	report := "\ufeffentity_id,type,debit,credit,amount,currency,fee,tax,settlement_id,settled_at,settlement_utr\n" +
+		"pay_1,payment,0,1464.62,\"1,499.00\",INR,35.38,5.40,setl_1,2026-10-02 09:30:00,UTR1\n" +
+		"rfnd_1,refund,499,0,499.00,INR,0,0,setl_1,1790000000,UTR1\n" +
+		",,,,,,,,,,\n" +
+		"adj_1,adjustment,0,10,10,INR,,,setl_1,,\n"
+
+	rows, err := parseSettlementReport(strings.NewReader(report), loc)
+	if err != nil {
+		t.Fatalf("parseSettlementReport() error = %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("parseSettlementReport() returned %d rows, want 3", len(rows))
+	}
+	first := rows[0]
+	if first.EntityID != "pay_1" || first.Amount != 149900 || first.Fee != 3538 || first.Tax != 540 || first.UTR != "UTR1" {
+		t.Fatalf("first row = %+v", first)
+	}
+	if want := time.Date(2026, 10, 2, 9, 30, 0, 0, loc); first.SettledAt == nil || !first.SettledAt.Equal(want) {
+		t.Fatalf("first row settled_at = %v, want %v", first.SettledAt, want)
+	}
+	if rows[1].EntityType != "refund" || rows[1].SettledAt == nil || rows[1].SettledAt.Unix() != 1790000000 {
+		t.Fatalf("second row = %+v", rows[1])
+	}
+	if rows[2].SettledAt != nil || rows[2].Fee != 0 {
+		t.Fatalf("third row = %+v", rows[2])
+	}
+
+	for _, bad := range []string{
+		"",
+		"entity_id,type,amount,fee,tax\npay_1,payment,10,0,0\n",
+		"entity_id,type,amount,fee,tax,settlement_id\n",
+		"entity_id,type,amount,fee,tax,settlement_id\npay_1,payment,ten,0,0,setl_1\n",
+		"entity_id,type,amount,fee,tax,settlement_id\npay_1,payment,10,1,2,setl_1\n",
+		"entity_id,type,amount,fee,tax,settlement_id\npay_1,payment,-10,0,0,setl_1\n",
+		"entity_id,type,amount,fee,tax,settlement_id,settled_at\npay_1,payment,10,0,0,setl_1,yesterday\n",
+	} {
+		var reportErr *SettlementReportError
+		if _, err := parseSettlementReport(strings.NewReader(bad), loc); !errors.As(err, &reportErr) {
+			t.Fatalf("parseSettlementReport(%q) error = %v, want *SettlementReportError", bad, err)
+		}
+	}
+}
+
+func TestWriteLedgerExports(t *testing.T) {
+	settings := ledgerSettings{accounts: map[string]string{AccountBank: "HDFC Bank"}}
+	journals := []ledgerJournal{{
+		Number:    "HD/2026-09/0001",
+		Kind:      LedgerPayment,
+		SourceRef: "b1",
+		Date:      time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+		Narration: "Session booking b1, paid offline (cash & card)",
+		Lines: []ledgerLine{
+			{Account: AccountBank, Debit: 149950},
+			{Account: AccountSessionRevenue, Credit: 149950},
+		},
+	}}
+
+	var tally bytes.Buffer
+	if err := writeTallyXML(&tally, journals, settings); err != nil {
+		t.Fatalf("writeTallyXML() error = %v", err)
+	}
+	compact := regexp.MustCompile(`>\s+<`).ReplaceAllString(tally.String(), "><")
+	for _, want := range []string{
+		"<TALLYREQUEST>Import Data</TALLYREQUEST>",
+		`<VOUCHER VCHTYPE="Journal" ACTION="Create">`,
+		"<DATE>20260930</DATE>",
+		"<VOUCHERNUMBER>HD/2026-09/0001</VOUCHERNUMBER>",
+		"(cash &amp; card)",
+		"<LEDGERNAME>HDFC Bank</LEDGERNAME><ISDEEMEDPOSITIVE>Yes</ISDEEMEDPOSITIVE><AMOUNT>-1499.50</AMOUNT>",
+		"<LEDGERNAME>session_revenue</LEDGERNAME><ISDEEMEDPOSITIVE>No</ISDEEMEDPOSITIVE><AMOUNT>1499.50</AMOUNT>",
+	} {
+		if !strings.Contains(compact, want) {
+			t.Fatalf("Tally XML missing %q:\n%s", want, tally.String())
+		}
+	}
+
+	var zoho bytes.Buffer
+	if err := writeZohoCSV(&zoho, journals, settings); err != nil {
+		t.Fatalf("writeZohoCSV() error = %v", err)
+	}
+	want := "Journal Date,Journal Number,Reference Number,Notes,Currency,Account,Description,Debit,Credit\n" +
+		"2026-09-30,HD/2026-09/0001,payment:b1,\"Session booking b1, paid offline (cash & card)\",INR,HDFC Bank,\"Session booking b1, paid offline (cash & card)\",1499.50,\n" +
+		"2026-09-30,HD/2026-09/0001,payment:b1,\"Session booking b1, paid offline (cash & card)\",INR,session_revenue,\"Session booking b1, paid offline (cash & card)\",,1499.50\n"
+	if zoho.String() != want {
+		t.Fatalf("Zoho CSV =\n%s\nwant\n%s", zoho.String(), want)
+	}
+}
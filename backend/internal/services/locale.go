@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// PreferredLocale returns a user's saved language preference, or "" when none
+// is stored or it cannot be read. "No preference" is cached as well, so the
+// lookup costs one Redis round trip per authenticated request.
+func PreferredLocale(ctx context.Context, userID string) string {
+	key := cache.UserLocaleKey(userID)
+	if locale, err := cache.Get[string](ctx, key); err == nil {
+		return locale
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var locale *string
+	err := database.Pool.QueryRow(queryCtx,
+		`SELECT preferred_locale FROM user_profiles WHERE id = $1`,
+		userID,
+	).Scan(&locale)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Warn("Failed to load locale preference", zap.String("user_id", userID), zap.Error(err))
+		return ""
+	}
+
+	value := ""
+	if locale != nil {
+		value = *locale
+	}
+	_ = cache.Set(ctx, key, value, cache.UserLocaleTTL)
+	return value
+}
+
+// SetPreferredLocale saves a user's language preference. An empty locale clears it.
+func SetPreferredLocale(ctx context.Context, userID, locale string) error {
+	_, err := database.Pool.Exec(ctx,
+		`INSERT INTO user_profiles (id, preferred_locale)
+		 VALUES ($1, NULLIF($2, ''))
+		 ON CONFLICT (id) DO UPDATE SET preferred_locale = EXCLUDED.preferred_locale`,
+		userID, locale,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Delete(ctx, cache.UserLocaleKey(userID)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to invalidate locale preference cache", zap.String("user_id", userID), zap.Error(err))
+	}
+	return nil
+}
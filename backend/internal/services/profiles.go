@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // validate IANA timezones on images without zoneinfo
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pii"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// profilePhoneField is bound into phone number ciphertexts.
+const profilePhoneField = "user_profiles.phone"
+
+// ErrProfileNotFound is returned for users without a profile row.
+var ErrProfileNotFound = errors.New("user profile not found")
+
+// profileColumns returns the columns read by scanProfile, in order, each
+// prefixed with prefix (a table alias and dot, or "").
+func profileColumns(prefix string) string {
+	return fmt.Sprintf(`%[1]sid::text, COALESCE(%[1]semail, ''), COALESCE(%[1]sfull_name, ''), COALESCE(%[1]sdisplay_name, ''),
+		COALESCE(%[1]sphone, ''), COALESCE(%[1]stimezone, ''), COALESCE(%[1]spreferred_locale, ''), %[1]snotification_preferences,
+		%[1]sblocked_at IS NOT NULL, %[1]screated_at, %[1]supdated_at`, prefix)
+}
+
+// ProfileUpdate changes the profile fields that are set; an empty string
+// clears a field.
+type ProfileUpdate struct {
+	DisplayName             *string
+	Phone                   *string
+	Timezone                *string
+	Locale                  *string
+	NotificationPreferences *models.NotificationPreferences
+}
+
+// GetUserProfile returns a user's profile, creating it on first use. email
+// (from the caller's token) fills in a missing sign-in email.
+func GetUserProfile(ctx context.Context, userID, email string) (models.UserProfile, error) {
+	if err := ensureUserProfile(ctx, userID, email); err != nil {
+		return models.UserProfile{}, err
+	}
+	return loadUserProfile(ctx, userID)
+}
+
+// UpdateUserProfile applies update to a user's profile and returns the result.
+func UpdateUserProfile(ctx context.Context, userID, email string, update ProfileUpdate) (models.UserProfile, error) {
+	if err := ensureUserProfile(ctx, userID, email); err != nil {
+		return models.UserProfile{}, err
+	}
+
+	sets := []string{"updated_at = NOW()"}
+	args := []interface{}{userID}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if update.DisplayName != nil {
+		set("display_name", nilIfBlank(*update.DisplayName))
+	}
+	if update.Phone != nil {
+		phone, err := sealProfilePhone(*update.Phone)
+		if err != nil {
+			return models.UserProfile{}, err
+		}
+		set("phone", phone)
+	}
+	if update.Timezone != nil {
+		set("timezone", nilIfBlank(*update.Timezone))
+	}
+	if update.Locale != nil {
+		set("preferred_locale", nilIfBlank(*update.Locale))
+	}
+	if update.NotificationPreferences != nil {
+		prefs, err := json.Marshal(update.NotificationPreferences)
+		if err != nil {
+			return models.UserProfile{}, err
+		}
+		set("notification_preferences", string(prefs))
+	}
+
+	var p models.UserProfile
+	err := scanProfile(database.Pool.QueryRow(ctx,
+		`UPDATE user_profiles SET `+strings.Join(sets, ", ")+` WHERE id = $1 RETURNING `+profileColumns(""),
+		args...,
+	), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrProfileNotFound
+	}
+	if err != nil {
+		return p, err
+	}
+
+	if update.Locale != nil {
+		if err := cache.Delete(ctx, cache.UserLocaleKey(userID)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+			logger.Warn("Failed to invalidate locale preference cache", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+	return p, nil
+}
+
+// ValidTimezone reports whether tz is an IANA timezone name.
+func ValidTimezone(tz string) bool {
+	if tz == "" || strings.EqualFold(tz, "local") {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// IsUserBlocked reports whether an admin has blocked the user. The answer is
+// cached and written through by SetUserBlocked; lookups fail open on
+// database errors, like the revocation check.
+func IsUserBlocked(ctx context.Context, userID string) (bool, error) {
+	key := cache.UserBlockedKey(userID)
+	if blocked, err := cache.Get[bool](ctx, key); err == nil {
+		return blocked, nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var blocked bool
+	err := database.Pool.QueryRow(queryCtx,
+		`SELECT EXISTS (SELECT 1 FROM user_profiles WHERE id = $1 AND blocked_at IS NOT NULL)`,
+		userID,
+	).Scan(&blocked)
+	if err != nil {
+		return false, err
+	}
+
+	_ = cache.Set(ctx, key, blocked, cache.UserBlockedTTL)
+	return blocked, nil
+}
+
+// SetUserBlocked blocks or unblocks a user. Blocked users are rejected by
+// every authenticated route until unblocked; their bookings are untouched.
+func SetUserBlocked(ctx context.Context, userID string, blocked bool, blockedBy, reason string) (models.AdminUser, error) {
+	var u models.AdminUser
+	err := scanProfile(database.Pool.QueryRow(ctx,
+		`UPDATE user_profiles
+		 SET blocked_at = CASE WHEN $2 THEN COALESCE(blocked_at, NOW()) END,
+		     blocked_by = CASE WHEN $2 THEN $3::uuid END,
+		     blocked_reason = CASE WHEN $2 THEN NULLIF($4, '') END,
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+profileColumns("")+`, blocked_at, blocked_by::text, blocked_reason`,
+		userID, blocked, parseUUID(blockedBy), reason,
+	), &u.UserProfile, &u.BlockedAt, &u.BlockedBy, &u.BlockedReason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return u, ErrProfileNotFound
+	}
+	if err != nil {
+		return u, err
+	}
+
+	// Write through so other instances apply the block on the next request.
+	if err := cache.Set(ctx, cache.UserBlockedKey(userID), blocked, cache.UserBlockedTTL); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to cache user block state", zap.String("user_id", userID), zap.Error(err))
+	}
+	return u, nil
+}
+
+// UserListFilter selects users for the admin users page. Status is "",
+// "active" or "blocked".
+type UserListFilter struct {
+	Search string
+	Status string
+}
+
+// UserListPage is how the admin user list pages: newest first by default.
+var UserListPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "p.created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "p.id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.AdminUser{}),
+}
+
+// ListUsers returns the page p of users matching filter, with their paid
+// booking counts.
+func ListUsers(ctx context.Context, filter UserListFilter, p pagination.Params) (pagination.Page[models.AdminUser], error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		args = append(args, "%"+strings.ToLower(search)+"%")
+		conditions = append(conditions, fmt.Sprintf(
+			`(LOWER(COALESCE(p.email, '')) LIKE $%[1]d OR LOWER(COALESCE(p.full_name, '')) LIKE $%[1]d
+			  OR LOWER(COALESCE(p.display_name, '')) LIKE $%[1]d OR p.id::text LIKE $%[1]d)`, len(args)))
+	}
+	switch filter.Status {
+	case "active":
+		conditions = append(conditions, "p.blocked_at IS NULL")
+	case "blocked":
+		conditions = append(conditions, "p.blocked_at IS NOT NULL")
+	}
+	after, afterArgs := p.Where(len(args) + 1)
+	conditions = append(conditions, after)
+	args = append(args, afterArgs...)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, p.blocked_at, p.blocked_by::text, p.blocked_reason,
+		       (SELECT COUNT(*) FROM bookings b WHERE b.user_id = p.id AND b.payment_status = 'paid'),
+		       %s
+		FROM user_profiles p
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d`,
+		profileColumns("p."), p.Select(), strings.Join(conditions, " AND "), p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.AdminUser]{}, err
+	}
+	defer rows.Close()
+
+	users := make([]models.AdminUser, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var u models.AdminUser
+		var pos pagination.Position
+		if err := scanProfile(rows, &u.UserProfile, &u.BlockedAt, &u.BlockedBy, &u.BlockedReason, &u.BookingCount, &pos.Value, &pos.Key); err != nil {
+			return pagination.Page[models.AdminUser]{}, err
+		}
+		users = append(users, u)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.AdminUser]{}, err
+	}
+	return pagination.NewPage(p, users, positions), nil
+}
+
+// GetAdminUser returns one user as shown to admins.
+func GetAdminUser(ctx context.Context, userID string) (models.AdminUser, error) {
+	var u models.AdminUser
+	err := scanProfile(database.Pool.QueryRow(ctx,
+		`SELECT `+profileColumns("p.")+`, p.blocked_at, p.blocked_by::text, p.blocked_reason,
+		        (SELECT COUNT(*) FROM bookings b WHERE b.user_id = p.id AND b.payment_status = 'paid')
+		 FROM user_profiles p
+		 WHERE p.id = $1`,
+		userID,
+	), &u.UserProfile, &u.BlockedAt, &u.BlockedBy, &u.BlockedReason, &u.BookingCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return u, ErrProfileNotFound
+	}
+	return u, err
+}
+
+func ensureUserProfile(ctx context.Context, userID, email string) error {
+	_, err := database.Pool.Exec(ctx,
+		`INSERT INTO user_profiles (id, email)
+		 VALUES ($1, NULLIF($2, ''))
+		 ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email
+		 WHERE user_profiles.email IS NULL AND EXCLUDED.email IS NOT NULL`,
+		userID, email,
+	)
+	return err
+}
+
+func loadUserProfile(ctx context.Context, userID string) (models.UserProfile, error) {
+	var p models.UserProfile
+	err := scanProfile(database.Pool.QueryRow(ctx,
+		`SELECT `+profileColumns("")+` FROM user_profiles WHERE id = $1`,
+		userID,
+	), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, ErrProfileNotFound
+	}
+	return p, err
+}
+
+// scanProfile reads profileColumns, then extra, from row into p, decoding
+// preferences and decrypting the phone number.
+func scanProfile(row pgx.Row, p *models.UserProfile, extra ...interface{}) error {
+	var prefs []byte
+	targets := append([]interface{}{
+		&p.ID, &p.Email, &p.FullName, &p.DisplayName, &p.Phone, &p.Timezone, &p.Locale, &prefs,
+		&p.Blocked, &p.CreatedAt, &p.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(targets...); err != nil {
+		return err
+	}
+
+	p.NotificationPreferences = decodeNotificationPreferences(prefs)
+	phone, err := openProfilePhone(p.Phone)
+	if err != nil {
+		return err
+	}
+	p.Phone = phone
+	return nil
+}
+
+// decodeNotificationPreferences reads stored preferences over the defaults,
+// so keys added later start at their default value.
+func decodeNotificationPreferences(raw []byte) models.NotificationPreferences {
+	prefs := models.DefaultNotificationPreferences()
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &prefs); err != nil {
+			return models.DefaultNotificationPreferences()
+		}
+	}
+	return prefs
+}
+
+// sealProfilePhone encrypts a phone number for storage when PII encryption
+// is enabled; blank numbers are stored as NULL.
+func sealProfilePhone(phone string) (*string, error) {
+	if phone == "" {
+		return nil, nil
+	}
+	k := pii.Default()
+	if k == nil {
+		return &phone, nil
+	}
+	sealed, err := k.Encrypt(profilePhoneField, phone)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+func openProfilePhone(value string) (string, error) {
+	if !isSealed(&value) {
+		return value, nil
+	}
+	k := pii.Default()
+	if k == nil {
+		return "", ErrPIIKeysMissing
+	}
+	return k.Decrypt(profilePhoneField, value)
+}
+
+func nilIfBlank(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
@@ -1,15 +1,12 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"html/template"
 	"os"
 	"sync"
 	"time"
 
-	"github.com/Himadryy/hidden-depths-backend/internal/templates"
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
 	"github.com/Himadryy/hidden-depths-backend/pkg/circuitbreaker"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
@@ -29,20 +26,23 @@ var ResendBreaker = circuitbreaker.New("resend-api", circuitbreaker.Config{
 type EmailService struct {
 	client    *resend.Client
 	fromEmail string
-	templates *template.Template
-	mu        sync.RWMutex
 }
 
 // EmailTemplateData holds common data for email templates.
+// Stored template versions may reference any of these fields, and translate
+// text with {{.T "English text"}}. Date and Time are already localized.
 type EmailTemplateData struct {
+	Locale      string
 	Name        string
 	Date        string
 	Time        string
 	MeetingLink string
-	LogoURL     string
-	ProfileURL  string
-	SupportURL  string
-	Year        int
+	// UnsubscribeURL is only set for notification mail when unsubscribe links are configured.
+	UnsubscribeURL string
+	LogoURL        string
+	ProfileURL     string
+	SupportURL     string
+	Year           int
 }
 
 var (
@@ -77,12 +77,6 @@ func NewEmailService(apiKey string) (*EmailService, error) {
 
 	client := resend.NewClient(apiKey)
 
-	// Parse embedded email templates from the templates package
-	tmpl, err := template.ParseFS(templates.EmailTemplates, "emails/*.html")
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse email templates: %w", err)
-	}
-
 	fromEmail := os.Getenv("RESEND_FROM_EMAIL")
 	if fromEmail == "" {
 		// Use Resend's default domain until custom domain is verified
@@ -92,87 +86,9 @@ func NewEmailService(apiKey string) (*EmailService, error) {
 	return &EmailService{
 		client:    client,
 		fromEmail: fromEmail,
-		templates: tmpl,
 	}, nil
 }
 
-// SendBookingConfirmation sends a booking confirmation email.
-func (s *EmailService) SendBookingConfirmation(to, name, date, timeSlot, meetingLink string) error {
-	if s == nil || s.client == nil {
-		logger.Warn("Email service not initialized, skipping confirmation email")
-		return nil
-	}
-
-	data := EmailTemplateData{
-		Name:        name,
-		Date:        date,
-		Time:        timeSlot,
-		MeetingLink: meetingLink,
-		LogoURL:     "https://hidden-depths-web.pages.dev/logo.png",
-		ProfileURL:  "https://hidden-depths-web.pages.dev/profile",
-		SupportURL:  "https://hidden-depths-web.pages.dev/contact",
-		Year:        time.Now().Year(),
-	}
-
-	body, err := s.renderTemplate("booking_confirmation.html", data)
-	if err != nil {
-		return apperror.InternalError(fmt.Errorf("failed to render confirmation template: %w", err))
-	}
-
-	return s.sendEmail(to, "✨ Booking Confirmed - Your Journey Begins", body)
-}
-
-// SendBookingReminder sends a booking reminder email.
-func (s *EmailService) SendBookingReminder(to, name, date, timeSlot, meetingLink string) error {
-	if s == nil || s.client == nil {
-		logger.Warn("Email service not initialized, skipping reminder email")
-		return nil
-	}
-
-	data := EmailTemplateData{
-		Name:        name,
-		Date:        date,
-		Time:        timeSlot,
-		MeetingLink: meetingLink,
-		LogoURL:     "https://hidden-depths-web.pages.dev/logo.png",
-		ProfileURL:  "https://hidden-depths-web.pages.dev/profile",
-		SupportURL:  "https://hidden-depths-web.pages.dev/contact",
-		Year:        time.Now().Year(),
-	}
-
-	body, err := s.renderTemplate("booking_reminder.html", data)
-	if err != nil {
-		return apperror.InternalError(fmt.Errorf("failed to render reminder template: %w", err))
-	}
-
-	return s.sendEmail(to, "⏰ Reminder: Your Session is Tomorrow", body)
-}
-
-// SendBookingCancellation sends a booking cancellation email.
-func (s *EmailService) SendBookingCancellation(to, name, date, timeSlot string) error {
-	if s == nil || s.client == nil {
-		logger.Warn("Email service not initialized, skipping cancellation email")
-		return nil
-	}
-
-	data := EmailTemplateData{
-		Name:       name,
-		Date:       date,
-		Time:       timeSlot,
-		LogoURL:    "https://hidden-depths-web.pages.dev/logo.png",
-		ProfileURL: "https://hidden-depths-web.pages.dev/profile",
-		SupportURL: "https://hidden-depths-web.pages.dev/contact",
-		Year:       time.Now().Year(),
-	}
-
-	body, err := s.renderTemplate("booking_cancellation.html", data)
-	if err != nil {
-		return apperror.InternalError(fmt.Errorf("failed to render cancellation template: %w", err))
-	}
-
-	return s.sendEmail(to, "Booking Cancelled - Hidden Depths", body)
-}
-
 // SendTestEmail sends a test email to verify the integration works.
 func (s *EmailService) SendTestEmail(to string) error {
 	if s == nil || s.client == nil {
@@ -202,26 +118,23 @@ func (s *EmailService) SendTestEmail(to string) error {
 	</html>
 	`
 
-	return s.sendEmail(to, "🧪 Test Email - Hidden Depths", body)
-}
-
-// renderTemplate renders an email template with the provided data.
-func (s *EmailService) renderTemplate(templateName string, data EmailTemplateData) (string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var buf bytes.Buffer
-	if err := s.templates.ExecuteTemplate(&buf, templateName, data); err != nil {
-		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
-	}
-	return buf.String(), nil
+	return s.sendEmail(OutgoingEmail{
+		To:       to,
+		Subject:  "🧪 Test Email - Hidden Depths",
+		HTML:     body,
+		Category: EmailTransactional,
+	})
 }
 
 // sendEmail sends an email via Resend with retry and circuit breaker.
-func (s *EmailService) sendEmail(to, subject, htmlBody string) error {
+func (s *EmailService) sendEmail(msg OutgoingEmail) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if err := prepareOutgoingEmail(ctx, &msg); err != nil {
+		return err
+	}
+
 	return retry.Do(ctx, retry.Config{
 		MaxAttempts: 3,
 		BaseDelay:   500 * time.Millisecond,
@@ -231,23 +144,25 @@ func (s *EmailService) sendEmail(to, subject, htmlBody string) error {
 		return ResendBreaker.Execute(func() error {
 			params := &resend.SendEmailRequest{
 				From:    s.fromEmail,
-				To:      []string{to},
-				Subject: subject,
-				Html:    htmlBody,
+				To:      []string{msg.To},
+				Subject: msg.Subject,
+				Html:    msg.HTML,
+				Text:    msg.Text,
+				Headers: msg.Headers,
 			}
 
 			sent, err := s.client.Emails.Send(params)
 			if err != nil {
 				logger.Warn("Resend email attempt failed",
-					zap.String("to", to),
-					zap.String("subject", subject),
+					zap.String("to", msg.To),
+					zap.String("subject", msg.Subject),
 					zap.Error(err),
 				)
 				return apperror.ExternalServiceError("resend", err)
 			}
 
 			logger.Info("Email sent successfully",
-				zap.String("to", to),
+				zap.String("to", msg.To),
 				zap.String("email_id", sent.Id),
 			)
 			return nil
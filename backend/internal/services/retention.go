@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// retentionBatchSize bounds each DELETE/UPDATE so a first run over years of
+// data does not hold row locks on a whole table.
+const retentionBatchSize = 5000
+
+// retentionTimeout bounds one full retention run.
+const retentionTimeout = 10 * time.Minute
+
+// ErrRetentionRunning is returned by RunRetention when another instance is
+// already running the job.
+var ErrRetentionRunning = errors.New("retention job is already running")
+
+// retentionPolicy removes or anonymises rows of one table older than a cutoff.
+// match selects eligible rows with $1 as the cutoff; it must stop matching a
+// row once change has been applied, so batches make progress.
+type retentionPolicy struct {
+	table  string
+	key    string // unique column used to batch
+	action string // "delete" or "anonymise"
+	match  string
+	change string // SET clause for "anonymise"
+}
+
+// retentionPolicies are keyed by the names used in config.DefaultRetention.
+var retentionPolicies = map[string]retentionPolicy{
+	"processed_webhooks": {
+		table:  "processed_webhooks",
+		key:    "event_id",
+		action: "delete",
+		match:  `processed_at < $1`,
+	},
+	"audit_log_pii": {
+		table:  "audit_logs",
+		key:    "id",
+		action: "anonymise",
+		match:  `created_at < $1 AND (ip_address IS NOT NULL OR user_agent IS NOT NULL)`,
+		change: `ip_address = NULL, user_agent = NULL, redacted_at = NOW()`,
+	},
+	"failed_bookings": {
+		table:  "bookings",
+		key:    "id",
+		action: "anonymise",
+		match:  `payment_status = 'failed' AND erased_at IS NULL AND COALESCE(released_at, failed_at, created_at) < $1`,
+		change: `name = 'Redacted', email = 'redacted+' || id || '@invalid', name_bidx = NULL, email_bidx = NULL, meeting_link = NULL, client_ip = NULL, erased_at = NOW()`,
+	},
+	"cancelled_bookings": {
+		table:  "bookings",
+		key:    "id",
+		action: "anonymise",
+		match:  `payment_status = 'cancelled' AND erased_at IS NULL AND COALESCE(cancelled_at, created_at) < $1`,
+		change: `name = 'Redacted', email = 'redacted+' || id || '@invalid', name_bidx = NULL, email_bidx = NULL, meeting_link = NULL, client_ip = NULL, erased_at = NOW()`,
+	},
+}
+
+// RetentionResult is what one policy changed (or would change, in a dry run).
+type RetentionResult struct {
+	Policy   string    `json:"policy"`
+	Table    string    `json:"table"`
+	Action   string    `json:"action"`
+	Cutoff   time.Time `json:"cutoff"`
+	Affected int64     `json:"affected"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// RetentionReport summarises one retention run.
+type RetentionReport struct {
+	DryRun     bool              `json:"dry_run"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Results    []RetentionResult `json:"results"`
+}
+
+// RunRetention applies every policy with a non-zero max age, or only counts
+// the affected rows when dryRun is set, and records the report in
+// retention_runs. Policies run independently: one failing does not stop the
+// rest, and its error is in the report. Only one instance runs at a time;
+// others get ErrRetentionRunning.
+func RunRetention(ctx context.Context, maxAges map[string]time.Duration, dryRun bool) (RetentionReport, error) {
+	report := RetentionReport{DryRun: dryRun, StartedAt: time.Now().UTC()}
+
+	err := withJobLock(ctx, "retention", func(ctx context.Context) error {
+		names := make([]string, 0, len(maxAges))
+		for name := range maxAges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			policy, ok := retentionPolicies[name]
+			if !ok || maxAges[name] <= 0 {
+				continue
+			}
+			result := RetentionResult{
+				Policy: name,
+				Table:  policy.table,
+				Action: policy.action,
+				Cutoff: report.StartedAt.Add(-maxAges[name]),
+			}
+			affected, err := applyRetention(ctx, policy, result.Cutoff, dryRun)
+			result.Affected = affected
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report.Results = append(report.Results, result)
+		}
+		return nil
+	})
+	if errors.Is(err, errJobLocked) {
+		return report, ErrRetentionRunning
+	}
+	if err != nil {
+		return report, err
+	}
+	report.FinishedAt = time.Now().UTC()
+
+	if err := recordRetentionRun(ctx, report); err != nil {
+		logger.Error("Failed to record retention run", zap.Error(err))
+	}
+	return report, nil
+}
+
+// applyRetention runs one policy in batches and returns the rows affected.
+func applyRetention(ctx context.Context, p retentionPolicy, cutoff time.Time, dryRun bool) (int64, error) {
+	if dryRun {
+		var n int64
+		err := database.Pool.QueryRow(ctx,
+			fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, p.table, p.match),
+			cutoff,
+		).Scan(&n)
+		return n, err
+	}
+
+	batch := fmt.Sprintf(`SELECT %s FROM %s WHERE %s LIMIT %d`, p.key, p.table, p.match, retentionBatchSize)
+	var query string
+	switch p.action {
+	case "delete":
+		query = fmt.Sprintf(`DELETE FROM %s WHERE %s IN (%s)`, p.table, p.key, batch)
+	case "anonymise":
+		query = fmt.Sprintf(`UPDATE %s SET %s WHERE %s IN (%s)`, p.table, p.change, p.key, batch)
+	default:
+		return 0, fmt.Errorf("unknown retention action %q", p.action)
+	}
+
+	var total int64
+	for {
+		tag, err := database.Pool.Exec(ctx, query, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += tag.RowsAffected()
+		if tag.RowsAffected() < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func recordRetentionRun(ctx context.Context, report RetentionReport) error {
+	results, err := json.Marshal(report.Results)
+	if err != nil {
+		return err
+	}
+	_, err = database.Pool.Exec(ctx,
+		`INSERT INTO retention_runs (dry_run, started_at, finished_at, report) VALUES ($1, $2, $3, $4)`,
+		report.DryRun, report.StartedAt, report.FinishedAt, results,
+	)
+	return err
+}
+
+// RecentRetentionRuns returns the latest retention runs, newest first.
+func RecentRetentionRuns(ctx context.Context, limit int) ([]RetentionReport, error) {
+	rows, err := database.Pool.Query(ctx,
+		`SELECT dry_run, started_at, finished_at, report FROM retention_runs ORDER BY started_at DESC LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []RetentionReport{}
+	for rows.Next() {
+		var r RetentionReport
+		var results []byte
+		if err := rows.Scan(&r.DryRun, &r.StartedAt, &r.FinishedAt, &results); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(results, &r.Results); err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// RetentionJob returns the scheduler entry for RunRetention and logs the report.
+func RetentionJob(maxAges map[string]time.Duration, dryRun bool) func() {
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), retentionTimeout)
+		defer cancel()
+
+		report, err := RunRetention(ctx, maxAges, dryRun)
+		if errors.Is(err, ErrRetentionRunning) {
+			logger.Info("Retention job skipped: running on another instance")
+			return
+		}
+		if err != nil {
+			logger.Error("Retention job failed", zap.Error(err))
+			return
+		}
+		for _, result := range report.Results {
+			fields := []zap.Field{
+				zap.String("policy", result.Policy),
+				zap.String("action", result.Action),
+				zap.Time("cutoff", result.Cutoff),
+				zap.Int64("affected", result.Affected),
+				zap.Bool("dry_run", report.DryRun),
+			}
+			if result.Error != "" {
+				logger.Error("Retention policy failed", append(fields, zap.String("error", result.Error))...)
+				continue
+			}
+			logger.Info("Retention policy applied", fields...)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/config"
+)
+
+func TestRetentionPoliciesMatchConfig(t *testing.T) {
+	for name := range config.DefaultRetention {
+		policy, ok := retentionPolicies[name]
+		if !ok {
+			t.Fatalf("config policy %q has no retention implementation", name)
+		}
+		if !strings.Contains(policy.match, "$1") {
+			t.Fatalf("policy %q does not filter by cutoff", name)
+		}
+		if policy.action == "anonymise" && policy.change == "" {
+			t.Fatalf("policy %q anonymises without a SET clause", name)
+		}
+	}
+	if len(retentionPolicies) != len(config.DefaultRetention) {
+		t.Fatalf("%d retention policies, %d configured", len(retentionPolicies), len(config.DefaultRetention))
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// maxTokenLifetime bounds how long a revoked jti is remembered when the
+// token's own expiry is unknown.
+const maxTokenLifetime = 7 * 24 * time.Hour
+
+// IsTokenRevoked reports whether a token was revoked by jti, or issued before
+// the user's forced-logout time. Lookups fail open (not revoked) on database
+// errors so a DB hiccup does not log everyone out; the error is still returned
+// for logging.
+func IsTokenRevoked(ctx context.Context, userID, jti string, issuedAt time.Time) (bool, error) {
+	notBefore, err := userNotBefore(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !notBefore.IsZero() && (issuedAt.IsZero() || issuedAt.Before(notBefore)) {
+		return true, nil
+	}
+
+	if jti == "" {
+		return false, nil
+	}
+	return tokenRevoked(ctx, jti)
+}
+
+// userNotBefore returns the user's forced-logout time, or zero if none.
+func userNotBefore(ctx context.Context, userID string) (time.Time, error) {
+	key := cache.UserNotBeforeKey(userID)
+	if unix, err := cache.Get[int64](ctx, key); err == nil {
+		if unix == 0 {
+			return time.Time{}, nil
+		}
+		return time.Unix(unix, 0), nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var notBefore time.Time
+	err := database.Pool.QueryRow(queryCtx,
+		`SELECT not_before FROM user_session_revocations WHERE user_id = $1`,
+		userID,
+	).Scan(&notBefore)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_ = cache.Set(ctx, key, int64(0), cache.RevocationTTL)
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_ = cache.Set(ctx, key, notBefore.Unix(), cache.RevocationTTL)
+	return notBefore, nil
+}
+
+func tokenRevoked(ctx context.Context, jti string) (bool, error) {
+	key := cache.RevokedTokenKey(jti)
+	if revoked, err := cache.Get[bool](ctx, key); err == nil {
+		return revoked, nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	var revoked bool
+	err := database.Pool.QueryRow(queryCtx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`,
+		jti,
+	).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+
+	_ = cache.Set(ctx, key, revoked, cache.RevocationTTL)
+	return revoked, nil
+}
+
+// RevokeToken blocks a single token by jti until expiresAt. A zero expiresAt
+// keeps the entry for maxTokenLifetime.
+func RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time, revokedBy, reason string) error {
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(maxTokenLifetime)
+	}
+
+	_, err := database.Pool.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_by, reason)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (jti) DO NOTHING`,
+		jti, parseUUID(userID), expiresAt, parseUUID(revokedBy), reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Write through so other instances see the revocation immediately.
+	if err := cache.Set(ctx, cache.RevokedTokenKey(jti), true, cache.RevocationTTL); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to cache token revocation", zap.String("jti", jti), zap.Error(err))
+	}
+	return nil
+}
+
+// RevokeUserSessions forces a logout: every token issued to userID before now
+// stops working, and cached sessions are purged. It returns the cutoff time.
+func RevokeUserSessions(ctx context.Context, userID, revokedBy, reason string) (time.Time, error) {
+	// Truncate to seconds: JWT iat has second precision, so a token issued in
+	// the same second as the logout stays valid rather than being rejected.
+	notBefore := time.Now().Truncate(time.Second)
+
+	_, err := database.Pool.Exec(ctx,
+		`INSERT INTO user_session_revocations (user_id, not_before, revoked_by, reason)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET not_before = EXCLUDED.not_before,
+		     revoked_by = EXCLUDED.revoked_by,
+		     reason = EXCLUDED.reason,
+		     updated_at = now()`,
+		userID, notBefore, parseUUID(revokedBy), reason,
+	)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := cache.Set(ctx, cache.UserNotBeforeKey(userID), notBefore.Unix(), cache.RevocationTTL); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to cache forced logout", zap.String("user_id", userID), zap.Error(err))
+	}
+	if _, err := PurgeUserSessions(ctx, userID); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to purge cached sessions", zap.String("user_id", userID), zap.Error(err))
+	}
+	return notBefore, nil
+}
+
+// PurgeUserSessions drops a user's cached sessions so their next request is
+// verified from scratch. It returns how many sessions were removed.
+func PurgeUserSessions(ctx context.Context, userID string) (int, error) {
+	indexKey := cache.UserSessionsKey(userID)
+	hashes, err := cache.SetMembers(ctx, indexKey)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(hashes)+1)
+	for _, hash := range hashes {
+		keys = append(keys, cache.SessionKey(hash))
+	}
+	keys = append(keys, indexKey)
+	if err := cache.Delete(ctx, keys...); err != nil {
+		return 0, err
+	}
+	return len(hashes), nil
+}
+
+// CleanupExpiredRevocations deletes jti revocations for tokens that have
+// expired anyway. Runs daily from the scheduler.
+func CleanupExpiredRevocations() {
+	ctx, cancel := context.WithTimeout(context.Background(), schedulerTimeout)
+	defer cancel()
+
+	result, err := database.Pool.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		logger.Error("Failed to clean up expired token revocations", zap.Error(err))
+		return
+	}
+	if n := result.RowsAffected(); n > 0 {
+		logger.Info("Cleaned up expired token revocations", zap.Int64("deleted", n))
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/cache"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/Himadryy/hidden-depths-backend/pkg/rbac"
+	"go.uber.org/zap"
+)
+
+// ErrLastOwner is returned when revoking the only remaining owner role.
+var ErrLastOwner = errors.New("cannot revoke the last owner")
+
+// UserRoles returns the roles assigned to a user. Results are cached briefly
+// so revocations take effect within cache.UserRolesTTL.
+func UserRoles(ctx context.Context, userID string) ([]rbac.Role, error) {
+	key := cache.UserRolesKey(userID)
+	if roles, err := cache.Get[[]rbac.Role](ctx, key); err == nil {
+		return roles, nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	rows, err := database.Pool.Query(queryCtx,
+		`SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []rbac.Role{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, rbac.Role(role))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_ = cache.Set(ctx, key, roles, cache.UserRolesTTL)
+	return roles, nil
+}
+
+// NewRoleLookup returns the role resolver used by the admin middleware.
+// Every address in bootstrapEmails (ADMIN_EMAILS) that holds no role is
+// promoted to owner on its admin request, so revoking a listed owner also
+// requires removing the address from ADMIN_EMAILS. The bootstrap grant is
+// audited like any other role grant.
+func NewRoleLookup(bootstrapEmails []string, audit *AuditService) func(ctx context.Context, userID, email string) ([]rbac.Role, error) {
+	return func(ctx context.Context, userID, email string) ([]rbac.Role, error) {
+		roles, err := UserRoles(ctx, userID)
+		if err != nil || len(roles) > 0 || !isBootstrapEmail(bootstrapEmails, email) {
+			return roles, err
+		}
+
+		created, err := bootstrapOwner(ctx, userID, email)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return roles, nil
+		}
+		logger.Warn("Bootstrapped owner from ADMIN_EMAILS", zap.String("user_id", userID))
+		audit.Log(ctx, "role.grant", userID, userID, "user", "", "", map[string]interface{}{
+			"role":   rbac.RoleOwner,
+			"email":  NormalizeEmailAddress(email),
+			"source": "bootstrap",
+		})
+		return []rbac.Role{rbac.RoleOwner}, nil
+	}
+}
+
+func isBootstrapEmail(bootstrapEmails []string, email string) bool {
+	email = NormalizeEmailAddress(email)
+	if email == "" {
+		return false
+	}
+	for _, allowed := range bootstrapEmails {
+		if NormalizeEmailAddress(allowed) == email {
+			return true
+		}
+	}
+	return false
+}
+
+// bootstrapOwner grants owner to userID if it holds no role. The advisory lock
+// serialises concurrent first requests so the grant is inserted and audited
+// once. It reports whether a row was inserted.
+func bootstrapOwner(ctx context.Context, userID, email string) (bool, error) {
+	txCtx, cancel := context.WithTimeout(ctx, dbQueryTimeout)
+	defer cancel()
+
+	tx, err := database.Pool.Begin(txCtx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(txCtx)
+
+	if _, err := tx.Exec(txCtx, `SELECT pg_advisory_xact_lock(hashtext('role_bootstrap'))`); err != nil {
+		return false, err
+	}
+	result, err := tx.Exec(txCtx,
+		`INSERT INTO user_roles (user_id, role, email)
+		 SELECT $1, 'owner', $2
+		 WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE user_id = $1)`,
+		userID, NormalizeEmailAddress(email),
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+	if err := tx.Commit(txCtx); err != nil {
+		return false, err
+	}
+	invalidateUserRoles(ctx, userID)
+	return true, nil
+}
+
+// RoleListPage is how role assignments page: grouped by role by default. A
+// user may hold several roles, so the key is the (user, role) pair.
+var RoleListPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"role":       {Expr: "role", Type: pagination.TypeText},
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "role",
+	Key:          pagination.Column{Expr: "user_id::text || ':' || role", Type: pagination.TypeText},
+	DefaultLimit: 50,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.UserRole{}),
+}
+
+// ListUserRoles returns the page p of role assignments.
+func ListUserRoles(ctx context.Context, p pagination.Params) (pagination.Page[models.UserRole], error) {
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT user_id, role, email, granted_by, created_at, %s
+		 FROM user_roles
+		 WHERE %s
+		 ORDER BY %s
+		 LIMIT $%d`, p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.UserRole]{}, err
+	}
+	defer rows.Close()
+
+	assignments := make([]models.UserRole, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var a models.UserRole
+		var pos pagination.Position
+		if err := rows.Scan(&a.UserID, &a.Role, &a.Email, &a.GrantedBy, &a.CreatedAt, &pos.Value, &pos.Key); err != nil {
+			return pagination.Page[models.UserRole]{}, err
+		}
+		assignments = append(assignments, a)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.UserRole]{}, err
+	}
+	return pagination.NewPage(p, assignments, positions), nil
+}
+
+// GrantRole assigns role to userID. It reports whether the assignment is new.
+func GrantRole(ctx context.Context, userID, email string, role rbac.Role, grantedBy string) (bool, error) {
+	result, err := database.Pool.Exec(ctx,
+		`INSERT INTO user_roles (user_id, role, email, granted_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id, role) DO NOTHING`,
+		userID, string(role), NormalizeEmailAddress(email), parseUUID(grantedBy),
+	)
+	if err != nil {
+		return false, err
+	}
+	invalidateUserRoles(ctx, userID)
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeRole removes role from userID. It reports whether an assignment was
+// removed and returns ErrLastOwner rather than leaving the system without an owner.
+func RevokeRole(ctx context.Context, userID string, role rbac.Role) (bool, error) {
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if role == rbac.RoleOwner {
+		// Lock owner rows so two owners cannot revoke each other concurrently.
+		var owners int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM (SELECT 1 FROM user_roles WHERE role = 'owner' FOR UPDATE) o`,
+		).Scan(&owners); err != nil {
+			return false, err
+		}
+		if owners <= 1 {
+			var held bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM user_roles WHERE user_id = $1 AND role = 'owner')`,
+				userID,
+			).Scan(&held); err != nil {
+				return false, err
+			}
+			if held {
+				return false, ErrLastOwner
+			}
+		}
+	}
+
+	result, err := tx.Exec(ctx,
+		`DELETE FROM user_roles WHERE user_id = $1 AND role = $2`,
+		userID, string(role),
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	invalidateUserRoles(ctx, userID)
+	return result.RowsAffected() > 0, nil
+}
+
+func invalidateUserRoles(ctx context.Context, userID string) {
+	if err := cache.Delete(ctx, cache.UserRolesKey(userID)); err != nil && !errors.Is(err, cache.ErrCacheDisabled) {
+		logger.Warn("Failed to invalidate user roles cache", zap.String("user_id", userID), zap.Error(err))
+	}
+}
@@ -3,7 +3,6 @@ package services
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/Himadryy/hidden-depths-backend/internal/database"
@@ -15,6 +14,41 @@ import (
 // Scheduler job timeouts - prevents hung connections from blocking the pool
 const schedulerTimeout = 30 * time.Second
 
+// errJobLocked is returned by withJobLock when another instance holds the lock.
+var errJobLocked = errors.New("job is already running elsewhere")
+
+// withJobLock runs fn while holding a Postgres session-level advisory lock
+// named after the job, so that when several API instances (or hdctl) run the
+// same job only one does the work. It returns errJobLocked without running fn
+// if the lock is taken.
+func withJobLock(ctx context.Context, job string, fn func(context.Context) error) error {
+	conn, err := database.Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, "job:"+job).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return errJobLocked
+	}
+	defer func() {
+		// Fresh context: the job's context may already be cancelled.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock(hashtext($1))`, "job:"+job); err != nil {
+			// Closing the session releases the lock; don't return it to the pool.
+			logger.Warn("Failed to release job lock, closing connection", zap.String("job", job), zap.Error(err))
+			_ = conn.Hijack().Close(unlockCtx)
+		}
+	}()
+
+	return fn(ctx)
+}
+
 // CheckAndSendReminders runs every hour to find bookings happening tomorrow
 func CheckAndSendReminders() {
 	logger.Info("Running reminder check...")
@@ -26,8 +60,15 @@ func CheckAndSendReminders() {
 	// Calculate "Tomorrow" date string (YYYY-MM-DD)
 	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02")
 
+	// Users who turned session reminders off in their profile are skipped
 	rows, err := database.Pool.Query(ctx,
-		"SELECT id, name, email, time, meeting_link FROM bookings WHERE date = $1 AND reminder_sent = FALSE AND payment_status = 'paid'",
+		`SELECT id, name, email, time, COALESCE(meeting_link, ''), locale, `+IntakePendingCondition+`
+		 FROM bookings b
+		 WHERE date = $1 AND reminder_sent = FALSE AND payment_status = 'paid'
+		   AND NOT EXISTS (
+		       SELECT 1 FROM user_profiles p
+		       WHERE p.id = b.user_id AND p.notification_preferences->>'session_reminders' = 'false'
+		   )`,
 		tomorrow,
 	)
 	if err != nil {
@@ -36,36 +77,26 @@ func CheckAndSendReminders() {
 	}
 	defer rows.Close()
 
-	// Get email service for Resend
-	emailSvc := GetEmailService()
-
 	for rows.Next() {
-		var id, name, email, timeSlot, meetingLink string
-		if err := rows.Scan(&id, &name, &email, &timeSlot, &meetingLink); err != nil {
+		var id, name, email, timeSlot, meetingLink, locale string
+		var intakePending bool
+		if err := rows.Scan(&id, &name, &email, &timeSlot, &meetingLink, &locale, &intakePending); err != nil {
 			logger.Error("Error scanning booking for reminder", zap.Error(err))
 			continue
 		}
-
-		// Send reminder email (prefer Resend, fallback to SMTP)
-		var sendErr error
-		if emailSvc != nil && emailSvc.IsEnabled() {
-			sendErr = emailSvc.SendBookingReminder(email, name, tomorrow, timeSlot, meetingLink)
-		} else {
-			// Fallback to legacy SMTP
-			subject := "Reminder: Your Sanctuary Session Tomorrow"
-			body := fmt.Sprintf(`
-				<h2>Hello %s,</h2>
-				<p>This is a gentle reminder that your session at <strong>Hidden Depths</strong> is scheduled for tomorrow at <strong>%s</strong>.</p>
-				<p>Please ensure you are in a quiet space 5 minutes before we begin.</p>
-				<p>Here is your secure link to join:</p>
-				<p><a href="%s" style="padding: 10px 20px; background-color: #E0B873; color: black; text-decoration: none; border-radius: 5px;">Join Video Session</a></p>
-				<br>
-				<p>Or view your booking details here: <a href="https://hidden-depths-web.pages.dev/profile">My Sanctuary Profile</a></p>
-			`, name, timeSlot, meetingLink)
-			sendErr = SendEmail(email, subject, body)
+		if err := OpenBookingPII(&name, &email); err != nil {
+			logger.Error("Error decrypting booking for reminder", zap.String("booking_id", id), zap.Error(err))
+			continue
 		}
 
-		if sendErr != nil {
+		// Send reminder email (Resend when configured, otherwise SMTP)
+		sendErr := SendTemplatedEmail(ctx, email, TemplateBookingReminder,
+			NewEmailTemplateData(locale, name, tomorrow, timeSlot, EmailJoinLink(intakePending, meetingLink)))
+
+		if errors.Is(sendErr, ErrEmailSuppressed) {
+			// Suppressed recipients are final; mark the reminder handled so it is not retried hourly.
+			logger.Info("Reminder skipped for suppressed recipient", zap.String("booking_id", id))
+		} else if sendErr != nil {
 			logger.Error("Failed to send reminder email", zap.String("email", email), zap.Error(sendErr))
 			continue
 		}
@@ -103,9 +134,9 @@ func CleanupAbandonedBookings() {
 			    released_at = COALESCE(released_at, NOW())
 			WHERE payment_status = 'pending'
 			  AND created_at < NOW() - INTERVAL '10 minutes'
-			RETURNING date
+			RETURNING id, date
 		)
-		SELECT DISTINCT date FROM expired`,
+		SELECT id, date FROM expired`,
 	)
 	if err != nil {
 		logger.Error("Error during abandoned booking cleanup", zap.Error(err))
@@ -114,19 +145,27 @@ func CleanupAbandonedBookings() {
 	defer rows.Close()
 
 	updatedDates := make([]string, 0, 4)
+	expiredIDs := make([]string, 0, 4)
 	for rows.Next() {
-		var date string
-		if err := rows.Scan(&date); err != nil {
+		var id, date string
+		if err := rows.Scan(&id, &date); err != nil {
 			logger.Error("Failed to scan cleanup date", zap.Error(err))
 			continue
 		}
-		updatedDates = append(updatedDates, date)
+		expiredIDs = append(expiredIDs, id)
+		if !containsString(updatedDates, date) {
+			updatedDates = append(updatedDates, date)
+		}
 	}
 	if err := rows.Err(); err != nil {
 		logger.Error("Cleanup cursor error", zap.Error(err))
 		return
 	}
 
+	for _, id := range expiredIDs {
+		RecordFunnelStep(ctx, id, FunnelExpired, "hold_expired_scheduler")
+	}
+
 	if len(updatedDates) == 0 {
 		return
 	}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Crisis screening severities. High means a risk to life (suicide, self-harm,
+// harming others); elevated covers hopelessness and being unsafe at home.
+const (
+	SeverityElevated = "elevated"
+	SeverityHigh     = "high"
+)
+
+// ScreeningResult is what a Screener found in one piece of text.
+type ScreeningResult struct {
+	Flagged  bool
+	Severity string   // highest severity matched, "" when not flagged
+	Rules    []string // IDs of the matched rules
+}
+
+// Screener inspects free text for crisis signals. Implementations must be
+// safe for concurrent use; a classifier service can replace the default
+// KeywordScreener through SetCrisisConfig.
+type Screener interface {
+	Screen(ctx context.Context, text string) (ScreeningResult, error)
+}
+
+// ScreeningRule flags text matching Pattern. Patterns run against lowercased
+// text with whitespace collapsed and typographic apostrophes straightened.
+type ScreeningRule struct {
+	ID       string
+	Severity string
+	Pattern  *regexp.Regexp
+}
+
+// KeywordScreener is the local, rule-based Screener. It errs on the side of
+// flagging: a false alarm costs a mentor a glance, a miss can cost a life.
+type KeywordScreener struct {
+	rules []ScreeningRule
+}
+
+// NewKeywordScreener returns a Screener applying rules.
+func NewKeywordScreener(rules []ScreeningRule) *KeywordScreener {
+	return &KeywordScreener{rules: rules}
+}
+
+// DefaultCrisisRules covers English plus Hindi and Bengali, in script and
+// romanized.
+func DefaultCrisisRules() []ScreeningRule {
+	return []ScreeningRule{
+		{"suicide", SeverityHigh, regexp.MustCompile(
+			`\b(suicid(e|al)|kill(ing)? myself|end(ing)? (it all|my life|my own life)|take my (own )?life|want(ed)? to die|wish i (was|were) dead|better off dead|don't want to (live|be alive|wake up)|no reason to live)\b`)},
+		{"suicide_hi", SeverityHigh, regexp.MustCompile(
+			`आत्महत्या|खुदकुशी|ख़ुदकुशी|मरना चाहत|जीना नहीं चाहत|\b(aatmahatya|atmahatya|khudkushi|marna chahta|marna chahti|jeena nahi chahta|jeena nahi chahti)\b`)},
+		{"suicide_bn", SeverityHigh, regexp.MustCompile(
+			`আত্মহত্যা|মরে যেতে চাই|বাঁচতে চাই না|\b(atmohotya|more jete chai|bachte chai na)\b`)},
+		{"self_harm", SeverityHigh, regexp.MustCompile(
+			`\b(self[- ]?harm(ing)?|cut(ting)? myself|hurt(ing)? myself|harm(ing)? myself|hang(ing)? myself|overdos(e|ing))\b`)},
+		{"harm_others", SeverityHigh, regexp.MustCompile(
+			`\b(kill|hurt) (him|her|them|someone|somebody|people|my (husband|wife|partner|child|kids))\b`)},
+		{"hopelessness", SeverityElevated, regexp.MustCompile(
+			`\b(hopeless|can't go on|no way out|nothing to live for|give up on (life|everything)|no point (in )?living)\b`)},
+		{"unsafe", SeverityElevated, regexp.MustCompile(
+			`\b(not safe at home|afraid for my life|(he|she|they) (hits|beats|hurts) me|being abused)\b`)},
+	}
+}
+
+// Screen implements Screener.
+func (s *KeywordScreener) Screen(_ context.Context, text string) (ScreeningResult, error) {
+	normalized := normalizeScreeningText(text)
+
+	var result ScreeningResult
+	for _, rule := range s.rules {
+		if rule.Pattern.MatchString(normalized) {
+			result.Rules = append(result.Rules, rule.ID)
+			result.Severity = higherSeverity(result.Severity, rule.Severity)
+		}
+	}
+	result.Flagged = len(result.Rules) > 0
+	return result, nil
+}
+
+func normalizeScreeningText(text string) string {
+	text = strings.NewReplacer("’", "'", "‘", "'", "`", "'").Replace(strings.ToLower(text))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// higherSeverity returns the more severe of a and b ("" is lowest).
+func higherSeverity(a, b string) string {
+	rank := func(s string) int {
+		switch s {
+		case SeverityHigh:
+			return 2
+		case SeverityElevated:
+			return 1
+		}
+		return 0
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestKeywordScreener(t *testing.T) {
+	screener := NewKeywordScreener(DefaultCrisisRules())
+	tests := []struct {
+		text     string
+		severity string
+		rules    []string
+	}{
+		{"I want to talk about stress at work", "", nil},
+		{"Honestly I don’t want to  wake up anymore", SeverityHigh, []string{"suicide"}},
+		{"I have been CUTTING MYSELF again", SeverityHigh, []string{"self_harm"}},
+		{"Feeling hopeless, and I keep thinking about suicide", SeverityHigh, []string{"suicide", "hopelessness"}},
+		{"Everything feels hopeless lately", SeverityElevated, []string{"hopelessness"}},
+		{"मैं अब जीना नहीं चाहता", SeverityHigh, []string{"suicide_hi"}},
+		{"kabhi kabhi khudkushi ka khayal aata hai", SeverityHigh, []string{"suicide_hi"}},
+		{"আমি আর বাঁচতে চাই না", SeverityHigh, []string{"suicide_bn"}},
+		{"My cat died last week", "", nil},
+	}
+	for _, tc := range tests {
+		got, err := screener.Screen(context.Background(), tc.text)
+		if err != nil {
+			t.Fatalf("Screen(%q) error = %v", tc.text, err)
+		}
+		if got.Flagged != (tc.severity != "") || got.Severity != tc.severity || !reflect.DeepEqual(got.Rules, tc.rules) {
+			t.Fatalf("Screen(%q) = %+v, want severity %q rules %v", tc.text, got, tc.severity, tc.rules)
+		}
+	}
+}
+
+func TestHelplinesFor(t *testing.T) {
+	tests := []struct {
+		region, fallback, want string
+	}{
+		{"us", "IN", "US"},
+		{"", "IN", "IN"},
+		{"FR", "IN", "IN"},
+		{"FR", "", ""},
+		{"XX", "ZZ", ""},
+	}
+	for _, tc := range tests {
+		got, lines := HelplinesFor(tc.region, tc.fallback)
+		if got != tc.want || len(lines) == 0 {
+			t.Fatalf("HelplinesFor(%q, %q) = %q with %d lines, want %q", tc.region, tc.fallback, got, len(lines), tc.want)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/internal/database"
+	"github.com/Himadryy/hidden-depths-backend/internal/models"
+	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
+	"github.com/Himadryy/hidden-depths-backend/pkg/pagination"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Settlement line match statuses (settlement_lines.match_status).
+const (
+	SettlementMatched        = "matched"
+	SettlementAmountMismatch = "amount_mismatch"
+	SettlementUnmatched      = "unmatched"
+	SettlementIgnored        = "ignored"
+)
+
+// ErrSettlementUploadNotFound is returned for unknown settlement upload IDs.
+var ErrSettlementUploadNotFound = errors.New("settlement upload not found")
+
+// SettlementReportError describes why a settlement report could not be read.
+type SettlementReportError struct {
+	Line   int // 1-based CSV line, 0 for the file as a whole
+	Reason string
+}
+
+func (e *SettlementReportError) Error() string {
+	if e.Line == 0 {
+		return e.Reason
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// settlementColumns are the Razorpay settlement recon report columns read;
+// others are ignored. Only settled_at and settlement_utr are optional.
+var settlementColumns = []string{"entity_id", "type", "amount", "fee", "tax", "settlement_id", "settled_at", "settlement_utr"}
+
+// settlementRow is a parsed report row. Amounts are in paise.
+type settlementRow struct {
+	EntityID     string
+	EntityType   string
+	Amount       int64
+	Fee          int64
+	Tax          int64
+	SettlementID string
+	SettledAt    *time.Time
+	UTR          string
+}
+
+// parseSettlementReport reads a Razorpay settlement recon CSV. Columns are
+// found by header name, so reports with extra or reordered columns work.
+func parseSettlementReport(r io.Reader, loc *time.Location) ([]settlementRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &SettlementReportError{Reason: "the file is empty"}
+	}
+	if err != nil {
+		return nil, &SettlementReportError{Reason: err.Error()}
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		index[name] = i
+	}
+	for _, name := range settlementColumns {
+		if _, ok := index[name]; !ok && name != "settled_at" && name != "settlement_utr" {
+			return nil, &SettlementReportError{Reason: fmt.Sprintf("missing column %q", name)}
+		}
+	}
+
+	rows := []settlementRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, &SettlementReportError{Line: line, Reason: err.Error()}
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := settlementRow{
+			EntityID:     field("entity_id"),
+			EntityType:   strings.ToLower(field("type")),
+			SettlementID: field("settlement_id"),
+			UTR:          field("settlement_utr"),
+		}
+		if row.EntityID == "" || row.SettlementID == "" {
+			return nil, &SettlementReportError{Line: line, Reason: "entity_id and settlement_id are required"}
+		}
+		for _, amount := range []struct {
+			name string
+			dst  *int64
+		}{{"amount", &row.Amount}, {"fee", &row.Fee}, {"tax", &row.Tax}} {
+			paise, err := parseRupees(field(amount.name))
+			if err != nil {
+				return nil, &SettlementReportError{Line: line, Reason: fmt.Sprintf("invalid %s %q", amount.name, field(amount.name))}
+			}
+			*amount.dst = paise
+		}
+		if row.Tax > row.Fee || (row.EntityType == "payment" && row.Fee > row.Amount) {
+			return nil, &SettlementReportError{Line: line, Reason: "fee must not exceed the amount, nor tax the fee"}
+		}
+		if raw := field("settled_at"); raw != "" {
+			settledAt, err := parseSettlementTime(raw, loc)
+			if err != nil {
+				return nil, &SettlementReportError{Line: line, Reason: fmt.Sprintf("invalid settled_at %q", raw)}
+			}
+			row.SettledAt = &settledAt
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, &SettlementReportError{Reason: "the report has no rows"}
+	}
+	return rows, nil
+}
+
+// parseRupees parses a non-negative rupee amount such as "1,499.00" into
+// paise. An empty string is zero.
+func parseRupees(raw string) (int64, error) {
+	raw = strings.ReplaceAll(raw, ",", "")
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	return int64(math.Round(v * 100)), nil
+}
+
+// settlementTimeLayouts are the timestamp formats seen in Razorpay reports.
+// Times without a zone are in loc.
+var settlementTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"2006-01-02",
+	"02/01/2006",
+}
+
+// parseSettlementTime parses a report timestamp or Unix seconds.
+func parseSettlementTime(raw string, loc *time.Location) (time.Time, error) {
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	for _, layout := range settlementTimeLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", raw)
+}
+
+// settlementEntry books a settled line as one journal. For a payment the
+// clearing account is emptied into the net payout and the gateway fee (GST
+// separately); for a refund the amount and any fee leave the bank.
+func settlementEntry(settlementID, entityID, entityType string, amount, fee, tax int64, utr string, bookingID *string, date time.Time) ledgerEntry {
+	narration := "Razorpay settlement " + settlementID + " of " + entityType + " " + entityID
+	if utr != "" {
+		narration += ", UTR " + utr
+	}
+	lines := []ledgerLine{
+		{Account: AccountGatewayFees, Debit: fee - tax},
+		{Account: AccountGSTInputCredit, Debit: tax},
+	}
+	if entityType == "refund" {
+		lines = append(lines,
+			ledgerLine{Account: AccountRazorpayClearing, Debit: amount},
+			ledgerLine{Account: AccountBank, Credit: amount + fee},
+		)
+	} else {
+		lines = append(lines,
+			ledgerLine{Account: AccountBank, Debit: amount - fee},
+			ledgerLine{Account: AccountRazorpayClearing, Credit: amount},
+		)
+	}
+	return ledgerEntry{
+		Kind:      LedgerSettlement,
+		SourceRef: settlementID + ":" + entityID,
+		BookingID: bookingID,
+		Date:      date,
+		Narration: narration,
+		Lines:     lines,
+	}
+}
+
+// postSettlementLines posts matched settlement lines that are not posted yet.
+// Unmatched lines are left for review.
+func postSettlementLines(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		rows, err := database.Pool.Query(ctx,
+			`SELECT s.settlement_id, s.entity_id, s.entity_type,
+			        (s.amount * 100)::bigint, (s.fee * 100)::bigint, (s.tax * 100)::bigint,
+			        s.utr, s.booking_id::text, COALESCE(s.settled_at, s.created_at)
+			 FROM settlement_lines s
+			 WHERE s.match_status IN ('matched', 'amount_mismatch') AND s.amount > 0
+			   AND NOT EXISTS (
+			       SELECT 1 FROM ledger_transactions t
+			       WHERE t.kind = 'settlement' AND t.source_ref = s.settlement_id || ':' || s.entity_id
+			   )
+			 ORDER BY s.created_at, s.id
+			 LIMIT `+strconv.Itoa(ledgerSyncBatch),
+		)
+		if err != nil {
+			return total, err
+		}
+		entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ledgerEntry, error) {
+			var settlementID, entityID, entityType, utr string
+			var amount, fee, tax int64
+			var bookingID *string
+			var date time.Time
+			err := row.Scan(&settlementID, &entityID, &entityType, &amount, &fee, &tax, &utr, &bookingID, &date)
+			return settlementEntry(settlementID, entityID, entityType, amount, fee, tax, utr, bookingID, date), err
+		})
+		if err != nil {
+			return total, err
+		}
+
+		for _, e := range entries {
+			posted, err := postLedgerEntry(ctx, e)
+			if err != nil {
+				return total, err
+			}
+			if posted {
+				total++
+			}
+		}
+		if len(entries) < ledgerSyncBatch {
+			return total, nil
+		}
+	}
+}
+
+// matchSettlementRow finds the booking a settled payment or refund belongs
+// to and whether the amounts agree.
+func matchSettlementRow(ctx context.Context, tx pgx.Tx, row settlementRow) (*string, string, error) {
+	var query string
+	switch row.EntityType {
+	case "payment":
+		query = `SELECT id::text, (COALESCE(amount, 0) * 100)::bigint FROM bookings WHERE razorpay_payment_id = $1 ORDER BY created_at DESC LIMIT 1`
+	case "refund":
+		query = `SELECT id::text, (COALESCE(refund_amount, 0) * 100)::bigint FROM bookings WHERE refund_id = $1 LIMIT 1`
+	default:
+		return nil, SettlementIgnored, nil
+	}
+
+	var bookingID string
+	var expected int64
+	err := tx.QueryRow(ctx, query, row.EntityID).Scan(&bookingID, &expected)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, SettlementUnmatched, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if expected != row.Amount {
+		return &bookingID, SettlementAmountMismatch, nil
+	}
+	return &bookingID, SettlementMatched, nil
+}
+
+// ImportSettlementReport stores a Razorpay settlement recon report, matches
+// each payment and refund to its booking, and posts matched lines to the
+// ledger. Rows already imported from an earlier report are counted as
+// duplicates and skipped. Malformed reports return a *SettlementReportError.
+func ImportSettlementReport(ctx context.Context, filename, uploadedBy string, r io.Reader) (models.SettlementUpload, error) {
+	upload := models.SettlementUpload{Filename: filename}
+	rows, err := parseSettlementReport(r, getLedgerConfig().location)
+	if err != nil {
+		return upload, err
+	}
+
+	tx, err := database.Pool.Begin(ctx)
+	if err != nil {
+		return upload, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO settlement_uploads (filename, uploaded_by) VALUES ($1, $2) RETURNING id, uploaded_by::text, created_at`,
+		filename, nilIfBlank(uploadedBy),
+	).Scan(&upload.ID, &upload.UploadedBy, &upload.CreatedAt); err != nil {
+		return upload, err
+	}
+
+	for _, row := range rows {
+		upload.Rows++
+		bookingID, status, err := matchSettlementRow(ctx, tx, row)
+		if err != nil {
+			return upload, err
+		}
+		result, err := tx.Exec(ctx,
+			`INSERT INTO settlement_lines
+			   (upload_id, settlement_id, entity_id, entity_type, amount, fee, tax, settled_at, utr, booking_id, match_status)
+			 VALUES ($1, $2, $3, $4, $5::numeric / 100, $6::numeric / 100, $7::numeric / 100, $8, $9, $10, $11)
+			 ON CONFLICT (settlement_id, entity_id) DO NOTHING`,
+			upload.ID, row.SettlementID, row.EntityID, row.EntityType, row.Amount, row.Fee, row.Tax, row.SettledAt, row.UTR, bookingID, status,
+		)
+		if err != nil {
+			return upload, err
+		}
+		if result.RowsAffected() == 0 {
+			upload.Duplicates++
+			continue
+		}
+		switch status {
+		case SettlementMatched:
+			upload.Matched++
+		case SettlementAmountMismatch:
+			upload.Mismatched++
+		case SettlementUnmatched:
+			upload.Unmatched++
+		default:
+			upload.Ignored++
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE settlement_uploads
+		 SET row_count = $2, matched = $3, mismatched = $4, unmatched = $5, ignored = $6, duplicates = $7
+		 WHERE id = $1`,
+		upload.ID, upload.Rows, upload.Matched, upload.Mismatched, upload.Unmatched, upload.Ignored, upload.Duplicates,
+	); err != nil {
+		return upload, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return upload, err
+	}
+
+	// Refunds and payments must be posted before their settlement clears them
+	if _, err := syncLedger(ctx); err != nil {
+		logger.Warn("Failed to post settlement to ledger", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+	return upload, nil
+}
+
+const settlementUploadColumns = `id, filename, uploaded_by::text, row_count, matched, mismatched, unmatched, ignored, duplicates, created_at`
+
+func scanSettlementUpload(row pgx.Row, extra ...any) (models.SettlementUpload, error) {
+	var u models.SettlementUpload
+	err := row.Scan(append([]any{&u.ID, &u.Filename, &u.UploadedBy, &u.Rows, &u.Matched, &u.Mismatched, &u.Unmatched,
+		&u.Ignored, &u.Duplicates, &u.CreatedAt}, extra...)...)
+	return u, err
+}
+
+// SettlementUploadPage is how settlement uploads page: newest first by
+// default.
+var SettlementUploadPage = pagination.Spec{
+	Sorts: map[string]pagination.Column{
+		"created_at": {Expr: "created_at", Type: pagination.TypeTime},
+	},
+	DefaultSort:  "-created_at",
+	Key:          pagination.Column{Expr: "id", Type: pagination.TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       pagination.JSONFields(models.SettlementUpload{}),
+}
+
+// ListSettlementUploads returns the page p of uploads.
+func ListSettlementUploads(ctx context.Context, p pagination.Params) (pagination.Page[models.SettlementUpload], error) {
+	after, args := p.Where(1)
+	args = append(args, p.Fetch())
+	rows, err := database.Pool.Query(ctx, fmt.Sprintf(
+		`SELECT `+settlementUploadColumns+`, %s FROM settlement_uploads WHERE %s ORDER BY %s LIMIT $%d`,
+		p.Select(), after, p.OrderBy(), len(args)),
+		args...,
+	)
+	if err != nil {
+		return pagination.Page[models.SettlementUpload]{}, err
+	}
+	defer rows.Close()
+
+	uploads := make([]models.SettlementUpload, 0, p.Fetch())
+	positions := make([]pagination.Position, 0, p.Fetch())
+	for rows.Next() {
+		var pos pagination.Position
+		u, err := scanSettlementUpload(rows, &pos.Value, &pos.Key)
+		if err != nil {
+			return pagination.Page[models.SettlementUpload]{}, err
+		}
+		uploads = append(uploads, u)
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return pagination.Page[models.SettlementUpload]{}, err
+	}
+	return pagination.NewPage(p, uploads, positions), nil
+}
+
+// GetSettlementUpload returns an upload with its lines, optionally only those
+// with one match status.
+func GetSettlementUpload(ctx context.Context, uploadID, status string) (models.SettlementUpload, []models.SettlementLine, error) {
+	upload, err := scanSettlementUpload(database.Pool.QueryRow(ctx,
+		`SELECT `+settlementUploadColumns+` FROM settlement_uploads WHERE id = $1`, uploadID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return upload, nil, ErrSettlementUploadNotFound
+	}
+	if err != nil {
+		return upload, nil, err
+	}
+
+	rows, err := database.Pool.Query(ctx,
+		`SELECT id, upload_id, settlement_id, entity_id, entity_type, amount::float8, fee::float8, tax::float8,
+		        settled_at, utr, booking_id::text, match_status, created_at
+		 FROM settlement_lines
+		 WHERE upload_id = $1 AND ($2 = '' OR match_status = $2)
+		 ORDER BY created_at, id`,
+		uploadID, status,
+	)
+	if err != nil {
+		return upload, nil, err
+	}
+	lines, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.SettlementLine, error) {
+		var l models.SettlementLine
+		err := row.Scan(&l.ID, &l.UploadID, &l.SettlementID, &l.EntityID, &l.EntityType, &l.Amount, &l.Fee, &l.Tax,
+			&l.SettledAt, &l.UTR, &l.BookingID, &l.MatchStatus, &l.CreatedAt)
+		return l, err
+	})
+	return upload, lines, err
+}
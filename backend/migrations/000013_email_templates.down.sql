@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.idx_email_templates_active;
+DROP TABLE IF EXISTS public.email_templates;
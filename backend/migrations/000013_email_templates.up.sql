@@ -0,0 +1,24 @@
+-- Migration 000013: admin-editable email templates.
+-- The embedded files in internal/templates/emails remain the defaults; rows here
+-- override them per template name. Every edit creates a new version and at most
+-- one version per template is active at a time.
+
+CREATE TABLE IF NOT EXISTS public.email_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    version INT NOT NULL CHECK (version > 0),
+    subject TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (name, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_templates_active
+ON public.email_templates (name)
+WHERE is_active;
+
+-- Only the Go backend (superuser connection) reads or writes templates.
+ALTER TABLE public.email_templates ENABLE ROW LEVEL SECURITY;
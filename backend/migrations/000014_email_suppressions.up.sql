@@ -0,0 +1,21 @@
+-- Migration 000014: email suppression list.
+-- Bounces and complaints reported by the Resend webhook block all mail to an
+-- address. Unsubscribes only block non-transactional mail (e.g. reminders);
+-- booking confirmations and cancellations are still delivered.
+
+CREATE TABLE IF NOT EXISTS public.email_suppressions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('bounce', 'complaint', 'unsubscribe')),
+    scope TEXT NOT NULL CHECK (scope IN ('all', 'non_transactional')),
+    source TEXT NOT NULL,
+    provider_event_id TEXT,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (email, reason)
+);
+
+-- UNIQUE (email, reason) also serves the per-recipient lookup before each send.
+
+-- Only the Go backend (superuser connection) reads or writes suppressions.
+ALTER TABLE public.email_suppressions ENABLE ROW LEVEL SECURITY;
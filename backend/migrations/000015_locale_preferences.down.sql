@@ -0,0 +1,2 @@
+ALTER TABLE public.bookings DROP COLUMN IF EXISTS locale;
+ALTER TABLE IF EXISTS public.user_profiles DROP COLUMN IF EXISTS preferred_locale;
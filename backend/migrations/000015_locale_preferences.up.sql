@@ -0,0 +1,16 @@
+-- Migration 000015: language preferences for localized emails and API messages.
+-- user_profiles is created by the Supabase auth trigger; create it here for
+-- fresh databases so PUT /me/locale has somewhere to write.
+
+CREATE TABLE IF NOT EXISTS public.user_profiles (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS preferred_locale TEXT;
+
+-- Locale negotiated when the booking was made; used for emails sent later
+-- (confirmation via webhook, reminders, cancellations).
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en';
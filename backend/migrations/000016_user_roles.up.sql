@@ -0,0 +1,18 @@
+-- Migration 000016: role-based access control for the admin API.
+-- Replaces the ADMIN_EMAILS allow-list. A user may hold several roles; the
+-- permissions each role grants are defined in pkg/rbac. ADMIN_EMAILS is only
+-- used to bootstrap owners: each listed address without a role becomes owner.
+
+CREATE TABLE IF NOT EXISTS public.user_roles (
+    user_id UUID NOT NULL, -- References auth.users
+    role TEXT NOT NULL CHECK (role IN ('owner', 'mentor', 'support', 'content-editor')),
+    email TEXT NOT NULL DEFAULT '', -- Snapshot for display; authorization uses user_id
+    granted_by UUID, -- NULL when bootstrapped from ADMIN_EMAILS
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON public.user_roles(role);
+
+-- Only the Go backend (superuser connection) reads or writes role assignments.
+ALTER TABLE public.user_roles ENABLE ROW LEVEL SECURITY;
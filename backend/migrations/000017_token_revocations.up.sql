@@ -0,0 +1,28 @@
+-- Migration 000017: access token revocation.
+-- revoked_tokens blocks individual tokens by their jti claim until they expire.
+-- user_session_revocations blocks every token issued to a user before
+-- not_before (forced logout). Redis caches both; these tables are the source
+-- of truth after a cache flush.
+
+CREATE TABLE IF NOT EXISTS public.revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id UUID,
+    expires_at TIMESTAMPTZ NOT NULL, -- Row can be deleted once the token has expired
+    revoked_by UUID,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires ON public.revoked_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS public.user_session_revocations (
+    user_id UUID PRIMARY KEY,
+    not_before TIMESTAMPTZ NOT NULL,
+    revoked_by UUID,
+    reason TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Only the Go backend (superuser connection) reads or writes revocations.
+ALTER TABLE public.revoked_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.user_session_revocations ENABLE ROW LEVEL SECURITY;
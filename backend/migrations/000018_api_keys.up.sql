@@ -0,0 +1,21 @@
+-- Migration 000018: service-to-service API keys.
+-- Keys are shown once at creation; only a SHA-256 hash is stored. The short
+-- public prefix identifies a key in lists and logs. Scopes use the same
+-- permission names as admin roles (see pkg/rbac).
+
+CREATE TABLE IF NOT EXISTS public.api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID,
+    expires_at TIMESTAMPTZ, -- NULL never expires
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Only the Go backend (superuser connection) reads or writes API keys.
+ALTER TABLE public.api_keys ENABLE ROW LEVEL SECURITY;
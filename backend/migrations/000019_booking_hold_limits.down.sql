@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.idx_bookings_client_ip_pending;
+DROP INDEX IF EXISTS public.idx_bookings_user_status_created;
+
+ALTER TABLE public.bookings
+    DROP COLUMN IF EXISTS client_ip;
@@ -0,0 +1,13 @@
+-- Migration 000019: per-client limits on booking holds.
+-- client_ip records where a booking was requested from (after trusted-proxy
+-- resolution) so unpaid holds can be capped per IP as well as per user.
+
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS client_ip TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_user_status_created
+ON public.bookings (user_id, payment_status, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_client_ip_pending
+ON public.bookings (client_ip, created_at DESC)
+WHERE payment_status = 'pending' AND client_ip IS NOT NULL;
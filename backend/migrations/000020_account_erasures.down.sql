@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.account_erasures;
+
+ALTER TABLE public.bookings
+    DROP COLUMN IF EXISTS erased_at;
@@ -0,0 +1,22 @@
+-- Migration 000020: self-service account erasure (GDPR / DPDP).
+-- Bookings are anonymised rather than deleted so payment and invoice records
+-- survive for the statutory retention period; erased_at marks them.
+-- account_erasures records that an erasure happened and what it touched. It
+-- holds no personal data beyond the (now unlinked) Supabase user ID.
+
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS public.account_erasures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    bookings_anonymised INT NOT NULL DEFAULT 0,
+    audit_entries_anonymised INT NOT NULL DEFAULT 0,
+    auth_user_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_erasures_user ON public.account_erasures(user_id);
+
+-- Only the Go backend (superuser connection) reads or writes erasure records.
+ALTER TABLE public.account_erasures ENABLE ROW LEVEL SECURITY;
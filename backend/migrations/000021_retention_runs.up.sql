@@ -0,0 +1,18 @@
+-- Migration 000021: data retention job history.
+-- One row per run of the retention job (scheduled or via hdctl), with the
+-- per-policy report of rows deleted or anonymised (or that would be, for
+-- dry runs).
+
+CREATE TABLE IF NOT EXISTS public.retention_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dry_run BOOLEAN NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    report JSONB NOT NULL DEFAULT '[]',
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_runs_started ON public.retention_runs(started_at DESC);
+
+-- Only the Go backend (superuser connection) reads or writes retention runs.
+ALTER TABLE public.retention_runs ENABLE ROW LEVEL SECURITY;
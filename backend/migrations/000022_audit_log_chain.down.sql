@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS public.idx_audit_created;
+DROP INDEX IF EXISTS public.idx_audit_entity;
+DROP INDEX IF EXISTS public.idx_audit_seq;
+
+ALTER TABLE public.audit_logs
+    DROP COLUMN IF EXISTS seq,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS content_digest,
+    DROP COLUMN IF EXISTS redacted_at;
@@ -0,0 +1,18 @@
+-- Migration 000022: tamper-evident audit log.
+-- Entries are appended in a single hash chain ordered by seq: each hash covers
+-- the previous entry's hash and this entry's action, actor, entity and time,
+-- plus content_digest, a digest of its IP, user agent and details. Retention
+-- and account erasure may later clear that content; they set redacted_at, and
+-- verification then skips the content check for the row but still checks the
+-- chain. Entries written before this migration have no seq and are not chained.
+
+ALTER TABLE public.audit_logs
+    ADD COLUMN IF NOT EXISTS seq BIGINT,
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT,
+    ADD COLUMN IF NOT EXISTS hash TEXT,
+    ADD COLUMN IF NOT EXISTS content_digest TEXT,
+    ADD COLUMN IF NOT EXISTS redacted_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_seq ON public.audit_logs(seq);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON public.audit_logs(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_created ON public.audit_logs(created_at DESC);
@@ -0,0 +1,8 @@
+-- Encrypted values are left in place; decrypt them (hdctl pii decrypt) before
+-- rolling back, or the API will serve ciphertext as names and emails.
+DROP INDEX IF EXISTS public.idx_bookings_email_bidx;
+DROP INDEX IF EXISTS public.idx_bookings_name_bidx;
+
+ALTER TABLE public.bookings
+    DROP COLUMN IF EXISTS email_bidx,
+    DROP COLUMN IF EXISTS name_bidx;
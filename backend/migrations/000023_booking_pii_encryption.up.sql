@@ -0,0 +1,17 @@
+-- Migration 000023: field-level encryption of booking names and emails.
+-- name and email keep their TEXT columns but hold "enc:<key id>:<ciphertext>"
+-- once encrypted (plaintext rows stay readable until "hdctl pii encrypt" has
+-- migrated them). Ciphertext cannot be searched, so name_bidx and email_bidx
+-- hold keyed hashes of the normalised values for exact-match lookups.
+
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS name_bidx TEXT,
+    ADD COLUMN IF NOT EXISTS email_bidx TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_name_bidx
+ON public.bookings (name_bidx)
+WHERE name_bidx IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_email_bidx
+ON public.bookings (email_bidx)
+WHERE email_bidx IS NOT NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE public.bookings DROP CONSTRAINT IF EXISTS bookings_session_status_check;
+
+ALTER TABLE public.bookings
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS payment_reference,
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS refund_amount,
+    DROP COLUMN IF EXISTS refund_id,
+    DROP COLUMN IF EXISTS session_status_at,
+    DROP COLUMN IF EXISTS session_status;
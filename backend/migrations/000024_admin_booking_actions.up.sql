@@ -0,0 +1,20 @@
+-- Migration 000024: admin actions on bookings.
+-- session_status records what happened to a confirmed session once it was due
+-- (payment_status only says whether it was paid for). Refund columns record a
+-- gateway refund issued on cancellation; payment_reference records how an
+-- offline payment was made; created_by is the admin who booked on a client's
+-- behalf.
+
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS session_status TEXT,
+    ADD COLUMN IF NOT EXISTS session_status_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS refund_id TEXT,
+    ADD COLUMN IF NOT EXISTS refund_amount DECIMAL(10, 2),
+    ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS payment_reference TEXT,
+    ADD COLUMN IF NOT EXISTS created_by UUID;
+
+ALTER TABLE public.bookings DROP CONSTRAINT IF EXISTS bookings_session_status_check;
+ALTER TABLE public.bookings
+    ADD CONSTRAINT bookings_session_status_check
+    CHECK (session_status IS NULL OR session_status IN ('completed', 'no_show'));
@@ -0,0 +1,15 @@
+ALTER TABLE public.bookings DROP CONSTRAINT IF EXISTS bookings_session_status_check;
+DROP INDEX IF EXISTS public.idx_bookings_session_status;
+
+UPDATE public.bookings SET session_status = 'no_show' WHERE session_status IN ('no_show_client', 'no_show_mentor');
+UPDATE public.bookings SET session_status = NULL WHERE session_status IN ('scheduled', 'in_progress', 'late_cancelled');
+
+ALTER TABLE public.bookings
+    ADD CONSTRAINT bookings_session_status_check
+    CHECK (session_status IS NULL OR session_status IN ('completed', 'no_show'));
+
+ALTER TABLE public.bookings
+    DROP COLUMN IF EXISTS session_ended_at,
+    DROP COLUMN IF EXISTS session_started_at,
+    DROP COLUMN IF EXISTS mentor_joined_at,
+    DROP COLUMN IF EXISTS client_joined_at;
@@ -0,0 +1,37 @@
+-- Migration 000025: session lifecycle separate from payment status.
+-- session_status follows a paid booking through
+--   scheduled -> in_progress -> completed | no_show_client | no_show_mentor
+-- or scheduled/in_progress -> late_cancelled when it is cancelled inside the
+-- late-cancellation window. It is NULL for bookings that were never paid or
+-- were cancelled in good time. session_status_at is the last transition.
+
+ALTER TABLE public.bookings
+    ADD COLUMN IF NOT EXISTS client_joined_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS mentor_joined_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS session_started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS session_ended_at TIMESTAMPTZ;
+
+ALTER TABLE public.bookings DROP CONSTRAINT IF EXISTS bookings_session_status_check;
+
+UPDATE public.bookings
+SET session_status = 'no_show_client'
+WHERE session_status = 'no_show';
+
+UPDATE public.bookings
+SET session_ended_at = COALESCE(session_ended_at, session_status_at)
+WHERE session_status IN ('completed', 'no_show_client');
+
+UPDATE public.bookings
+SET session_status = 'scheduled',
+    session_status_at = COALESCE(confirmed_at, created_at)
+WHERE payment_status = 'paid' AND session_status IS NULL;
+
+ALTER TABLE public.bookings
+    ADD CONSTRAINT bookings_session_status_check
+    CHECK (session_status IS NULL OR session_status IN (
+        'scheduled', 'in_progress', 'completed', 'no_show_client', 'no_show_mentor', 'late_cancelled'
+    ));
+
+CREATE INDEX IF NOT EXISTS idx_bookings_session_status
+ON public.bookings (session_status)
+WHERE session_status IS NOT NULL;
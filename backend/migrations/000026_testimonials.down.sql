@@ -0,0 +1,16 @@
+DROP POLICY IF EXISTS "Public can view published testimonials" ON public.testimonials;
+
+DROP INDEX IF EXISTS public.idx_testimonials_user;
+DROP INDEX IF EXISTS public.idx_testimonials_status_created;
+DROP INDEX IF EXISTS public.idx_testimonials_booking_unique;
+
+ALTER TABLE public.testimonials DROP CONSTRAINT IF EXISTS testimonials_rating_check;
+ALTER TABLE public.testimonials DROP CONSTRAINT IF EXISTS testimonials_status_check;
+
+ALTER TABLE public.testimonials
+    DROP COLUMN IF EXISTS moderation_note,
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS allow_publish,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS booking_id;
@@ -0,0 +1,71 @@
+-- Migration 000026: post-session feedback and testimonial moderation.
+-- testimonials was created directly in Supabase; create it here for fresh
+-- databases, then link each entry to the booking it reviews and replace the
+-- approved/featured flags with a moderation status. The flags are kept in
+-- sync with status for clients that still read them.
+
+CREATE TABLE IF NOT EXISTS public.testimonials (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    name TEXT NOT NULL DEFAULT 'Anonymous',
+    content TEXT NOT NULL,
+    rating INTEGER NOT NULL,
+    is_anonymous BOOLEAN NOT NULL DEFAULT TRUE,
+    session_date DATE,
+    approved BOOLEAN NOT NULL DEFAULT FALSE,
+    featured BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE public.testimonials
+    ADD COLUMN IF NOT EXISTS booking_id UUID REFERENCES public.bookings(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS allow_publish BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS moderated_by UUID,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS moderation_note TEXT;
+
+UPDATE public.testimonials
+SET status = CASE WHEN featured THEN 'featured' WHEN approved THEN 'approved' ELSE 'pending' END
+WHERE status = 'pending' AND (approved OR featured);
+
+ALTER TABLE public.testimonials DROP CONSTRAINT IF EXISTS testimonials_status_check;
+ALTER TABLE public.testimonials
+    ADD CONSTRAINT testimonials_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected', 'featured'));
+
+-- Older rows were inserted by the browser without server-side checks
+ALTER TABLE public.testimonials DROP CONSTRAINT IF EXISTS testimonials_rating_check;
+ALTER TABLE public.testimonials
+    ADD CONSTRAINT testimonials_rating_check
+    CHECK (rating BETWEEN 1 AND 5) NOT VALID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_testimonials_booking_unique
+ON public.testimonials (booking_id)
+WHERE booking_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_testimonials_status_created
+ON public.testimonials (status, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_testimonials_user
+ON public.testimonials (user_id)
+WHERE user_id IS NOT NULL;
+
+-- Only the Go backend (superuser connection) writes testimonials, so drop
+-- whatever browser policies were set up in Supabase; the public may read
+-- published entries only.
+ALTER TABLE public.testimonials ENABLE ROW LEVEL SECURITY;
+
+DO $$
+DECLARE
+    pol RECORD;
+BEGIN
+    FOR pol IN SELECT policyname FROM pg_policies WHERE schemaname = 'public' AND tablename = 'testimonials' LOOP
+        EXECUTE format('DROP POLICY %I ON public.testimonials', pol.policyname);
+    END LOOP;
+END $$;
+
+CREATE POLICY "Public can view published testimonials" ON public.testimonials
+    FOR SELECT TO anon, authenticated
+    USING (status IN ('approved', 'featured') AND allow_publish);
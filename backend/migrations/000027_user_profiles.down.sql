@@ -0,0 +1,28 @@
+DROP POLICY IF EXISTS "Users can view own profile" ON public.user_profiles;
+
+DROP INDEX IF EXISTS public.idx_user_profiles_created;
+DROP INDEX IF EXISTS public.idx_user_profiles_blocked;
+DROP INDEX IF EXISTS public.idx_user_profiles_email;
+
+CREATE OR REPLACE FUNCTION public.handle_new_user()
+RETURNS trigger
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = public
+AS $$
+BEGIN
+  INSERT INTO public.user_profiles (id)
+  VALUES (NEW.id)
+  ON CONFLICT (id) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+
+ALTER TABLE public.user_profiles
+    DROP COLUMN IF EXISTS blocked_reason,
+    DROP COLUMN IF EXISTS blocked_by,
+    DROP COLUMN IF EXISTS blocked_at,
+    DROP COLUMN IF EXISTS notification_preferences,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS display_name;
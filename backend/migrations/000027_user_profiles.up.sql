@@ -0,0 +1,81 @@
+-- Migration 000027: user profiles served by the API.
+-- user_profiles is created by the Supabase auth trigger (handle_new_user);
+-- 000015 creates it on fresh databases, so the CREATE below is a safety no-op.
+-- Add the fields users edit through /me and the block state admins manage
+-- through /admin/users.
+
+CREATE TABLE IF NOT EXISTS public.user_profiles (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE public.user_profiles
+    ADD COLUMN IF NOT EXISTS email TEXT,
+    ADD COLUMN IF NOT EXISTS full_name TEXT,
+    ADD COLUMN IF NOT EXISTS preferred_locale TEXT,
+    ADD COLUMN IF NOT EXISTS display_name TEXT,
+    ADD COLUMN IF NOT EXISTS phone TEXT, -- encrypted when PII_ENCRYPTION_KEYS is set
+    ADD COLUMN IF NOT EXISTS timezone TEXT,
+    ADD COLUMN IF NOT EXISTS notification_preferences JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blocked_by UUID,
+    ADD COLUMN IF NOT EXISTS blocked_reason TEXT,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Copy the sign-in email into new profiles so admins can search by it.
+CREATE OR REPLACE FUNCTION public.handle_new_user()
+RETURNS trigger
+LANGUAGE plpgsql
+SECURITY DEFINER
+SET search_path = public
+AS $$
+BEGIN
+  INSERT INTO public.user_profiles (id, email, full_name)
+  VALUES (NEW.id, NEW.email, NEW.raw_user_meta_data->>'full_name')
+  ON CONFLICT (id) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+
+DO $$
+BEGIN
+    IF to_regclass('auth.users') IS NOT NULL THEN
+        UPDATE public.user_profiles p
+        SET email = u.email,
+            full_name = COALESCE(p.full_name, u.raw_user_meta_data->>'full_name')
+        FROM auth.users u
+        WHERE u.id = p.id AND p.email IS NULL;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_user_profiles_email
+ON public.user_profiles (lower(email));
+
+CREATE INDEX IF NOT EXISTS idx_user_profiles_blocked
+ON public.user_profiles (blocked_at)
+WHERE blocked_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_profiles_created
+ON public.user_profiles (created_at DESC);
+
+-- Only the Go backend (superuser connection) writes profiles: users edit
+-- theirs through /me and admins set the block state through /admin/users.
+-- Drop whatever browser policies were set up in Supabase so a blocked user
+-- cannot clear blocked_at; users may still read their own row.
+ALTER TABLE public.user_profiles ENABLE ROW LEVEL SECURITY;
+
+DO $$
+DECLARE
+    pol RECORD;
+BEGIN
+    FOR pol IN SELECT policyname FROM pg_policies WHERE schemaname = 'public' AND tablename = 'user_profiles' LOOP
+        EXECUTE format('DROP POLICY %I ON public.user_profiles', pol.policyname);
+    END LOOP;
+END $$;
+
+REVOKE INSERT, UPDATE, DELETE ON public.user_profiles FROM anon, authenticated;
+
+CREATE POLICY "Users can view own profile" ON public.user_profiles
+    FOR SELECT TO authenticated
+    USING (auth.uid() = id);
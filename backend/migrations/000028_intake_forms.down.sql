@@ -0,0 +1,4 @@
+GRANT SELECT ON public.bookings TO authenticated;
+
+DROP TABLE IF EXISTS public.intake_responses;
+DROP TABLE IF EXISTS public.intake_forms;
@@ -0,0 +1,52 @@
+-- Migration 000028: pre-session intake questionnaires.
+-- Admins define the questionnaire as versioned JSON (every edit is a new
+-- version, at most one active). Clients answer it once, before their first
+-- session; each response is tied to the booking and the exact version
+-- answered. Answers are encrypted when PII_ENCRYPTION_KEYS is set.
+
+CREATE TABLE IF NOT EXISTS public.intake_forms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version INT NOT NULL UNIQUE CHECK (version > 0),
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    questions JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_intake_forms_active
+ON public.intake_forms (is_active)
+WHERE is_active;
+
+CREATE TABLE IF NOT EXISTS public.intake_responses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL UNIQUE REFERENCES public.bookings(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    form_id UUID NOT NULL REFERENCES public.intake_forms(id),
+    answers TEXT NOT NULL,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_intake_responses_user
+ON public.intake_responses (user_id);
+
+-- Only the Go backend (superuser connection) reads or writes intake data.
+ALTER TABLE public.intake_forms ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.intake_responses ENABLE ROW LEVEL SECURITY;
+
+-- The meeting link is withheld until the intake form is answered, so clients
+-- must get it from the API (GET /bookings/my, POST /bookings/{id}/join). Keep
+-- the "Users can view own bookings" policy for every other column.
+DO $$
+DECLARE
+    cols TEXT;
+BEGIN
+    SELECT string_agg(quote_ident(column_name), ', ' ORDER BY ordinal_position) INTO cols
+    FROM information_schema.columns
+    WHERE table_schema = 'public' AND table_name = 'bookings' AND column_name <> 'meeting_link';
+
+    REVOKE SELECT ON public.bookings FROM anon, authenticated;
+    EXECUTE format('GRANT SELECT (%s) ON public.bookings TO authenticated', cols);
+END $$;
@@ -0,0 +1,30 @@
+-- Migration 000029: restricted log of crisis signals found in free text.
+-- One row per screened submission that matched a screening rule. The flagged
+-- text is kept only here, encrypted when PII_ENCRYPTION_KEYS is set, for the
+-- mentor to follow up; alerts and the audit log carry no text.
+
+CREATE TABLE IF NOT EXISTS public.crisis_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    booking_id UUID REFERENCES public.bookings(id) ON DELETE SET NULL,
+    source TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('elevated', 'high')),
+    rules TEXT[] NOT NULL DEFAULT '{}',
+    excerpt TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+    alerted_at TIMESTAMPTZ,
+    acknowledged_by UUID,
+    acknowledged_at TIMESTAMPTZ,
+    acknowledgement_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_crisis_events_open
+ON public.crisis_events (created_at DESC)
+WHERE acknowledged_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_crisis_events_user
+ON public.crisis_events (user_id);
+
+-- Only the Go backend (superuser connection) reads or writes crisis events.
+ALTER TABLE public.crisis_events ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.booking_funnel_events;
@@ -0,0 +1,23 @@
+-- Migration 000030: booking funnel events.
+-- Records when a paid booking first reached each step between the slot hold
+-- and payment settlement, with the time elapsed since the hold was created,
+-- so drop-off and latency can be measured per step.
+
+CREATE TABLE IF NOT EXISTS public.booking_funnel_events (
+    booking_id UUID NOT NULL REFERENCES public.bookings(id) ON DELETE CASCADE,
+    step TEXT NOT NULL CHECK (step IN (
+        'hold_created', 'checkout_opened', 'verify_called', 'webhook_received',
+        'confirmed', 'released', 'expired', 'failed'
+    )),
+    detail TEXT NOT NULL DEFAULT '',
+    elapsed_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (booking_id, step)
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_funnel_events_holds
+ON public.booking_funnel_events (created_at)
+WHERE step = 'hold_created';
+
+-- Only the Go backend (superuser connection) reads or writes funnel events.
+ALTER TABLE public.booking_funnel_events ENABLE ROW LEVEL SECURITY;
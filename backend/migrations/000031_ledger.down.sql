@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.settlement_lines;
+DROP TABLE IF EXISTS public.settlement_uploads;
+DROP TABLE IF EXISTS public.ledger_periods;
+DROP TABLE IF EXISTS public.ledger_lines;
+DROP TABLE IF EXISTS public.ledger_transactions;
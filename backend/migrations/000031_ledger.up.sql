@@ -0,0 +1,82 @@
+-- Migration 000031: double-entry ledger and Razorpay settlement matching.
+-- Every movement of money is a balanced transaction of ledger lines:
+--   payment      Dr razorpay_clearing (or bank when paid offline), Cr session_revenue
+--   discount     Dr discounts_allowed, Cr session_revenue
+--   refund       Dr session_revenue, Cr razorpay_clearing
+--   settlement   Dr bank (net payout), Dr gateway_fees, Dr gst_input_credit,
+--                Cr razorpay_clearing (refunds: Dr razorpay_clearing, Cr bank)
+-- (kind, source_ref) makes posting idempotent. Closed periods (months) are
+-- final: postings that arrive late are dated into the current period.
+
+CREATE TABLE IF NOT EXISTS public.ledger_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL CHECK (kind IN ('payment', 'discount', 'refund', 'settlement')),
+    source_ref TEXT NOT NULL,
+    booking_id UUID REFERENCES public.bookings(id) ON DELETE SET NULL,
+    posted_on DATE NOT NULL,
+    narration TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, source_ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_transactions_posted_on
+ON public.ledger_transactions (posted_on);
+
+CREATE TABLE IF NOT EXISTS public.ledger_lines (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES public.ledger_transactions(id) ON DELETE CASCADE,
+    account TEXT NOT NULL,
+    debit NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    CHECK ((debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_lines_transaction
+ON public.ledger_lines (transaction_id);
+
+CREATE TABLE IF NOT EXISTS public.ledger_periods (
+    period TEXT PRIMARY KEY CHECK (period ~ '^[0-9]{4}-[0-9]{2}$'),
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_by UUID
+);
+
+CREATE TABLE IF NOT EXISTS public.settlement_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    filename TEXT NOT NULL DEFAULT '',
+    uploaded_by UUID,
+    row_count INT NOT NULL DEFAULT 0,
+    matched INT NOT NULL DEFAULT 0,
+    mismatched INT NOT NULL DEFAULT 0,
+    unmatched INT NOT NULL DEFAULT 0,
+    ignored INT NOT NULL DEFAULT 0,
+    duplicates INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS public.settlement_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    upload_id UUID NOT NULL REFERENCES public.settlement_uploads(id) ON DELETE CASCADE,
+    settlement_id TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    tax NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    settled_at TIMESTAMPTZ,
+    utr TEXT NOT NULL DEFAULT '',
+    booking_id UUID REFERENCES public.bookings(id) ON DELETE SET NULL,
+    match_status TEXT NOT NULL CHECK (match_status IN ('matched', 'amount_mismatch', 'unmatched', 'ignored')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (settlement_id, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlement_lines_review
+ON public.settlement_lines (created_at DESC)
+WHERE match_status IN ('amount_mismatch', 'unmatched');
+
+-- Only the Go backend (superuser connection) reads or writes the ledger.
+ALTER TABLE public.ledger_transactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.ledger_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.ledger_periods ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.settlement_uploads ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.settlement_lines ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS public.idx_insights_sort_order_id;
+DROP INDEX IF EXISTS public.idx_bookings_user_date_id;
+DROP INDEX IF EXISTS public.idx_bookings_created_id;
+
+ALTER TABLE public.insights
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN sort_order DROP NOT NULL;
+ALTER TABLE public.bookings ALTER COLUMN created_at DROP NOT NULL;
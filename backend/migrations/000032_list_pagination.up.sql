@@ -0,0 +1,22 @@
+-- Migration 000032: keyset pagination.
+-- List endpoints page by (sort column, id) row comparisons, which skip rows
+-- whose sort column is NULL, so the sortable columns that were nullable get
+-- backfilled and made NOT NULL. The indexes serve the default sorts.
+
+UPDATE public.bookings SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE public.bookings ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE public.insights SET sort_order = 0 WHERE sort_order IS NULL;
+UPDATE public.insights SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE public.insights
+    ALTER COLUMN sort_order SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_created_id
+ON public.bookings (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_user_date_id
+ON public.bookings (user_id, date DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_insights_sort_order_id
+ON public.insights (sort_order, id);
@@ -3,6 +3,10 @@ package apperror
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
 )
 
 // AppError is the base domain error type. All operational errors
@@ -14,6 +18,11 @@ type AppError struct {
 	Retryable  bool              `json:"retryable"`
 	Context    map[string]string `json:"context,omitempty"`
 	Cause      error             `json:"-"`
+
+	// msgID and args rebuild Message in another locale. An empty msgID means
+	// Message itself is the catalog key.
+	msgID string
+	args  []any
 }
 
 func (e *AppError) Error() string {
@@ -38,6 +47,26 @@ func (e *AppError) WithContext(key, value string) *AppError {
 	return e
 }
 
+// Localize returns Message translated into locale. The error code is unchanged.
+// String arguments are looked up in the catalog too, so reasons such as
+// "booking is not pending" are translated when a translation exists.
+func (e *AppError) Localize(locale string) string {
+	if locale == "" || locale == i18n.Default {
+		return e.Message
+	}
+	if e.msgID == "" {
+		return i18n.T(locale, e.Message)
+	}
+	args := make([]any, len(e.args))
+	for i, arg := range e.args {
+		if s, ok := arg.(string); ok {
+			arg = i18n.T(locale, s)
+		}
+		args[i] = arg
+	}
+	return i18n.T(locale, e.msgID, args...)
+}
+
 // IsRetryable checks if any error in the chain is retryable.
 func IsRetryable(err error) bool {
 	if appErr, ok := AsAppError(err); ok {
@@ -105,12 +134,55 @@ func BookingUnauthorized() *AppError {
 	}
 }
 
+func HoldLimitReached(limit int) *AppError {
+	return &AppError{
+		Code:       "HOLD_LIMIT_REACHED",
+		Message:    "You have too many unpaid bookings in progress. Complete or release one first.",
+		HTTPStatus: http.StatusTooManyRequests,
+		Retryable:  true,
+		Context:    map[string]string{"limit": strconv.Itoa(limit)},
+	}
+}
+
+func BookingLimitReached(limit int) *AppError {
+	return &AppError{
+		Code:       "BOOKING_LIMIT_REACHED",
+		Message:    fmt.Sprintf("You already have %d upcoming sessions booked", limit),
+		msgID:      "You already have %d upcoming sessions booked",
+		args:       []any{limit},
+		HTTPStatus: http.StatusConflict,
+		Retryable:  false,
+		Context:    map[string]string{"limit": strconv.Itoa(limit)},
+	}
+}
+
+func BookingCooldown(until time.Time) *AppError {
+	return &AppError{
+		Code:       "BOOKING_COOLDOWN",
+		Message:    "Too many unfinished bookings. Please try again later.",
+		HTTPStatus: http.StatusTooManyRequests,
+		Retryable:  true,
+		Context:    map[string]string{"retry_at": until.UTC().Format(time.RFC3339)},
+	}
+}
+
+func CaptchaFailed() *AppError {
+	return &AppError{
+		Code:       "CAPTCHA_FAILED",
+		Message:    "CAPTCHA verification failed. Please try again.",
+		HTTPStatus: http.StatusBadRequest,
+		Retryable:  true,
+	}
+}
+
 // --- Payment Domain Errors ---
 
 func PaymentDeclined(reason string) *AppError {
 	return &AppError{
 		Code:       "PAYMENT_DECLINED",
 		Message:    fmt.Sprintf("Payment declined: %s", reason),
+		msgID:      "Payment declined: %s",
+		args:       []any{reason},
 		HTTPStatus: http.StatusPaymentRequired,
 		Retryable:  false,
 		Context:    map[string]string{"reason": reason},
@@ -157,6 +229,20 @@ func ValidationError(field, reason string) *AppError {
 	}
 }
 
+// ValidationErrorf is ValidationError with a formatted reason. The format string
+// is the catalog key, so the reason can be localized.
+func ValidationErrorf(field, format string, args ...any) *AppError {
+	return &AppError{
+		Code:       "VALIDATION_ERROR",
+		Message:    fmt.Sprintf(format, args...),
+		HTTPStatus: http.StatusBadRequest,
+		Retryable:  false,
+		Context:    map[string]string{"field": field},
+		msgID:      format,
+		args:       args,
+	}
+}
+
 func InvalidPayload(cause error) *AppError {
 	return &AppError{
 		Code:       "INVALID_PAYLOAD",
@@ -197,6 +283,36 @@ func AuthTokenInvalid(cause error) *AppError {
 	}
 }
 
+// AuthTokenRevoked is returned for tokens revoked by jti or forced logout.
+func AuthTokenRevoked() *AppError {
+	return &AppError{
+		Code:       "AUTH_TOKEN_REVOKED",
+		Message:    "Session has been revoked. Please sign in again.",
+		HTTPStatus: http.StatusUnauthorized,
+		Retryable:  false,
+	}
+}
+
+// AccountBlocked is returned for users an admin has blocked.
+func AccountBlocked() *AppError {
+	return &AppError{
+		Code:       "ACCOUNT_BLOCKED",
+		Message:    "This account has been suspended. Please contact support.",
+		HTTPStatus: http.StatusForbidden,
+		Retryable:  false,
+	}
+}
+
+// APIKeyInvalid is returned for unknown, expired or revoked API keys.
+func APIKeyInvalid() *AppError {
+	return &AppError{
+		Code:       "API_KEY_INVALID",
+		Message:    "Invalid or expired API key",
+		HTTPStatus: http.StatusUnauthorized,
+		Retryable:  false,
+	}
+}
+
 func AdminAccessDenied() *AppError {
 	return &AppError{
 		Code:       "ADMIN_ACCESS_DENIED",
@@ -206,12 +322,62 @@ func AdminAccessDenied() *AppError {
 	}
 }
 
+// PermissionDenied is returned when the caller's roles do not grant permission.
+func PermissionDenied(permission string) *AppError {
+	return &AppError{
+		Code:       "PERMISSION_DENIED",
+		Message:    "You do not have permission to perform this action",
+		HTTPStatus: http.StatusForbidden,
+		Retryable:  false,
+		Context:    map[string]string{"permission": permission},
+	}
+}
+
+// --- Resource Errors ---
+
+func ResourceNotFound(resource, id string) *AppError {
+	return &AppError{
+		Code:       "RESOURCE_NOT_FOUND",
+		Message:    fmt.Sprintf("%s not found", resource),
+		msgID:      "%s not found",
+		args:       []any{resource},
+		HTTPStatus: http.StatusNotFound,
+		Retryable:  false,
+		Context:    map[string]string{"resource": resource, "id": id},
+	}
+}
+
+// --- Account Errors ---
+
+func AccountHasUpcomingBookings() *AppError {
+	return &AppError{
+		Code:       "ACCOUNT_HAS_UPCOMING_BOOKINGS",
+		Message:    "Cancel your upcoming sessions before deleting your account",
+		HTTPStatus: http.StatusConflict,
+		Retryable:  false,
+	}
+}
+
+// --- Email Errors ---
+
+func EmailSuppressed(reason string) *AppError {
+	return &AppError{
+		Code:       "EMAIL_SUPPRESSED",
+		Message:    "Recipient is on the email suppression list",
+		HTTPStatus: http.StatusUnprocessableEntity,
+		Retryable:  false,
+		Context:    map[string]string{"reason": reason},
+	}
+}
+
 // --- Infrastructure Errors ---
 
 func DatabaseError(operation string, cause error) *AppError {
 	return &AppError{
 		Code:       "DB_ERROR",
 		Message:    fmt.Sprintf("Database operation failed: %s", operation),
+		msgID:      "Database operation failed: %s",
+		args:       []any{operation},
 		HTTPStatus: http.StatusInternalServerError,
 		Retryable:  true,
 		Context:    map[string]string{"operation": operation},
@@ -223,6 +389,8 @@ func ExternalServiceError(service string, cause error) *AppError {
 	return &AppError{
 		Code:       "EXTERNAL_SERVICE_ERROR",
 		Message:    fmt.Sprintf("%s is temporarily unavailable", service),
+		msgID:      "%s is temporarily unavailable",
+		args:       []any{service},
 		HTTPStatus: http.StatusBadGateway,
 		Retryable:  true,
 		Context:    map[string]string{"service": service},
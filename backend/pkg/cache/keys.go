@@ -11,11 +11,36 @@ const (
 	// InsightsTTL - insights data rarely changes (admin-only edits)
 	InsightsTTL = 5 * time.Minute
 
-	// RateLimitTTL - sliding window expiry for rate limiting
-	RateLimitTTL = 1 * time.Minute
+	// IntakeFormTTL - the active intake form changes only on admin edits
+	IntakeFormTTL = 5 * time.Minute
+
+	// TestimonialsTTL - published testimonials change only on moderation
+	TestimonialsTTL = 5 * time.Minute
 
 	// SessionTTL - balance security vs. performance for token caching
 	SessionTTL = 15 * time.Minute
+
+	// EmailTemplateTTL - active template versions change only on admin edits
+	EmailTemplateTTL = 5 * time.Minute
+
+	// UserLocaleTTL - language preference is read on every authenticated request
+	UserLocaleTTL = 10 * time.Minute
+
+	// UserRolesTTL - short so revoked admin roles stop working quickly
+	UserRolesTTL = 1 * time.Minute
+
+	// RevocationTTL - revocation lookups (including "not revoked") are written
+	// through on revoke, so this only bounds how long a cache miss is remembered
+	RevocationTTL = 10 * time.Minute
+
+	// UserBlockedTTL - block state is written through on block and unblock
+	UserBlockedTTL = 10 * time.Minute
+
+	// APIKeyTTL - validated API keys; revocation deletes the entry immediately
+	APIKeyTTL = 1 * time.Minute
+
+	// AnalyticsTTL - admin analytics aggregates; a minute of lag is acceptable
+	AnalyticsTTL = 1 * time.Minute
 )
 
 // Key prefixes for cache namespacing
@@ -24,6 +49,19 @@ const (
 	PrefixInsights  = "insights:"
 	PrefixRateLimit = "ratelimit:"
 	PrefixSession   = "session:"
+
+	PrefixEmailTemplate = "emailtpl:"
+	PrefixUserLocale    = "userlocale:"
+	PrefixUserRoles     = "userroles:"
+	PrefixUserSessions  = "usersessions:"
+	PrefixRevokedToken  = "revokedjti:"
+	PrefixUserNotBefore = "notbefore:"
+	PrefixAPIKey        = "apikey:"
+	PrefixTestimonials  = "testimonials:"
+	PrefixUserBlocked   = "userblocked:"
+	PrefixIntakeForm    = "intakeform:"
+	PrefixAnalytics     = "analytics:"
+	PrefixFunnel        = "funnel:"
 )
 
 // SlotsKey returns the cache key for booking slots for a specific date
@@ -31,17 +69,78 @@ func SlotsKey(date string) string {
 	return PrefixSlots + date
 }
 
-// InsightsKey returns the cache key for all insights
-func InsightsKey() string {
-	return PrefixInsights + "all"
+// InsightsKey returns the cache key for one page of insights, identified by
+// its pagination cache key
+func InsightsKey(page string) string {
+	return PrefixInsights + "page:" + page
 }
 
-// RateLimitKey returns the cache key for rate limiting a specific IP/endpoint
-func RateLimitKey(ip, endpoint string) string {
-	return PrefixRateLimit + ip + ":" + endpoint
+// TestimonialsKey returns the cache key for the published testimonials
+func TestimonialsKey() string {
+	return PrefixTestimonials + "published"
+}
+
+// RateLimitKey returns the cache key for a client's bucket under a named policy
+func RateLimitKey(client, policy string) string {
+	return PrefixRateLimit + policy + ":" + client
 }
 
 // SessionKey returns the cache key for a session token hash
 func SessionKey(tokenHash string) string {
 	return PrefixSession + tokenHash
 }
+
+// EmailTemplateKey returns the cache key for the active version of an email template
+func EmailTemplateKey(name string) string {
+	return PrefixEmailTemplate + name
+}
+
+// UserLocaleKey returns the cache key for a user's saved language preference
+func UserLocaleKey(userID string) string {
+	return PrefixUserLocale + userID
+}
+
+// UserRolesKey returns the cache key for a user's admin role assignments
+func UserRolesKey(userID string) string {
+	return PrefixUserRoles + userID
+}
+
+// UserSessionsKey returns the cache key for the set of session hashes cached for a user
+func UserSessionsKey(userID string) string {
+	return PrefixUserSessions + userID
+}
+
+// RevokedTokenKey returns the cache key recording whether a token jti is revoked
+func RevokedTokenKey(jti string) string {
+	return PrefixRevokedToken + jti
+}
+
+// UserNotBeforeKey returns the cache key for a user's forced-logout timestamp
+func UserNotBeforeKey(userID string) string {
+	return PrefixUserNotBefore + userID
+}
+
+// UserBlockedKey returns the cache key recording whether an admin blocked a user
+func UserBlockedKey(userID string) string {
+	return PrefixUserBlocked + userID
+}
+
+// IntakeFormKey returns the cache key for the active intake form
+func IntakeFormKey() string {
+	return PrefixIntakeForm + "active"
+}
+
+// APIKeyKey returns the cache key for a validated API key, by key hash
+func APIKeyKey(hash string) string {
+	return PrefixAPIKey + hash
+}
+
+// AnalyticsKey returns the cache key for admin analytics over a date range
+func AnalyticsKey(granularity, from, to, timezone string) string {
+	return PrefixAnalytics + granularity + ":" + from + ":" + to + ":" + timezone
+}
+
+// FunnelKey returns the cache key for the booking funnel over a date range
+func FunnelKey(from, to, timezone string) string {
+	return PrefixFunnel + from + ":" + to + ":" + timezone
+}
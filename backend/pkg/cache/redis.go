@@ -215,6 +215,51 @@ func TTL(ctx context.Context, key string) (time.Duration, error) {
 	return client.TTL(ctx, key).Result()
 }
 
+// SetAdd adds members to a set and refreshes its TTL
+func SetAdd(ctx context.Context, key string, ttl time.Duration, members ...string) error {
+	if !IsEnabled() {
+		return ErrCacheDisabled
+	}
+
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+
+	pipe := client.TxPipeline()
+	pipe.SAdd(ctx, key, args...)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("Cache set add failed", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetMembers returns all members of a set (empty when the key does not exist)
+func SetMembers(ctx context.Context, key string) ([]string, error) {
+	if !IsEnabled() {
+		return nil, ErrCacheDisabled
+	}
+	return client.SMembers(ctx, key).Result()
+}
+
+// Script is a Lua script executed atomically by Redis
+type Script = redis.Script
+
+// NewScript prepares a Lua script; it is loaded on first use and run by SHA after that
+func NewScript(src string) *Script {
+	return redis.NewScript(src)
+}
+
+// RunScript executes script with the given keys and arguments
+func RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	if !IsEnabled() {
+		return nil, ErrCacheDisabled
+	}
+	return script.Run(ctx, client, keys, args...).Result()
+}
+
 // GetClient returns the underlying Redis client for advanced operations
 func GetClient() *redis.Client {
 	return client
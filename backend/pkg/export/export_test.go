@@ -0,0 +1,83 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(FormatCSV, &buf, "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	amount := 1500.5
+	when := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	rows := [][]any{
+		{"id", "amount", "note", "at"},
+		{"b1", &amount, "=HYPERLINK(\"x\")", when},
+		{"b2", -20.0, nil, (*time.Time)(nil)},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row...); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	want := "id,amount,note,at\nb1,1500.5,\"'=HYPERLINK(\"\"x\"\")\",2026-10-01T09:30:00Z\nb2,-20,,\n"
+	if buf.String() != want {
+		t.Fatalf("CSV = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(FormatXLSX, &buf, "Bookings: 2026/10")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := w.WriteRow("name", "amount"); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := w.WriteRow("A & B <c>", 42); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Bookings_ 2026_10"`) {
+		t.Fatalf("workbook = %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{`<row r="2">`, `A &amp; B &lt;c&gt;`, `<c t="n"><v>42</v></c>`, `</sheetData></worksheet>`} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet missing %q: %s", want, sheet)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package i18n
+
+// bengali is the Bengali catalog, keyed by English message ID.
+var bengali = map[string]string{
+	// API errors
+	"This slot is no longer available":                                              "এই স্লটটি আর উপলব্ধ নেই",
+	"Another user is completing payment for this slot. Try again shortly.":          "অন্য একজন ব্যবহারকারী এই স্লটের জন্য পেমেন্ট সম্পন্ন করছেন। একটু পরে আবার চেষ্টা করুন।",
+	"Booking does not exist":                                                        "বুকিংটি নেই",
+	"You are not authorized to modify this booking":                                 "এই বুকিং পরিবর্তন করার অনুমতি আপনার নেই",
+	"You have too many unpaid bookings in progress. Complete or release one first.": "আপনার অনেকগুলি অপরিশোধিত বুকিং চলছে। আগে একটি সম্পূর্ণ করুন বা ছেড়ে দিন।",
+	"You already have %d upcoming sessions booked":                                  "আপনার ইতিমধ্যে %dটি আসন্ন সেশন বুক করা আছে",
+	"Too many unfinished bookings. Please try again later.":                         "অনেকগুলি অসম্পূর্ণ বুকিং। অনুগ্রহ করে পরে আবার চেষ্টা করুন।",
+	"CAPTCHA verification failed. Please try again.":                                "CAPTCHA যাচাই ব্যর্থ হয়েছে। অনুগ্রহ করে আবার চেষ্টা করুন।",
+	"Payment declined: %s":                                                          "পেমেন্ট প্রত্যাখ্যাত: %s",
+	"booking is not pending":                                                        "বুকিংটি অপেক্ষমাণ নয়",
+	"booking is no longer pending":                                                  "বুকিংটি আর অপেক্ষমাণ নয়",
+	"Invalid payment signature":                                                     "অবৈধ পেমেন্ট স্বাক্ষর",
+	"Payment gateway timed out. Please try again.":                                  "পেমেন্ট গেটওয়ের সময় শেষ হয়ে গেছে। অনুগ্রহ করে আবার চেষ্টা করুন।",
+	"Payment service temporarily unavailable":                                       "পেমেন্ট পরিষেবা সাময়িকভাবে অনুপলব্ধ",
+	"Invalid request payload":                                                       "অবৈধ অনুরোধের ডেটা",
+	"Authorization header required":                                                 "অথরাইজেশন হেডার প্রয়োজন",
+	"Invalid authorization header format":                                           "অথরাইজেশন হেডারের বিন্যাস অবৈধ",
+	"Invalid or expired token":                                                      "অবৈধ বা মেয়াদোত্তীর্ণ টোকেন",
+	"Invalid or expired API key":                                                    "অবৈধ বা মেয়াদোত্তীর্ণ API কী",
+	"Session has been revoked. Please sign in again.":                               "সেশন বাতিল করা হয়েছে। অনুগ্রহ করে আবার সাইন ইন করুন।",
+	"This account has been suspended. Please contact support.":                      "এই অ্যাকাউন্টটি স্থগিত করা হয়েছে। অনুগ্রহ করে সহায়তার সাথে যোগাযোগ করুন।",
+	"Admin access only":                                                             "শুধুমাত্র অ্যাডমিনের জন্য",
+	"You do not have permission to perform this action":                             "এই কাজটি করার অনুমতি আপনার নেই",
+	"%s not found": "%s পাওয়া যায়নি",
+	"Cancel your upcoming sessions before deleting your account": "অ্যাকাউন্ট মুছে ফেলার আগে আপনার আসন্ন সেশনগুলি বাতিল করুন",
+	"Recipient is on the email suppression list":                 "প্রাপক ইমেল বন্ধ তালিকায় রয়েছেন",
+	"Database operation failed: %s":                              "ডেটাবেস অপারেশন ব্যর্থ: %s",
+	"%s is temporarily unavailable":                              "%s সাময়িকভাবে অনুপলব্ধ",
+	"Too many requests. Please slow down.":                       "অনেক বেশি অনুরোধ। অনুগ্রহ করে একটু ধীরে চলুন।",
+	"An unexpected error occurred":                               "একটি অপ্রত্যাশিত ত্রুটি ঘটেছে",
+
+	// Validation
+	"Date is required":                                         "তারিখ প্রয়োজন",
+	"Invalid date format. Use YYYY-MM-DD.":                     "তারিখের বিন্যাস অবৈধ। YYYY-MM-DD ব্যবহার করুন।",
+	"Invalid date format":                                      "তারিখের বিন্যাস অবৈধ",
+	"Invalid date":                                             "অবৈধ তারিখ",
+	"Cannot book dates in the past":                            "অতীতের তারিখে বুক করা যাবে না",
+	"Cannot book more than 3 months in advance":                "৩ মাসের বেশি আগে বুক করা যাবে না",
+	"Time is required":                                         "সময় প্রয়োজন",
+	"Invalid time format. Use format like '12:00 PM'":          "সময়ের বিন্যাস অবৈধ। '12:00 PM'-এর মতো বিন্যাস ব্যবহার করুন",
+	"Name is required":                                         "নাম প্রয়োজন",
+	"Name must be at least 2 characters":                       "নাম অন্তত ২ অক্ষরের হতে হবে",
+	"Name must be less than 100 characters":                    "নাম ১০০ অক্ষরের কম হতে হবে",
+	"Name contains invalid characters":                         "নামে অবৈধ অক্ষর রয়েছে",
+	"Email is required":                                        "ইমেল প্রয়োজন",
+	"Email is too long":                                        "ইমেলটি খুব দীর্ঘ",
+	"Invalid email format":                                     "ইমেলের বিন্যাস অবৈধ",
+	"Email address is required":                                "ইমেল ঠিকানা প্রয়োজন",
+	"This date is not available for booking":                   "এই তারিখটি বুকিংয়ের জন্য উপলব্ধ নয়",
+	"This time slot is not available for booking":              "এই সময়ের স্লটটি বুকিংয়ের জন্য উপলব্ধ নয়",
+	"No booking dates are currently available":                 "এই মুহূর্তে বুকিংয়ের জন্য কোনো তারিখ উপলব্ধ নেই",
+	"Bookings are currently limited to: %s":                    "বুকিং বর্তমানে এই তারিখগুলিতে সীমাবদ্ধ: %s",
+	"Set confirm to true to delete your account":               "অ্যাকাউন্ট মুছে ফেলা নিশ্চিত করতে confirm true করুন",
+	"Booking ID is required":                                   "বুকিং আইডি প্রয়োজন",
+	"Coupon code is required":                                  "কুপন কোড প্রয়োজন",
+	"Invalid coupon code":                                      "অবৈধ কুপন কোড",
+	"This coupon is no longer active":                          "এই কুপনটি আর সক্রিয় নেই",
+	"This coupon has reached its usage limit":                  "এই কুপনটির ব্যবহারের সীমা পূর্ণ হয়েছে",
+	"This coupon has expired":                                  "এই কুপনটির মেয়াদ শেষ হয়ে গেছে",
+	"Order ID does not match booking":                          "অর্ডার আইডি বুকিংয়ের সাথে মেলে না",
+	"Booking is no longer pending":                             "বুকিংটি আর অপেক্ষমাণ নয়",
+	"Booking is already confirmed and cannot be auto-released": "বুকিংটি ইতিমধ্যে নিশ্চিত হয়েছে এবং স্বয়ংক্রিয়ভাবে ছেড়ে দেওয়া যাবে না",
+	"All payment fields are required":                          "পেমেন্টের সব ঘর পূরণ করা প্রয়োজন",
+	"Only confirmed bookings can be cancelled here":            "এখানে শুধুমাত্র নিশ্চিত বুকিং বাতিল করা যায়",
+	"Unsupported locale":                                       "অসমর্থিত ভাষা",
+	"Invalid user ID":                                          "অবৈধ ব্যবহারকারী আইডি",
+	"Unknown role":                                             "অজানা ভূমিকা",
+	"Cannot remove the last owner":                             "শেষ মালিককে সরানো যাবে না",
+	"Booking ID must be a UUID":                                "বুকিং আইডি একটি UUID হতে হবে",
+	"The session has already started":                          "সেশনটি ইতিমধ্যে শুরু হয়ে গেছে",
+	"This session is not open to join yet":                     "এই সেশনে এখনও যোগ দেওয়া যাবে না",
+	"This session has ended":                                   "এই সেশনটি শেষ হয়ে গেছে",
+	"Rating must be between 1 and 5":                           "রেটিং ১ থেকে ৫-এর মধ্যে হতে হবে",
+	"Feedback must be between %d and %d characters":            "মতামত %d থেকে %d অক্ষরের মধ্যে হতে হবে",
+	"Feedback opens once the session has ended":                "সেশন শেষ হওয়ার পরেই মতামত দেওয়া যাবে",
+	"Feedback is only open for sessions that took place":       "শুধুমাত্র অনুষ্ঠিত সেশনের জন্য মতামত দেওয়া যাবে",
+	"Feedback has already been submitted for this session":     "এই সেশনের জন্য মতামত ইতিমধ্যে দেওয়া হয়েছে",
+	"Invalid phone number":                                     "অবৈধ ফোন নম্বর",
+	"Unknown timezone":                                         "অজানা সময় অঞ্চল",
+	"No profile fields to update":                              "আপডেট করার মতো কোনো প্রোফাইল ফিল্ড নেই",
+	"Complete the intake form before joining your session":     "সেশনে যোগ দেওয়ার আগে ইনটেক ফর্মটি পূরণ করুন",
+	"Intake answers can no longer be changed":                  "ইনটেক উত্তর আর পরিবর্তন করা যাবে না",
+	"No intake form is needed for this session":                "এই সেশনের জন্য ইনটেক ফর্মের প্রয়োজন নেই",
+	"Unknown question: %s":                                     "অজানা প্রশ্ন: %s",
+	"This question is required":                                "এই প্রশ্নটি আবশ্যক",
+	"Answer must be text":                                      "উত্তরটি লেখা হতে হবে",
+	"Answer must be at most %d characters":                     "উত্তর সর্বোচ্চ %d অক্ষরের হতে হবে",
+	"Choose one of the listed options":                         "তালিকাভুক্ত বিকল্পগুলির একটি বেছে নিন",
+	"Answer must be a whole number from %d to %d":              "উত্তর %d থেকে %d এর মধ্যে একটি পূর্ণসংখ্যা হতে হবে",
+	"Answer must be yes or no":                                 "উত্তর হ্যাঁ বা না হতে হবে",
+
+	// Pagination
+	"limit must be a positive integer":                             "limit একটি ধনাত্মক পূর্ণসংখ্যা হতে হবে",
+	"sort must be one of: %s":                                      "sort এগুলির একটি হতে হবে: %s",
+	"Invalid cursor":                                               "অবৈধ cursor",
+	"The cursor belongs to a different sort":                       "এই cursor অন্য একটি sort-এর",
+	"Unknown field: %s":                                            "অজানা ফিল্ড: %s",
+	"page is not supported; follow pagination.next_cursor instead": "page সমর্থিত নয়; এর বদলে pagination.next_cursor ব্যবহার করুন",
+
+	// API success messages
+	"Booking successful":                     "বুকিং সফল হয়েছে",
+	"Payment initiated":                      "পেমেন্ট শুরু হয়েছে",
+	"Payment already initiated":              "পেমেন্ট ইতিমধ্যে শুরু হয়েছে",
+	"Payment verified and booking confirmed": "পেমেন্ট যাচাই হয়েছে এবং বুকিং নিশ্চিত হয়েছে",
+	"Payment already verified":               "পেমেন্ট ইতিমধ্যে যাচাই করা হয়েছে",
+	"Booking cancelled successfully":         "বুকিং সফলভাবে বাতিল করা হয়েছে",
+	"Pending booking released":               "অপেক্ষমাণ বুকিং ছেড়ে দেওয়া হয়েছে",
+	"Checkout recorded":                      "চেকআউট নথিভুক্ত হয়েছে",
+	"Pending booking already released":       "অপেক্ষমাণ বুকিং ইতিমধ্যে ছেড়ে দেওয়া হয়েছে",
+	"Slots fetched successfully":             "স্লট সফলভাবে আনা হয়েছে",
+	"User bookings fetched":                  "আপনার বুকিংগুলি আনা হয়েছে",
+	"Booking status fetched":                 "বুকিংয়ের অবস্থা আনা হয়েছে",
+	"Coupon is valid":                        "কুপনটি বৈধ",
+	"Active subscription found":              "সক্রিয় সাবস্ক্রিপশন পাওয়া গেছে",
+	"No active subscription found":           "কোনো সক্রিয় সাবস্ক্রিপশন পাওয়া যায়নি",
+	"Account deleted":                        "অ্যাকাউন্ট মুছে ফেলা হয়েছে",
+	"Language preference updated":            "ভাষার পছন্দ আপডেট করা হয়েছে",
+	"Role granted":                           "ভূমিকা দেওয়া হয়েছে",
+	"Role revoked":                           "ভূমিকা প্রত্যাহার করা হয়েছে",
+	"Session joined":                         "সেশনে যোগ দেওয়া হয়েছে",
+	"Thank you for your feedback":            "আপনার মতামতের জন্য ধন্যবাদ",
+	"Testimonials fetched successfully":      "প্রশংসাপত্র সফলভাবে আনা হয়েছে",
+	"Profile fetched":                        "প্রোফাইল আনা হয়েছে",
+	"Profile updated":                        "প্রোফাইল আপডেট করা হয়েছে",
+	"Intake form fetched":                    "ইনটেক ফর্ম পাওয়া গেছে",
+	"Intake form submitted":                  "ইনটেক ফর্ম জমা দেওয়া হয়েছে",
+
+	// Email subjects
+	"✨ Booking Confirmed - Your Journey Begins": "✨ বুকিং নিশ্চিত - আপনার যাত্রা শুরু",
+	"⏰ Reminder: Your Session is Tomorrow":      "⏰ রিমাইন্ডার: আপনার সেশন আগামীকাল",
+	"Booking Cancelled - Hidden Depths":         "বুকিং বাতিল - Hidden Depths",
+
+	// Email bodies
+	"Hello":             "নমস্কার",
+	"Welcome,":          "স্বাগতম,",
+	"Date":              "তারিখ",
+	"Time":              "সময়",
+	"Session Details":   "সেশনের বিবরণ",
+	"Join Your Session": "আপনার সেশনে যোগ দিন",
+	"Manage Bookings":   "বুকিং পরিচালনা করুন",
+	"My Profile":        "আমার প্রোফাইল",
+	"Contact Support":   "সহায়তায় যোগাযোগ করুন",
+	"© %d Hidden Depths. All rights reserved.": "© %d Hidden Depths. সর্বস্বত্ব সংরক্ষিত।",
+
+	"Your session with Hidden Depths is confirmed for %s at %s.": "Hidden Depths-এর সাথে আপনার সেশনটি %s, %s-এ নিশ্চিত হয়েছে।",
+	"Booking Confirmed":        "বুকিং নিশ্চিত",
+	"Your journey begins soon": "আপনার যাত্রা শীঘ্রই শুরু হবে",
+	"Your sanctuary session has been confirmed. We look forward to guiding you on your path to inner peace and clarity.": "আপনার সেশনটি নিশ্চিত হয়েছে। অন্তরের শান্তি ও স্পষ্টতার পথে আপনাকে পথ দেখাতে আমরা অপেক্ষায় আছি।",
+	"Save this link for easy access on the day of your session.":                                                         "সেশনের দিন সহজে যোগ দিতে এই লিঙ্কটি সংরক্ষণ করে রাখুন।",
+	"You're receiving this because you booked a session with Hidden Depths.":                                             "আপনি Hidden Depths-এ একটি সেশন বুক করেছেন বলে এই ইমেলটি পাচ্ছেন।",
+
+	"Reminder: Your session with Hidden Depths is tomorrow at %s.": "স্মরণ করিয়ে দিচ্ছি: Hidden Depths-এর সাথে আপনার সেশন আগামীকাল %s-এ।",
+	"Session Reminder":         "সেশন রিমাইন্ডার",
+	"Your session is tomorrow": "আপনার সেশন আগামীকাল",
+	"This is a friendly reminder that your sanctuary session is scheduled for tomorrow. We're looking forward to connecting with you.": "মনে করিয়ে দিচ্ছি যে আপনার সেশনটি আগামীকাল নির্ধারিত। আপনার সাথে যুক্ত হওয়ার অপেক্ষায় আছি।",
+	"💡 Preparation Tips":                                                   "💡 প্রস্তুতির টিপস",
+	"Find a quiet, comfortable space":                                      "একটি শান্ত, আরামদায়ক জায়গা বেছে নিন",
+	"Test your camera and microphone beforehand":                           "আগে থেকে ক্যামেরা ও মাইক্রোফোন পরীক্ষা করে নিন",
+	"Have water nearby and minimize distractions":                          "কাছে জল রাখুন এবং মনোযোগ বিঘ্নকারী জিনিস কমিয়ে দিন",
+	"Join a few minutes early to settle in":                                "স্থির হয়ে বসতে কয়েক মিনিট আগে যোগ দিন",
+	"Need to reschedule? Visit your profile to manage your bookings.":      "সময় বদলাতে চান? বুকিং পরিচালনা করতে আপনার প্রোফাইলে যান।",
+	"You're receiving this reminder because you have an upcoming session.": "আপনার একটি আসন্ন সেশন থাকায় আপনি এই রিমাইন্ডারটি পাচ্ছেন।",
+	"Unsubscribe from session reminders":                                   "সেশন রিমাইন্ডার বন্ধ করুন",
+
+	"Your session for %s at %s has been cancelled.": "%s তারিখে %s-এর আপনার সেশনটি বাতিল করা হয়েছে।",
+	"Booking Cancelled":                             "বুকিং বাতিল",
+	"We hope to see you again soon":                 "আশা করি শীঘ্রই আবার দেখা হবে",
+	"This email confirms that your session has been cancelled. If you didn't request this cancellation, please contact our support team immediately.": "এই ইমেলটি নিশ্চিত করছে যে আপনার সেশনটি বাতিল করা হয়েছে। আপনি যদি এই বাতিলের অনুরোধ না করে থাকেন, অনুগ্রহ করে অবিলম্বে আমাদের সহায়তা দলের সাথে যোগাযোগ করুন।",
+	"Cancelled Session": "বাতিল সেশন",
+	"Ready to rebook?":  "আবার বুক করতে প্রস্তুত?",
+	"Life happens, and we understand. When you're ready to continue your journey, we'll be here. Book a new session anytime that works for you.": "জীবনে এমন হয়, আমরা বুঝি। আপনি যখন আবার আপনার যাত্রা শুরু করতে প্রস্তুত হবেন, আমরা এখানেই থাকব। আপনার সুবিধামতো যেকোনো সময় নতুন সেশন বুক করুন।",
+	"Book New Session": "নতুন সেশন বুক করুন",
+	"If you have any questions, don't hesitate to reach out.":                           "কোনো প্রশ্ন থাকলে নির্দ্বিধায় যোগাযোগ করুন।",
+	"You're receiving this because a booking associated with your email was cancelled.": "আপনার ইমেলের সাথে যুক্ত একটি বুকিং বাতিল হওয়ায় আপনি এই ইমেলটি পাচ্ছেন।",
+
+	// Unsubscribe page
+	"Link not valid": "লিঙ্কটি বৈধ নয়",
+	"This unsubscribe link is invalid or has been altered.":                                      "এই আনসাবস্ক্রাইব লিঙ্কটি অবৈধ বা পরিবর্তিত হয়েছে।",
+	"Unsubscribe from reminders?":                                                                "রিমাইন্ডার বন্ধ করবেন?",
+	"You will stop receiving session reminder emails. Booking confirmations will still be sent.": "আপনি আর সেশন রিমাইন্ডার ইমেল পাবেন না। বুকিং নিশ্চিতকরণ এখনও পাঠানো হবে।",
+	"Unsubscribe":         "আনসাবস্ক্রাইব",
+	"You're unsubscribed": "আপনি আনসাবস্ক্রাইব করেছেন",
+	"You will no longer receive session reminder emails.":        "আপনি আর সেশন রিমাইন্ডার ইমেল পাবেন না।",
+	"Something went wrong":                                       "কিছু একটা ভুল হয়েছে",
+	"We could not process your request. Please try again later.": "আমরা আপনার অনুরোধটি প্রক্রিয়া করতে পারিনি। অনুগ্রহ করে পরে আবার চেষ্টা করুন।",
+
+	// Crisis support
+	"If you are thinking about harming yourself or are in danger, please reach out to one of these services now. You don't have to go through this alone.": "আপনি যদি নিজের ক্ষতি করার কথা ভাবেন বা বিপদে থাকেন, তাহলে এখনই এই পরিষেবাগুলির কোনো একটির সাথে যোগাযোগ করুন। আপনাকে এটা একা সামলাতে হবে না।",
+}
@@ -0,0 +1,194 @@
+package i18n
+
+// hindi is the Hindi catalog, keyed by English message ID.
+var hindi = map[string]string{
+	// API errors
+	"This slot is no longer available":                                              "यह स्लॉट अब उपलब्ध नहीं है",
+	"Another user is completing payment for this slot. Try again shortly.":          "कोई अन्य उपयोगकर्ता इस स्लॉट के लिए भुगतान पूरा कर रहा है। थोड़ी देर में फिर कोशिश करें।",
+	"Booking does not exist":                                                        "बुकिंग मौजूद नहीं है",
+	"You are not authorized to modify this booking":                                 "आपको इस बुकिंग में बदलाव करने की अनुमति नहीं है",
+	"You have too many unpaid bookings in progress. Complete or release one first.": "आपकी बहुत सारी बिना भुगतान वाली बुकिंग प्रक्रिया में हैं। पहले किसी एक को पूरा करें या छोड़ें।",
+	"You already have %d upcoming sessions booked":                                  "आपके पहले से %d आगामी सत्र बुक हैं",
+	"Too many unfinished bookings. Please try again later.":                         "बहुत सारी अधूरी बुकिंग। कृपया बाद में फिर से प्रयास करें।",
+	"CAPTCHA verification failed. Please try again.":                                "CAPTCHA सत्यापन विफल रहा। कृपया फिर से प्रयास करें।",
+	"Payment declined: %s":                                                          "भुगतान अस्वीकृत: %s",
+	"booking is not pending":                                                        "बुकिंग लंबित नहीं है",
+	"booking is no longer pending":                                                  "बुकिंग अब लंबित नहीं है",
+	"Invalid payment signature":                                                     "अमान्य भुगतान हस्ताक्षर",
+	"Payment gateway timed out. Please try again.":                                  "भुगतान गेटवे का समय समाप्त हो गया। कृपया फिर से प्रयास करें।",
+	"Payment service temporarily unavailable":                                       "भुगतान सेवा अस्थायी रूप से उपलब्ध नहीं है",
+	"Invalid request payload":                                                       "अमान्य अनुरोध डेटा",
+	"Authorization header required":                                                 "प्राधिकरण हेडर आवश्यक है",
+	"Invalid authorization header format":                                           "प्राधिकरण हेडर का प्रारूप अमान्य है",
+	"Invalid or expired token":                                                      "अमान्य या समाप्त टोकन",
+	"Invalid or expired API key":                                                    "अमान्य या समाप्त API कुंजी",
+	"Session has been revoked. Please sign in again.":                               "सत्र रद्द कर दिया गया है। कृपया फिर से साइन इन करें।",
+	"This account has been suspended. Please contact support.":                      "यह खाता निलंबित कर दिया गया है। कृपया सहायता से संपर्क करें।",
+	"Admin access only":                                                             "केवल व्यवस्थापक के लिए",
+	"You do not have permission to perform this action":                             "आपको यह कार्य करने की अनुमति नहीं है",
+	"%s not found": "%s नहीं मिला",
+	"Cancel your upcoming sessions before deleting your account": "अपना खाता हटाने से पहले अपने आगामी सत्र रद्द करें",
+	"Recipient is on the email suppression list":                 "प्राप्तकर्ता ईमेल रोक सूची में है",
+	"Database operation failed: %s":                              "डेटाबेस कार्य विफल: %s",
+	"%s is temporarily unavailable":                              "%s अस्थायी रूप से उपलब्ध नहीं है",
+	"Too many requests. Please slow down.":                       "बहुत अधिक अनुरोध। कृपया थोड़ा रुकें।",
+	"An unexpected error occurred":                               "एक अप्रत्याशित त्रुटि हुई",
+
+	// Validation
+	"Date is required":                                         "तारीख आवश्यक है",
+	"Invalid date format. Use YYYY-MM-DD.":                     "तारीख का प्रारूप अमान्य है। YYYY-MM-DD का उपयोग करें।",
+	"Invalid date format":                                      "तारीख का प्रारूप अमान्य है",
+	"Invalid date":                                             "अमान्य तारीख",
+	"Cannot book dates in the past":                            "पिछली तारीखों के लिए बुकिंग नहीं की जा सकती",
+	"Cannot book more than 3 months in advance":                "3 महीने से अधिक पहले बुकिंग नहीं की जा सकती",
+	"Time is required":                                         "समय आवश्यक है",
+	"Invalid time format. Use format like '12:00 PM'":          "समय का प्रारूप अमान्य है। '12:00 PM' जैसा प्रारूप उपयोग करें",
+	"Name is required":                                         "नाम आवश्यक है",
+	"Name must be at least 2 characters":                       "नाम कम से कम 2 अक्षरों का होना चाहिए",
+	"Name must be less than 100 characters":                    "नाम 100 अक्षरों से कम होना चाहिए",
+	"Name contains invalid characters":                         "नाम में अमान्य अक्षर हैं",
+	"Email is required":                                        "ईमेल आवश्यक है",
+	"Email is too long":                                        "ईमेल बहुत लंबा है",
+	"Invalid email format":                                     "ईमेल का प्रारूप अमान्य है",
+	"Email address is required":                                "ईमेल पता आवश्यक है",
+	"This date is not available for booking":                   "यह तारीख बुकिंग के लिए उपलब्ध नहीं है",
+	"This time slot is not available for booking":              "यह समय स्लॉट बुकिंग के लिए उपलब्ध नहीं है",
+	"No booking dates are currently available":                 "इस समय बुकिंग के लिए कोई तारीख उपलब्ध नहीं है",
+	"Bookings are currently limited to: %s":                    "बुकिंग अभी केवल इन तारीखों तक सीमित है: %s",
+	"Set confirm to true to delete your account":               "खाता हटाने की पुष्टि करने के लिए confirm को true पर सेट करें",
+	"Booking ID is required":                                   "बुकिंग आईडी आवश्यक है",
+	"Coupon code is required":                                  "कूपन कोड आवश्यक है",
+	"Invalid coupon code":                                      "अमान्य कूपन कोड",
+	"This coupon is no longer active":                          "यह कूपन अब सक्रिय नहीं है",
+	"This coupon has reached its usage limit":                  "यह कूपन अपनी उपयोग सीमा तक पहुँच गया है",
+	"This coupon has expired":                                  "इस कूपन की अवधि समाप्त हो गई है",
+	"Order ID does not match booking":                          "ऑर्डर आईडी बुकिंग से मेल नहीं खाती",
+	"Booking is no longer pending":                             "बुकिंग अब लंबित नहीं है",
+	"Booking is already confirmed and cannot be auto-released": "बुकिंग पहले ही पक्की हो चुकी है और अपने आप रद्द नहीं की जा सकती",
+	"All payment fields are required":                          "भुगतान के सभी फ़ील्ड आवश्यक हैं",
+	"Only confirmed bookings can be cancelled here":            "यहाँ केवल पक्की बुकिंग ही रद्द की जा सकती हैं",
+	"Unsupported locale":                                       "असमर्थित भाषा",
+	"Invalid user ID":                                          "अमान्य उपयोगकर्ता आईडी",
+	"Unknown role":                                             "अज्ञात भूमिका",
+	"Cannot remove the last owner":                             "अंतिम स्वामी को हटाया नहीं जा सकता",
+	"Booking ID must be a UUID":                                "बुकिंग आईडी एक UUID होनी चाहिए",
+	"The session has already started":                          "सत्र पहले ही शुरू हो चुका है",
+	"This session is not open to join yet":                     "इस सत्र में अभी शामिल नहीं हुआ जा सकता",
+	"This session has ended":                                   "यह सत्र समाप्त हो चुका है",
+	"Rating must be between 1 and 5":                           "रेटिंग 1 से 5 के बीच होनी चाहिए",
+	"Feedback must be between %d and %d characters":            "प्रतिक्रिया %d से %d अक्षरों के बीच होनी चाहिए",
+	"Feedback opens once the session has ended":                "सत्र समाप्त होने के बाद ही प्रतिक्रिया दी जा सकती है",
+	"Feedback is only open for sessions that took place":       "प्रतिक्रिया केवल हुए सत्रों के लिए दी जा सकती है",
+	"Feedback has already been submitted for this session":     "इस सत्र के लिए प्रतिक्रिया पहले ही दी जा चुकी है",
+	"Invalid phone number":                                     "अमान्य फ़ोन नंबर",
+	"Unknown timezone":                                         "अज्ञात समय क्षेत्र",
+	"No profile fields to update":                              "अपडेट करने के लिए कोई प्रोफ़ाइल फ़ील्ड नहीं",
+	"Complete the intake form before joining your session":     "सत्र में शामिल होने से पहले इनटेक फ़ॉर्म भरें",
+	"Intake answers can no longer be changed":                  "इनटेक उत्तर अब बदले नहीं जा सकते",
+	"No intake form is needed for this session":                "इस सत्र के लिए इनटेक फ़ॉर्म की आवश्यकता नहीं है",
+	"Unknown question: %s":                                     "अज्ञात प्रश्न: %s",
+	"This question is required":                                "यह प्रश्न आवश्यक है",
+	"Answer must be text":                                      "उत्तर पाठ होना चाहिए",
+	"Answer must be at most %d characters":                     "उत्तर अधिकतम %d अक्षरों का होना चाहिए",
+	"Choose one of the listed options":                         "दिए गए विकल्पों में से चुनें",
+	"Answer must be a whole number from %d to %d":              "उत्तर %d से %d तक की पूर्ण संख्या होना चाहिए",
+	"Answer must be yes or no":                                 "उत्तर हाँ या नहीं होना चाहिए",
+
+	// Pagination
+	"limit must be a positive integer":                             "limit एक धनात्मक पूर्णांक होना चाहिए",
+	"sort must be one of: %s":                                      "sort इनमें से एक होना चाहिए: %s",
+	"Invalid cursor":                                               "अमान्य cursor",
+	"The cursor belongs to a different sort":                       "यह cursor किसी दूसरे sort का है",
+	"Unknown field: %s":                                            "अज्ञात फ़ील्ड: %s",
+	"page is not supported; follow pagination.next_cursor instead": "page समर्थित नहीं है; इसके बजाय pagination.next_cursor का उपयोग करें",
+
+	// API success messages
+	"Booking successful":                     "बुकिंग सफल रही",
+	"Payment initiated":                      "भुगतान शुरू हो गया",
+	"Payment already initiated":              "भुगतान पहले ही शुरू हो चुका है",
+	"Payment verified and booking confirmed": "भुगतान सत्यापित और बुकिंग पक्की हो गई",
+	"Payment already verified":               "भुगतान पहले ही सत्यापित हो चुका है",
+	"Booking cancelled successfully":         "बुकिंग सफलतापूर्वक रद्द कर दी गई",
+	"Pending booking released":               "लंबित बुकिंग छोड़ दी गई",
+	"Checkout recorded":                      "चेकआउट दर्ज किया गया",
+	"Pending booking already released":       "लंबित बुकिंग पहले ही छोड़ी जा चुकी है",
+	"Slots fetched successfully":             "स्लॉट सफलतापूर्वक प्राप्त हुए",
+	"User bookings fetched":                  "आपकी बुकिंग प्राप्त हुईं",
+	"Booking status fetched":                 "बुकिंग की स्थिति प्राप्त हुई",
+	"Coupon is valid":                        "कूपन मान्य है",
+	"Active subscription found":              "सक्रिय सदस्यता मिली",
+	"No active subscription found":           "कोई सक्रिय सदस्यता नहीं मिली",
+	"Account deleted":                        "खाता हटा दिया गया",
+	"Language preference updated":            "भाषा की पसंद अपडेट की गई",
+	"Role granted":                           "भूमिका दी गई",
+	"Role revoked":                           "भूमिका वापस ली गई",
+	"Session joined":                         "सत्र में शामिल हुए",
+	"Thank you for your feedback":            "आपकी प्रतिक्रिया के लिए धन्यवाद",
+	"Testimonials fetched successfully":      "प्रशंसापत्र सफलतापूर्वक प्राप्त हुए",
+	"Profile fetched":                        "प्रोफ़ाइल प्राप्त हुई",
+	"Profile updated":                        "प्रोफ़ाइल अपडेट की गई",
+	"Intake form fetched":                    "इनटेक फ़ॉर्म प्राप्त हुआ",
+	"Intake form submitted":                  "इनटेक फ़ॉर्म जमा हो गया",
+
+	// Email subjects
+	"✨ Booking Confirmed - Your Journey Begins": "✨ बुकिंग पक्की - आपकी यात्रा शुरू होती है",
+	"⏰ Reminder: Your Session is Tomorrow":      "⏰ अनुस्मारक: आपका सत्र कल है",
+	"Booking Cancelled - Hidden Depths":         "बुकिंग रद्द - Hidden Depths",
+
+	// Email bodies
+	"Hello":             "नमस्ते",
+	"Welcome,":          "स्वागत है,",
+	"Date":              "तारीख",
+	"Time":              "समय",
+	"Session Details":   "सत्र का विवरण",
+	"Join Your Session": "अपने सत्र से जुड़ें",
+	"Manage Bookings":   "बुकिंग प्रबंधित करें",
+	"My Profile":        "मेरी प्रोफ़ाइल",
+	"Contact Support":   "सहायता से संपर्क करें",
+	"© %d Hidden Depths. All rights reserved.": "© %d Hidden Depths. सर्वाधिकार सुरक्षित।",
+
+	"Your session with Hidden Depths is confirmed for %s at %s.": "Hidden Depths के साथ आपका सत्र %s, %s के लिए पक्का हो गया है।",
+	"Booking Confirmed":        "बुकिंग पक्की हो गई",
+	"Your journey begins soon": "आपकी यात्रा जल्द शुरू होगी",
+	"Your sanctuary session has been confirmed. We look forward to guiding you on your path to inner peace and clarity.": "आपका सत्र पक्का हो गया है। हम आंतरिक शांति और स्पष्टता की ओर आपकी राह में आपका मार्गदर्शन करने के लिए उत्सुक हैं।",
+	"Save this link for easy access on the day of your session.":                                                         "सत्र के दिन आसानी से जुड़ने के लिए यह लिंक सहेज लें।",
+	"You're receiving this because you booked a session with Hidden Depths.":                                             "आपको यह ईमेल इसलिए मिल रहा है क्योंकि आपने Hidden Depths के साथ एक सत्र बुक किया है।",
+
+	"Reminder: Your session with Hidden Depths is tomorrow at %s.": "याद दिलाना: Hidden Depths के साथ आपका सत्र कल %s पर है।",
+	"Session Reminder":         "सत्र अनुस्मारक",
+	"Your session is tomorrow": "आपका सत्र कल है",
+	"This is a friendly reminder that your sanctuary session is scheduled for tomorrow. We're looking forward to connecting with you.": "यह एक याद दिलाने वाला संदेश है कि आपका सत्र कल निर्धारित है। हम आपसे जुड़ने के लिए उत्सुक हैं।",
+	"💡 Preparation Tips":                                                   "💡 तैयारी के सुझाव",
+	"Find a quiet, comfortable space":                                      "एक शांत, आरामदायक जगह चुनें",
+	"Test your camera and microphone beforehand":                           "पहले से अपना कैमरा और माइक्रोफ़ोन जाँच लें",
+	"Have water nearby and minimize distractions":                          "पास में पानी रखें और ध्यान भटकाने वाली चीज़ें कम करें",
+	"Join a few minutes early to settle in":                                "सहज होने के लिए कुछ मिनट पहले जुड़ें",
+	"Need to reschedule? Visit your profile to manage your bookings.":      "समय बदलना है? अपनी बुकिंग प्रबंधित करने के लिए अपनी प्रोफ़ाइल पर जाएँ।",
+	"You're receiving this reminder because you have an upcoming session.": "आपको यह अनुस्मारक इसलिए मिल रहा है क्योंकि आपका एक आगामी सत्र है।",
+	"Unsubscribe from session reminders":                                   "सत्र अनुस्मारकों की सदस्यता छोड़ें",
+
+	"Your session for %s at %s has been cancelled.": "%s, %s का आपका सत्र रद्द कर दिया गया है।",
+	"Booking Cancelled":                             "बुकिंग रद्द",
+	"We hope to see you again soon":                 "हमें उम्मीद है कि आप जल्द ही फिर मिलेंगे",
+	"This email confirms that your session has been cancelled. If you didn't request this cancellation, please contact our support team immediately.": "यह ईमेल पुष्टि करता है कि आपका सत्र रद्द कर दिया गया है। यदि आपने यह रद्दीकरण नहीं किया है, तो कृपया तुरंत हमारी सहायता टीम से संपर्क करें।",
+	"Cancelled Session": "रद्द किया गया सत्र",
+	"Ready to rebook?":  "फिर से बुक करने के लिए तैयार हैं?",
+	"Life happens, and we understand. When you're ready to continue your journey, we'll be here. Book a new session anytime that works for you.": "ज़िंदगी में ऐसा होता है, और हम समझते हैं। जब आप अपनी यात्रा जारी रखने के लिए तैयार हों, हम यहीं होंगे। जब भी आपके लिए सुविधाजनक हो, नया सत्र बुक करें।",
+	"Book New Session": "नया सत्र बुक करें",
+	"If you have any questions, don't hesitate to reach out.":                           "यदि आपके कोई प्रश्न हैं, तो बेझिझक संपर्क करें।",
+	"You're receiving this because a booking associated with your email was cancelled.": "आपको यह ईमेल इसलिए मिल रहा है क्योंकि आपके ईमेल से जुड़ी एक बुकिंग रद्द की गई है।",
+
+	// Unsubscribe page
+	"Link not valid": "लिंक मान्य नहीं है",
+	"This unsubscribe link is invalid or has been altered.":                                      "यह सदस्यता छोड़ने का लिंक अमान्य है या बदल दिया गया है।",
+	"Unsubscribe from reminders?":                                                                "अनुस्मारकों की सदस्यता छोड़ें?",
+	"You will stop receiving session reminder emails. Booking confirmations will still be sent.": "आपको सत्र अनुस्मारक ईमेल मिलना बंद हो जाएगा। बुकिंग की पुष्टि अब भी भेजी जाएगी।",
+	"Unsubscribe":         "सदस्यता छोड़ें",
+	"You're unsubscribed": "आपने सदस्यता छोड़ दी है",
+	"You will no longer receive session reminder emails.":        "अब आपको सत्र अनुस्मारक ईमेल नहीं मिलेंगे।",
+	"Something went wrong":                                       "कुछ गलत हो गया",
+	"We could not process your request. Please try again later.": "हम आपके अनुरोध को संसाधित नहीं कर सके। कृपया बाद में फिर प्रयास करें।",
+
+	// Crisis support
+	"If you are thinking about harming yourself or are in danger, please reach out to one of these services now. You don't have to go through this alone.": "अगर आप खुद को नुकसान पहुँचाने के बारे में सोच रहे हैं या खतरे में हैं, तो कृपया अभी इनमें से किसी सेवा से संपर्क करें। आपको यह अकेले नहीं सहना है।",
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type calendarNames struct {
+	weekdays [7]string // Sunday first, matching time.Weekday
+	months   [12]string
+	am, pm   string
+	digits   *strings.Replacer // nil keeps ASCII digits
+}
+
+var calendars = map[string]calendarNames{
+	Hindi: {
+		weekdays: [7]string{"रविवार", "सोमवार", "मंगलवार", "बुधवार", "गुरुवार", "शुक्रवार", "शनिवार"},
+		months:   [12]string{"जनवरी", "फ़रवरी", "मार्च", "अप्रैल", "मई", "जून", "जुलाई", "अगस्त", "सितंबर", "अक्टूबर", "नवंबर", "दिसंबर"},
+		am:       "पूर्वाह्न",
+		pm:       "अपराह्न",
+	},
+	Bengali: {
+		weekdays: [7]string{"রবিবার", "সোমবার", "মঙ্গলবার", "বুধবার", "বৃহস্পতিবার", "শুক্রবার", "শনিবার"},
+		months:   [12]string{"জানুয়ারি", "ফেব্রুয়ারি", "মার্চ", "এপ্রিল", "মে", "জুন", "জুলাই", "আগস্ট", "সেপ্টেম্বর", "অক্টোবর", "নভেম্বর", "ডিসেম্বর"},
+		am:       "পূর্বাহ্ণ",
+		pm:       "অপরাহ্ণ",
+		digits: strings.NewReplacer(
+			"0", "০", "1", "১", "2", "২", "3", "৩", "4", "৪",
+			"5", "৫", "6", "৬", "7", "৭", "8", "৮", "9", "৯",
+		),
+	},
+}
+
+// FormatDate renders a calendar date for display, e.g. "Sunday, April 19, 2026"
+// in English or "रविवार, 19 अप्रैल 2026" in Hindi.
+func FormatDate(locale string, date time.Time) string {
+	names, ok := calendars[locale]
+	if !ok {
+		return date.Format("Monday, January 2, 2006")
+	}
+	out := names.weekdays[date.Weekday()] + ", " +
+		strconv.Itoa(date.Day()) + " " + names.months[date.Month()-1] + " " + strconv.Itoa(date.Year())
+	return names.localizeDigits(out)
+}
+
+// FormatDateString formats a YYYY-MM-DD date, returning the input unchanged if
+// it does not parse.
+func FormatDateString(locale, date string) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return FormatDate(locale, parsed)
+}
+
+// FormatTimeSlot localizes a booking slot such as "08:00 PM", returning the
+// input unchanged if it does not parse.
+func FormatTimeSlot(locale, slot string) string {
+	names, ok := calendars[locale]
+	if !ok {
+		return slot
+	}
+	parsed, err := time.Parse("3:04 PM", strings.TrimSpace(slot))
+	if err != nil {
+		return slot
+	}
+	meridiem := names.am
+	if parsed.Hour() >= 12 {
+		meridiem = names.pm
+	}
+	return names.localizeDigits(parsed.Format("3:04") + " " + meridiem)
+}
+
+func (c calendarNames) localizeDigits(s string) string {
+	if c.digits == nil {
+		return s
+	}
+	return c.digits.Replace(s)
+}
@@ -0,0 +1,116 @@
+// Package i18n holds the message catalog and locale negotiation for user-facing
+// text. Messages are keyed by their English source string (gettext style), so
+// untranslated strings fall back to English and error codes stay stable.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported locales.
+const (
+	English = "en"
+	Hindi   = "hi"
+	Bengali = "bn"
+
+	Default = English
+)
+
+// catalogs maps a locale to its translations, keyed by English message ID.
+var catalogs = map[string]map[string]string{
+	Hindi:   hindi,
+	Bengali: bengali,
+}
+
+type contextKey struct{}
+
+// Supported returns the locales with a catalog, default first.
+func Supported() []string {
+	return []string{English, Hindi, Bengali}
+}
+
+// IsSupported reports whether locale has a catalog.
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok || locale == English
+}
+
+// Normalize maps a language tag such as "hi-IN" to a supported locale.
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	if IsSupported(primary) {
+		return primary, true
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// honouring q-values. It returns Default when nothing matches.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return Default
+		}
+		if locale, ok := Normalize(c.tag); ok {
+			return locale
+		}
+	}
+	return Default
+}
+
+// T translates msgID into locale and formats it with args. Missing
+// translations fall back to the English message ID.
+func T(locale, msgID string, args ...any) string {
+	msg := msgID
+	if translated, ok := catalogs[locale][msgID]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// WithLocale returns a context carrying locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the negotiated locale, or Default if none was set.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return Default
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"hi-IN", Hindi},
+		{"bn-BD,bn;q=0.9,en;q=0.8", Bengali},
+		{"fr-FR,hi;q=0.5,en;q=0.7", English},
+		{"fr-FR,de;q=0.9", English},
+		{"en;q=0,hi;q=0.1", Hindi},
+		{"*", English},
+		{"hi;q=bogus,bn;q=0.2", Bengali},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Fatalf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{"HI", Hindi, true},
+		{"bn_IN", Bengali, true},
+		{" en-GB ", English, true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Normalize(tt.tag)
+		if got != tt.want || ok != tt.wantOK {
+			t.Fatalf("Normalize(%q) = %q, %v; want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		locale string
+		msgID  string
+		args   []any
+		want   string
+	}{
+		{English, "Booking successful", nil, "Booking successful"},
+		{Hindi, "Booking successful", nil, "बुकिंग सफल रही"},
+		{Bengali, "Untranslated message", nil, "Untranslated message"},
+		{"fr", "Booking successful", nil, "Booking successful"},
+		{Hindi, "%s not found", []any{"Template"}, "Template नहीं मिला"},
+		{English, "100% sure", nil, "100% sure"},
+	}
+
+	for _, tt := range tests {
+		if got := T(tt.locale, tt.msgID, tt.args...); got != tt.want {
+			t.Fatalf("T(%q, %q) = %q, want %q", tt.locale, tt.msgID, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateAndTime(t *testing.T) {
+	tests := []struct {
+		locale   string
+		date     string
+		slot     string
+		wantDate string
+		wantTime string
+	}{
+		{English, "2026-04-19", "3:30 PM", "Sunday, April 19, 2026", "3:30 PM"},
+		{Hindi, "2026-04-19", "3:30 PM", "रविवार, 19 अप्रैल 2026", "3:30 अपराह्न"},
+		{Bengali, "2026-04-19", "3:30 PM", "রবিবার, ১৯ এপ্রিল ২০২৬", "৩:৩০ অপরাহ্ণ"},
+		{Hindi, "not-a-date", "soon", "not-a-date", "soon"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDateString(tt.locale, tt.date); got != tt.wantDate {
+			t.Fatalf("FormatDateString(%q, %q) = %q, want %q", tt.locale, tt.date, got, tt.wantDate)
+		}
+		if got := FormatTimeSlot(tt.locale, tt.slot); got != tt.wantTime {
+			t.Fatalf("FormatTimeSlot(%q, %q) = %q, want %q", tt.locale, tt.slot, got, tt.wantTime)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package pagination implements keyset (cursor) pagination, sorting and
+// field selection for list endpoints.
+//
+// Each endpoint describes what it allows in a Spec. Parse reads the limit,
+// sort, cursor and fields query parameters against it, and the resulting
+// Params supply the SQL to fetch one page:
+//
+//	SELECT <columns>, <p.Select()> FROM t
+//	WHERE <filters> AND <p.Where(n)>
+//	ORDER BY <p.OrderBy()> LIMIT <p.Fetch()>
+//
+// The two Select columns give each row's Position. NewPage trims the extra
+// row and turns the last position into the opaque next_cursor, and Respond
+// writes the page with a Link rel="next" header.
+//
+// Cursors hold the sort and the last row's sort value and key, so a page
+// boundary stays put when rows are inserted or deleted before it.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/response"
+	"github.com/google/uuid"
+)
+
+// Column types, used to validate cursor values and cast them back in SQL.
+const (
+	TypeText    = "text"
+	TypeInt     = "bigint"
+	TypeNumeric = "numeric"
+	TypeUUID    = "uuid"
+	TypeDate    = "date"
+	TypeTime    = "timestamptz"
+)
+
+// Column is a field a list can be sorted on.
+type Column struct {
+	Expr string // SQL expression; must never be NULL
+	Type string // one of the Type constants
+}
+
+// Spec describes what one list endpoint allows.
+type Spec struct {
+	Sorts        map[string]Column // sortable fields by name
+	DefaultSort  string            // field name, prefixed with "-" for descending
+	Key          Column            // unique tiebreaker, e.g. the primary key
+	DefaultLimit int
+	MaxLimit     int
+	Fields       []string // JSON fields fields= may select; empty disables selection
+}
+
+// First returns the first page with the default sort and limit items, for
+// callers that do not parse a request.
+func (s Spec) First(limit int) Params {
+	name, desc := splitSort(s.DefaultSort)
+	return Params{Limit: limit, Sort: name, Desc: desc, spec: s}
+}
+
+// Params are the pagination parameters of one request.
+type Params struct {
+	Limit  int
+	Sort   string // field name
+	Desc   bool
+	Fields []string // selected JSON fields; nil for all
+	after  *Position
+	spec   Spec
+}
+
+// Position is a row's place in a sorted list: its sort value and key, as
+// returned by the Params.Select columns.
+type Position struct {
+	Value string
+	Key   string
+}
+
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Key   string `json:"k"`
+}
+
+// Parse reads limit (or per_page), sort, cursor and fields from q. A cursor
+// carries its sort, so sort may be left out when following one. page is
+// rejected unless it is 1, since lists are no longer paged by offset.
+func Parse(q url.Values, spec Spec) (Params, *apperror.AppError) {
+	p := Params{Limit: spec.DefaultLimit, spec: spec}
+
+	if raw := strings.TrimSpace(q.Get("page")); raw != "" && raw != "1" {
+		return p, apperror.ValidationError("page", "page is not supported; follow pagination.next_cursor instead")
+	}
+
+	field, raw := "limit", strings.TrimSpace(q.Get("limit"))
+	if raw == "" {
+		field, raw = "per_page", strings.TrimSpace(q.Get("per_page"))
+	}
+	if raw != "" {
+		val, err := strconv.Atoi(raw)
+		if err != nil || val < 1 {
+			return p, apperror.ValidationError(field, "limit must be a positive integer")
+		}
+		p.Limit = min(val, spec.MaxLimit)
+	}
+
+	sortParam := strings.TrimSpace(q.Get("sort"))
+	var c *cursor
+	if raw := strings.TrimSpace(q.Get("cursor")); raw != "" {
+		var ok bool
+		if c, ok = decodeCursor(raw); !ok {
+			return p, apperror.ValidationError("cursor", "Invalid cursor")
+		}
+		if sortParam == "" {
+			sortParam = c.Sort
+		} else if sortParam != c.Sort {
+			return p, apperror.ValidationError("cursor", "The cursor belongs to a different sort")
+		}
+	}
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	p.Sort, p.Desc = splitSort(sortParam)
+	column, ok := spec.Sorts[p.Sort]
+	if !ok {
+		return p, apperror.ValidationErrorf("sort", "sort must be one of: %s", strings.Join(sortNames(spec), ", "))
+	}
+
+	if c != nil {
+		if !validValue(column.Type, c.Value) || !validValue(spec.Key.Type, c.Key) {
+			return p, apperror.ValidationError("cursor", "Invalid cursor")
+		}
+		p.after = &Position{Value: c.Value, Key: c.Key}
+	}
+
+	if raw := strings.TrimSpace(q.Get("fields")); raw != "" {
+		allowed := make(map[string]bool, len(spec.Fields))
+		for _, f := range spec.Fields {
+			allowed[f] = true
+		}
+		for _, f := range strings.Split(raw, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if !allowed[f] {
+				return p, apperror.ValidationErrorf("fields", "Unknown field: %s", f)
+			}
+			p.Fields = append(p.Fields, f)
+		}
+	}
+	return p, nil
+}
+
+// SortParam is the sort in query form, e.g. "-created_at".
+func (p Params) SortParam() string {
+	if p.Desc {
+		return "-" + p.Sort
+	}
+	return p.Sort
+}
+
+// Where returns the condition selecting rows after the cursor, with its
+// placeholders numbered from next, and its arguments. Without a cursor it is
+// "TRUE".
+func (p Params) Where(next int) (string, []any) {
+	if p.after == nil {
+		return "TRUE", nil
+	}
+	column := p.spec.Sorts[p.Sort]
+	op := ">"
+	if p.Desc {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::%s)",
+		column.Expr, p.spec.Key.Expr, op, next, column.Type, next+1, p.spec.Key.Type,
+	), []any{p.after.Value, p.after.Key}
+}
+
+// OrderBy is the ORDER BY list for the sort, with the key as tiebreaker.
+func (p Params) OrderBy() string {
+	dir := "ASC"
+	if p.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", p.spec.Sorts[p.Sort].Expr, dir, p.spec.Key.Expr, dir)
+}
+
+// Select is the pair of columns to scan into a row's Position.
+func (p Params) Select() string {
+	return cursorText(p.spec.Sorts[p.Sort]) + ", " + cursorText(p.spec.Key)
+}
+
+// Fetch is the LIMIT to query: one more than the page, to tell whether
+// another page follows.
+func (p Params) Fetch() int {
+	return p.Limit + 1
+}
+
+// CacheKey identifies the page p selects, for caching it. Field selection is
+// applied after caching and is not part of the key.
+func (p Params) CacheKey() string {
+	key := fmt.Sprintf("%s:%d", p.SortParam(), p.Limit)
+	if p.after != nil {
+		key += ":" + encodeCursor(cursor{Value: p.after.Value, Key: p.after.Key})
+	}
+	return key
+}
+
+// cursorText renders a column as text that its type parses back exactly.
+func cursorText(c Column) string {
+	switch c.Type {
+	case TypeTime:
+		return fmt.Sprintf(`to_char((%s) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')`, c.Expr)
+	case TypeDate:
+		return fmt.Sprintf(`to_char(%s, 'YYYY-MM-DD')`, c.Expr)
+	default:
+		return fmt.Sprintf("(%s)::text", c.Expr)
+	}
+}
+
+// validValue reports whether value is a valid cursor value of type typ, so a
+// tampered cursor is a validation error rather than a failed query.
+func validValue(typ, value string) bool {
+	var err error
+	switch typ {
+	case TypeText:
+	case TypeInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case TypeNumeric:
+		_, err = strconv.ParseFloat(value, 64)
+	case TypeUUID:
+		_, err = uuid.Parse(value)
+	case TypeDate:
+		_, err = time.Parse("2006-01-02", value)
+	case TypeTime:
+		_, err = time.Parse(time.RFC3339Nano, value)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+func splitSort(sort string) (string, bool) {
+	if name, ok := strings.CutPrefix(sort, "-"); ok {
+		return name, true
+	}
+	return sort, false
+}
+
+func sortNames(spec Spec) []string {
+	names := make([]string, 0, len(spec.Sorts))
+	for name := range spec.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(raw string) (*cursor, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, false
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort == "" {
+		return nil, false
+	}
+	return &c, true
+}
+
+// Page is one page of a list.
+type Page[T any] struct {
+	Items      []T                 `json:"items"`
+	Pagination response.Pagination `json:"pagination"`
+}
+
+// NewPage trims items, fetched with p.Fetch(), to the page and builds the
+// next cursor from the last item's position. positions[i] belongs to
+// items[i].
+func NewPage[T any](p Params, items []T, positions []Position) Page[T] {
+	page := Page[T]{Items: items, Pagination: response.Pagination{Limit: p.Limit, Sort: p.SortParam()}}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > p.Limit && len(positions) >= p.Limit {
+		last := positions[p.Limit-1]
+		page.Items = items[:p.Limit]
+		page.Pagination.HasMore = true
+		page.Pagination.NextCursor = encodeCursor(cursor{Sort: p.SortParam(), Value: last.Value, Key: last.Key})
+	}
+	return page
+}
+
+// Respond writes a page: the selected fields of each item as data, the
+// pagination metadata in the envelope, and a Link header to the next page.
+func Respond[T any](w http.ResponseWriter, r *http.Request, p Params, page Page[T], message string) {
+	if page.Pagination.NextCursor != "" {
+		q := r.URL.Query()
+		q.Set("cursor", page.Pagination.NextCursor)
+		q.Del("page")
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, q.Encode()))
+	}
+
+	data, err := Select(page.Items, p.Fields)
+	if err != nil {
+		response.AppErr(w, apperror.InternalError(err))
+		return
+	}
+	response.JSONPage(w, http.StatusOK, data, page.Pagination, message)
+}
+
+// Select returns items with only the given JSON fields, or items unchanged
+// when fields is empty.
+func Select[T any](items []T, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &objects); err != nil {
+		return nil, err
+	}
+	selected := make([]map[string]json.RawMessage, len(objects))
+	for i, obj := range objects {
+		selected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := obj[f]; ok {
+				selected[i][f] = v
+			}
+		}
+	}
+	return selected, nil
+}
+
+// JSONFields lists the JSON field names of struct v, for Spec.Fields. The
+// fields of embedded structs are listed as their own, as encoding/json
+// flattens them.
+func JSONFields(v any) []string {
+	return jsonFields(reflect.TypeOf(v))
+}
+
+func jsonFields(t reflect.Type) []string {
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
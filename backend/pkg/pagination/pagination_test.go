@@ -0,0 +1,190 @@
+package pagination
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Created time.Time `json:"created_at"`
+	secret  string
+	Skipped string `json:"-"`
+}
+
+var testSpec = Spec{
+	Sorts: map[string]Column{
+		"created_at": {Expr: "created_at", Type: TypeTime},
+		"amount":     {Expr: "COALESCE(amount, 0)", Type: TypeNumeric},
+	},
+	DefaultSort:  "-created_at",
+	Key:          Column{Expr: "id", Type: TypeUUID},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+	Fields:       JSONFields(testItem{}),
+}
+
+const testKey = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+func testCursor(sort, value, key string) string {
+	return encodeCursor(cursor{Sort: sort, Value: value, Key: key})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantErr   string // field of the validation error, if any
+		wantLimit int
+		wantSort  string
+		wantAfter bool
+	}{
+		{"", "", 20, "-created_at", false},
+		{"limit=5&sort=amount", "", 5, "amount", false},
+		{"per_page=500", "", 100, "-created_at", false},
+		{"page=1&limit=10", "", 10, "-created_at", false},
+		{"page=2", "page", 0, "", false},
+		{"limit=0", "limit", 0, "", false},
+		{"per_page=ten", "per_page", 0, "", false},
+		{"sort=-email", "sort", 0, "", false},
+		{"cursor=" + testCursor("-created_at", "2026-10-01T09:30:00.123456Z", testKey), "", 20, "-created_at", true},
+		{"sort=amount&cursor=" + testCursor("amount", "1499.00", testKey), "", 20, "amount", true},
+		{"sort=amount&cursor=" + testCursor("-created_at", "2026-10-01T09:30:00Z", testKey), "cursor", 0, "", false},
+		{"cursor=" + testCursor("-created_at", "yesterday", testKey), "cursor", 0, "", false},
+		{"cursor=" + testCursor("-created_at", "2026-10-01T09:30:00Z", "1; DROP TABLE"), "cursor", 0, "", false},
+		{"cursor=" + testCursor("-email", "a", testKey), "sort", 0, "", false},
+		{"cursor=not-a-cursor!", "cursor", 0, "", false},
+		{"fields=id,created_at", "", 20, "-created_at", false},
+		{"fields=id,secret", "fields", 0, "", false},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		p, appErr := Parse(q, testSpec)
+		if tt.wantErr != "" {
+			if appErr == nil || appErr.Context["field"] != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, want a validation error on %q", tt.query, appErr, tt.wantErr)
+			}
+			continue
+		}
+		if appErr != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.query, appErr)
+		}
+		if p.Limit != tt.wantLimit || p.SortParam() != tt.wantSort || (p.after != nil) != tt.wantAfter {
+			t.Fatalf("Parse(%q) = limit %d, sort %q, after %v; want %d, %q, %v",
+				tt.query, p.Limit, p.SortParam(), p.after, tt.wantLimit, tt.wantSort, tt.wantAfter)
+		}
+	}
+}
+
+func TestParamsSQL(t *testing.T) {
+	p := testSpec.First(10)
+	if where, args := p.Where(3); where != "TRUE" || args != nil {
+		t.Fatalf("Where() without cursor = %q, %v", where, args)
+	}
+	if got, want := p.OrderBy(), "created_at DESC, id DESC"; got != want {
+		t.Fatalf("OrderBy() = %q, want %q", got, want)
+	}
+
+	q := url.Values{"sort": {"amount"}, "cursor": {testCursor("amount", "1499.00", testKey)}}
+	p, appErr := Parse(q, testSpec)
+	if appErr != nil {
+		t.Fatalf("Parse() error = %v", appErr)
+	}
+	where, args := p.Where(3)
+	if want := "(COALESCE(amount, 0), id) > ($3::numeric, $4::uuid)"; where != want {
+		t.Fatalf("Where() = %q, want %q", where, want)
+	}
+	if !reflect.DeepEqual(args, []any{"1499.00", testKey}) {
+		t.Fatalf("Where() args = %v", args)
+	}
+	if got, want := p.OrderBy(), "COALESCE(amount, 0) ASC, id ASC"; got != want {
+		t.Fatalf("OrderBy() = %q, want %q", got, want)
+	}
+	if got, want := p.Select(), "(COALESCE(amount, 0))::text, (id)::text"; got != want {
+		t.Fatalf("Select() = %q, want %q", got, want)
+	}
+	if p.Fetch() != 21 {
+		t.Fatalf("Fetch() = %d, want 21", p.Fetch())
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	p := testSpec.First(2)
+	positions := []Position{
+		{Value: "2026-10-03T00:00:00Z", Key: "k3"},
+		{Value: "2026-10-02T00:00:00Z", Key: "k2"},
+		{Value: "2026-10-01T00:00:00Z", Key: "k1"},
+	}
+
+	page := NewPage(p, []string{"c", "b", "a"}, positions)
+	if len(page.Items) != 2 || !page.Pagination.HasMore {
+		t.Fatalf("NewPage() = %+v, want 2 items and more", page)
+	}
+	c, ok := decodeCursor(page.Pagination.NextCursor)
+	if !ok || *c != (cursor{Sort: "-created_at", Value: "2026-10-02T00:00:00Z", Key: "k2"}) {
+		t.Fatalf("next cursor = %+v", c)
+	}
+
+	last := NewPage(p, []string{"a"}, positions[2:])
+	if len(last.Items) != 1 || last.Pagination.HasMore || last.Pagination.NextCursor != "" {
+		t.Fatalf("NewPage() on the last page = %+v", last)
+	}
+	if empty := NewPage[string](p, nil, nil); empty.Items == nil {
+		t.Fatal("NewPage() on no rows returned nil items, want an empty array")
+	}
+}
+
+func TestRespond(t *testing.T) {
+	q := url.Values{"fields": {"id,name"}, "limit": {"1"}, "status": {"paid"}}
+	p, appErr := Parse(q, testSpec)
+	if appErr != nil {
+		t.Fatalf("Parse() error = %v", appErr)
+	}
+	items := []testItem{{ID: "b2", Name: "Asha"}, {ID: "b1"}}
+	page := NewPage(p, items, []Position{{Value: "2026-10-02T00:00:00Z", Key: testKey}, {}})
+
+	r := httptest.NewRequest("GET", "/api/admin/bookings?"+q.Encode(), nil)
+	w := httptest.NewRecorder()
+	Respond(w, r, p, page, "")
+
+	link := w.Header().Get("Link")
+	if !strings.HasPrefix(link, "</api/admin/bookings?") || !strings.HasSuffix(link, `>; rel="next"`) ||
+		!strings.Contains(link, "cursor="+page.Pagination.NextCursor) || !strings.Contains(link, "status=paid") {
+		t.Fatalf("Link = %q", link)
+	}
+
+	var body struct {
+		Data       []map[string]any `json:"data"`
+		Pagination struct {
+			Limit      int    `json:"limit"`
+			Sort       string `json:"sort"`
+			NextCursor string `json:"next_cursor"`
+			HasMore    bool   `json:"has_more"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if len(body.Data) != 1 || !reflect.DeepEqual(body.Data[0], map[string]any{"id": "b2", "name": "Asha"}) {
+		t.Fatalf("data = %v, want only the selected fields of the first item", body.Data)
+	}
+	if body.Pagination.Limit != 1 || body.Pagination.Sort != "-created_at" || !body.Pagination.HasMore || body.Pagination.NextCursor == "" {
+		t.Fatalf("pagination = %+v", body.Pagination)
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	type embedded struct {
+		testItem
+		Count int `json:"count"`
+	}
+	want := []string{"id", "name", "created_at", "count"}
+	if got := JSONFields(embedded{}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("JSONFields() = %v, want %v", got, want)
+	}
+}
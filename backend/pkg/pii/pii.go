@@ -0,0 +1,193 @@
+// Package pii encrypts personal data stored in the database and derives blind
+// indexes that allow exact-match lookups on it without decrypting.
+//
+// Values are sealed with AES-256-GCM. The stored form is
+// "enc:<key id>:<base64 nonce+ciphertext>", so keys can be rotated: new values
+// use the first (current) key, and older keys stay available for decryption
+// until every row has been re-encrypted. Values without the "enc:" prefix are
+// treated as plaintext written before encryption was enabled.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix marks an encrypted value.
+const Prefix = "enc:"
+
+// KeySize is the length of encryption and blind index keys (AES-256).
+const KeySize = 32
+
+// Key is one encryption key and the ID recorded in values it sealed.
+type Key struct {
+	ID  string
+	Key []byte
+}
+
+// Keyring seals and opens values and computes blind indexes.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+	index   []byte
+}
+
+// New builds a keyring. keys[0] is the current key; the rest are only used to
+// decrypt. indexKey must differ from every encryption key and must not change
+// while indexed rows exist, since lookups recompute the index from it.
+func New(keys []Key, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("blind index key must be %d bytes", KeySize)
+	}
+
+	k := &Keyring{current: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys)), index: indexKey}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, ":,= ") {
+			return nil, fmt.Errorf("invalid key ID %q", key.ID)
+		}
+		if _, dup := k.aeads[key.ID]; dup {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		if len(key.Key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", key.ID, KeySize)
+		}
+		if hmac.Equal(key.Key, indexKey) {
+			return nil, fmt.Errorf("key %q is also the blind index key", key.ID)
+		}
+		block, err := aes.NewCipher(key.Key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[key.ID] = aead
+	}
+	return k, nil
+}
+
+// CurrentPrefix is the prefix of values sealed with the current key.
+func (k *Keyring) CurrentPrefix() string {
+	return Prefix + k.current + ":"
+}
+
+// Encrypt seals plaintext with the current key. field binds the ciphertext to
+// its column, so a value copied into another column fails to decrypt.
+func (k *Keyring) Encrypt(field, plaintext string) (string, error) {
+	aead := k.aeads[k.current]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return k.CurrentPrefix() + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Values without Prefix are
+// returned unchanged.
+func (k *Keyring) Decrypt(field, value string) (string, error) {
+	if !strings.HasPrefix(value, Prefix) {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(value[len(Prefix):], ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key %q", id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups. Callers
+// normalise value first (e.g. lower-case an email) so equal values match.
+func (k *Keyring) BlindIndex(field, value string) string {
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseKeys parses "id=base64key,..." as used by PII_ENCRYPTION_KEYS, keeping
+// the order so the first key is current.
+func ParseKeys(raw string) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected id=base64key", entry)
+		}
+		key, err := DecodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.TrimSpace(id), err)
+		}
+		keys = append(keys, Key{ID: strings.TrimSpace(id), Key: key})
+	}
+	return keys, nil
+}
+
+// DecodeKey decodes a standard base64 key and checks its length.
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("key is not valid base64")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// defaultKeyring is set by Init; nil means encryption is disabled.
+var defaultKeyring *Keyring
+
+// Init enables encryption with keys and indexKey. With no keys it leaves
+// encryption disabled and values are stored as plaintext.
+func Init(keys []Key, indexKey []byte) error {
+	if len(keys) == 0 {
+		defaultKeyring = nil
+		return nil
+	}
+	k, err := New(keys, indexKey)
+	if err != nil {
+		return err
+	}
+	defaultKeyring = k
+	return nil
+}
+
+// Enabled reports whether Init configured a keyring.
+func Enabled() bool {
+	return defaultKeyring != nil
+}
+
+// Default returns the keyring configured by Init, or nil.
+func Default() *Keyring {
+	return defaultKeyring
+}
@@ -0,0 +1,81 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestKeyringRoundTripAndRotation(t *testing.T) {
+	old, err := New([]Key{{ID: "1", Key: testKey(1)}}, testKey(9))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sealed, err := old.Encrypt("bookings.email", "a@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(sealed, "enc:1:") || strings.Contains(sealed, "example") {
+		t.Fatalf("unexpected sealed value %q", sealed)
+	}
+
+	rotated, err := New([]Key{{ID: "2", Key: testKey(2)}, {ID: "1", Key: testKey(1)}}, testKey(9))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got, err := rotated.Decrypt("bookings.email", sealed); err != nil || got != "a@example.com" {
+		t.Fatalf("Decrypt after rotation = %q, %v", got, err)
+	}
+	if _, err := rotated.Decrypt("bookings.name", sealed); err == nil {
+		t.Fatalf("value decrypted under another field")
+	}
+	if got, _ := rotated.Decrypt("bookings.email", "plain@example.com"); got != "plain@example.com" {
+		t.Fatalf("plaintext not passed through: %q", got)
+	}
+	if rotated.BlindIndex("bookings.email", "a@example.com") != old.BlindIndex("bookings.email", "a@example.com") {
+		t.Fatalf("blind index changed with the encryption key")
+	}
+	if old.BlindIndex("bookings.email", "x") == old.BlindIndex("bookings.name", "x") {
+		t.Fatalf("blind index does not depend on the field")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	tests := []struct {
+		raw     string
+		ids     []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"2=" + k1 + ", 1=" + k1, []string{"2", "1"}, false},
+		{k1, nil, true},
+		{"1=short", nil, true},
+		{"1=" + base64.StdEncoding.EncodeToString([]byte("16-byte-key-1234")), nil, true},
+	}
+	for _, tt := range tests {
+		keys, err := ParseKeys(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseKeys(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+		}
+		if len(keys) != len(tt.ids) {
+			t.Fatalf("ParseKeys(%q) = %d keys, want %d", tt.raw, len(keys), len(tt.ids))
+		}
+		for i, id := range tt.ids {
+			if keys[i].ID != id {
+				t.Fatalf("ParseKeys(%q)[%d].ID = %q, want %q", tt.raw, i, keys[i].ID, id)
+			}
+		}
+	}
+}
+
+func TestNewRejectsIndexKeyReuse(t *testing.T) {
+	if _, err := New([]Key{{ID: "1", Key: testKey(1)}}, testKey(1)); err == nil {
+		t.Fatalf("accepted an encryption key reused as the blind index key")
+	}
+}
@@ -0,0 +1,173 @@
+// Package rbac defines the admin roles and the permissions each one grants.
+// Role assignments are stored in the user_roles table; this package only holds
+// the static role -> permission mapping so it can be checked without I/O.
+package rbac
+
+import "sort"
+
+// Role is a named bundle of permissions assigned to a user.
+type Role string
+
+// Permission guards a group of admin routes.
+type Permission string
+
+const (
+	RoleOwner         Role = "owner"
+	RoleMentor        Role = "mentor"
+	RoleSupport       Role = "support"
+	RoleContentEditor Role = "content-editor"
+)
+
+const (
+	PermStatsRead               Permission = "stats:read"
+	PermBookingsRead            Permission = "bookings:read"
+	PermBookingsManage          Permission = "bookings:manage"
+	PermRefundsIssue            Permission = "refunds:issue"
+	PermInsightsWrite           Permission = "insights:write"
+	PermEmailTemplatesWrite     Permission = "email_templates:write"
+	PermEmailSuppressionsManage Permission = "email_suppressions:manage"
+	PermEmailSendTest           Permission = "email:send_test"
+	PermSessionsManage          Permission = "sessions:manage"
+	PermRolesManage             Permission = "roles:manage"
+	PermAPIKeysManage           Permission = "api_keys:manage"
+	PermAuditRead               Permission = "audit:read"
+	PermTestimonialsModerate    Permission = "testimonials:moderate"
+	PermUsersRead               Permission = "users:read"
+	PermUsersManage             Permission = "users:manage"
+	PermIntakeFormsWrite        Permission = "intake_forms:write"
+	PermIntakeRead              Permission = "intake:read"
+	PermCrisisRead              Permission = "crisis:read"
+	PermLedgerManage            Permission = "ledger:manage"
+)
+
+// allPermissions lists every permission; owners hold all of them. Add new
+// permissions here as well as to the constants above.
+var allPermissions = []Permission{
+	PermStatsRead,
+	PermBookingsRead,
+	PermBookingsManage,
+	PermRefundsIssue,
+	PermInsightsWrite,
+	PermEmailTemplatesWrite,
+	PermEmailSuppressionsManage,
+	PermEmailSendTest,
+	PermSessionsManage,
+	PermRolesManage,
+	PermAPIKeysManage,
+	PermAuditRead,
+	PermTestimonialsModerate,
+	PermUsersRead,
+	PermUsersManage,
+	PermIntakeFormsWrite,
+	PermIntakeRead,
+	PermCrisisRead,
+	PermLedgerManage,
+}
+
+// apiKeyScopes are the permissions an API key may carry. Managing roles,
+// sessions and other keys stays with human owners.
+var apiKeyScopes = map[Permission]bool{
+	PermStatsRead:               true,
+	PermBookingsRead:            true,
+	PermUsersRead:               true,
+	PermInsightsWrite:           true,
+	PermEmailTemplatesWrite:     true,
+	PermEmailSuppressionsManage: true,
+	PermEmailSendTest:           true,
+	PermAuditRead:               true, // log shipping to a SIEM
+}
+
+// rolePermissions lists what each role may do. Owners are granted everything
+// in Allows and are not listed here.
+var rolePermissions = map[Role][]Permission{
+	RoleMentor: {
+		PermStatsRead,
+		PermBookingsRead,
+		PermBookingsManage,
+		PermUsersRead,
+		PermIntakeFormsWrite,
+		PermIntakeRead,
+		PermCrisisRead,
+	},
+	RoleSupport: {
+		PermBookingsRead,
+		PermBookingsManage,
+		PermUsersRead,
+		PermUsersManage,
+		PermEmailSuppressionsManage,
+		PermEmailSendTest,
+		PermSessionsManage,
+	},
+	RoleContentEditor: {
+		PermInsightsWrite,
+		PermEmailTemplatesWrite,
+		PermTestimonialsModerate,
+	},
+}
+
+// Roles returns every assignable role.
+func Roles() []Role {
+	return []Role{RoleOwner, RoleMentor, RoleSupport, RoleContentEditor}
+}
+
+// IsValid reports whether role is a known role.
+func IsValid(role Role) bool {
+	if role == RoleOwner {
+		return true
+	}
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allows reports whether any of roles grants perm.
+func Allows(roles []Role, perm Permission) bool {
+	for _, role := range roles {
+		if role == RoleOwner {
+			return true
+		}
+		for _, granted := range rolePermissions[role] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions returns the sorted, de-duplicated permissions granted by roles.
+func Permissions(roles []Role) []Permission {
+	seen := make(map[Permission]bool)
+	for _, role := range roles {
+		if role == RoleOwner {
+			for _, perm := range allPermissions {
+				seen[perm] = true
+			}
+			continue
+		}
+		for _, perm := range rolePermissions[role] {
+			seen[perm] = true
+		}
+	}
+
+	perms := make([]Permission, 0, len(seen))
+	for perm := range seen {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
+
+// IsAPIKeyScope reports whether perm may be granted to an API key.
+func IsAPIKeyScope(perm Permission) bool {
+	return apiKeyScopes[perm]
+}
+
+// HasScope reports whether scopes include perm.
+func HasScope(scopes []Permission, perm Permission) bool {
+	for _, scope := range scopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
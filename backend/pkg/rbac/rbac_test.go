@@ -0,0 +1,136 @@
+package rbac
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []Role
+		perm  Permission
+		want  bool
+	}{
+		{"owner has everything", []Role{RoleOwner}, PermRolesManage, true},
+		{"content editor manages insights", []Role{RoleContentEditor}, PermInsightsWrite, true},
+		{"content editor cannot see bookings", []Role{RoleContentEditor}, PermBookingsRead, false},
+		{"mentor reads bookings", []Role{RoleMentor}, PermBookingsRead, true},
+		{"mentor cannot edit templates", []Role{RoleMentor}, PermEmailTemplatesWrite, false},
+		{"support manages suppressions", []Role{RoleSupport}, PermEmailSuppressionsManage, true},
+		{"support forces logouts", []Role{RoleSupport}, PermSessionsManage, true},
+		{"mentor cannot force logouts", []Role{RoleMentor}, PermSessionsManage, false},
+		{"only owners manage roles", []Role{RoleMentor, RoleSupport, RoleContentEditor}, PermRolesManage, false},
+		{"only owners read the audit log", []Role{RoleMentor, RoleSupport, RoleContentEditor}, PermAuditRead, false},
+		{"mentor manages bookings", []Role{RoleMentor}, PermBookingsManage, true},
+		{"only owners issue refunds", []Role{RoleMentor, RoleSupport, RoleContentEditor}, PermRefundsIssue, false},
+		{"content editor moderates testimonials", []Role{RoleContentEditor}, PermTestimonialsModerate, true},
+		{"support blocks users", []Role{RoleSupport}, PermUsersManage, true},
+		{"mentor cannot block users", []Role{RoleMentor}, PermUsersManage, false},
+		{"mentor reads intake answers", []Role{RoleMentor}, PermIntakeRead, true},
+		{"only mentors read intake answers", []Role{RoleSupport, RoleContentEditor}, PermIntakeRead, false},
+		{"mentor reads the crisis log", []Role{RoleMentor}, PermCrisisRead, true},
+		{"only mentors read the crisis log", []Role{RoleSupport, RoleContentEditor}, PermCrisisRead, false},
+		{"roles combine", []Role{RoleContentEditor, RoleMentor}, PermStatsRead, true},
+		{"unknown role grants nothing", []Role{"superuser"}, PermStatsRead, false},
+		{"no roles", nil, PermStatsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allows(tt.roles, tt.perm); got != tt.want {
+				t.Fatalf("Allows(%v, %q) = %v, want %v", tt.roles, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	got := Permissions([]Role{RoleContentEditor, RoleContentEditor})
+	want := []Permission{PermEmailTemplatesWrite, PermInsightsWrite, PermTestimonialsModerate}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Permissions(content-editor) = %v, want %v", got, want)
+	}
+
+	owner := Permissions([]Role{RoleOwner})
+	for _, perm := range owner {
+		if !Allows([]Role{RoleOwner}, perm) {
+			t.Fatalf("owner permission %q not allowed", perm)
+		}
+	}
+	if len(owner) != len(allPermissions) {
+		t.Fatalf("expected owner to list all %d permissions, got %v", len(allPermissions), owner)
+	}
+}
+
+// TestAllPermissionsComplete checks that every Perm constant declared in
+// rbac.go is listed in allPermissions, so owners see it in /admin/roles/me.
+func TestAllPermissionsComplete(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "rbac.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse rbac.go: %v", err)
+	}
+	listed := make(map[string]bool, len(allPermissions))
+	for _, perm := range allPermissions {
+		listed[string(perm)] = true
+	}
+	consts := 0
+	for name, obj := range file.Scope.Objects {
+		if obj.Kind != ast.Con || !strings.HasPrefix(name, "Perm") {
+			continue
+		}
+		consts++
+		lit := obj.Decl.(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+		if !listed[strings.Trim(lit.Value, `"`)] {
+			t.Fatalf("%s is missing from allPermissions", name)
+		}
+	}
+	if consts != len(allPermissions) {
+		t.Fatalf("rbac.go declares %d permissions, allPermissions lists %d", consts, len(allPermissions))
+	}
+	for _, perms := range rolePermissions {
+		for _, perm := range perms {
+			if !listed[string(perm)] {
+				t.Fatalf("role permission %q is missing from allPermissions", perm)
+			}
+		}
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	for _, role := range Roles() {
+		if !IsValid(role) {
+			t.Fatalf("expected %q to be valid", role)
+		}
+	}
+	if IsValid("admin") {
+		t.Fatalf("expected legacy admin role to be invalid")
+	}
+}
+
+func TestIsAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		perm Permission
+		want bool
+	}{
+		{PermBookingsRead, true},
+		{PermStatsRead, true},
+		{PermRolesManage, false},
+		{PermAPIKeysManage, false},
+		{PermRefundsIssue, false},
+		{PermSessionsManage, false},
+		{PermIntakeRead, false},
+		{PermCrisisRead, false},
+		{"bookings:write", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAPIKeyScope(tt.perm); got != tt.want {
+			t.Fatalf("IsAPIKeyScope(%q) = %v, want %v", tt.perm, got, tt.want)
+		}
+	}
+}
@@ -7,17 +7,34 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Himadryy/hidden-depths-backend/pkg/apperror"
+	"github.com/Himadryy/hidden-depths-backend/pkg/i18n"
 	"github.com/Himadryy/hidden-depths-backend/pkg/logger"
 )
 
+// Pagination describes the page of a list response. Follow NextCursor, or
+// the Link rel="next" header, for the next page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
 type APIResponse struct {
-	Success   bool        `json:"success"`
-	Data      interface{} `json:"data,omitempty"`
-	Message   string      `json:"message,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	ErrorCode string      `json:"error_code,omitempty"`
-	Retryable *bool       `json:"retryable,omitempty"`
-	RequestID string      `json:"request_id,omitempty"`
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	ErrorCode  string      `json:"error_code,omitempty"`
+	Retryable  *bool       `json:"retryable,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+}
+
+// responseLocale returns the locale negotiated by the Locale middleware, which
+// records it in the Content-Language response header.
+func responseLocale(w http.ResponseWriter) string {
+	return w.Header().Get("Content-Language")
 }
 
 // JSON sends a standard JSON response
@@ -27,20 +44,35 @@ func JSON(w http.ResponseWriter, status int, data interface{}, message string) {
 	if err := json.NewEncoder(w).Encode(APIResponse{
 		Success:   status >= 200 && status < 300,
 		Data:      data,
-		Message:   message,
+		Message:   i18n.T(responseLocale(w), message),
 		RequestID: w.Header().Get("X-Request-Id"),
 	}); err != nil {
 		logger.Log.Error("Failed to encode JSON response", zap.Error(err))
 	}
 }
 
+// JSONPage sends a standard JSON response for one page of a list.
+func JSONPage(w http.ResponseWriter, status int, data interface{}, page Pagination, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(APIResponse{
+		Success:    status >= 200 && status < 300,
+		Data:       data,
+		Pagination: &page,
+		Message:    i18n.T(responseLocale(w), message),
+		RequestID:  w.Header().Get("X-Request-Id"),
+	}); err != nil {
+		logger.Log.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
+
 // Error sends a standard error response (backward compatible)
 func Error(w http.ResponseWriter, status int, errMessage string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(APIResponse{
 		Success:   false,
-		Error:     errMessage,
+		Error:     i18n.T(responseLocale(w), errMessage),
 		RequestID: w.Header().Get("X-Request-Id"),
 	}); err != nil {
 		logger.Log.Error("Failed to encode error response", zap.Error(err))
@@ -48,14 +80,15 @@ func Error(w http.ResponseWriter, status int, errMessage string) {
 }
 
 // AppError sends a structured error response from a domain error.
-// Includes error code, retryable flag, and request ID.
+// Includes error code, retryable flag, and request ID. The message is
+// localized; the error code never is.
 func AppErr(w http.ResponseWriter, err *apperror.AppError) {
 	retryable := err.Retryable
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(err.HTTPStatus)
 	if encErr := json.NewEncoder(w).Encode(APIResponse{
 		Success:   false,
-		Error:     err.Message,
+		Error:     err.Localize(responseLocale(w)),
 		ErrorCode: err.Code,
 		Retryable: &retryable,
 		RequestID: w.Header().Get("X-Request-Id"),
@@ -14,7 +14,8 @@ import {
   IndianRupee,
   Clock
 } from 'lucide-react';
-import { supabase } from '@/lib/supabase';
+import { useAuth } from '@/context/AuthProvider';
+import { getApiUrl, fetchWithTimeout } from '@/lib/api';
 
 interface AnalyticsData {
   totalBookings: number;
@@ -22,16 +23,25 @@ interface AnalyticsData {
   totalUsers: number;
   bookingsByDay: { date: string; count: number }[];
   bookingsByTime: { time: string; count: number }[];
-  recentBookings: { date: string; time: string; status: string }[];
+}
+
+// Subset of GET /admin/analytics used here
+interface AnalyticsResponse {
+  series: { period: string; bookings: number }[];
+  totals: { bookings: number; revenue: number; new_users: number; returning_users: number };
+  slots: { time: string; booked: number }[];
 }
 
 export default function AdminAnalyticsPage() {
   const router = useRouter();
+  const { session } = useAuth();
   const [data, setData] = useState<AnalyticsData | null>(null);
   const [loading, setLoading] = useState(true);
   const [timeRange, setTimeRange] = useState<'7d' | '30d' | '90d'>('30d');
 
   const fetchAnalytics = async () => {
+    if (!session?.access_token) return;
+
     setLoading(true);
     try {
       const daysBack = timeRange === '7d' ? 7 : timeRange === '30d' ? 30 : 90;
@@ -39,51 +49,24 @@ export default function AdminAnalyticsPage() {
       startDate.setDate(startDate.getDate() - daysBack);
       const startDateStr = startDate.toISOString().split('T')[0];
 
-      // Fetch bookings
-      const { data: bookings, error: bookingsError } = await supabase
-        .from('bookings')
-        .select('date, time, payment_status, created_at')
-        .eq('payment_status', 'paid')
-        .gte('date', startDateStr);
-
-      if (bookingsError) throw bookingsError;
-
-      // Fetch user count
-      const { count: userCount } = await supabase
-        .from('user_profiles')
-        .select('*', { count: 'exact', head: true });
-
-      // Process bookings by day
-      const byDay: Record<string, number> = {};
-      const byTime: Record<string, number> = {};
-      
-      bookings?.forEach(booking => {
-        // By day
-        byDay[booking.date] = (byDay[booking.date] || 0) + 1;
-        
-        // By time slot
-        byTime[booking.time] = (byTime[booking.time] || 0) + 1;
+      const res = await fetchWithTimeout(`${getApiUrl()}/admin/analytics?granularity=day&from=${startDateStr}`, {
+        headers: {
+          'Authorization': `Bearer ${session.access_token}`
+        }
       });
-
-      const bookingsByDay = Object.entries(byDay)
-        .map(([date, count]) => ({ date, count }))
-        .sort((a, b) => new Date(a.date).getTime() - new Date(b.date).getTime());
-
-      const bookingsByTime = Object.entries(byTime)
-        .map(([time, count]) => ({ time, count }))
-        .sort((a, b) => a.time.localeCompare(b.time));
+      if (!res.ok) throw new Error('Failed to fetch analytics');
+      const analytics: AnalyticsResponse = (await res.json()).data;
 
       setData({
-        totalBookings: bookings?.length || 0,
-        totalRevenue: (bookings?.length || 0) * 99,
-        totalUsers: userCount || 0,
-        bookingsByDay,
-        bookingsByTime,
-        recentBookings: (bookings || []).slice(0, 5).map(b => ({
-          date: b.date,
-          time: b.time,
-          status: b.payment_status
-        }))
+        totalBookings: analytics.totals.bookings,
+        totalRevenue: analytics.totals.revenue,
+        totalUsers: analytics.totals.new_users + analytics.totals.returning_users,
+        bookingsByDay: analytics.series
+          .filter(p => p.bookings > 0)
+          .map(p => ({ date: p.period, count: p.bookings })),
+        bookingsByTime: analytics.slots
+          .filter(slot => slot.booked > 0)
+          .map(slot => ({ time: slot.time, count: slot.booked })),
       });
     } catch (err) {
       console.error('Failed to fetch analytics:', err);
@@ -94,7 +77,7 @@ export default function AdminAnalyticsPage() {
 
   useEffect(() => {
     fetchAnalytics();
-  }, [timeRange]);
+  }, [session, timeRange]);
 
   const maxBookingsPerDay = data?.bookingsByDay.reduce((max, d) => Math.max(max, d.count), 0) || 1;
   const maxBookingsPerTime = data?.bookingsByTime.reduce((max, d) => Math.max(max, d.count), 0) || 1;
@@ -193,10 +176,10 @@ export default function AdminAnalyticsPage() {
                   <div className="p-2 rounded-lg bg-[var(--accent)]/10">
                     <Users className="w-5 h-5 text-[var(--accent)]" />
                   </div>
-                  <span className="text-xs font-bold uppercase tracking-wider text-muted">Total Users</span>
+                  <span className="text-xs font-bold uppercase tracking-wider text-muted">Clients</span>
                 </div>
                 <p className="text-3xl font-serif">{data.totalUsers}</p>
-                <p className="text-xs text-muted mt-1">registered accounts</p>
+                <p className="text-xs text-muted mt-1">booked in this period</p>
               </motion.div>
             </div>
 
@@ -40,9 +40,13 @@ export default function AdminBookingsPage() {
   const [error, setError] = useState<string | null>(null);
   const [search, setSearch] = useState('');
   const [statusFilter, setStatusFilter] = useState<StatusFilter>('all');
-  const [page, setPage] = useState(1);
-  const [totalPages, setTotalPages] = useState(1);
+  // Cursors of the pages visited so far; the last one is the current page
+  // ('' for the first). Going back pops it.
+  const [cursors, setCursors] = useState<string[]>(['']);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const perPage = 20;
+  const page = cursors.length;
+  const cursor = cursors[cursors.length - 1];
 
   const fetchBookings = async () => {
     if (!session?.access_token) return;
@@ -54,8 +58,8 @@ export default function AdminBookingsPage() {
       const apiUrl = getApiUrl();
       const statusParam = statusFilter === 'confirmed' ? 'paid' : statusFilter;
       const params = new URLSearchParams({
-        page: page.toString(),
-        per_page: perPage.toString(),
+        limit: perPage.toString(),
+        ...(cursor && { cursor }),
         ...(statusParam !== 'all' && { status: statusParam }),
         ...(search && { search }),
       });
@@ -71,8 +75,8 @@ export default function AdminBookingsPage() {
       }
 
       const data = await res.json();
-      setBookings(data.data?.bookings || []);
-      setTotalPages(Math.ceil((data.data?.total || 0) / perPage));
+      setBookings(data.data || []);
+      setNextCursor(data.pagination?.has_more ? data.pagination.next_cursor : null);
     } catch (err) {
       console.error('Failed to fetch bookings:', err);
       setError('Failed to load bookings. Make sure the backend is running.');
@@ -83,12 +87,15 @@ export default function AdminBookingsPage() {
 
   useEffect(() => {
     fetchBookings();
-  }, [session, page, statusFilter]);
+  }, [session, cursor, statusFilter]);
 
   const handleSearch = (e: React.FormEvent) => {
     e.preventDefault();
-    setPage(1);
-    fetchBookings();
+    if (cursors.length > 1) {
+      setCursors(['']);
+    } else {
+      fetchBookings();
+    }
   };
 
   const getStatusIcon = (status: string) => {
@@ -182,7 +189,7 @@ export default function AdminBookingsPage() {
               value={statusFilter}
               onChange={(e) => {
                 setStatusFilter(e.target.value as StatusFilter);
-                setPage(1);
+                setCursors(['']);
               }}
               className="px-4 py-3 bg-glass border border-glass rounded-xl text-sm focus:border-[var(--accent)] focus:outline-none transition-colors"
             >
@@ -267,21 +274,21 @@ export default function AdminBookingsPage() {
             </motion.div>
 
             {/* Pagination */}
-            {totalPages > 1 && (
+            {(page > 1 || nextCursor) && (
               <div className="flex items-center justify-center gap-4">
                 <button
-                  onClick={() => setPage(p => Math.max(1, p - 1))}
+                  onClick={() => setCursors(c => (c.length > 1 ? c.slice(0, -1) : c))}
                   disabled={page === 1}
                   className="p-2 rounded-lg border border-glass hover:border-[var(--accent)] disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
                 >
                   <ChevronLeft size={20} />
                 </button>
                 <span className="text-sm">
-                  Page {page} of {totalPages}
+                  Page {page}
                 </span>
                 <button
-                  onClick={() => setPage(p => Math.min(totalPages, p + 1))}
-                  disabled={page === totalPages}
+                  onClick={() => nextCursor && setCursors(c => [...c, nextCursor])}
+                  disabled={!nextCursor}
                   className="p-2 rounded-lg border border-glass hover:border-[var(--accent)] disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
                 >
                   <ChevronRight size={20} />
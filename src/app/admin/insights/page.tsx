@@ -17,7 +17,7 @@ import {
   Loader2
 } from 'lucide-react';
 import { supabase } from '@/lib/supabase';
-import { getApiUrl, fetchWithTimeout } from '@/lib/api';
+import { getApiUrl, fetchWithTimeout, fetchAllPages } from '@/lib/api';
 import Image from 'next/image';
 
 interface Insight {
@@ -53,13 +53,7 @@ export default function InsightsCMS() {
     
     const fetchInsights = async () => {
       try {
-        const res = await fetchWithTimeout(`${apiUrl}/insights`);
-        if (res.ok) {
-          const data = await res.json();
-          // Unwrap Go backend {success, data} wrapper
-          const insightsArray = Array.isArray(data) ? data : (data.data || []);
-          setInsights(insightsArray);
-        }
+        setInsights(await fetchAllPages<Insight>(`${apiUrl}/insights`));
       } catch (err) {
         console.error('Error fetching insights:', err);
       } finally {
@@ -72,12 +66,7 @@ export default function InsightsCMS() {
 
   const refetchInsights = async () => {
     try {
-      const res = await fetchWithTimeout(`${apiUrl}/insights`);
-      if (res.ok) {
-        const data = await res.json();
-        const insightsArray = Array.isArray(data) ? data : (data.data || []);
-        setInsights(insightsArray);
-      }
+      setInsights(await fetchAllPages<Insight>(`${apiUrl}/insights`));
     } catch (err) {
       console.error('Error fetching insights:', err);
     }
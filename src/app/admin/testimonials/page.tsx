@@ -11,11 +11,14 @@ import {
   Loader2,
   ArrowLeft,
   RefreshCw,
-  Eye,
+  Ban,
   EyeOff,
   Sparkles
 } from 'lucide-react';
-import { supabase } from '@/lib/supabase';
+import { useAuth } from '@/context/AuthProvider';
+import { getApiUrl, fetchWithTimeout, fetchAllPages } from '@/lib/api';
+
+type TestimonialStatus = 'pending' | 'approved' | 'rejected' | 'featured';
 
 interface Testimonial {
   id: string;
@@ -24,36 +27,33 @@ interface Testimonial {
   content: string;
   rating: number;
   is_anonymous: boolean;
-  approved: boolean;
-  featured: boolean;
+  allow_publish: boolean;
+  status: TestimonialStatus;
   created_at: string;
 }
 
 export default function AdminTestimonialsPage() {
   const router = useRouter();
+  const { session } = useAuth();
   const [testimonials, setTestimonials] = useState<Testimonial[]>([]);
   const [loading, setLoading] = useState(true);
-  const [filter, setFilter] = useState<'all' | 'pending' | 'approved'>('all');
+  const [filter, setFilter] = useState<'all' | TestimonialStatus>('all');
   const [updating, setUpdating] = useState<string | null>(null);
 
+  const authHeaders = (): Record<string, string> => ({
+    'Authorization': `Bearer ${session?.access_token}`,
+  });
+
   const fetchTestimonials = async () => {
+    if (!session?.access_token) return;
+
     setLoading(true);
     try {
-      let query = supabase
-        .from('testimonials')
-        .select('*')
-        .order('created_at', { ascending: false });
-
-      if (filter === 'pending') {
-        query = query.eq('approved', false);
-      } else if (filter === 'approved') {
-        query = query.eq('approved', true);
-      }
-
-      const { data, error } = await query;
-
-      if (error) throw error;
-      setTestimonials(data || []);
+      const params = new URLSearchParams(filter !== 'all' ? { status: filter } : {});
+      setTestimonials(await fetchAllPages<Testimonial>(
+        `${getApiUrl()}/admin/testimonials?${params}`,
+        { headers: authHeaders() }
+      ));
     } catch (err) {
       console.error('Failed to fetch testimonials:', err);
     } finally {
@@ -63,23 +63,27 @@ export default function AdminTestimonialsPage() {
 
   useEffect(() => {
     fetchTestimonials();
-  }, [filter]);
+  }, [session, filter]);
 
-  const updateTestimonial = async (id: string, updates: Partial<Testimonial>) => {
+  const setStatus = async (id: string, status: TestimonialStatus) => {
     setUpdating(id);
     try {
-      const { error } = await supabase
-        .from('testimonials')
-        .update(updates)
-        .eq('id', id);
-
-      if (error) throw error;
+      const res = await fetchWithTimeout(`${getApiUrl()}/admin/testimonials/${id}`, {
+        method: 'PATCH',
+        headers: { ...authHeaders(), 'Content-Type': 'application/json' },
+        body: JSON.stringify({ status }),
+      });
+      if (!res.ok) {
+        const body = await res.json().catch(() => null);
+        throw new Error(body?.error || 'Failed to update testimonial');
+      }
 
-      setTestimonials(prev => 
-        prev.map(t => t.id === id ? { ...t, ...updates } : t)
+      setTestimonials(prev =>
+        prev.map(t => t.id === id ? { ...t, status } : t)
       );
     } catch (err) {
       console.error('Failed to update testimonial:', err);
+      alert(err instanceof Error ? err.message : 'Failed to update testimonial');
     } finally {
       setUpdating(null);
     }
@@ -90,12 +94,11 @@ export default function AdminTestimonialsPage() {
     
     setUpdating(id);
     try {
-      const { error } = await supabase
-        .from('testimonials')
-        .delete()
-        .eq('id', id);
-
-      if (error) throw error;
+      const res = await fetchWithTimeout(`${getApiUrl()}/admin/testimonials/${id}`, {
+        method: 'DELETE',
+        headers: authHeaders(),
+      });
+      if (!res.ok) throw new Error('Failed to delete testimonial');
 
       setTestimonials(prev => prev.filter(t => t.id !== id));
     } catch (err) {
@@ -145,7 +148,7 @@ export default function AdminTestimonialsPage() {
 
         {/* Filters */}
         <div className="flex gap-2">
-          {(['all', 'pending', 'approved'] as const).map((f) => (
+          {(['all', 'pending', 'approved', 'featured', 'rejected'] as const).map((f) => (
             <button
               key={f}
               onClick={() => setFilter(f)}
@@ -179,9 +182,11 @@ export default function AdminTestimonialsPage() {
                 animate={{ opacity: 1, y: 0 }}
                 transition={{ delay: index * 0.05 }}
                 className={`bg-glass border rounded-2xl p-6 ${
-                  testimonial.approved 
-                    ? 'border-green-500/30' 
-                    : 'border-yellow-500/30'
+                  testimonial.status === 'approved' || testimonial.status === 'featured'
+                    ? 'border-green-500/30'
+                    : testimonial.status === 'rejected'
+                      ? 'border-red-500/30'
+                      : 'border-yellow-500/30'
                 }`}
               >
                 <div className="flex flex-col md:flex-row md:items-start justify-between gap-4">
@@ -189,16 +194,26 @@ export default function AdminTestimonialsPage() {
                     {/* Header */}
                     <div className="flex items-center gap-3">
                       <div className="flex">{renderStars(testimonial.rating)}</div>
-                      {testimonial.featured && (
+                      {testimonial.status === 'featured' && (
                         <span className="px-2 py-1 bg-[var(--accent)]/20 text-[var(--accent)] text-xs rounded-full flex items-center gap-1">
                           <Sparkles size={12} /> Featured
                         </span>
                       )}
-                      {!testimonial.approved && (
+                      {testimonial.status === 'pending' && (
                         <span className="px-2 py-1 bg-yellow-500/20 text-yellow-500 text-xs rounded-full">
                           Pending Review
                         </span>
                       )}
+                      {testimonial.status === 'rejected' && (
+                        <span className="px-2 py-1 bg-red-500/20 text-red-500 text-xs rounded-full">
+                          Rejected
+                        </span>
+                      )}
+                      {!testimonial.allow_publish && (
+                        <span className="px-2 py-1 bg-gray-500/20 text-gray-400 text-xs rounded-full">
+                          Private
+                        </span>
+                      )}
                     </div>
 
                     {/* Content */}
@@ -220,17 +235,18 @@ export default function AdminTestimonialsPage() {
                       <Loader2 className="w-5 h-5 animate-spin" />
                     ) : (
                       <>
-                        {!testimonial.approved ? (
+                        {testimonial.status === 'pending' || testimonial.status === 'rejected' ? (
                           <button
-                            onClick={() => updateTestimonial(testimonial.id, { approved: true })}
-                            className="p-2 rounded-lg bg-green-500/10 text-green-500 hover:bg-green-500/20 transition-colors"
-                            title="Approve"
+                            onClick={() => setStatus(testimonial.id, 'approved')}
+                            disabled={!testimonial.allow_publish}
+                            className="p-2 rounded-lg bg-green-500/10 text-green-500 hover:bg-green-500/20 transition-colors disabled:opacity-40 disabled:cursor-not-allowed"
+                            title={testimonial.allow_publish ? 'Approve' : 'The author did not allow publishing'}
                           >
                             <Check size={18} />
                           </button>
                         ) : (
                           <button
-                            onClick={() => updateTestimonial(testimonial.id, { approved: false })}
+                            onClick={() => setStatus(testimonial.id, 'pending')}
                             className="p-2 rounded-lg bg-gray-500/10 text-gray-500 hover:bg-gray-500/20 transition-colors"
                             title="Unapprove"
                           >
@@ -238,17 +254,29 @@ export default function AdminTestimonialsPage() {
                           </button>
                         )}
                         
-                        <button
-                          onClick={() => updateTestimonial(testimonial.id, { featured: !testimonial.featured })}
-                          className={`p-2 rounded-lg transition-colors ${
-                            testimonial.featured 
-                              ? 'bg-[var(--accent)]/20 text-[var(--accent)]' 
-                              : 'bg-gray-500/10 text-gray-500 hover:bg-gray-500/20'
-                          }`}
-                          title={testimonial.featured ? 'Unfeature' : 'Feature'}
-                        >
-                          <Sparkles size={18} />
-                        </button>
+                        {(testimonial.status === 'approved' || testimonial.status === 'featured') && (
+                          <button
+                            onClick={() => setStatus(testimonial.id, testimonial.status === 'featured' ? 'approved' : 'featured')}
+                            className={`p-2 rounded-lg transition-colors ${
+                              testimonial.status === 'featured'
+                                ? 'bg-[var(--accent)]/20 text-[var(--accent)]' 
+                                : 'bg-gray-500/10 text-gray-500 hover:bg-gray-500/20'
+                            }`}
+                            title={testimonial.status === 'featured' ? 'Unfeature' : 'Feature'}
+                          >
+                            <Sparkles size={18} />
+                          </button>
+                        )}
+
+                        {testimonial.status === 'pending' && (
+                          <button
+                            onClick={() => setStatus(testimonial.id, 'rejected')}
+                            className="p-2 rounded-lg bg-gray-500/10 text-gray-500 hover:bg-gray-500/20 transition-colors"
+                            title="Reject"
+                          >
+                            <Ban size={18} />
+                          </button>
+                        )}
                         
                         <button
                           onClick={() => deleteTestimonial(testimonial.id)}
@@ -13,71 +13,54 @@ import {
   RefreshCw,
   Mail,
   Calendar,
-  Clock
+  Ban
 } from 'lucide-react';
-import { supabase } from '@/lib/supabase';
+import { useAuth } from '@/context/AuthProvider';
+import { getApiUrl, fetchWithTimeout } from '@/lib/api';
 
 interface UserProfile {
   id: string;
   email: string;
-  full_name: string | null;
+  full_name: string;
   created_at: string;
-  last_sign_in_at: string | null;
+  blocked: boolean;
   booking_count: number;
 }
 
 export default function AdminUsersPage() {
   const router = useRouter();
+  const { session } = useAuth();
   const [users, setUsers] = useState<UserProfile[]>([]);
   const [loading, setLoading] = useState(true);
   const [search, setSearch] = useState('');
-  const [page, setPage] = useState(1);
-  const [totalCount, setTotalCount] = useState(0);
+  // Cursors of the pages visited so far; the last one is the current page
+  // ('' for the first). Going back pops it.
+  const [cursors, setCursors] = useState<string[]>(['']);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
   const perPage = 20;
+  const page = cursors.length;
+  const cursor = cursors[cursors.length - 1];
 
   const fetchUsers = async () => {
+    if (!session?.access_token) return;
+
     setLoading(true);
     try {
-      // Query user_profiles with booking count
-      let query = supabase
-        .from('user_profiles')
-        .select('*', { count: 'exact' });
-
-      if (search) {
-        query = query.or(`email.ilike.%${search}%,full_name.ilike.%${search}%`);
-      }
-
-      const { data, error, count } = await query
-        .order('created_at', { ascending: false })
-        .range((page - 1) * perPage, page * perPage - 1);
-
-      if (error) throw error;
-
-      // Get booking counts for these users
-      const userIds = data?.map(u => u.id) || [];
-      
-      let usersWithCounts: UserProfile[] = [];
-      
-      if (userIds.length > 0) {
-        const { data: bookings } = await supabase
-          .from('bookings')
-          .select('user_id')
-          .in('user_id', userIds)
-          .eq('payment_status', 'paid');
-
-        const bookingCounts: Record<string, number> = {};
-        bookings?.forEach(b => {
-          bookingCounts[b.user_id] = (bookingCounts[b.user_id] || 0) + 1;
-        });
-
-        usersWithCounts = (data || []).map(user => ({
-          ...user,
-          booking_count: bookingCounts[user.id] || 0
-        }));
-      }
-
-      setUsers(usersWithCounts);
-      setTotalCount(count || 0);
+      const params = new URLSearchParams({
+        limit: perPage.toString(),
+        ...(cursor && { cursor }),
+        ...(search && { search }),
+      });
+      const res = await fetchWithTimeout(`${getApiUrl()}/admin/users?${params}`, {
+        headers: {
+          'Authorization': `Bearer ${session.access_token}`
+        }
+      });
+      if (!res.ok) throw new Error('Failed to fetch users');
+
+      const data = await res.json();
+      setUsers(data.data || []);
+      setNextCursor(data.pagination?.has_more ? data.pagination.next_cursor : null);
     } catch (err) {
       console.error('Failed to fetch users:', err);
     } finally {
@@ -87,16 +70,17 @@ export default function AdminUsersPage() {
 
   useEffect(() => {
     fetchUsers();
-  }, [page]);
+  }, [session, cursor]);
 
   const handleSearch = (e: React.FormEvent) => {
     e.preventDefault();
-    setPage(1);
-    fetchUsers();
+    if (cursors.length > 1) {
+      setCursors(['']);
+    } else {
+      fetchUsers();
+    }
   };
 
-  const totalPages = Math.ceil(totalCount / perPage);
-
   const formatDate = (dateStr: string | null) => {
     if (!dateStr) return 'Never';
     return new Date(dateStr).toLocaleDateString('en-IN', {
@@ -106,21 +90,6 @@ export default function AdminUsersPage() {
     });
   };
 
-  const getTimeAgo = (dateStr: string | null) => {
-    if (!dateStr) return 'Never';
-    const date = new Date(dateStr);
-    const now = new Date();
-    const diffMs = now.getTime() - date.getTime();
-    const diffDays = Math.floor(diffMs / (1000 * 60 * 60 * 24));
-    
-    if (diffDays === 0) return 'Today';
-    if (diffDays === 1) return 'Yesterday';
-    if (diffDays < 7) return `${diffDays} days ago`;
-    if (diffDays < 30) return `${Math.floor(diffDays / 7)} weeks ago`;
-    if (diffDays < 365) return `${Math.floor(diffDays / 30)} months ago`;
-    return `${Math.floor(diffDays / 365)} years ago`;
-  };
-
   return (
     <div className="min-h-screen bg-[var(--background)] text-theme pt-24 px-6 pb-20">
       <div className="max-w-7xl mx-auto space-y-8">
@@ -139,7 +108,6 @@ export default function AdminUsersPage() {
               User Management
             </div>
             <h1 className="font-serif text-3xl">Registered Users</h1>
-            <p className="text-muted text-sm mt-1">{totalCount} total users</p>
           </div>
           
           <button
@@ -223,10 +191,10 @@ export default function AdminUsersPage() {
                     </div>
                     <div>
                       <p className="text-xs text-muted flex items-center justify-center gap-1">
-                        <Clock size={10} />
-                        Last seen
+                        <Ban size={10} />
+                        Status
                       </p>
-                      <p className="text-xs">{getTimeAgo(user.last_sign_in_at)}</p>
+                      <p className={`text-xs ${user.blocked ? 'text-red-400' : ''}`}>{user.blocked ? 'Blocked' : 'Active'}</p>
                     </div>
                   </div>
                 </motion.div>
@@ -234,21 +202,21 @@ export default function AdminUsersPage() {
             </div>
 
             {/* Pagination */}
-            {totalPages > 1 && (
+            {(page > 1 || nextCursor) && (
               <div className="flex items-center justify-center gap-4">
                 <button
-                  onClick={() => setPage(p => Math.max(1, p - 1))}
+                  onClick={() => setCursors(prev => prev.slice(0, -1))}
                   disabled={page === 1}
                   className="p-2 rounded-lg border border-glass hover:border-[var(--accent)] disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
                 >
                   <ChevronLeft size={20} />
                 </button>
                 <span className="text-sm">
-                  Page {page} of {totalPages}
+                  Page {page}
                 </span>
                 <button
-                  onClick={() => setPage(p => Math.min(totalPages, p + 1))}
-                  disabled={page === totalPages}
+                  onClick={() => nextCursor && setCursors(prev => [...prev, nextCursor])}
+                  disabled={!nextCursor}
                   className="p-2 rounded-lg border border-glass hover:border-[var(--accent)] disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
                 >
                   <ChevronRight size={20} />
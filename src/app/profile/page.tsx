@@ -6,7 +6,8 @@ import { useAuth } from '@/context/AuthProvider';
 import { motion } from 'framer-motion';
 import { Calendar, Clock, Loader2, ArrowLeft, History, CalendarDays } from 'lucide-react';
 import { supabase } from '@/lib/supabase';
-import { getApiUrl, fetchWithTimeout } from '@/lib/api';
+import { getApiUrl, fetchWithTimeout, fetchAllPages } from '@/lib/api';
+import FeedbackModal from '@/components/FeedbackModal';
 
 interface Booking {
   id: string;
@@ -23,6 +24,7 @@ export default function ProfilePage() {
   const router = useRouter();
   const [bookings, setBookings] = useState<Booking[]>([]);
   const [loading, setLoading] = useState(true);
+  const [feedbackBookingId, setFeedbackBookingId] = useState<string | null>(null);
 
   useEffect(() => {
     if (authLoading) return;
@@ -38,31 +40,14 @@ export default function ProfilePage() {
         const token = session?.access_token;
         const apiUrl = getApiUrl();
         
-        // Use Go API if available
-        if (apiUrl && token) {
-            const res = await fetchWithTimeout(`${apiUrl}/bookings/my`, {
-                headers: {
-                    'Authorization': `Bearer ${token}`
-                }
-            });
-            if (res.ok) {
-                const data = await res.json();
-                // Handle Go backend wrapper { success: true, data: [...] }
-                const bookingsArray = Array.isArray(data) ? data : (data.data || []);
-                setBookings(bookingsArray);
-                return;
+        // Bookings come only from the API, which withholds meeting links
+        // until the intake form is answered
+        if (!apiUrl || !token) return;
+        setBookings(await fetchAllPages<Booking>(`${apiUrl}/bookings/my`, {
+            headers: {
+                'Authorization': `Bearer ${token}`
             }
-        }
-
-        // Fallback to Supabase
-        const { data, error } = await supabase
-          .from('bookings')
-          .select('*')
-          .eq('user_id', user.id)
-          .order('date', { ascending: false });
-
-        if (error) throw error;
-        setBookings(data || []);
+        }));
 
       } catch (err) {
         console.error('Error loading bookings:', err);
@@ -82,34 +67,22 @@ export default function ProfilePage() {
         const token = session?.access_token;
         const apiUrl = getApiUrl();
 
-        // API Call
-        if (apiUrl && token) {
-            const res = await fetchWithTimeout(`${apiUrl}/bookings/${bookingId}`, {
-                method: 'DELETE',
-                headers: {
-                    'Authorization': `Bearer ${token}`,
-                    'X-Booking-Cancel': 'confirmed',
-                }
-            });
-
-            if (res.ok) {
-                // Remove from state immediately
-                setBookings(prev => prev.filter(b => b.id !== bookingId));
-            } else {
-                alert('Failed to cancel booking. Please try again.');
-            }
-            return;
-        }
+        if (!apiUrl || !token) throw new Error('Not signed in');
 
-        // Supabase Fallback
-        const { error } = await supabase
-            .from('bookings')
-            .delete()
-            .eq('id', bookingId)
-            .eq('user_id', user!.id);
+        const res = await fetchWithTimeout(`${apiUrl}/bookings/${bookingId}`, {
+            method: 'DELETE',
+            headers: {
+                'Authorization': `Bearer ${token}`,
+                'X-Booking-Cancel': 'confirmed',
+            }
+        });
 
-        if (error) throw error;
-        setBookings(prev => prev.filter(b => b.id !== bookingId));
+        if (res.ok) {
+            // Remove from state immediately
+            setBookings(prev => prev.filter(b => b.id !== bookingId));
+        } else {
+            alert('Failed to cancel booking. Please try again.');
+        }
 
     } catch (err) {
         console.error("Cancellation error:", err);
@@ -117,6 +90,15 @@ export default function ProfilePage() {
     }
   };
 
+  // Our own session room gets the booking ID so it can ask for feedback afterwards
+  const sessionLink = (booking: Booking) => {
+    const url = new URL(booking.meeting_link!, window.location.origin);
+    if (url.origin === window.location.origin) {
+      url.searchParams.set('booking', booking.id);
+    }
+    return url.toString();
+  };
+
   const upcomingBookings = bookings.filter(b => new Date(`${b.date}T${convertTo24Hour(b.time)}`) >= new Date());
   const pastBookings = bookings.filter(b => new Date(`${b.date}T${convertTo24Hour(b.time)}`) < new Date());
 
@@ -194,7 +176,7 @@ export default function ProfilePage() {
                                 </div>
                                 <div className="mt-6 pt-4 border-t border-glass flex gap-3">
                                     <button 
-                                        onClick={() => booking.meeting_link && window.open(booking.meeting_link, '_blank')}
+                                        onClick={() => booking.meeting_link && window.open(sessionLink(booking), '_blank')}
                                         disabled={!booking.meeting_link}
                                         className="flex-1 py-2 bg-[var(--foreground)] text-[var(--background)] rounded-lg text-xs font-bold uppercase tracking-widest hover:opacity-90 transition-opacity disabled:opacity-50 disabled:cursor-not-allowed"
                                     >
@@ -232,9 +214,17 @@ export default function ProfilePage() {
                                     <p className="font-serif text-theme">{new Date(booking.date).toLocaleDateString()}</p>
                                     <p className="text-xs text-muted">{booking.time}</p>
                                 </div>
-                                <span className="px-3 py-1 bg-green-500/10 text-green-500 text-[10px] font-bold uppercase tracking-widest rounded-full">
-                                    Completed
-                                </span>
+                                <div className="flex items-center gap-3">
+                                    <button
+                                        onClick={() => setFeedbackBookingId(booking.id)}
+                                        className="px-3 py-1 border border-glass hover:border-[var(--accent)] text-[10px] font-bold uppercase tracking-widest rounded-full transition-all"
+                                    >
+                                        Share Feedback
+                                    </button>
+                                    <span className="px-3 py-1 bg-green-500/10 text-green-500 text-[10px] font-bold uppercase tracking-widest rounded-full">
+                                        Completed
+                                    </span>
+                                </div>
                             </div>
                         ))
                     ) : (
@@ -243,6 +233,14 @@ export default function ProfilePage() {
                 </div>
             </section>
         </div>
+
+        {feedbackBookingId && (
+            <FeedbackModal
+                isOpen
+                onClose={() => setFeedbackBookingId(null)}
+                bookingId={feedbackBookingId}
+            />
+        )}
     </div>
   );
 }
@@ -26,6 +26,8 @@ function SessionContent() {
   
   // Get room ID from query param: /session?room=abc123
   const roomId = searchParams.get('room') || '';
+  // Booking the room belongs to, added by the profile page; feedback needs it
+  const bookingId = searchParams.get('booking') || '';
   
   const [displayName, setDisplayName] = useState('');
   const [isJoining, setIsJoining] = useState(false);
@@ -100,10 +102,13 @@ function SessionContent() {
   if (sessionEnded) {
     return (
       <>
-        <FeedbackModal 
-          isOpen={showFeedback} 
-          onClose={() => setShowFeedback(false)}
-        />
+        {bookingId && (
+          <FeedbackModal
+            isOpen={showFeedback}
+            onClose={() => setShowFeedback(false)}
+            bookingId={bookingId}
+          />
+        )}
         <main className="min-h-screen bg-[var(--background)] flex items-center justify-center p-4">
           <div className="max-w-md w-full bg-[var(--card-bg)] border border-[var(--card-border)] rounded-2xl p-8 text-center">
             <div className="w-16 h-16 mx-auto mb-6 rounded-full bg-green-500/10 flex items-center justify-center">
@@ -123,7 +128,7 @@ function SessionContent() {
             )}
             
             <div className="space-y-3">
-              {!isMentor && (
+              {!isMentor && bookingId && (
                 <button
                   onClick={() => setShowFeedback(true)}
                   className="block w-full py-3 px-6 bg-[var(--accent)] text-white rounded-full hover:bg-[var(--accent-deep)] transition-colors"
@@ -10,6 +10,7 @@ import {
   createBooking,
   verifyPayment,
   cancelPendingBooking,
+  reportCheckoutOpened,
   type BookingPolicy,
 } from '@/lib/bookingService';
 import { useAuth } from '@/context/AuthProvider';
@@ -501,6 +502,7 @@ export default function BookingCalendar({ onClose }: { onClose: () => void }) {
                 setIsSubmitting(false);
             });
             rzp1.open();
+            reportCheckoutOpened(pendingBookingId);
         } else {
             // Free Session - Already confirmed by createBooking
             setView('success');
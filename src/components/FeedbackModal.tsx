@@ -4,21 +4,21 @@ import { useState } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
 import { Star, X, Loader2, CheckCircle } from 'lucide-react';
 import { supabase } from '@/lib/supabase';
-import { useAuth } from '@/context/AuthProvider';
+import { getApiUrl, fetchWithTimeout } from '@/lib/api';
 
 interface FeedbackModalProps {
   isOpen: boolean;
   onClose: () => void;
-  sessionDate?: string;
+  bookingId: string;
 }
 
-export default function FeedbackModal({ isOpen, onClose, sessionDate }: FeedbackModalProps) {
-  const { user } = useAuth();
+export default function FeedbackModal({ isOpen, onClose, bookingId }: FeedbackModalProps) {
   const [rating, setRating] = useState(0);
   const [hoverRating, setHoverRating] = useState(0);
   const [content, setContent] = useState('');
   const [name, setName] = useState('');
   const [isAnonymous, setIsAnonymous] = useState(true);
+  const [allowPublish, setAllowPublish] = useState(false);
   const [submitting, setSubmitting] = useState(false);
   const [submitted, setSubmitted] = useState(false);
   const [error, setError] = useState<string | null>(null);
@@ -41,19 +41,31 @@ export default function FeedbackModal({ isOpen, onClose, sessionDate }: Feedback
     setError(null);
 
     try {
-      const { error: dbError } = await supabase
-        .from('testimonials')
-        .insert({
-          user_id: user?.id || null,
-          name: isAnonymous ? 'Anonymous' : name.trim(),
-          content: content.trim(),
+      const { data: { session } } = await supabase.auth.getSession();
+      const token = session?.access_token;
+      const apiUrl = getApiUrl();
+      if (!apiUrl || !token) throw new Error('Not signed in');
+
+      // The API checks the session has ended and holds the feedback for moderation
+      const res = await fetchWithTimeout(`${apiUrl}/bookings/${bookingId}/feedback`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+        },
+        body: JSON.stringify({
           rating,
+          content: content.trim(),
           is_anonymous: isAnonymous,
-          session_date: sessionDate || new Date().toISOString().split('T')[0],
-          approved: false, // Requires admin approval
-        });
-
-      if (dbError) throw dbError;
+          name: isAnonymous ? '' : name.trim(),
+          allow_publish: allowPublish,
+        }),
+      });
+      if (!res.ok) {
+        const body = await res.json().catch(() => null);
+        setError(body?.error || 'Failed to submit feedback. Please try again.');
+        return;
+      }
 
       setSubmitted(true);
       setTimeout(() => {
@@ -156,7 +168,7 @@ export default function FeedbackModal({ isOpen, onClose, sessionDate }: Feedback
                 <textarea
                   value={content}
                   onChange={(e) => setContent(e.target.value)}
-                  placeholder="Share your experience... (this may be featured on our website)"
+                  placeholder="Share your experience..."
                   className="w-full px-4 py-3 rounded-xl bg-[var(--background)] border border-[var(--glass-border)] text-[var(--foreground)] placeholder:text-[var(--text-muted)] focus:outline-none focus:border-[var(--accent)] transition-colors resize-none h-24 mb-4"
                 />
 
@@ -171,6 +183,17 @@ export default function FeedbackModal({ isOpen, onClose, sessionDate }: Feedback
                   <span className="text-sm text-[var(--foreground)]">Keep me anonymous</span>
                 </label>
 
+                {/* Publishing consent */}
+                <label className="flex items-center gap-3 mb-4 cursor-pointer">
+                  <input
+                    type="checkbox"
+                    checked={allowPublish}
+                    onChange={(e) => setAllowPublish(e.target.checked)}
+                    className="w-4 h-4 rounded border-gray-600 text-[var(--accent)] focus:ring-[var(--accent)] focus:ring-offset-0 bg-transparent"
+                  />
+                  <span className="text-sm text-[var(--foreground)]">Allow this to be featured on our website</span>
+                </label>
+
                 {/* Name input (if not anonymous) */}
                 {!isAnonymous && (
                   <input
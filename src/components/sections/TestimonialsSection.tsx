@@ -3,7 +3,7 @@
 import { useEffect, useState } from 'react';
 import { motion } from 'framer-motion';
 import Container from '@/components/ui/Container';
-import { supabase } from '@/lib/supabase';
+import { getApiUrl, fetchWithTimeout } from '@/lib/api';
 
 interface Testimonial {
   id: string;
@@ -58,18 +58,17 @@ export default function TestimonialsSection() {
   useEffect(() => {
     async function fetchTestimonials() {
       try {
-        const { data, error } = await supabase
-          .from('testimonials')
-          .select('id, name, content, rating, is_anonymous, created_at')
-          .eq('approved', true)
-          .order('featured', { ascending: false })
-          .order('created_at', { ascending: false })
-          .limit(6);
+        const apiUrl = getApiUrl();
+        if (!apiUrl) return;
 
-        if (error) throw error;
-        
-        if (data && data.length > 0) {
-          setTestimonials(data);
+        // Published testimonials, featured first; the API names anonymous ones "Anonymous"
+        const res = await fetchWithTimeout(`${apiUrl}/testimonials`);
+        if (!res.ok) throw new Error('Failed to fetch testimonials');
+        const body = await res.json();
+        const data: Omit<Testimonial, 'is_anonymous'>[] = body.data || [];
+
+        if (data.length > 0) {
+          setTestimonials(data.slice(0, 6).map(t => ({ ...t, is_anonymous: t.name === 'Anonymous' })));
         }
       } catch (err) {
         console.error('Failed to fetch testimonials:', err);
@@ -57,3 +57,31 @@ export async function fetchWithTimeout(
     clearTimeout(timeout);
   }
 }
+
+/**
+ * Fetches every page of a cursor-paginated list endpoint, following
+ * pagination.next_cursor until has_more is false, and returns all items.
+ * Throws if any page fails.
+ */
+export async function fetchAllPages<T>(
+  url: string,
+  options: RequestInit = {},
+  limit: number = 100
+): Promise<T[]> {
+  const items: T[] = [];
+  let cursor = '';
+  do {
+    const pageUrl = new URL(url);
+    pageUrl.searchParams.set('limit', limit.toString());
+    if (cursor) pageUrl.searchParams.set('cursor', cursor);
+
+    const res = await fetchWithTimeout(pageUrl.toString(), options);
+    if (!res.ok) {
+      throw new Error(`Request failed with status ${res.status}`);
+    }
+    const body = await res.json();
+    items.push(...(body.data || []));
+    cursor = body.pagination?.has_more ? body.pagination.next_cursor || '' : '';
+  } while (cursor);
+  return items;
+}
@@ -285,3 +285,25 @@ export const cancelPendingBooking = async (bookingId: string): Promise<void> =>
         // Best-effort cleanup — scheduler will catch anything we miss
     }
 };
+
+// Report that the Razorpay checkout opened for a pending booking, for funnel analytics
+export const reportCheckoutOpened = async (bookingId: string): Promise<void> => {
+    const apiUrl = getApiUrl();
+    if (!apiUrl) return;
+
+    try {
+        const { data: { session } } = await supabase.auth.getSession();
+        const token = session?.access_token;
+        if (!token) return;
+
+        const res = await fetchWithTimeout(`${apiUrl}/bookings/${bookingId}/checkout-opened`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${token}` },
+        });
+        if (!res.ok) {
+            logger.warn('Checkout opened report failed', { bookingId, status: res.status });
+        }
+    } catch {
+        // Best-effort analytics — never block the checkout
+    }
+};
@@ -49,20 +49,15 @@ export const INSIGHTS_DATA: Insight[] = [
   }
 ];
 
-import { getApiUrl, fetchWithTimeout } from './api';
+import { getApiUrl, fetchAllPages } from './api';
 
 // Simulation of an Async Database Fetch (Phase 1 Requirement)
 export const fetchInsights = async (): Promise<Insight[]> => {
   const apiUrl = getApiUrl();
   if (apiUrl) {
     try {
-      const res = await fetchWithTimeout(`${apiUrl}/insights`);
-      if (res.ok) {
-        const data = await res.json();
-        // Unwrap Go backend {success, data} wrapper
-        const insights = Array.isArray(data) ? data : (data.data || []);
-        if (insights.length > 0) return insights;
-      }
+      const insights = await fetchAllPages<Insight>(`${apiUrl}/insights`);
+      if (insights.length > 0) return insights;
     } catch (err) {
       console.warn('Failed to fetch insights from API, using fallback data.', err);
     }